@@ -0,0 +1,323 @@
+package rdf
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"github.com/0x51-dev/rdf/trig"
+	"strings"
+)
+
+// FromNQuads returns a dataset containing the quads of the given N-Quads document.
+func FromNQuads(doc nq.Document) *Dataset {
+	d := NewDataset()
+	for _, q := range doc {
+		var name Node
+		if q.GraphLabel != nil {
+			name = fromNTriplesNode(q.GraphLabel)
+		}
+		d.graph(name).add(fromNTriplesTriple(q.Triple))
+	}
+	return d
+}
+
+// FromNQuadsStar returns a dataset containing the quads of the given N-Quads-star document. Quoted triples and triple
+// terms are both represented by triple terms.
+func FromNQuadsStar(doc nqs.Document) *Dataset {
+	d := NewDataset()
+	for _, q := range doc {
+		var name Node
+		if q.GraphLabel != nil {
+			name = fromNTriplesNode(q.GraphLabel)
+		}
+		d.graph(name).add(fromNTriplesStarTriple(q.Triple))
+	}
+	return d
+}
+
+// FromNTriples returns a graph containing the triples of the given N-Triples document. Escape sequences are
+// replaced, literals without datatype get the xsd:string (or rdf:langString) datatype.
+func FromNTriples(doc nt.Document) *Graph {
+	g := NewGraph()
+	for _, t := range doc {
+		g.add(fromNTriplesTriple(t))
+	}
+	return g
+}
+
+// FromNTriplesStar returns a graph containing the triples of the given N-Triples-star document. Quoted triples and
+// triple terms are both represented by triple terms.
+func FromNTriplesStar(doc nts.Document) *Graph {
+	g := NewGraph()
+	for _, t := range doc {
+		g.add(fromNTriplesStarTriple(t))
+	}
+	return g
+}
+
+// FromTriG returns a dataset containing the quads of the given TriG document.
+func FromTriG(doc trig.Document) (*Dataset, error) {
+	quads, err := trig.EvaluateDocument(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	return FromNQuads(quads), nil
+}
+
+// ToNQuads returns the quads of the dataset as an N-Quads document. The quads of the default graph come first, followed
+// by the quads of the named graphs.
+func (d *Dataset) ToNQuads() (nq.Document, error) {
+	triples, err := d.Default.ToNTriples()
+	if err != nil {
+		return nil, err
+	}
+	var doc nq.Document
+	for _, t := range triples {
+		doc = append(doc, nq.NewQuadFromTriple(t, nil))
+	}
+	for _, name := range d.Names() {
+		label, err := toNTriplesNode(name)
+		if err != nil {
+			return nil, err
+		}
+		graphLabel, ok := label.(nt.Subject)
+		if !ok {
+			return nil, fmt.Errorf("graph name: not an IRI or blank node: %s", name.GetValue())
+		}
+		triples, err := d.Graph(name).ToNTriples()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range triples {
+			doc = append(doc, nq.NewQuadFromTriple(t, graphLabel))
+		}
+	}
+	return doc, nil
+}
+
+// ToNQuadsStar returns the quads of the dataset as an N-Quads-star document, see ToNTriplesStar.
+func (d *Dataset) ToNQuadsStar(m nt.Mode) (nqs.Document, error) {
+	triples, err := d.Default.ToNTriplesStar(m)
+	if err != nil {
+		return nil, err
+	}
+	var doc nqs.Document
+	for _, t := range triples {
+		doc = append(doc, nqs.Quad{Triple: t})
+	}
+	for _, name := range d.Names() {
+		label, err := toNTriplesNode(name)
+		if err != nil {
+			return nil, err
+		}
+		graphLabel, ok := label.(nt.Subject)
+		if !ok {
+			return nil, fmt.Errorf("graph name: not an IRI or blank node: %s", name.GetValue())
+		}
+		triples, err := d.Graph(name).ToNTriplesStar(m)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range triples {
+			doc = append(doc, nqs.Quad{Triple: t, GraphLabel: graphLabel})
+		}
+	}
+	return doc, nil
+}
+
+// ToNTriples returns the triples of the graph as an N-Triples document, in the order in which they were added. Returns
+// an error if the graph contains generalized triples, e.g. literals as subjects or blank nodes as predicates.
+func (g *Graph) ToNTriples() (nt.Document, error) {
+	var doc nt.Document
+	for _, t := range g.Triples() {
+		s, err := toNTriplesNode(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+		subject, ok := s.(nt.Subject)
+		if !ok {
+			return nil, fmt.Errorf("subject: not an IRI or blank node: %s", t.Subject.GetValue())
+		}
+		predicate, ok := t.Predicate.(*IRIReference)
+		if !ok {
+			return nil, fmt.Errorf("predicate: not an IRI: %s", t.Predicate.GetValue())
+		}
+		object, err := toNTriplesNode(t.Object)
+		if err != nil {
+			return nil, err
+		}
+		doc = append(doc, nt.Triple{
+			Subject:   subject,
+			Predicate: nt.IRIReference(predicate.Value),
+			Object:    object,
+		})
+	}
+	return doc, nil
+}
+
+// ToNTriplesStar returns the triples of the graph as an N-Triples-star document, in the order in which they were added.
+// Triple terms are written as quoted triples, or as triple terms if the given version of RDF is RDF 1.2.
+func (g *Graph) ToNTriplesStar(m nt.Mode) (nts.Document, error) {
+	var doc nts.Document
+	for _, t := range g.Triples() {
+		triple, err := toNTriplesStarTriple(t, m)
+		if err != nil {
+			return nil, err
+		}
+		doc = append(doc, triple)
+	}
+	return doc, nil
+}
+
+func fromNTriplesNode(n any) Node {
+	switch n := n.(type) {
+	case nt.BlankNode:
+		return &BlankNode{Attribute: "_:" + string(n)}
+	case *nt.BlankNode:
+		return &BlankNode{Attribute: "_:" + string(*n)}
+	case nt.IRIReference:
+		return &IRIReference{Value: escape.Unescape(string(n))}
+	case *nt.IRIReference:
+		return &IRIReference{Value: escape.Unescape(string(*n))}
+	case nt.Literal:
+		return fromNTriplesLiteral(n)
+	case *nt.Literal:
+		return fromNTriplesLiteral(*n)
+	default:
+		panic(fmt.Sprintf("unknown node type %T", n))
+	}
+}
+
+func fromNTriplesLiteral(l nt.Literal) *Literal {
+	literal := &Literal{
+		Value:    escape.Unescape(l.Value),
+		Datatype: XSDString,
+	}
+	switch {
+	case l.Direction != "":
+		literal.Datatype = XSDNSDirString
+		literal.Language = l.Language
+		literal.Direction = l.Direction
+	case l.Language != "":
+		literal.Datatype = XSDNSString
+		literal.Language = l.Language
+	case l.Reference != nil:
+		literal.Datatype = DataType(escape.Unescape(string(*l.Reference)))
+	}
+	return literal
+}
+
+func fromNTriplesTriple(t nt.Triple) *Triple {
+	return &Triple{
+		Subject:   fromNTriplesNode(t.Subject),
+		Predicate: fromNTriplesNode(t.Predicate),
+		Object:    fromNTriplesNode(t.Object),
+	}
+}
+
+func fromNTriplesStarNode(n any) Node {
+	switch n := n.(type) {
+	case nts.BlankNode:
+		return fromNTriplesNode(nt.BlankNode(n))
+	case *nts.BlankNode:
+		return fromNTriplesNode(nt.BlankNode(*n))
+	case nts.IRIReference:
+		return fromNTriplesNode(nt.IRIReference(n))
+	case *nts.IRIReference:
+		return fromNTriplesNode(nt.IRIReference(*n))
+	case nts.Literal:
+		return fromNTriplesLiteral(nt.Literal(n))
+	case *nts.Literal:
+		return fromNTriplesLiteral(nt.Literal(*n))
+	case nts.QuotedTriple:
+		return &TripleTerm{Triple: *fromNTriplesStarTriple(n.Triple)}
+	case *nts.QuotedTriple:
+		return &TripleTerm{Triple: *fromNTriplesStarTriple(n.Triple)}
+	case nts.TripleTerm:
+		return &TripleTerm{Triple: *fromNTriplesStarTriple(n.Triple)}
+	case *nts.TripleTerm:
+		return &TripleTerm{Triple: *fromNTriplesStarTriple(n.Triple)}
+	default:
+		return fromNTriplesNode(n)
+	}
+}
+
+func fromNTriplesStarTriple(t nts.Triple) *Triple {
+	return &Triple{
+		Subject:   fromNTriplesStarNode(t.Subject),
+		Predicate: fromNTriplesNode(t.Predicate),
+		Object:    fromNTriplesStarNode(t.Object),
+	}
+}
+
+func toNTriplesNode(n Node) (nt.Object, error) {
+	switch n := n.(type) {
+	case *BlankNode:
+		return nt.BlankNode(strings.TrimPrefix(n.Attribute, "_:")), nil
+	case *IRIReference:
+		return nt.IRIReference(n.Value), nil
+	case *Literal:
+		literal := nt.Literal{Value: escape.String(n.Value)}
+		switch {
+		case n.Language != "":
+			literal.Language = n.Language
+			literal.Direction = n.Direction
+		case n.Datatype != "" && n.Datatype != XSDString:
+			datatype := nt.IRIReference(n.Datatype)
+			literal.Reference = &datatype
+		}
+		return literal, nil
+	default:
+		return nil, fmt.Errorf("unknown node type %T", n)
+	}
+}
+
+func toNTriplesStarNode(n Node, m nt.Mode) (nts.Object, error) {
+	if t, ok := n.(*TripleTerm); ok {
+		triple, err := toNTriplesStarTriple(&t.Triple, m)
+		if err != nil {
+			return nil, err
+		}
+		if m == nt.RDF12 {
+			return nts.TripleTerm{Triple: triple}, nil
+		}
+		return nts.QuotedTriple{Triple: triple}, nil
+	}
+	o, err := toNTriplesNode(n)
+	if err != nil {
+		return nil, err
+	}
+	switch o := o.(type) {
+	case nt.BlankNode:
+		return nts.BlankNode(o), nil
+	case nt.IRIReference:
+		return nts.IRIReference(o), nil
+	case nt.Literal:
+		return nts.Literal(o), nil
+	default:
+		return nil, fmt.Errorf("unknown node type %T", o)
+	}
+}
+
+func toNTriplesStarTriple(t *Triple, m nt.Mode) (nts.Triple, error) {
+	s, err := toNTriplesStarNode(t.Subject, m)
+	if err != nil {
+		return nts.Triple{}, err
+	}
+	subject, ok := s.(nts.Subject)
+	if !ok {
+		return nts.Triple{}, fmt.Errorf("subject: not an IRI, blank node or quoted triple: %s", t.Subject.GetValue())
+	}
+	predicate, ok := t.Predicate.(*IRIReference)
+	if !ok {
+		return nts.Triple{}, fmt.Errorf("predicate: not an IRI: %s", t.Predicate.GetValue())
+	}
+	object, err := toNTriplesStarNode(t.Object, m)
+	if err != nil {
+		return nts.Triple{}, err
+	}
+	return nts.Triple{Subject: subject, Predicate: nt.IRIReference(predicate.Value), Object: object}, nil
+}
@@ -0,0 +1,173 @@
+package rdf
+
+import (
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"github.com/0x51-dev/rdf/trig"
+	"testing"
+)
+
+func TestFromNTriples(t *testing.T) {
+	doc, err := nt.ParseDocument(`_:alice <http://xmlns.com/foaf/0.1/name> "Alice" .
+_:alice <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:alice <http://xmlns.com/foaf/0.1/title> "Dr.é"@en .
+_:alice <http://xmlns.com/foaf/0.1/knows> <http://example.org/bob> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := FromNTriples(doc)
+	if g.Len() != 4 {
+		t.Fatalf("expected 4 triples, got %d", g.Len())
+	}
+	alice := &BlankNode{Attribute: "_:alice"}
+	for _, o := range []Node{
+		&Literal{Value: "Alice", Datatype: XSDString},
+		&Literal{Value: "42", Datatype: XSDInteger},
+		&Literal{Value: "Dr.é", Datatype: XSDNSString, Language: "en"},
+		&IRIReference{Value: "http://example.org/bob"},
+	} {
+		if g.Find(alice, nil, o) == nil {
+			t.Errorf("expected triple with object %s", o.GetValue())
+		}
+	}
+
+	doc2, err := g.ToNTriples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Equal(doc2) {
+		t.Error(doc, doc2)
+	}
+
+	g.Add(alice, alice, alice)
+	if _, err := g.ToNTriples(); err == nil {
+		t.Error("expected error for generalized triple")
+	}
+}
+
+func TestFromNQuads(t *testing.T) {
+	doc, err := nq.ParseDocument(`<http://example.org/s> <http://example.org/p> "o" .
+<http://example.org/s> <http://example.org/p> _:o <http://example.org/g> .
+_:o <http://example.org/p> "o" _:g .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := FromNQuads(doc)
+	if d.Default.Len() != 1 {
+		t.Errorf("expected 1 triple in the default graph, got %d", d.Default.Len())
+	}
+	if len(d.Names()) != 2 {
+		t.Fatalf("expected 2 named graphs, got %d", len(d.Names()))
+	}
+	if g := d.Graph(&IRIReference{Value: "http://example.org/g"}); g == nil || g.Len() != 1 {
+		t.Error("expected named graph")
+	}
+	if g := d.Graph(&BlankNode{Attribute: "_:g"}); g == nil || g.Len() != 1 {
+		t.Error("expected blank node graph")
+	}
+
+	doc2, err := d.ToNQuads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Equal(doc2) {
+		t.Error(doc, doc2)
+	}
+}
+
+func TestFromNQuadsStar(t *testing.T) {
+	doc, err := nqs.ParseDocument(`<< _:a <http://example.org/p> "o" >> <http://example.org/q> _:a .
+<http://example.org/s> <http://example.org/p> << _:a <http://example.org/p> "o" >> <http://example.org/g> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := FromNQuadsStar(doc)
+	if d.Len() != 2 {
+		t.Fatalf("expected 2 quads, got %d", d.Len())
+	}
+	tt := &TripleTerm{Triple{
+		Subject:   &BlankNode{Attribute: "_:a"},
+		Predicate: &IRIReference{Value: "http://example.org/p"},
+		Object:    &Literal{Value: "o", Datatype: XSDString},
+	}}
+	if q := d.Find(nil, nil, tt, nil); q == nil || q.Graph == nil {
+		t.Error("expected quad with triple term in named graph")
+	}
+
+	doc2, err := d.ToNQuadsStar(nt.RDFStar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Equal(doc2) {
+		t.Error(doc, doc2)
+	}
+}
+
+func TestFromNTriplesStar(t *testing.T) {
+	doc, err := nts.ParseDocument(`<< <http://example.org/alice> <http://example.org/knows> <http://example.org/bob> >> <http://example.org/source> _:s .
+_:s <http://example.org/says> << _:s <http://example.org/says> << <http://example.org/a> <http://example.org/b> "c"@en >> >> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := FromNTriplesStar(doc)
+	if g.Len() != 2 {
+		t.Fatalf("expected 2 triples, got %d", g.Len())
+	}
+	if g.Find(&TripleTerm{Triple{Subject: &IRIReference{Value: "http://example.org/alice"}}}, nil, nil) == nil {
+		t.Error("expected triple with quoted subject")
+	}
+
+	doc2, err := g.ToNTriplesStar(nt.RDFStar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Equal(doc2) {
+		t.Error(doc, doc2)
+	}
+	if _, err := g.ToNTriples(); err == nil {
+		t.Error("expected error for triple terms in N-Triples")
+	}
+
+	// RDF 1.2 only supports triple terms as objects.
+	if _, err := g.ToNTriplesStar(nt.RDF12); err == nil {
+		t.Error("expected error for triple term as subject")
+	}
+	g.Remove(g.Find(&TripleTerm{}, nil, nil))
+	doc3, err := g.ToNTriplesStar(nt.RDF12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc3[0].Object.(nts.TripleTerm); !ok {
+		t.Errorf("expected triple term, got %T", doc3[0].Object)
+	}
+}
+
+func TestFromTriG(t *testing.T) {
+	doc, err := trig.ParseDocument(`@prefix ex: <http://example.org/> .
+ex:s ex:p ex:o .
+ex:g { ex:s ex:p [ ex:q "o" ] . }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := FromTriG(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Default.Len() != 1 {
+		t.Errorf("expected 1 triple in the default graph, got %d", d.Default.Len())
+	}
+	g := d.Graph(&IRIReference{Value: "http://example.org/g"})
+	if g == nil || g.Len() != 2 {
+		t.Fatal("expected named graph with 2 triples")
+	}
+	if g.Find(nil, &IRIReference{Value: "http://example.org/q"}, &Literal{Value: "o", Datatype: XSDString}) == nil {
+		t.Error("expected triple")
+	}
+}
@@ -0,0 +1,261 @@
+package rdf
+
+// Dataset is a collection of graphs, consisting of a default graph and zero or more named graphs. Graphs are named by
+// an IRI or a blank node.
+type Dataset struct {
+	// Default is the default graph, which has no name.
+	Default *Graph
+
+	// names contains the names of the named graphs, in the order in which they were added.
+	names  []Node
+	graphs map[termKey]*Graph
+}
+
+// NewDataset returns a new dataset with an empty default graph.
+func NewDataset() *Dataset {
+	return &Dataset{
+		Default: NewGraph(),
+		graphs:  make(map[termKey]*Graph),
+	}
+}
+
+// Add adds the triple to the graph with the given name, nil adds it to the default graph. The graph is created if it
+// does not exist yet.
+func (d *Dataset) Add(s, p, o, g Node) {
+	d.graph(g).Add(s, p, o)
+}
+
+// AddGraph adds all the triples of the source graph to the destination graph, which is created if it does not exist
+// yet. Nil refers to the default graph.
+func (d *Dataset) AddGraph(src, dst Node) {
+	from := d.Graph(src)
+	if from == nil || sameName(src, dst) {
+		return
+	}
+	to := d.graph(dst)
+	for _, t := range from.Triples() {
+		to.add(t)
+	}
+}
+
+// Contains returns true if the graph with the given name contains the triple. Nil refers to the default graph.
+func (d *Dataset) Contains(s, p, o, g Node) bool {
+	graph := d.Graph(g)
+	return graph != nil && graph.Contains(s, p, o)
+}
+
+// CopyGraph replaces the triples of the destination graph with the triples of the source graph. Nil refers to the
+// default graph.
+func (d *Dataset) CopyGraph(src, dst Node) {
+	if sameName(src, dst) {
+		return
+	}
+	d.DropGraph(dst)
+	d.AddGraph(src, dst)
+}
+
+// CreateGraph returns the graph with the given name, an empty graph is created if it does not exist yet.
+func (d *Dataset) CreateGraph(name Node) *Graph {
+	return d.graph(name)
+}
+
+// DropGraph removes the graph with the given name from the dataset. Nil clears the default graph, which is never
+// removed.
+func (d *Dataset) DropGraph(name Node) {
+	if name == nil {
+		d.Default = NewGraph()
+		return
+	}
+	k := newTermKey(name)
+	if _, ok := d.graphs[k]; !ok {
+		return
+	}
+	delete(d.graphs, k)
+	for i, n := range d.names {
+		if newTermKey(n) == k {
+			d.names = append(d.names[:i], d.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Find returns the first quad matching the given pattern, nil nodes match any node. A nil graph matches all graphs,
+// including the default graph; use Default.Find to only match the default graph.
+func (d *Dataset) Find(s, p, o, g Node) *Quad {
+	for _, name := range d.match(g) {
+		if t := d.Graph(name).Find(s, p, o); t != nil {
+			return &Quad{Triple: t, Graph: name}
+		}
+	}
+	return nil
+}
+
+// FindAll returns all quads matching the given pattern, nil nodes match any node. A nil graph matches all graphs,
+// including the default graph; use Default.FindAll to only match the default graph. Quads of the default graph come
+// first, followed by those of the named graphs in the order in which they were added.
+func (d *Dataset) FindAll(s, p, o, g Node) []*Quad {
+	var quads []*Quad
+	for _, name := range d.match(g) {
+		for _, t := range d.Graph(name).FindAll(s, p, o) {
+			quads = append(quads, &Quad{Triple: t, Graph: name})
+		}
+	}
+	return quads
+}
+
+// Graph returns the graph with the given name, nil returns the default graph. Returns nil if the dataset contains no
+// graph with the given name.
+func (d *Dataset) Graph(name Node) *Graph {
+	if name == nil {
+		return d.Default
+	}
+	return d.graphs[newTermKey(name)]
+}
+
+// Len returns the number of quads in the dataset.
+func (d *Dataset) Len() int {
+	var n int
+	if d.Default != nil {
+		n += d.Default.Len()
+	}
+	for _, g := range d.graphs {
+		n += g.Len()
+	}
+	return n
+}
+
+// MoveGraph replaces the triples of the destination graph with the triples of the source graph, after which the source
+// graph is removed. Nil refers to the default graph.
+func (d *Dataset) MoveGraph(src, dst Node) {
+	if sameName(src, dst) {
+		return
+	}
+	d.CopyGraph(src, dst)
+	d.DropGraph(src)
+}
+
+// Names returns the names of all the named graphs in the dataset, in the order in which they were added.
+func (d *Dataset) Names() []Node {
+	return append([]Node(nil), d.names...)
+}
+
+// Union returns a view of the union (merge) of the graphs with the given names, nil refers to the default graph. If no
+// names are given, the view contains the default graph and all named graphs. Changes to the dataset are reflected in
+// the view.
+func (d *Dataset) Union(names ...Node) *Union {
+	return &Union{dataset: d, names: names, all: len(names) == 0}
+}
+
+// graph returns the graph with the given name, a new graph is created if it does not exist yet.
+func (d *Dataset) graph(name Node) *Graph {
+	if name == nil {
+		if d.Default == nil {
+			d.Default = NewGraph()
+		}
+		return d.Default
+	}
+	if d.graphs == nil {
+		d.graphs = make(map[termKey]*Graph)
+	}
+	k := newTermKey(name)
+	g, ok := d.graphs[k]
+	if !ok {
+		g = NewGraph()
+		d.names = append(d.names, name)
+		d.graphs[k] = g
+	}
+	return g
+}
+
+// match returns the names of the (existing) graphs matching the given graph pattern.
+func (d *Dataset) match(g Node) []Node {
+	if g != nil {
+		if d.Graph(g) == nil {
+			return nil
+		}
+		return []Node{g}
+	}
+	var names []Node
+	if d.Default != nil {
+		names = append(names, nil)
+	}
+	return append(names, d.names...)
+}
+
+// Quad is a triple within a graph of a dataset. The graph is nil for triples of the default graph.
+type Quad struct {
+	*Triple
+	Graph Node
+}
+
+// Union is a read-only view of the merge of multiple graphs of a dataset. Triples that occur in multiple graphs are
+// only returned once.
+type Union struct {
+	dataset *Dataset
+	names   []Node
+	all     bool
+}
+
+// Contains returns true if one of the graphs contains the given triple.
+func (u *Union) Contains(s, p, o Node) bool {
+	for _, g := range u.graphs() {
+		if g.Contains(s, p, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the first triple matching the given pattern, nil nodes match any node.
+func (u *Union) Find(s, p, o Node) *Triple {
+	for _, g := range u.graphs() {
+		if t := g.Find(s, p, o); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// FindAll returns all (distinct) triples matching the given pattern, nil nodes match any node.
+func (u *Union) FindAll(s, p, o Node) []*Triple {
+	var triples []*Triple
+	seen := make(map[[3]termKey]bool)
+	for _, g := range u.graphs() {
+		for _, t := range g.FindAll(s, p, o) {
+			k := [3]termKey{newTermKey(t.Subject), newTermKey(t.Predicate), newTermKey(t.Object)}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			triples = append(triples, t)
+		}
+	}
+	return triples
+}
+
+// Len returns the number of distinct triples in the union.
+func (u *Union) Len() int {
+	return len(u.FindAll(nil, nil, nil))
+}
+
+func (u *Union) graphs() []*Graph {
+	names := u.names
+	if u.all {
+		names = u.dataset.match(nil)
+	}
+	var graphs []*Graph
+	for _, name := range names {
+		if g := u.dataset.Graph(name); g != nil {
+			graphs = append(graphs, g)
+		}
+	}
+	return graphs
+}
+
+// sameName returns true if both names refer to the same graph.
+func sameName(a, b Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return newTermKey(a) == newTermKey(b)
+}
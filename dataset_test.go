@@ -0,0 +1,76 @@
+package rdf
+
+import "testing"
+
+func TestDataset(t *testing.T) {
+	var (
+		s  = &IRIReference{"https://example.org/s"}
+		p  = &IRIReference{"https://example.org/p"}
+		o  = &Literal{Value: "o", Datatype: XSDString}
+		g1 = &IRIReference{"https://example.org/g1"}
+		g2 = &BlankNode{"_:g2"}
+	)
+
+	d := NewDataset()
+	d.Add(s, p, o, nil)
+	d.Add(s, p, o, g1)
+	d.Add(s, p, s, g1)
+	d.Add(s, p, o, g2)
+	if d.Len() != 4 {
+		t.Fatalf("expected 4 quads, got %d", d.Len())
+	}
+	if !d.Contains(s, p, s, &IRIReference{"https://example.org/g1"}) || d.Contains(s, p, s, nil) {
+		t.Error("unexpected contains")
+	}
+
+	// Graph wildcard.
+	if n := len(d.FindAll(s, p, o, nil)); n != 3 {
+		t.Errorf("expected 3 quads, got %d", n)
+	}
+	if n := len(d.FindAll(nil, nil, nil, g1)); n != 2 {
+		t.Errorf("expected 2 quads, got %d", n)
+	}
+	if q := d.Find(nil, nil, nil, &BlankNode{"_:g2"}); q == nil || !q.Graph.Equal(g2) {
+		t.Error("expected quad in blank node graph")
+	}
+	if q := d.Find(nil, nil, s, nil); q == nil || !q.Graph.Equal(g1) {
+		t.Error("expected quad in named graph")
+	}
+	if q := d.Find(nil, nil, nil, &IRIReference{"https://example.org/g3"}); q != nil {
+		t.Error("expected no quad")
+	}
+
+	// Union of all graphs.
+	u := d.Union()
+	if u.Len() != 2 {
+		t.Errorf("expected 2 distinct triples, got %d", u.Len())
+	}
+	if u.Contains(s, p, s) != true {
+		t.Error("expected triple in union")
+	}
+	if n := d.Union(nil, g2).Len(); n != 1 {
+		t.Errorf("expected 1 distinct triple, got %d", n)
+	}
+
+	// Graph management.
+	d.CopyGraph(g1, nil)
+	if d.Default.Len() != 2 || d.Graph(g1).Len() != 2 {
+		t.Error("expected copied graph")
+	}
+	d.MoveGraph(g1, g2)
+	if d.Graph(g1) != nil || d.Graph(g2).Len() != 2 || len(d.Names()) != 1 {
+		t.Error("expected moved graph")
+	}
+	d.AddGraph(nil, g1)
+	if d.Graph(g1).Len() != 2 || len(d.Names()) != 2 {
+		t.Error("expected added graph")
+	}
+	d.DropGraph(g2)
+	d.DropGraph(nil)
+	if d.Graph(g2) != nil || d.Default.Len() != 0 || d.Len() != 2 {
+		t.Error("expected dropped graphs")
+	}
+	if g := d.CreateGraph(g2); g == nil || g.Len() != 0 || len(d.Names()) != 2 {
+		t.Error("expected empty graph")
+	}
+}
@@ -21,6 +21,8 @@ const (
 
 	XSDNS       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
 	XSDNSString = XSDNS + "langString"
+	// XSDNSDirString is the datatype of language-tagged strings with a base direction (RDF 1.2).
+	XSDNSDirString = XSDNS + "dirLangString"
 )
 
 // NativeType returns the native type of the given value.
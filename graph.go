@@ -1,61 +1,185 @@
 package rdf
 
+import (
+	"fmt"
+	"sort"
+)
+
+// Graph is an in-memory set of triples. Nodes are interned and triples are indexed by subject, predicate and object
+// (SPO, POS and OSP), so that any pattern can be matched without scanning the whole graph. Nodes are matched by value,
+// not by identity. Triple terms can be used as (nested) patterns, e.g. a triple term with a nil predicate and object
+// matches all triple terms with the given subject.
 type Graph struct {
-	triples []*Triple
+	terms *terms
+	// seq is the sequence number of the next triple, used to return triples in insertion order.
+	seq     uint64
+	triples map[[3]int]*entry
+	spo     index
+	pos     index
+	osp     index
 }
 
+// NewGraph returns a new graph containing the given triples. The zero value of Graph is an empty graph.
 func NewGraph(ts ...*Triple) *Graph {
-	return &Graph{triples: ts}
+	g := new(Graph)
+	for _, t := range ts {
+		g.add(t)
+	}
+	return g
 }
 
+// Add adds the triple to the graph, if it does not already contain it.
 func (g *Graph) Add(s, p, o Node) {
-	t := &Triple{s, p, o}
-	g.triples = append(g.triples, t)
+	g.add(&Triple{s, p, o})
+}
+
+// Contains returns true if the graph contains the given triple.
+func (g *Graph) Contains(s, p, o Node) bool {
+	k, ok := g.lookup(s, p, o)
+	if !ok {
+		return false
+	}
+	_, ok = g.triples[k]
+	return ok
 }
 
-// Find returns the first triple matching the given pattern.
+// Find returns the first triple matching the given pattern, nil nodes match any node. Triples are ordered by the
+// order in which they were added to the graph.
 func (g *Graph) Find(s, p, o Node) *Triple {
-	for t := range g.iter() {
-		if (s == nil || t.Subject == s) &&
-			(p == nil || t.Predicate == p) &&
-			(o == nil || t.Object == o) {
-			return t
+	var first *entry
+	g.match(s, p, o, func(e *entry) {
+		if first == nil || e.seq < first.seq {
+			first = e
 		}
+	})
+	if first == nil {
+		return nil
 	}
-	return nil
+	return first.triple
 }
 
-// FindAll returns all triples matching the given pattern.
+// FindAll returns all triples matching the given pattern, nil nodes match any node. Triples are ordered by the order in
+// which they were added to the graph.
 func (g *Graph) FindAll(s, p, o Node) []*Triple {
-	var triples []*Triple
-	for t := range g.iter() {
-		if (s == nil || t.Subject == s) &&
-			(p == nil || t.Predicate == p) &&
-			(o == nil || t.Object == o) {
-			triples = append(triples, t)
+	var entries []*entry
+	g.match(s, p, o, func(e *entry) {
+		entries = append(entries, e)
+	})
+	return sortEntries(entries)
+}
+
+// Len returns the number of triples in the graph.
+func (g *Graph) Len() int {
+	return len(g.triples)
+}
+
+// Remove removes the triple from the graph, if it contains it.
+func (g *Graph) Remove(t *Triple) {
+	k, ok := g.lookup(t.Subject, t.Predicate, t.Object)
+	if !ok {
+		return
+	}
+	if _, ok := g.triples[k]; !ok {
+		return
+	}
+	delete(g.triples, k)
+	g.spo.remove(k[0], k[1], k[2])
+	g.pos.remove(k[1], k[2], k[0])
+	g.osp.remove(k[2], k[0], k[1])
+	for _, id := range k {
+		g.terms.release(id)
+	}
+}
+
+// Triples returns all triples of the graph, in the order in which they were added.
+func (g *Graph) Triples() []*Triple {
+	return g.FindAll(nil, nil, nil)
+}
+
+func (g *Graph) add(t *Triple) {
+	if g.terms == nil {
+		g.terms = newTerms()
+		g.triples = make(map[[3]int]*entry)
+		g.spo, g.pos, g.osp = make(index), make(index), make(index)
+	}
+	k := [3]int{g.terms.intern(t.Subject), g.terms.intern(t.Predicate), g.terms.intern(t.Object)}
+	if _, ok := g.triples[k]; ok {
+		for _, id := range k {
+			g.terms.release(id)
 		}
+		return
 	}
-	return triples
+	e := &entry{triple: t, seq: g.seq}
+	g.seq++
+	g.triples[k] = e
+	g.spo.add(k[0], k[1], k[2], e)
+	g.pos.add(k[1], k[2], k[0], e)
+	g.osp.add(k[2], k[0], k[1], e)
 }
 
-func (g *Graph) Remove(t *Triple) {
-	for i, other := range g.triples {
-		if t.Equal(other) {
-			g.triples = append(g.triples[:i], g.triples[i+1:]...)
-			return
+// lookup returns the identifiers of the given nodes, returns false if one of the nodes is not part of the graph.
+func (g *Graph) lookup(s, p, o Node) ([3]int, bool) {
+	var k [3]int
+	for i, n := range []Node{s, p, o} {
+		id, ok := g.terms.id(n)
+		if !ok {
+			return k, false
 		}
+		k[i] = id
 	}
+	return k, true
 }
 
-func (g *Graph) iter() chan *Triple {
-	ch := make(chan *Triple)
-	go func() {
-		for _, t := range g.triples {
-			ch <- t
+// match calls f for every triple that matches the given pattern, in no particular order.
+func (g *Graph) match(s, p, o Node, f func(e *entry)) {
+	var (
+		ids      [3]int
+		patterns [3]Node
+	)
+	for i, n := range []Node{s, p, o} {
+		if n == nil {
+			ids[i] = -1
+			continue
+		}
+		if tt, ok := n.(*TripleTerm); ok && tt.isPattern() {
+			// Triple term patterns can not be looked up, the matching triples are filtered instead.
+			ids[i], patterns[i] = -1, n
+			continue
+		}
+		id, ok := g.terms.id(n)
+		if !ok {
+			return
+		}
+		ids[i] = id
+	}
+	if patterns != [3]Node{} {
+		next := f
+		f = func(e *entry) {
+			t := e.triple
+			if matchNode(patterns[0], t.Subject) && matchNode(patterns[1], t.Predicate) &&
+				matchNode(patterns[2], t.Object) {
+				next(e)
+			}
+		}
+	}
+	switch s, p, o := ids[0], ids[1], ids[2]; {
+	case 0 <= s && 0 <= p && 0 <= o:
+		if e, ok := g.triples[ids]; ok {
+			f(e)
+		}
+	case 0 <= s && 0 <= o:
+		g.osp.match(o, s, f)
+	case 0 <= s:
+		g.spo.match(s, p, f)
+	case 0 <= p:
+		g.pos.match(p, o, f)
+	case 0 <= o:
+		g.osp.match(o, -1, f)
+	default:
+		for _, e := range g.triples {
+			f(e)
 		}
-		close(ch)
-	}()
-	return ch
+	}
 }
 
 type Triple struct {
@@ -71,3 +195,159 @@ func NewTriple(s, p, o Node) *Triple {
 func (t *Triple) Equal(other *Triple) bool {
 	return t.Subject.Equal(other.Subject) && t.Predicate.Equal(other.Predicate) && t.Object.Equal(other.Object)
 }
+
+// matchNode returns true if the node matches the pattern, nil patterns match any node.
+func matchNode(pattern, n Node) bool {
+	if pattern == nil {
+		return true
+	}
+	if p, ok := pattern.(*TripleTerm); ok && p.isPattern() {
+		t, ok := n.(*TripleTerm)
+		return ok && matchNode(p.Subject, t.Subject) && matchNode(p.Predicate, t.Predicate) &&
+			matchNode(p.Object, t.Object)
+	}
+	return newTermKey(pattern) == newTermKey(n)
+}
+
+type entry struct {
+	triple *Triple
+	seq    uint64
+}
+
+func sortEntries(entries []*entry) []*Triple {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	triples := make([]*Triple, len(entries))
+	for i, e := range entries {
+		triples[i] = e.triple
+	}
+	return triples
+}
+
+// index is a three level index of term identifiers, e.g. subject -> predicate -> object.
+type index map[int]map[int]map[int]*entry
+
+func (i index) add(a, b, c int, e *entry) {
+	ab, ok := i[a]
+	if !ok {
+		ab = make(map[int]map[int]*entry)
+		i[a] = ab
+	}
+	abc, ok := ab[b]
+	if !ok {
+		abc = make(map[int]*entry)
+		ab[b] = abc
+	}
+	abc[c] = e
+}
+
+// match calls f for every entry of the first identifier, restricted to the second identifier if not negative.
+func (i index) match(a, b int, f func(e *entry)) {
+	if 0 <= b {
+		for _, e := range i[a][b] {
+			f(e)
+		}
+		return
+	}
+	for _, bc := range i[a] {
+		for _, e := range bc {
+			f(e)
+		}
+	}
+}
+
+func (i index) remove(a, b, c int) {
+	delete(i[a][b], c)
+	if len(i[a][b]) == 0 {
+		delete(i[a], b)
+	}
+	if len(i[a]) == 0 {
+		delete(i, a)
+	}
+}
+
+// termKey identifies a node by value.
+type termKey struct {
+	kind      string
+	value     string
+	datatype  DataType
+	language  string
+	direction string
+}
+
+func newTermKey(n Node) termKey {
+	switch n := n.(type) {
+	case *BlankNode:
+		return termKey{kind: "b", value: n.Attribute}
+	case *IRIReference:
+		return termKey{kind: "i", value: n.Value}
+	case *Literal:
+		return termKey{kind: "l", value: n.Value, datatype: n.Datatype, language: n.Language, direction: n.Direction}
+	case *TripleTerm:
+		// The keys of the components are quoted, so the value is unambiguous.
+		k := [3]termKey{newTermKey(n.Subject), newTermKey(n.Predicate), newTermKey(n.Object)}
+		return termKey{kind: "t", value: fmt.Sprintf("%#v", k)}
+	case nil:
+		return termKey{}
+	default:
+		return termKey{kind: fmt.Sprintf("%T", n), value: n.GetValue()}
+	}
+}
+
+// terms interns nodes, equal nodes share the same identifier. Identifiers are reference counted and released once no
+// triple uses them anymore.
+type terms struct {
+	ids   map[termKey]int
+	refs  map[int]int
+	keys  map[int]termKey
+	next  int
+	freed []int
+}
+
+func newTerms() *terms {
+	return &terms{
+		ids:  make(map[termKey]int),
+		refs: make(map[int]int),
+		keys: make(map[int]termKey),
+	}
+}
+
+// id returns the identifier of the node, if interned.
+func (t *terms) id(n Node) (int, bool) {
+	if t == nil || n == nil {
+		return 0, false
+	}
+	id, ok := t.ids[newTermKey(n)]
+	return id, ok
+}
+
+// intern returns the identifier of the node and increments its reference count.
+func (t *terms) intern(n Node) int {
+	k := newTermKey(n)
+	id, ok := t.ids[k]
+	if !ok {
+		if l := len(t.freed); 0 < l {
+			id, t.freed = t.freed[l-1], t.freed[:l-1]
+		} else {
+			id = t.next
+			t.next++
+		}
+		t.ids[k] = id
+		t.keys[id] = k
+	}
+	t.refs[id]++
+	return id
+}
+
+// release decrements the reference count of the identifier.
+func (t *terms) release(id int) {
+	t.refs[id]--
+	if 0 < t.refs[id] {
+		return
+	}
+	delete(t.ids, t.keys[id])
+	delete(t.keys, id)
+	delete(t.refs, id)
+	t.freed = append(t.freed, id)
+}
@@ -35,3 +35,89 @@ func TestGraph(t *testing.T) {
 		t.Error("expected 2 triples")
 	}
 }
+
+func TestGraph_indexed(t *testing.T) {
+	var g Graph
+	if g.Find(nil, nil, nil) != nil || g.Contains(&BlankNode{"a"}, &BlankNode{"b"}, &BlankNode{"c"}) {
+		t.Error("expected empty graph")
+	}
+
+	p := &IRIReference{"https://example.org/p"}
+	g.Add(&IRIReference{"https://example.org/s"}, p, &Literal{Value: "o", Datatype: XSDString})
+	g.Add(&IRIReference{"https://example.org/s"}, p, &Literal{Value: "o", Datatype: XSDString})
+	g.Add(&BlankNode{"b"}, p, &IRIReference{"https://example.org/s"})
+	if g.Len() != 2 {
+		t.Errorf("expected 2 triples, got %d", g.Len())
+	}
+
+	// Nodes are matched by value.
+	s := &IRIReference{"https://example.org/s"}
+	if !g.Contains(s, &IRIReference{"https://example.org/p"}, &Literal{Value: "o", Datatype: XSDString}) {
+		t.Error("expected triple")
+	}
+	if g.Contains(s, p, &Literal{Value: "o", Datatype: XSDInteger}) {
+		t.Error("unexpected triple")
+	}
+	for _, test := range []struct {
+		s, p, o Node
+		n       int
+	}{
+		{s, nil, nil, 1},
+		{nil, p, nil, 2},
+		{nil, nil, s, 1},
+		{s, nil, &Literal{Value: "o", Datatype: XSDString}, 1},
+		{nil, p, s, 1},
+		{&BlankNode{"b"}, p, nil, 1},
+		{&BlankNode{"c"}, nil, nil, 0},
+	} {
+		if n := len(g.FindAll(test.s, test.p, test.o)); n != test.n {
+			t.Errorf("%v %v %v: expected %d triples, got %d", test.s, test.p, test.o, test.n, n)
+		}
+	}
+
+	g.Remove(NewTriple(&BlankNode{"b"}, p, s))
+	if g.Len() != 1 || g.Contains(&BlankNode{"b"}, p, s) {
+		t.Error("expected triple to be removed")
+	}
+	if len(g.FindAll(nil, nil, s)) != 0 {
+		t.Error("expected no triples")
+	}
+	g.Remove(NewTriple(s, p, &Literal{Value: "o", Datatype: XSDString}))
+	if g.Len() != 0 || len(g.Triples()) != 0 {
+		t.Error("expected empty graph")
+	}
+}
+
+func TestGraph_tripleTerm(t *testing.T) {
+	var (
+		alice  = &IRIReference{"http://example.org/alice"}
+		bob    = &IRIReference{"http://example.org/bob"}
+		knows  = &IRIReference{"http://example.org/knows"}
+		says   = &IRIReference{"http://example.org/says"}
+		source = &IRIReference{"http://example.org/source"}
+	)
+	ab := &TripleTerm{Triple{alice, knows, bob}}
+	ba := &TripleTerm{Triple{bob, knows, alice}}
+	g := NewGraph(
+		NewTriple(ab, source, bob),
+		NewTriple(ba, source, alice),
+		NewTriple(alice, says, &TripleTerm{Triple{bob, says, ab}}),
+	)
+
+	if !g.Contains(&TripleTerm{Triple{alice, knows, bob}}, source, bob) {
+		t.Error("expected triple terms to be matched by value")
+	}
+	if triples := g.FindAll(&TripleTerm{Triple{alice, nil, nil}}, nil, nil); len(triples) != 1 || triples[0].Object != bob {
+		t.Error(triples)
+	}
+	if triples := g.FindAll(&TripleTerm{Triple{nil, knows, nil}}, source, nil); len(triples) != 2 {
+		t.Error(triples)
+	}
+	// Nested patterns.
+	if triples := g.FindAll(nil, nil, &TripleTerm{Triple{bob, nil, &TripleTerm{Triple{nil, nil, bob}}}}); len(triples) != 1 {
+		t.Error(triples)
+	}
+	if triples := g.FindAll(nil, nil, &TripleTerm{Triple{alice, nil, nil}}); len(triples) != 0 {
+		t.Error(triples)
+	}
+}
@@ -0,0 +1,78 @@
+// Package escape implements the escape sequences of the N-Triples family of syntaxes (ECHAR and UCHAR).
+package escape
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// String escapes the characters of a literal value that can not be written directly in a N-Triples string.
+func String(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Unescape replaces the escape sequences (ECHAR and UCHAR) in the given value. Invalid escape sequences are left
+// untouched.
+func Unescape(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			b.WriteByte(v[i])
+			continue
+		}
+		switch c := v[i+1]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case '"', '\'', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if len(v) < i+2+n {
+				b.WriteByte(v[i])
+				continue
+			}
+			r, err := strconv.ParseUint(v[i+2:i+2+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				b.WriteByte(v[i])
+				continue
+			}
+			b.WriteRune(rune(r))
+			i += n
+		default:
+			b.WriteByte(v[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,280 @@
+// Package isomorphism implements an isomorphism check for RDF graphs and datasets, independent of their concrete
+// representation.
+//
+// Blank nodes are partitioned by colour refinement, every blank node starts with the same colour, which is refined
+// with the colours of the blank nodes it shares statements with until the partition is stable. Blank nodes that can
+// still not be distinguished are paired by a backtracking search.
+package isomorphism
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Find returns a bijection from the blank nodes of a to the blank nodes of b, so that the statements of a are equal
+// to the statements of b after replacing the blank nodes. Statements are treated as sets, duplicates are ignored.
+func Find(a, b []Statement) (map[string]string, bool) {
+	ga, gb := newGraph(a), newGraph(b)
+	if len(ga.statements) != len(gb.statements) || len(ga.blankNodes) != len(gb.blankNodes) {
+		return nil, false
+	}
+	return search(ga, gb, ga.colours(), gb.colours())
+}
+
+func hash(v string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(v))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// histogram returns the number of blank nodes per colour.
+func histogram(colours map[string]string) map[string]int {
+	h := make(map[string]int)
+	for _, c := range colours {
+		h[c]++
+	}
+	return h
+}
+
+func search(a, b *graph, ca, cb map[string]string) (map[string]string, bool) {
+	ca, cb, ok := stabilize(a, b, ca, cb)
+	if !ok {
+		return nil, false
+	}
+
+	// Pick the smallest class of blank nodes that share the same colour.
+	var colour string
+	var found bool
+	h := histogram(ca)
+	for c, n := range h {
+		if 1 < n && (!found || n < h[colour] || (n == h[colour] && c < colour)) {
+			colour, found = c, true
+		}
+	}
+	if !found {
+		// Every blank node has a unique colour.
+		mapping := make(map[string]string, len(ca))
+		colours := make(map[string]string, len(cb))
+		for bn, c := range cb {
+			colours[c] = bn
+		}
+		for bn, c := range ca {
+			mapping[bn] = colours[c]
+		}
+		return mapping, a.mapsTo(b, mapping)
+	}
+
+	var x string
+	for _, bn := range a.blankNodes {
+		if ca[bn] == colour {
+			x = bn
+			break
+		}
+	}
+	individual := hash(colour + "\x02")
+	for _, y := range b.blankNodes {
+		if cb[y] != colour {
+			continue
+		}
+		ca2, cb2 := copyColours(ca), copyColours(cb)
+		ca2[x], cb2[y] = individual, individual
+		if mapping, ok := search(a, b, ca2, cb2); ok {
+			return mapping, true
+		}
+	}
+	return nil, false
+}
+
+// stabilize refines the colours of both graphs until the partitions are stable. Returns false if the colours of both
+// graphs diverge, in which case the graphs are not isomorphic.
+func stabilize(a, b *graph, ca, cb map[string]string) (map[string]string, map[string]string, bool) {
+	for {
+		ha, hb := histogram(ca), histogram(cb)
+		if !sameHistogram(ha, hb) {
+			return nil, nil, false
+		}
+		na, nb := a.refine(ca), b.refine(cb)
+		if len(histogram(na)) == len(ha) && len(histogram(nb)) == len(hb) {
+			return ca, cb, true
+		}
+		ca, cb = na, nb
+	}
+}
+
+func copyColours(colours map[string]string) map[string]string {
+	c := make(map[string]string, len(colours))
+	for k, v := range colours {
+		c[k] = v
+	}
+	return c
+}
+
+func sameHistogram(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for c, n := range a {
+		if b[c] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// Statement is a triple or quad, or any other sequence of terms.
+type Statement []Term
+
+func (s Statement) key(label func(string) string) string {
+	var b strings.Builder
+	for _, t := range s {
+		if t.BlankNode {
+			b.WriteString("_:" + label(t.Value))
+		} else {
+			b.WriteString(t.Value)
+		}
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// Term is a term of a statement. Blank nodes are identified by their label, all other terms are identified by their
+// value, which must be unique for the term (e.g. an unescaped N-Triples representation).
+type Term struct {
+	Value     string
+	BlankNode bool
+}
+
+type graph struct {
+	statements []Statement
+	keys       map[string]bool
+	blankNodes []string
+	index      map[string][]int
+}
+
+func newGraph(statements []Statement) *graph {
+	g := &graph{
+		keys:  make(map[string]bool),
+		index: make(map[string][]int),
+	}
+	identity := func(v string) string { return v }
+	for _, s := range statements {
+		k := s.key(identity)
+		if g.keys[k] {
+			continue
+		}
+		g.keys[k] = true
+		i := len(g.statements)
+		g.statements = append(g.statements, s)
+		for _, t := range s {
+			if !t.BlankNode {
+				continue
+			}
+			idx := g.index[t.Value]
+			if len(idx) == 0 {
+				g.blankNodes = append(g.blankNodes, t.Value)
+			}
+			if len(idx) == 0 || idx[len(idx)-1] != i {
+				g.index[t.Value] = append(idx, i)
+			}
+		}
+	}
+	sort.Strings(g.blankNodes)
+	return g
+}
+
+// colours returns the initial colours, all blank nodes start with the same colour.
+func (g *graph) colours() map[string]string {
+	colours := make(map[string]string, len(g.blankNodes))
+	for _, bn := range g.blankNodes {
+		colours[bn] = ""
+	}
+	return colours
+}
+
+// mapsTo returns true if the graph is equal to the other graph, after replacing its blank nodes with the mapping.
+func (g *graph) mapsTo(other *graph, mapping map[string]string) bool {
+	label := func(v string) string { return mapping[v] }
+	for _, s := range g.statements {
+		if !other.keys[s.key(label)] {
+			return false
+		}
+	}
+	return true
+}
+
+// refine returns the new colour of every blank node, based on its current colour and the statements it occurs in.
+func (g *graph) refine(colours map[string]string) map[string]string {
+	next := make(map[string]string, len(colours))
+	for _, bn := range g.blankNodes {
+		var signatures []string
+		for _, i := range g.index[bn] {
+			var b strings.Builder
+			for _, t := range g.statements[i] {
+				switch {
+				case !t.BlankNode:
+					_, _ = fmt.Fprintf(&b, "t%s", t.Value)
+				case t.Value == bn:
+					b.WriteString("s")
+				default:
+					_, _ = fmt.Fprintf(&b, "b%s", colours[t.Value])
+				}
+				b.WriteByte(0)
+			}
+			signatures = append(signatures, b.String())
+		}
+		sort.Strings(signatures)
+		next[bn] = hash(colours[bn] + "\x01" + strings.Join(signatures, "\x01"))
+	}
+	return next
+}
+
+// BlankNode returns the term of a blank node with the given label.
+func BlankNode(label string) Term {
+	return Term{Value: label, BlankNode: true}
+}
+
+// FromNTriples returns the term of a blank node, IRI or literal in the N-Triples syntax (e.g. the string of a term of
+// the ntriples package, which can not be imported here).
+func FromNTriples(v string) Term {
+	switch {
+	case strings.HasPrefix(v, "_:"):
+		return BlankNode(v[2:])
+	case strings.HasPrefix(v, "<"):
+		return IRI(strings.TrimSuffix(v[1:], ">"))
+	}
+	// The lexical form ends at the last quote, the datatype IRI and language tag can not contain quotes.
+	i := strings.LastIndex(v, `"`)
+	var datatype, language, direction string
+	switch suffix := v[i+1:]; {
+	case strings.HasPrefix(suffix, "^^"):
+		datatype = strings.TrimSuffix(strings.TrimPrefix(suffix, "^^<"), ">")
+	case strings.HasPrefix(suffix, "@"):
+		language, direction, _ = strings.Cut(suffix[1:], "--")
+	}
+	return Literal(v[1:i], datatype, language, direction)
+}
+
+// IRI returns the term of an IRI, the value may contain escape sequences.
+func IRI(v string) Term {
+	return Term{Value: "<" + escape.Unescape(v) + ">"}
+}
+
+// Literal returns the term of a literal, the value and datatype may contain escape sequences. Literals with the
+// xsd:string datatype are equal to simple literals and language tags are compared case-insensitively.
+func Literal(v string, datatype string, language string, direction string) Term {
+	s := `"` + escape.Unescape(v) + `"`
+	if direction != "" {
+		return Term{Value: s + "@" + strings.ToLower(language) + "--" + direction}
+	}
+	if language != "" {
+		return Term{Value: s + "@" + strings.ToLower(language)}
+	}
+	if dt := IRI(datatype); datatype != "" && dt.Value != "<http://www.w3.org/2001/XMLSchema#string>" {
+		s += "^^" + dt.Value
+	}
+	return Term{Value: s}
+}
@@ -0,0 +1,46 @@
+// Package lines implements the line handling shared by the readers of line-based syntaxes (N-Triples and N-Quads).
+package lines
+
+import (
+	"bytes"
+	"strings"
+)
+
+// MaxSize is the maximum size of a single line (statement) in bytes.
+const MaxSize = 16 * 1024 * 1024
+
+// IsBlank returns true if the line does not contain a statement, i.e. only whitespace or a comment.
+func IsBlank(line string) bool {
+	line = strings.TrimLeft(line, " \t")
+	return len(line) == 0 || line[0] == '#'
+}
+
+// Scan is a split function for a bufio.Scanner that returns each line of text, stripped of any trailing end-of-line
+// marker. Unlike bufio.ScanLines, it also accepts a lone carriage return as end-of-line marker.
+func Scan(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); 0 <= i {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// Carriage return, check whether it is followed by a newline.
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// Request more data.
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	// Request more data.
+	return 0, nil, nil
+}
@@ -32,6 +32,11 @@ func NewReport(test ttl.IRI) *Report {
 			"https://www.w3.org/TR/n-quads/",
 			"https://www.w3.org/TR/turtle/",
 			"https://www.w3.org/TR/trig/",
+			"https://www.w3.org/TR/rdf-syntax-grammar/",
+			"https://www.w3.org/TR/rdf-canon/",
+			"https://www.w3.org/TR/json-ld11-api/",
+			"https://www.w3.org/TR/json-ld11-framing/",
+			"https://w3c.github.io/rdf-star/cg-spec/",
 		},
 		Developer: []testsuite.Developer{
 			{
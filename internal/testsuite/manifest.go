@@ -1,6 +1,7 @@
 package testsuite
 
 import (
+	"encoding/json"
 	"fmt"
 	ttl "github.com/0x51-dev/rdf/turtle"
 )
@@ -29,6 +30,22 @@ func LoadManifest(raw string) (*Manifest, error) {
 	}
 	manifest, ok := sm[""]
 	if !ok {
+		// Some manifests name the root, e.g. <manifest-urdna2015> a mf:Manifest.
+		for _, t := range sm {
+			pom, err := t.PredicateObjectMap()
+			if err != nil {
+				return nil, err
+			}
+			typ, ok := pom["rdf:type"]
+			if !ok {
+				typ = pom["a"]
+			}
+			if typ.String() == "mf:Manifest" {
+				manifest = t
+			}
+		}
+	}
+	if manifest == nil {
 		return nil, fmt.Errorf("manifest: no root")
 	}
 	pom, err := manifest.PredicateObjectMap()
@@ -63,6 +80,54 @@ func LoadManifest(raw string) (*Manifest, error) {
 	return &Manifest{Keys: keys, Entries: entries}, nil
 }
 
+// LoadJSONManifest loads a manifest in the JSON-LD format used by the JSON-LD test suites. The type of a test is its
+// first type (e.g. jld:PositiveEvaluationTest), the result is either the expected output or the expected error code.
+func LoadJSONManifest(raw []byte) (*Manifest, error) {
+	var manifest struct {
+		Sequence []struct {
+			ID              string         `json:"@id"`
+			Type            []string       `json:"@type"`
+			Name            string         `json:"name"`
+			Purpose         string         `json:"purpose"`
+			Input           string         `json:"input"`
+			Context         string         `json:"context"`
+			Frame           string         `json:"frame"`
+			Expect          string         `json:"expect"`
+			ExpectErrorCode string         `json:"expectErrorCode"`
+			Option          map[string]any `json:"option"`
+		} `json:"sequence"`
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, err
+	}
+	var keys []string
+	entries := make(map[string]*Test)
+	for _, e := range manifest.Sequence {
+		if len(e.Type) == 0 {
+			return nil, fmt.Errorf("test: no type")
+		}
+		if e.Input == "" {
+			return nil, fmt.Errorf("test: no input")
+		}
+		result := e.Expect
+		if e.ExpectErrorCode != "" {
+			result = e.ExpectErrorCode
+		}
+		keys = append(keys, e.ID)
+		entries[e.ID] = &Test{
+			Type:    e.Type[0],
+			Name:    e.Name,
+			Comment: e.Purpose,
+			Action:  e.Input,
+			Result:  result,
+			Option:  e.Option,
+			Context: e.Context,
+			Frame:   e.Frame,
+		}
+	}
+	return &Manifest{Keys: keys, Entries: entries}, nil
+}
+
 type Test struct {
 	Type     string
 	Name     string
@@ -70,6 +135,18 @@ type Test struct {
 	Approval ApprovalType
 	Action   string
 	Result   string
+	// HashAlgorithm is the hash algorithm used by RDF canonicalization tests, empty for the default (SHA256).
+	HashAlgorithm string
+	// Option contains the options of JSON-LD tests.
+	Option map[string]any
+	// Context and Frame are the documents containing the context and the frame of JSON-LD compaction, flattening and
+	// framing tests.
+	Context string
+	Frame   string
+	// Data and GraphData are the documents containing the default graph and the named graphs of SPARQL query
+	// evaluation tests, the action is the query.
+	Data      string
+	GraphData []string
 }
 
 func NewTest(triple *ttl.Triple) (*Test, error) {
@@ -96,18 +173,51 @@ func NewTest(triple *ttl.Triple) (*Test, error) {
 	if !ok {
 		return nil, fmt.Errorf("test: no action")
 	}
+	var a, data string
+	var graphData []string
+	switch action := action[0].(type) {
+	case *ttl.IRI:
+		a = action.Value
+	case ttl.BlankNodePropertyList:
+		// SPARQL query evaluation tests, e.g. [ qt:query <q.rq> ; qt:data <d.ttl> ].
+		pom, err := ttl.Triple{PredicateObjectList: ttl.PredicateObjectList(action)}.PredicateObjectMap()
+		if err != nil {
+			return nil, err
+		}
+		query, ok := pom["qt:query"]
+		if !ok {
+			return nil, fmt.Errorf("test: no query")
+		}
+		a = (query[0].(*ttl.IRI)).Value
+		if d, ok := pom["qt:data"]; ok {
+			data = (d[0].(*ttl.IRI)).Value
+		}
+		for _, g := range pom["qt:graphData"] {
+			graphData = append(graphData, (g.(*ttl.IRI)).Value)
+		}
+	default:
+		return nil, fmt.Errorf("test: invalid action %T", action)
+	}
 	result, ok := pom["mf:result"]
 	var r string
 	if ok {
 		r = (result[0].(*ttl.IRI)).Value
 	}
+	var hashAlgorithm string
+	if h, ok := pom["rdfc:hashAlgorithm"]; ok {
+		hashAlgorithm = (h[0].(*ttl.StringLiteral)).Value
+	}
 
 	return &Test{
 		Type:     typ.String(),
 		Name:     (name[0].(*ttl.StringLiteral)).Value,
 		Comment:  comment.String(),
 		Approval: ApprovalType(approval.String()),
-		Action:   (action[0].(*ttl.IRI)).Value,
+		Action:   a,
 		Result:   r, // optional, only with eval tests
+
+		HashAlgorithm: hashAlgorithm,
+		Data:          data,
+		GraphData:     graphData,
 	}, nil
 }
@@ -0,0 +1,200 @@
+// Package iri implements the resolution of relative IRI references, as defined by RFC 3986.
+package iri
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	schemeRegex    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*:`)
+	referenceRegex = regexp.MustCompile(`^(([^:/?#]+):)?(//([^/?#]*))?([^?#]*)(\?([^#]*))?(#(.*))?$`)
+)
+
+// IsAbsolute returns true if the IRI starts with a scheme, i.e. it does not need to be resolved.
+func IsAbsolute(v string) bool {
+	return schemeRegex.MatchString(v)
+}
+
+// Resolve resolves the reference against the base IRI, as defined by RFC 3986 section 5.2. This includes the removal
+// of dot segments and the handling of references that only consist of a query, fragment or authority. The reference is
+// returned as is if the base IRI is empty.
+func Resolve(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	r := parseReference(ref)
+	if r.scheme != "" {
+		r.path = removeDotSegments(r.path)
+		return r.String()
+	}
+	b := parseReference(base)
+	t := reference{scheme: b.scheme, fragment: r.fragment, hasFragment: r.hasFragment}
+	switch {
+	case r.hasAuthority:
+		t.authority, t.hasAuthority = r.authority, true
+		t.path = removeDotSegments(r.path)
+		t.query, t.hasQuery = r.query, r.hasQuery
+	case r.path == "":
+		t.authority, t.hasAuthority = b.authority, b.hasAuthority
+		t.path = b.path
+		t.query, t.hasQuery = b.query, b.hasQuery
+		if r.hasQuery {
+			t.query, t.hasQuery = r.query, true
+		}
+	default:
+		t.authority, t.hasAuthority = b.authority, b.hasAuthority
+		if strings.HasPrefix(r.path, "/") {
+			t.path = removeDotSegments(r.path)
+		} else {
+			t.path = removeDotSegments(merge(b, r.path))
+		}
+		t.query, t.hasQuery = r.query, r.hasQuery
+	}
+	return t.String()
+}
+
+// Relativize returns the shortest reference that resolves to the IRI against the base IRI (see Resolve), the IRI is
+// returned as is if it does not share the scheme and authority of the base IRI.
+func Relativize(base, iri string) string {
+	if base == "" {
+		return iri
+	}
+	b, r := parseReference(base), parseReference(iri)
+	if b.scheme != r.scheme || b.hasAuthority != r.hasAuthority || b.authority != r.authority || r.scheme == "" {
+		return iri
+	}
+	var rel string
+	switch {
+	case r.path == b.path && r.hasQuery == b.hasQuery && r.query == b.query:
+		if r.hasFragment {
+			return "#" + r.fragment
+		}
+		rel = r.path[strings.LastIndexByte(r.path, '/')+1:]
+		if rel == "" {
+			rel = "./"
+		}
+	case r.path == b.path && r.hasQuery:
+	default:
+		baseDir := strings.Split(b.path, "/")
+		baseDir = baseDir[:len(baseDir)-1]
+		segments := strings.Split(r.path, "/")
+		i := 0
+		for i < len(baseDir) && i < len(segments)-1 && baseDir[i] == segments[i] {
+			i++
+		}
+		if i == 0 && 0 < len(baseDir) {
+			// Nothing in common, use an absolute path.
+			rel = r.path
+			break
+		}
+		rel = strings.Repeat("../", len(baseDir)-i) + strings.Join(segments[i:], "/")
+		switch {
+		case rel == "":
+			rel = "./"
+		case strings.Contains(strings.SplitN(rel, "/", 2)[0], ":"):
+			// The first segment would be mistaken for a scheme.
+			rel = "./" + rel
+		}
+	}
+	if r.hasQuery {
+		rel += "?" + r.query
+	}
+	if r.hasFragment {
+		rel += "#" + r.fragment
+	}
+	if Resolve(base, rel) != iri {
+		return iri
+	}
+	return rel
+}
+
+func merge(base reference, path string) string {
+	if base.hasAuthority && base.path == "" {
+		return "/" + path
+	}
+	i := strings.LastIndexByte(base.path, '/')
+	return base.path[:i+1] + path
+}
+
+func removeDotSegments(path string) string {
+	var out []string
+	in := path
+	for in != "" {
+		switch {
+		case strings.HasPrefix(in, "../"):
+			in = in[3:]
+		case strings.HasPrefix(in, "./"):
+			in = in[2:]
+		case strings.HasPrefix(in, "/./"):
+			in = in[2:]
+		case in == "/.":
+			in = "/"
+		case strings.HasPrefix(in, "/../"):
+			in = in[3:]
+			if 0 < len(out) {
+				out = out[:len(out)-1]
+			}
+		case in == "/..":
+			in = "/"
+			if 0 < len(out) {
+				out = out[:len(out)-1]
+			}
+		case in == "." || in == "..":
+			in = ""
+		default:
+			i := strings.IndexByte(in[1:], '/')
+			if i < 0 {
+				out = append(out, in)
+				in = ""
+			} else {
+				out = append(out, in[:i+1])
+				in = in[i+1:]
+			}
+		}
+	}
+	return strings.Join(out, "")
+}
+
+type reference struct {
+	scheme       string
+	authority    string
+	path         string
+	query        string
+	fragment     string
+	hasAuthority bool
+	hasQuery     bool
+	hasFragment  bool
+}
+
+func parseReference(v string) reference {
+	m := referenceRegex.FindStringSubmatch(v)
+	return reference{
+		scheme:       m[2],
+		authority:    m[4],
+		path:         m[5],
+		query:        m[7],
+		fragment:     m[9],
+		hasAuthority: m[3] != "",
+		hasQuery:     m[6] != "",
+		hasFragment:  m[8] != "",
+	}
+}
+
+func (r reference) String() string {
+	var b strings.Builder
+	if r.scheme != "" {
+		b.WriteString(r.scheme + ":")
+	}
+	if r.hasAuthority {
+		b.WriteString("//" + r.authority)
+	}
+	b.WriteString(r.path)
+	if r.hasQuery {
+		b.WriteString("?" + r.query)
+	}
+	if r.hasFragment {
+		b.WriteString("#" + r.fragment)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package iri_test
+
+import (
+	"github.com/0x51-dev/rdf/iri"
+	"testing"
+)
+
+// base is the base IRI of the examples of RFC 3986 section 5.4.
+const base = "http://a/b/c/d;p?q"
+
+func TestResolve(t *testing.T) {
+	for ref, expected := range map[string]string{
+		// Normal examples.
+		"g:h":     "g:h",
+		"g":       "http://a/b/c/g",
+		"./g":     "http://a/b/c/g",
+		"g/":      "http://a/b/c/g/",
+		"/g":      "http://a/g",
+		"//g":     "http://g",
+		"?y":      "http://a/b/c/d;p?y",
+		"g?y":     "http://a/b/c/g?y",
+		"#s":      "http://a/b/c/d;p?q#s",
+		"g#s":     "http://a/b/c/g#s",
+		"g?y#s":   "http://a/b/c/g?y#s",
+		";x":      "http://a/b/c/;x",
+		"g;x":     "http://a/b/c/g;x",
+		"g;x?y#s": "http://a/b/c/g;x?y#s",
+		"":        "http://a/b/c/d;p?q",
+		".":       "http://a/b/c/",
+		"./":      "http://a/b/c/",
+		"..":      "http://a/b/",
+		"../":     "http://a/b/",
+		"../g":    "http://a/b/g",
+		"../..":   "http://a/",
+		"../../":  "http://a/",
+		"../../g": "http://a/g",
+		// Abnormal examples.
+		"../../../g":    "http://a/g",
+		"../../../../g": "http://a/g",
+		"/./g":          "http://a/g",
+		"/../g":         "http://a/g",
+		"g.":            "http://a/b/c/g.",
+		".g":            "http://a/b/c/.g",
+		"g..":           "http://a/b/c/g..",
+		"..g":           "http://a/b/c/..g",
+		"./../g":        "http://a/b/g",
+		"./g/.":         "http://a/b/c/g/",
+		"g/./h":         "http://a/b/c/g/h",
+		"g/../h":        "http://a/b/c/h",
+		"g;x=1/./y":     "http://a/b/c/g;x=1/y",
+		"g;x=1/../y":    "http://a/b/c/y",
+		"g?y/./x":       "http://a/b/c/g?y/./x",
+		"g?y/../x":      "http://a/b/c/g?y/../x",
+		"g#s/./x":       "http://a/b/c/g#s/./x",
+		"g#s/../x":      "http://a/b/c/g#s/../x",
+		"http:g":        "http:g",
+	} {
+		if v := iri.Resolve(base, ref); v != expected {
+			t.Errorf("%q: expected %q, got %q", ref, expected, v)
+		}
+	}
+	if v := iri.Resolve("", "g"); v != "g" {
+		t.Error(v)
+	}
+}
+
+func TestRelativize(t *testing.T) {
+	for _, v := range []string{
+		"http://a/b/c/g",
+		"http://a/b/c/g/",
+		"http://a/g",
+		"http://a/b/c/d;p?y",
+		"http://a/b/c/d;p?q#s",
+		"http://a/b/c/",
+		"http://a/b/g",
+		"http://g/h",
+		"https://a/b/c/g",
+		"http://a/b/c/g:h",
+	} {
+		rel := iri.Relativize(base, v)
+		if r := iri.Resolve(base, rel); r != v {
+			t.Errorf("%q: relativized to %q, which resolves to %q", v, rel, r)
+		}
+	}
+	for v, expected := range map[string]string{
+		"http://a/b/c/g":       "g",
+		"http://a/b/c/d;p?q#s": "#s",
+		"http://a/b/g":         "../g",
+		"http://g/h":           "http://g/h",
+	} {
+		if rel := iri.Relativize(base, v); rel != expected {
+			t.Errorf("%q: expected %q, got %q", v, expected, rel)
+		}
+	}
+}
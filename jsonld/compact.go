@@ -0,0 +1,813 @@
+package jsonld
+
+import (
+	"github.com/0x51-dev/rdf/iri"
+	"sort"
+	"strings"
+)
+
+// Compact compacts the JSON-LD document according to the given context, shortening IRIs to terms or compact IRIs and
+// values to their native representation where possible. The document is expanded first.
+func Compact(input, context any, options *Options) (map[string]any, error) {
+	p := newProcessor(options)
+	expanded, err := p.expandDocument(input, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.compactDocument(expanded, context, false)
+}
+
+// compactDocument compacts the expanded document, the nodes are wrapped in a @graph entry if there is more than one
+// node, or if graph is true.
+func (p *processor) compactDocument(expanded []any, context any, graph bool) (map[string]any, error) {
+	if m, ok := context.(map[string]any); ok {
+		if v, ok := m["@context"]; ok {
+			context = v
+		}
+	}
+	active, err := p.processContext(newContext(p.options.Base), context, p.options.Base, nil, false, true, true)
+	if err != nil {
+		return nil, err
+	}
+	compacted, err := p.compact(active, "", expanded)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := compacted.(map[string]any)
+	switch {
+	case ok && graph:
+		result = map[string]any{p.alias(active, "@graph"): []any{result}}
+	case !ok:
+		nodes, _ := compacted.([]any)
+		if len(nodes) == 0 && !graph {
+			result = make(map[string]any)
+		} else {
+			result = map[string]any{p.alias(active, "@graph"): arrayOf(nodes)}
+		}
+	}
+	switch c := context.(type) {
+	case nil:
+	case map[string]any:
+		if len(c) != 0 {
+			result["@context"] = c
+		}
+	case []any:
+		if len(c) != 0 {
+			result["@context"] = c
+		}
+	default:
+		result["@context"] = c
+	}
+	return result, nil
+}
+
+// compact implements the compaction algorithm, an empty active property represents null.
+func (p *processor) compact(active *activeContext, property string, element any) (any, error) {
+	switch e := element.(type) {
+	case []any:
+		result := []any{}
+		for _, item := range e {
+			v, err := p.compact(active, property, item)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				result = append(result, v)
+			}
+		}
+		def := active.terms[property]
+		if len(result) != 1 || p.options.NoCompactArrays || property == "@graph" || property == "@set" ||
+			def.hasContainer("@list") || def.hasContainer("@set") {
+			return result, nil
+		}
+		return result[0], nil
+	case map[string]any:
+		return p.compactMap(active, property, e)
+	default:
+		return element, nil
+	}
+}
+
+func (p *processor) compactMap(active *activeContext, property string, e map[string]any) (any, error) {
+	typeScoped := active
+	// Contexts that do not propagate are reverted when entering a new node object.
+	if _, ok := e["@id"]; active.previous != nil && !isValueObject(e) && !(ok && len(e) == 1) {
+		active = active.previous
+	}
+	var err error
+	if d := active.terms[property]; d != nil && d.hasContext {
+		if active, err = p.processContext(active, d.context, d.baseURL, nil, true, true, true); err != nil {
+			return nil, err
+		}
+	}
+	def := active.terms[property]
+	if isValueObject(e) {
+		return p.compactValue(active, property, e)
+	}
+	if isNodeReference(e) && def != nil && (def.typ == "@id" || def.typ == "@vocab") {
+		if _, ok := e["@index"]; !ok || def.hasContainer("@index") {
+			return p.compactIRI(active, e["@id"].(string), nil, def.typ == "@vocab", false)
+		}
+	}
+	if isListObject(e) && def.hasContainer("@list") {
+		return p.compact(active, property, e["@list"])
+	}
+
+	insideReverse := property == "@reverse"
+	result := make(map[string]any)
+	if types, ok := e["@type"].([]any); ok {
+		var compacted []string
+		for _, t := range types {
+			s, _ := t.(string)
+			c, err := p.compactIRI(active, s, nil, true, false)
+			if err != nil {
+				return nil, err
+			}
+			compacted = append(compacted, c)
+		}
+		sort.Strings(compacted)
+		for _, t := range compacted {
+			if d, ok := typeScoped.terms[t]; ok && d.hasContext {
+				if active, err = p.processContext(active, d.context, d.baseURL, nil, false, false, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, ep := range sortedKeys(e) {
+		ev := e[ep]
+		switch ep {
+		case "@id":
+			s, _ := ev.(string)
+			id, err := p.compactIRI(active, s, nil, false, false)
+			if err != nil {
+				return nil, err
+			}
+			result[p.alias(active, "@id")] = id
+			continue
+		case "@type":
+			var types []any
+			for _, t := range asArray(ev) {
+				s, _ := t.(string)
+				c, err := p.compactIRI(typeScoped, s, nil, true, false)
+				if err != nil {
+					return nil, err
+				}
+				types = append(types, c)
+			}
+			alias := p.alias(active, "@type")
+			array := p.options.NoCompactArrays ||
+				(!p.options.processingMode(JSONLD10) && active.terms[alias].hasContainer("@set"))
+			addCompacted(result, alias, types, array)
+			continue
+		case "@reverse":
+			cv, err := p.compact(active, "@reverse", ev)
+			if err != nil {
+				return nil, err
+			}
+			reverse, _ := cv.(map[string]any)
+			for _, k := range sortedKeys(reverse) {
+				if d := active.terms[k]; d != nil && d.reverse {
+					addCompacted(result, k, reverse[k], p.options.NoCompactArrays || d.hasContainer("@set"))
+					delete(reverse, k)
+				}
+			}
+			if len(reverse) != 0 {
+				result[p.alias(active, "@reverse")] = reverse
+			}
+			continue
+		case "@index":
+			if def.hasContainer("@index") {
+				continue
+			}
+			result[p.alias(active, ep)] = ev
+			continue
+		case "@direction", "@language", "@value":
+			result[p.alias(active, ep)] = ev
+			continue
+		}
+
+		items := asArray(ev)
+		if len(items) == 0 {
+			iap, err := p.compactIRI(active, ep, ev, true, insideReverse)
+			if err != nil {
+				return nil, err
+			}
+			nest, err := p.nestResult(active, result, iap)
+			if err != nil {
+				return nil, err
+			}
+			addCompacted(nest, iap, []any{}, true)
+		}
+		for _, item := range items {
+			iap, err := p.compactIRI(active, ep, item, true, insideReverse)
+			if err != nil {
+				return nil, err
+			}
+			nest, err := p.nestResult(active, result, iap)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.compactItem(active, nest, ep, iap, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// compactItem compacts an item of the value of the expanded property, and adds it to the result using the given item
+// active property.
+func (p *processor) compactItem(active *activeContext, result map[string]any, ep, iap string, item any) error {
+	def := active.terms[iap]
+	array := p.options.NoCompactArrays || def.hasContainer("@set") || ep == "@graph" || ep == "@list"
+	im, _ := item.(map[string]any)
+	element := item
+	switch {
+	case isListObject(item):
+		element = im["@list"]
+	case isGraphObject(item):
+		element = im["@graph"]
+	}
+	ci, err := p.compact(active, iap, element)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isListObject(item):
+		ci = asArray(ci)
+		if def.hasContainer("@list") {
+			result[iap] = ci
+			return nil
+		}
+		list := map[string]any{p.alias(active, "@list"): ci}
+		if index, ok := im["@index"]; ok {
+			list[p.alias(active, "@index")] = index
+		}
+		addCompacted(result, iap, list, array)
+	case isGraphObject(item):
+		id, hasID := im["@id"].(string)
+		mapArray := p.options.NoCompactArrays || def.hasContainer("@set")
+		switch {
+		case def.hasContainer("@graph") && def.hasContainer("@id"):
+			key := p.alias(active, "@none")
+			if hasID {
+				if key, err = p.compactIRI(active, id, nil, false, false); err != nil {
+					return err
+				}
+			}
+			addCompacted(mapEntry(result, iap), key, ci, mapArray)
+		case def.hasContainer("@graph") && def.hasContainer("@index") && !hasID:
+			key, ok := im["@index"].(string)
+			if !ok {
+				key = p.alias(active, "@none")
+			}
+			addCompacted(mapEntry(result, iap), key, ci, mapArray)
+		case def.hasContainer("@graph") && !hasID:
+			// Multiple nodes would be interpreted as multiple named graphs.
+			if a, ok := ci.([]any); ok && 1 < len(a) {
+				ci = map[string]any{p.alias(active, "@included"): a}
+			}
+			addCompacted(result, iap, ci, array)
+		default:
+			graph := map[string]any{p.alias(active, "@graph"): ci}
+			if hasID {
+				if graph[p.alias(active, "@id")], err = p.compactIRI(active, id, nil, false, false); err != nil {
+					return err
+				}
+			}
+			if index, ok := im["@index"]; ok {
+				graph[p.alias(active, "@index")] = index
+			}
+			addCompacted(result, iap, graph, array)
+		}
+	case !def.hasContainer("@graph") && (def.hasContainer("@language") || def.hasContainer("@index") ||
+		def.hasContainer("@id") || def.hasContainer("@type")):
+		key, err := p.containerKey(active, def, iap, im, &ci)
+		if err != nil {
+			return err
+		}
+		addCompacted(mapEntry(result, iap), key, ci, p.options.NoCompactArrays || def.hasContainer("@set"))
+	default:
+		addCompacted(result, iap, ci, array)
+	}
+	return nil
+}
+
+// containerKey returns the key of the compacted item within a language, index, id or type map. The compacted item is
+// updated to no longer contain the information represented by the key.
+func (p *processor) containerKey(
+	active *activeContext,
+	def *term,
+	iap string,
+	expanded map[string]any,
+	compacted *any,
+) (string, error) {
+	cm, _ := (*compacted).(map[string]any)
+	var key any
+	switch {
+	case def.hasContainer("@language"):
+		if v, ok := expanded["@value"]; ok {
+			*compacted = v
+		}
+		key = expanded["@language"]
+	case def.hasContainer("@index") && def.index == "":
+		key = expanded["@index"]
+		delete(cm, p.alias(active, "@index"))
+	case def.hasContainer("@index"):
+		// Property-valued index, the first value of the property is used as key.
+		if values := valuesOf(cm, def.index); 0 < len(values) {
+			if _, ok := values[0].(string); ok {
+				key = values[0]
+				setValues(cm, def.index, values[1:])
+			}
+		}
+	case def.hasContainer("@id"):
+		alias := p.alias(active, "@id")
+		key = cm[alias]
+		delete(cm, alias)
+	case def.hasContainer("@type"):
+		alias := p.alias(active, "@type")
+		if values := valuesOf(cm, alias); 0 < len(values) {
+			key = values[0]
+			setValues(cm, alias, values[1:])
+		}
+		if _, ok := expanded["@id"]; ok && len(cm) == 1 {
+			c, err := p.compact(active, iap, map[string]any{"@id": expanded["@id"]})
+			if err != nil {
+				return "", err
+			}
+			*compacted = c
+		}
+	}
+	if s, ok := key.(string); ok {
+		return s, nil
+	}
+	return p.alias(active, "@none"), nil
+}
+
+// nestResult returns the map the values of the given term are added to, which is a nested map if the term has a
+// nest value.
+func (p *processor) nestResult(active *activeContext, result map[string]any, t string) (map[string]any, error) {
+	d := active.terms[t]
+	if d == nil || d.nest == "" {
+		return result, nil
+	}
+	nest, _, err := p.expandIRI(active, d.nest, false, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if nest != "@nest" {
+		return nil, newError(InvalidNestValue, "%s", d.nest)
+	}
+	return mapEntry(result, d.nest), nil
+}
+
+// compactValue implements the value compaction algorithm for value objects.
+func (p *processor) compactValue(active *activeContext, property string, value map[string]any) (any, error) {
+	def := active.terms[property]
+	language, direction := active.language, active.direction
+	var typ string
+	if def != nil {
+		typ = def.typ
+		if def.hasLanguage {
+			language = def.language
+		}
+		if def.hasDirection {
+			direction = def.direction
+		}
+	}
+	_, hasIndex := value["@index"]
+	preserveIndex := hasIndex && !def.hasContainer("@index")
+
+	v := value["@value"]
+	t, hasType := value["@type"]
+	l, _ := value["@language"].(string)
+	d, _ := value["@direction"].(string)
+	_, isString := v.(string)
+	switch {
+	case hasType && t == typ:
+		if !preserveIndex {
+			return v, nil
+		}
+	case typ == "@none" || hasType:
+	case !isString || (strings.EqualFold(l, language) && d == direction):
+		if !preserveIndex {
+			return v, nil
+		}
+	}
+
+	result := make(map[string]any, len(value))
+	for _, k := range sortedKeys(value) {
+		ev := value[k]
+		switch k {
+		case "@index":
+			if !preserveIndex {
+				continue
+			}
+		case "@type":
+			s, _ := ev.(string)
+			c, err := p.compactIRI(active, s, nil, true, false)
+			if err != nil {
+				return nil, err
+			}
+			ev = c
+		}
+		result[p.alias(active, k)] = ev
+	}
+	return result, nil
+}
+
+// alias returns the compacted form of the keyword, keywords never get confused with a compact IRI.
+func (p *processor) alias(active *activeContext, keyword string) string {
+	v, _ := p.compactIRI(active, keyword, nil, true, false)
+	return v
+}
+
+// compactIRI implements the IRI compaction algorithm, the value is used to select the term that matches it best.
+func (p *processor) compactIRI(active *activeContext, v string, value any, vocab, reverse bool) (string, error) {
+	if vocab {
+		if _, ok := active.inverseContext()[v]; ok {
+			t, err := p.selectTerm(active, v, value, reverse)
+			if err != nil || t != "" {
+				return t, err
+			}
+		}
+		if active.hasVocab && strings.HasPrefix(v, active.vocab) && len(active.vocab) < len(v) {
+			suffix := v[len(active.vocab):]
+			if _, ok := active.terms[suffix]; !ok {
+				return suffix, nil
+			}
+		}
+	}
+
+	var compact string
+	for t, d := range active.terms {
+		if d.id == "" || d.id == v || !d.prefix || !strings.HasPrefix(v, d.id) {
+			continue
+		}
+		candidate := t + ":" + v[len(d.id):]
+		if compact != "" && (len(compact) < len(candidate) || (len(compact) == len(candidate) && compact < candidate)) {
+			continue
+		}
+		if c, ok := active.terms[candidate]; !ok || (c.id == v && value == nil) {
+			compact = candidate
+		}
+	}
+	if compact != "" {
+		return compact, nil
+	}
+
+	if i := strings.IndexByte(v, ':'); 0 < i && isAbsoluteIRI(v) {
+		if d, ok := active.terms[v[:i]]; ok && d.prefix && !strings.HasPrefix(v[i+1:], "//") {
+			return "", newError(IRIConfusedWithPrefix, "%s", v)
+		}
+	}
+	if !vocab && !p.options.NoCompactToRelative {
+		return iri.Relativize(active.base, v), nil
+	}
+	return v, nil
+}
+
+// selectTerm implements the term selection algorithm, including the preparation of the containers and preferred
+// values based on the value. Returns an empty string if no term matches.
+func (p *processor) selectTerm(active *activeContext, iri string, value any, reverse bool) (string, error) {
+	defaultLanguage := "@none"
+	switch {
+	case active.direction != "":
+		defaultLanguage = strings.ToLower(active.language + "_" + active.direction)
+	case active.language != "":
+		defaultLanguage = strings.ToLower(active.language)
+	}
+	m, _ := value.(map[string]any)
+	if v, ok := m["@preserve"]; ok {
+		value = asArray(v)[0]
+		m, _ = value.(map[string]any)
+	}
+	_, hasIndex := m["@index"]
+
+	var containers []string
+	typeLanguage, typeLanguageValue := "@language", "@null"
+	if hasIndex && !isGraphObject(value) {
+		containers = append(containers, "@index", "@index@set")
+	}
+	list, _ := m["@list"].([]any)
+	switch {
+	case reverse:
+		typeLanguage, typeLanguageValue = "@type", "@reverse"
+		containers = append(containers, "@set")
+	case isListObject(value):
+		if !hasIndex {
+			containers = append(containers, "@list")
+		}
+		commonType, commonLanguage := "", ""
+		if len(list) == 0 {
+			commonLanguage = defaultLanguage
+		}
+		for _, item := range list {
+			itemLanguage, itemType := "@none", "@none"
+			if im, ok := item.(map[string]any); ok && isValueObject(item) {
+				l, hasLanguage := im["@language"].(string)
+				d, hasDirection := im["@direction"].(string)
+				switch t, hasType := im["@type"].(string); {
+				case hasDirection:
+					itemLanguage = strings.ToLower(l + "_" + d)
+				case hasLanguage:
+					itemLanguage = strings.ToLower(l)
+				case hasType:
+					itemType = t
+				default:
+					itemLanguage = "@null"
+				}
+			} else {
+				itemType = "@id"
+			}
+			if commonLanguage == "" {
+				commonLanguage = itemLanguage
+			} else if itemLanguage != commonLanguage && isValueObject(item) {
+				commonLanguage = "@none"
+			}
+			if commonType == "" {
+				commonType = itemType
+			} else if itemType != commonType {
+				commonType = "@none"
+			}
+			if commonLanguage == "@none" && commonType == "@none" {
+				break
+			}
+		}
+		if commonLanguage == "" {
+			commonLanguage = "@none"
+		}
+		if commonType == "" {
+			commonType = "@none"
+		}
+		if commonType != "@none" {
+			typeLanguage, typeLanguageValue = "@type", commonType
+		} else {
+			typeLanguageValue = commonLanguage
+		}
+	case isGraphObject(value):
+		_, hasID := m["@id"]
+		if hasIndex {
+			containers = append(containers, "@graph@index", "@graph@index@set")
+		}
+		if hasID {
+			containers = append(containers, "@graph@id", "@graph@id@set")
+		}
+		containers = append(containers, "@graph", "@graph@set", "@set")
+		if !hasIndex {
+			containers = append(containers, "@graph@index", "@graph@index@set")
+		}
+		if !hasID {
+			containers = append(containers, "@graph@id", "@graph@id@set")
+		}
+		containers = append(containers, "@index", "@index@set")
+		typeLanguage, typeLanguageValue = "@type", "@id"
+	default:
+		if isValueObject(value) {
+			l, hasLanguage := m["@language"].(string)
+			d, hasDirection := m["@direction"].(string)
+			t, hasType := m["@type"].(string)
+			switch {
+			case hasDirection && !hasIndex:
+				typeLanguageValue = strings.ToLower(l + "_" + d)
+				containers = append(containers, "@language", "@language@set")
+			case hasLanguage && !hasIndex:
+				typeLanguageValue = strings.ToLower(l)
+				containers = append(containers, "@language", "@language@set")
+			case hasType:
+				typeLanguage, typeLanguageValue = "@type", t
+			}
+		} else {
+			typeLanguage, typeLanguageValue = "@type", "@id"
+			containers = append(containers, "@id", "@id@set", "@type", "@set@type")
+		}
+		containers = append(containers, "@set")
+	}
+	containers = append(containers, "@none")
+	if !p.options.processingMode(JSONLD10) {
+		if !hasIndex {
+			containers = append(containers, "@index", "@index@set")
+		}
+		if isValueObject(value) && len(m) == 1 {
+			containers = append(containers, "@language", "@language@set")
+		}
+	}
+
+	var preferred []string
+	if typeLanguageValue == "@reverse" {
+		preferred = append(preferred, "@reverse")
+	}
+	if id, ok := m["@id"].(string); ok && (typeLanguageValue == "@id" || typeLanguageValue == "@reverse") {
+		c, err := p.compactIRI(active, id, nil, true, false)
+		if err != nil {
+			return "", err
+		}
+		if d, ok := active.terms[c]; ok && d.id == id {
+			preferred = append(preferred, "@vocab", "@id", "@none")
+		} else {
+			preferred = append(preferred, "@id", "@vocab", "@none")
+		}
+	} else {
+		preferred = append(preferred, typeLanguageValue, "@none")
+		if isListObject(value) && len(list) == 0 {
+			typeLanguage = "@any"
+		}
+	}
+	preferred = append(preferred, "@any")
+	for _, v := range preferred {
+		if i := strings.IndexByte(v, '_'); 0 <= i {
+			// Fall back to terms that only match the direction.
+			preferred = append(preferred, v[i:])
+			break
+		}
+	}
+
+	containerMap := active.inverseContext()[iri]
+	for _, c := range containers {
+		typeLanguageMap, ok := containerMap[c]
+		if !ok {
+			continue
+		}
+		valueMap := typeLanguageMap[typeLanguage]
+		for _, v := range preferred {
+			if t, ok := valueMap[v]; ok {
+				return t, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// inverseContext maps IRIs to containers, containers to @language, @type or @any, and those to the values and their
+// preferred term.
+type inverseContext map[string]map[string]map[string]map[string]string
+
+// inverseContext returns the inverse context of the active context, it is created on first use.
+func (c *activeContext) inverseContext() inverseContext {
+	if c.inverse != nil {
+		return c.inverse
+	}
+	defaultLanguage := "@none"
+	if c.language != "" {
+		defaultLanguage = strings.ToLower(c.language)
+	}
+	result := make(inverseContext)
+	terms := sortedKeys(c.terms)
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len(terms[i]) < len(terms[j])
+	})
+	for _, t := range terms {
+		d := c.terms[t]
+		if d.id == "" {
+			continue
+		}
+		container := "@none"
+		if len(d.container) != 0 {
+			container = strings.Join(sortedStrings(d.container), "")
+		}
+		containerMap, ok := result[d.id]
+		if !ok {
+			containerMap = make(map[string]map[string]map[string]string)
+			result[d.id] = containerMap
+		}
+		typeLanguageMap, ok := containerMap[container]
+		if !ok {
+			typeLanguageMap = map[string]map[string]string{
+				"@language": {},
+				"@type":     {},
+				"@any":      {"@none": t},
+			}
+			containerMap[container] = typeLanguageMap
+		}
+		languages, types := typeLanguageMap["@language"], typeLanguageMap["@type"]
+		switch {
+		case d.reverse:
+			setDefault(types, "@reverse", t)
+		case d.typ == "@none":
+			setDefault(languages, "@any", t)
+			setDefault(types, "@any", t)
+		case d.typ != "":
+			setDefault(types, d.typ, t)
+		case d.hasLanguage && d.hasDirection:
+			key := "@null"
+			switch {
+			case d.language != "" && d.direction != "":
+				key = strings.ToLower(d.language + "_" + d.direction)
+			case d.language != "":
+				key = strings.ToLower(d.language)
+			case d.direction != "":
+				key = "_" + d.direction
+			}
+			setDefault(languages, key, t)
+		case d.hasLanguage:
+			key := "@null"
+			if d.language != "" {
+				key = strings.ToLower(d.language)
+			}
+			setDefault(languages, key, t)
+		case d.hasDirection:
+			key := "@none"
+			if d.direction != "" {
+				key = "_" + d.direction
+			}
+			setDefault(languages, key, t)
+		case c.direction != "":
+			setDefault(languages, strings.ToLower(c.language+"_"+c.direction), t)
+			setDefault(languages, "@none", t)
+			setDefault(types, "@none", t)
+		default:
+			setDefault(languages, defaultLanguage, t)
+			setDefault(languages, "@none", t)
+			setDefault(types, "@none", t)
+		}
+	}
+	c.inverse = result
+	return result
+}
+
+func setDefault(m map[string]string, key, value string) {
+	if _, ok := m[key]; !ok {
+		m[key] = value
+	}
+}
+
+// addCompacted adds the value to the given key of the compacted object, the value is only converted into an array if
+// the key has multiple values, or if asArray is true.
+func addCompacted(m map[string]any, key string, value any, asArray bool) {
+	if asArray {
+		if v, ok := m[key]; !ok {
+			m[key] = []any{}
+		} else if _, ok := v.([]any); !ok {
+			m[key] = []any{v}
+		}
+	}
+	if a, ok := value.([]any); ok {
+		for _, v := range a {
+			addCompacted(m, key, v, false)
+		}
+		return
+	}
+	v, ok := m[key]
+	if !ok {
+		m[key] = value
+		return
+	}
+	a, ok := v.([]any)
+	if !ok {
+		a = []any{v}
+	}
+	m[key] = append(a, value)
+}
+
+// mapEntry returns the map of the given key, it is created if it does not exist yet.
+func mapEntry(m map[string]any, key string) map[string]any {
+	v, ok := m[key].(map[string]any)
+	if !ok {
+		v = make(map[string]any)
+		m[key] = v
+	}
+	return v
+}
+
+// valuesOf returns the values of the given key as an array.
+func valuesOf(m map[string]any, key string) []any {
+	v, ok := m[key]
+	if !ok {
+		return nil
+	}
+	return asArray(v)
+}
+
+// setValues sets the remaining values of the key, the key is removed if there are none.
+func setValues(m map[string]any, key string, values []any) {
+	switch len(values) {
+	case 0:
+		delete(m, key)
+	case 1:
+		m[key] = values[0]
+	default:
+		m[key] = values
+	}
+}
+
+// isNodeReference returns true if the value is a node object that only contains an @id entry, and optionally an
+// @index entry.
+func isNodeReference(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	if _, ok := m["@id"]; !ok {
+		return false
+	}
+	for k := range m {
+		if k != "@id" && k != "@index" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	"testing"
+)
+
+func ExampleCompact() {
+	var doc any
+	_ = json.Unmarshal([]byte(`[{
+	"@id": "http://example.com/alice",
+	"@type": ["http://xmlns.com/foaf/0.1/Person"],
+	"http://xmlns.com/foaf/0.1/name": [{"@value": "Alice"}]
+}]`), &doc)
+	compacted, _ := jsonld.Compact(doc, map[string]any{"@vocab": "http://xmlns.com/foaf/0.1/"}, nil)
+	raw, _ := json.Marshal(compacted)
+	fmt.Println(string(raw))
+	// Output:
+	// {"@context":{"@vocab":"http://xmlns.com/foaf/0.1/"},"@id":"http://example.com/alice","@type":"Person","name":"Alice"}
+}
+
+func TestCompact(t *testing.T) {
+	runJSONTests(t, "compact", func(t *testing.T, s *testSuite, e *testsuite.Test) (any, error) {
+		return jsonld.Compact(s.readJSON(t, e.Action), s.readJSON(t, e.Context), s.options(e))
+	})
+}
@@ -0,0 +1,757 @@
+package jsonld
+
+import (
+	"github.com/0x51-dev/rdf/iri"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxRemoteContexts limits the number of nested remote contexts, to protect against recursive inclusion.
+const maxRemoteContexts = 32
+
+// activeContext is an active context, the result of processing one or more local contexts.
+type activeContext struct {
+	base         string
+	originalBase string
+	vocab        string
+	hasVocab     bool
+	language     string
+	direction    string
+	terms        map[string]*term
+	// previous is the context to revert to when entering a new node object, used for type-scoped contexts that do not
+	// propagate.
+	previous *activeContext
+	// inverse is the inverse context, created on demand by the compaction algorithm.
+	inverse inverseContext
+}
+
+func newContext(base string) *activeContext {
+	return &activeContext{base: base, originalBase: base, terms: make(map[string]*term)}
+}
+
+func (c *activeContext) clone() *activeContext {
+	n := *c
+	n.inverse = nil
+	n.terms = make(map[string]*term, len(c.terms))
+	for k, v := range c.terms {
+		n.terms[k] = v
+	}
+	return &n
+}
+
+// container returns the container mapping of the given term, if any.
+func (c *activeContext) container(t string) []string {
+	if d, ok := c.terms[t]; ok {
+		return d.container
+	}
+	return nil
+}
+
+func (c *activeContext) hasProtectedTerms() bool {
+	for _, t := range c.terms {
+		if t.protected {
+			return true
+		}
+	}
+	return false
+}
+
+// term is a term definition.
+type term struct {
+	// id is the IRI mapping, or keyword, of the term. Empty if the term is explicitly decoupled from an IRI.
+	id           string
+	reverse      bool
+	typ          string
+	language     string
+	hasLanguage  bool
+	direction    string
+	hasDirection bool
+	container    []string
+	context      any
+	hasContext   bool
+	baseURL      string
+	prefix       bool
+	protected    bool
+	index        string
+	nest         string
+}
+
+func (t *term) hasContainer(v string) bool {
+	return t != nil && slices.Contains(t.container, v)
+}
+
+// equal returns true if both definitions are the same, ignoring whether they are protected.
+func (t *term) equal(o *term) bool {
+	a, b := *t, *o
+	a.protected, b.protected = false, false
+	return reflect.DeepEqual(a, b)
+}
+
+// processor holds the state shared by the processing algorithms.
+type processor struct {
+	options *Options
+	// contexts caches the remote contexts that have been loaded.
+	contexts map[string]any
+}
+
+func newProcessor(options *Options) *processor {
+	if options == nil {
+		options = new(Options)
+	}
+	return &processor{options: options, contexts: make(map[string]any)}
+}
+
+// initialContext returns the initial active context, including the expand context of the options.
+func (p *processor) initialContext() (*activeContext, error) {
+	active := newContext(p.options.Base)
+	if c := p.options.ExpandContext; c != nil {
+		if m, ok := c.(map[string]any); ok {
+			if v, ok := m["@context"]; ok {
+				c = v
+			}
+		}
+		return p.processContext(active, c, p.options.Base, nil, false, true, true)
+	}
+	return active, nil
+}
+
+// processContext implements the context processing algorithm.
+func (p *processor) processContext(
+	active *activeContext,
+	local any,
+	baseURL string,
+	remote []string,
+	overrideProtected, propagate, validateScoped bool,
+) (*activeContext, error) {
+	result := active.clone()
+	if m, ok := local.(map[string]any); ok {
+		if v, ok := m["@propagate"]; ok {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, newError(InvalidPropagateValue, "%v", v)
+			}
+			propagate = b
+		}
+	}
+	if !propagate && result.previous == nil {
+		result.previous = active
+	}
+
+	for _, context := range asArray(local) {
+		switch c := context.(type) {
+		case nil:
+			if !overrideProtected && result.hasProtectedTerms() {
+				return nil, newError(InvalidContextNullification, "context contains protected terms")
+			}
+			previous := result
+			result = newContext(active.originalBase)
+			if !propagate {
+				result.previous = previous
+			}
+			continue
+		case string:
+			var err error
+			if result, err = p.processRemoteContext(result, c, baseURL, remote, validateScoped); err != nil {
+				return nil, err
+			}
+			continue
+		case map[string]any:
+			if err := p.processLocalContext(result, c, baseURL, remote, overrideProtected); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, newError(InvalidLocalContext, "%v", context)
+		}
+	}
+	return result, nil
+}
+
+func (p *processor) processRemoteContext(
+	result *activeContext,
+	ref, baseURL string,
+	remote []string,
+	validateScoped bool,
+) (*activeContext, error) {
+	url := iri.Resolve(baseURL, ref)
+	if !validateScoped && slices.Contains(remote, url) {
+		return result, nil
+	}
+	if maxRemoteContexts <= len(remote) {
+		return nil, newError(ContextOverflow, "%s", url)
+	}
+	context, err := p.loadContext(url)
+	if err != nil {
+		return nil, err
+	}
+	return p.processContext(result, context, url, append(slices.Clone(remote), url), false, true, validateScoped)
+}
+
+// loadContext returns the @context entry of the remote document with the given URL.
+func (p *processor) loadContext(url string) (any, error) {
+	if c, ok := p.contexts[url]; ok {
+		return c, nil
+	}
+	if p.options.DocumentLoader == nil {
+		return nil, newError(LoadingRemoteContextFailed, "%s: no document loader", url)
+	}
+	doc, err := p.options.DocumentLoader.LoadDocument(url)
+	if err != nil {
+		return nil, newError(LoadingRemoteContextFailed, "%s: %s", url, err)
+	}
+	m, ok := doc.Document.(map[string]any)
+	if !ok {
+		return nil, newError(InvalidRemoteContext, "%s: not a map", url)
+	}
+	c, ok := m["@context"]
+	if !ok {
+		return nil, newError(InvalidRemoteContext, "%s: no @context entry", url)
+	}
+	p.contexts[url] = c
+	return c, nil
+}
+
+func (p *processor) processLocalContext(
+	result *activeContext,
+	c map[string]any,
+	baseURL string,
+	remote []string,
+	overrideProtected bool,
+) error {
+	if v, ok := c["@version"]; ok {
+		if v != 1.1 {
+			return newError(InvalidVersionValue, "%v", v)
+		}
+		if p.options.processingMode(JSONLD10) {
+			return newError(ProcessingModeConflict, "@version is not supported in %s", JSONLD10)
+		}
+	}
+	if v, ok := c["@import"]; ok {
+		if p.options.processingMode(JSONLD10) {
+			return newError(InvalidContextEntry, "@import is not supported in %s", JSONLD10)
+		}
+		ref, ok := v.(string)
+		if !ok {
+			return newError(InvalidImportValue, "%v", v)
+		}
+		imported, err := p.loadContext(iri.Resolve(baseURL, ref))
+		if err != nil {
+			return err
+		}
+		m, ok := imported.(map[string]any)
+		if !ok {
+			return newError(InvalidRemoteContext, "imported context is not a map")
+		}
+		if _, ok := m["@import"]; ok {
+			return newError(InvalidContextEntry, "imported context contains @import")
+		}
+		merged := make(map[string]any, len(m)+len(c))
+		for k, v := range m {
+			merged[k] = v
+		}
+		for k, v := range c {
+			merged[k] = v
+		}
+		c = merged
+	}
+	if v, ok := c["@base"]; ok && len(remote) == 0 {
+		switch v := v.(type) {
+		case nil:
+			result.base = ""
+		case string:
+			switch {
+			case isAbsoluteIRI(v):
+				result.base = v
+			case result.base != "":
+				result.base = iri.Resolve(result.base, v)
+			default:
+				return newError(InvalidBaseIRI, "relative base IRI without base: %s", v)
+			}
+		default:
+			return newError(InvalidBaseIRI, "%v", v)
+		}
+	}
+	if v, ok := c["@vocab"]; ok {
+		switch v := v.(type) {
+		case nil:
+			result.vocab, result.hasVocab = "", false
+		case string:
+			if !isAbsoluteIRI(v) && !isBlankNode(v) && p.options.processingMode(JSONLD10) {
+				return newError(InvalidVocabMapping, "relative vocabulary mapping: %s", v)
+			}
+			vocab, ok, err := p.expandIRI(result, v, true, true, nil, nil)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return newError(InvalidVocabMapping, "%s", v)
+			}
+			result.vocab, result.hasVocab = vocab, true
+		default:
+			return newError(InvalidVocabMapping, "%v", v)
+		}
+	}
+	if v, ok := c["@language"]; ok {
+		switch v := v.(type) {
+		case nil:
+			result.language = ""
+		case string:
+			result.language = v
+		default:
+			return newError(InvalidDefaultLanguage, "%v", v)
+		}
+	}
+	if v, ok := c["@direction"]; ok {
+		if p.options.processingMode(JSONLD10) {
+			return newError(InvalidContextEntry, "@direction is not supported in %s", JSONLD10)
+		}
+		switch v {
+		case nil:
+			result.direction = ""
+		case "ltr", "rtl":
+			result.direction = v.(string)
+		default:
+			return newError(InvalidBaseDirection, "%v", v)
+		}
+	}
+	if _, ok := c["@propagate"]; ok && p.options.processingMode(JSONLD10) {
+		return newError(InvalidContextEntry, "@propagate is not supported in %s", JSONLD10)
+	}
+
+	if v, ok := c["@protected"]; ok {
+		if _, ok := v.(bool); !ok {
+			return newError(InvalidProtectedValue, "%v", v)
+		}
+	}
+	defined := make(map[string]bool)
+	for _, k := range sortedKeys(c) {
+		switch k {
+		case "@base", "@direction", "@import", "@language", "@propagate", "@protected", "@version", "@vocab":
+			continue
+		}
+		if err := p.createTerm(result, c, k, defined, baseURL, overrideProtected, remote); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTerm implements the create term definition algorithm.
+func (p *processor) createTerm(
+	active *activeContext,
+	local map[string]any,
+	t string,
+	defined map[string]bool,
+	baseURL string,
+	overrideProtected bool,
+	remote []string,
+) error {
+	if done, ok := defined[t]; ok {
+		if done {
+			return nil
+		}
+		return newError(CyclicIRIMapping, "%s", t)
+	}
+	if t == "" {
+		return newError(InvalidTermDefinition, "empty term")
+	}
+	defined[t] = false
+	value := local[t]
+
+	if t == "@type" && p.options.processingMode(JSONLD11) {
+		m, ok := value.(map[string]any)
+		if !ok || len(m) == 0 {
+			return newError(KeywordRedefinition, "%s", t)
+		}
+		for k, v := range m {
+			switch {
+			case k == "@container" && v == "@set":
+			case k == "@protected":
+			default:
+				return newError(KeywordRedefinition, "%s", t)
+			}
+		}
+	} else if isKeyword(t) {
+		return newError(KeywordRedefinition, "%s", t)
+	} else if looksLikeKeyword(t) {
+		// Terms that have the form of a keyword are ignored.
+		defined[t] = true
+		return nil
+	}
+
+	previous := active.terms[t]
+	delete(active.terms, t)
+
+	simple := false
+	var m map[string]any
+	switch v := value.(type) {
+	case nil:
+		m = map[string]any{"@id": nil}
+	case string:
+		m, simple = map[string]any{"@id": v}, true
+	case map[string]any:
+		m = v
+	default:
+		return newError(InvalidTermDefinition, "%s", t)
+	}
+
+	// Terms are protected if the local context is protected, unless overridden by the definition itself.
+	protected, _ := local["@protected"].(bool)
+	def := &term{protected: protected}
+	for k := range m {
+		switch k {
+		case "@id", "@reverse", "@container", "@context", "@language", "@type":
+		case "@direction", "@index", "@nest", "@prefix", "@protected":
+			if p.options.processingMode(JSONLD10) {
+				return newError(InvalidTermDefinition, "%s: %s is not supported in %s", t, k, JSONLD10)
+			}
+		default:
+			return newError(InvalidTermDefinition, "%s: unexpected entry %s", t, k)
+		}
+	}
+	if v, ok := m["@protected"]; ok {
+		b, ok := v.(bool)
+		if !ok {
+			return newError(InvalidProtectedValue, "%v", v)
+		}
+		def.protected = b
+	}
+	if v, ok := m["@type"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return newError(InvalidTypeMapping, "%v", v)
+		}
+		typ, ok, err := p.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !ok:
+			return newError(InvalidTypeMapping, "%s", s)
+		case typ == "@json" || typ == "@none":
+			if p.options.processingMode(JSONLD10) {
+				return newError(InvalidTypeMapping, "%s is not supported in %s", typ, JSONLD10)
+			}
+		case typ == "@id" || typ == "@vocab":
+		case !isAbsoluteIRI(typ):
+			return newError(InvalidTypeMapping, "%s", typ)
+		}
+		def.typ = typ
+	}
+
+	if v, ok := m["@reverse"]; ok {
+		if _, ok := m["@id"]; ok {
+			return newError(InvalidReverseProperty, "%s: @reverse and @id", t)
+		}
+		if _, ok := m["@nest"]; ok {
+			return newError(InvalidReverseProperty, "%s: @reverse and @nest", t)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return newError(InvalidIRIMapping, "%v", v)
+		}
+		if looksLikeKeyword(s) {
+			defined[t] = true
+			return nil
+		}
+		id, ok, err := p.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if !ok || !strings.Contains(id, ":") {
+			return newError(InvalidIRIMapping, "%s", s)
+		}
+		def.id, def.reverse = id, true
+		if v, ok := m["@container"]; ok {
+			switch v {
+			case nil, "@set", "@index":
+				if v != nil {
+					def.container = []string{v.(string)}
+				}
+			default:
+				return newError(InvalidReverseProperty, "%s: invalid container %v", t, v)
+			}
+		}
+		active.terms[t] = def
+		defined[t] = true
+		return nil
+	}
+
+	if v, ok := m["@id"]; ok && v != t {
+		switch v := v.(type) {
+		case nil:
+			// The term is decoupled from any IRI.
+		case string:
+			if !isKeyword(v) && looksLikeKeyword(v) {
+				defined[t] = true
+				return nil
+			}
+			id, ok, err := p.expandIRI(active, v, false, true, local, defined)
+			if err != nil {
+				return err
+			}
+			if !ok || (!isKeyword(id) && !strings.Contains(id, ":")) {
+				return newError(InvalidIRIMapping, "%s: %s", t, v)
+			}
+			if id == "@context" {
+				return newError(InvalidKeywordAlias, "%s", t)
+			}
+			def.id = id
+			if 0 < strings.IndexByte(strings.TrimSuffix(t, ":"), ':') || strings.Contains(t, "/") {
+				defined[t] = true
+				expanded, _, err := p.expandIRI(active, t, false, true, local, defined)
+				if err != nil {
+					return err
+				}
+				if expanded != id {
+					return newError(InvalidIRIMapping, "%s does not expand to %s", t, id)
+				}
+			}
+			if !strings.ContainsAny(t, ":/") && simple &&
+				(isBlankNode(id) || strings.ContainsAny(id[len(id)-1:], ":/?#[]@")) {
+				def.prefix = true
+			}
+		default:
+			return newError(InvalidIRIMapping, "%s: %v", t, v)
+		}
+	} else if i := strings.IndexByte(t[1:], ':'); 0 <= i {
+		prefix, suffix := t[:i+1], t[i+2:]
+		if _, ok := local[prefix]; ok {
+			if err := p.createTerm(active, local, prefix, defined, baseURL, false, remote); err != nil {
+				return err
+			}
+		}
+		if d, ok := active.terms[prefix]; ok {
+			def.id = d.id + suffix
+		} else {
+			def.id = t
+		}
+	} else if strings.Contains(t, "/") {
+		id, ok, err := p.expandIRI(active, t, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if !ok || !isAbsoluteIRI(id) {
+			return newError(InvalidIRIMapping, "%s", t)
+		}
+		def.id = id
+	} else if t == "@type" {
+		def.id = "@type"
+	} else if active.hasVocab {
+		def.id = active.vocab + t
+	} else {
+		return newError(InvalidIRIMapping, "%s: no vocabulary mapping", t)
+	}
+
+	if v, ok := m["@container"]; ok {
+		container, err := p.containerMapping(v)
+		if err != nil {
+			return err
+		}
+		def.container = container
+		if def.hasContainer("@type") {
+			switch def.typ {
+			case "":
+				def.typ = "@id"
+			case "@id", "@vocab":
+			default:
+				return newError(InvalidTypeMapping, "%s: type map requires @id or @vocab", t)
+			}
+		}
+	}
+	if v, ok := m["@index"]; ok {
+		s, ok := v.(string)
+		if !def.hasContainer("@index") || !ok || isKeyword(s) {
+			return newError(InvalidTermDefinition, "%s: invalid @index %v", t, v)
+		}
+		def.index = s
+	}
+	if v, ok := m["@context"]; ok {
+		if p.options.processingMode(JSONLD10) {
+			return newError(InvalidTermDefinition, "%s: scoped contexts are not supported in %s", t, JSONLD10)
+		}
+		if _, err := p.processContext(active, v, baseURL, remote, true, true, false); err != nil {
+			return newError(InvalidScopedContext, "%s: %s", t, err)
+		}
+		def.context, def.hasContext, def.baseURL = v, true, baseURL
+	}
+	if v, ok := m["@language"]; ok && def.typ == "" {
+		switch v := v.(type) {
+		case nil, string:
+			def.language, _ = v.(string)
+			def.hasLanguage = true
+		default:
+			return newError(InvalidLanguageMapping, "%s: %v", t, v)
+		}
+	}
+	if v, ok := m["@direction"]; ok && def.typ == "" {
+		switch v {
+		case nil, "ltr", "rtl":
+			def.direction, _ = v.(string)
+			def.hasDirection = true
+		default:
+			return newError(InvalidBaseDirection, "%s: %v", t, v)
+		}
+	}
+	if v, ok := m["@nest"]; ok {
+		s, ok := v.(string)
+		if !ok || (isKeyword(s) && s != "@nest") {
+			return newError(InvalidNestValue, "%s: %v", t, v)
+		}
+		def.nest = s
+	}
+	if v, ok := m["@prefix"]; ok {
+		if strings.ContainsAny(t, ":/") {
+			return newError(InvalidTermDefinition, "%s: @prefix on compact IRI", t)
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return newError(InvalidPrefixValue, "%s: %v", t, v)
+		}
+		if b && isKeyword(def.id) {
+			return newError(InvalidTermDefinition, "%s: keyword can not be a prefix", t)
+		}
+		def.prefix = b
+	}
+
+	if !overrideProtected && previous != nil && previous.protected {
+		if !def.equal(previous) {
+			return newError(ProtectedTermRedefinition, "%s", t)
+		}
+		def = previous
+	}
+	active.terms[t] = def
+	defined[t] = true
+	return nil
+}
+
+// containerMapping validates the value of a @container entry.
+func (p *processor) containerMapping(v any) ([]string, error) {
+	var container []string
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		container = []string{v}
+	case []any:
+		if p.options.processingMode(JSONLD10) {
+			return nil, newError(InvalidContainerMapping, "%v", v)
+		}
+		for _, c := range v {
+			s, ok := c.(string)
+			if !ok {
+				return nil, newError(InvalidContainerMapping, "%v", v)
+			}
+			container = append(container, s)
+		}
+	default:
+		return nil, newError(InvalidContainerMapping, "%v", v)
+	}
+	var others []string
+	for _, c := range container {
+		switch c {
+		case "@graph", "@id", "@type":
+			if p.options.processingMode(JSONLD10) {
+				return nil, newError(InvalidContainerMapping, "%s is not supported in %s", c, JSONLD10)
+			}
+		case "@index", "@language", "@list", "@set":
+		default:
+			return nil, newError(InvalidContainerMapping, "%s", c)
+		}
+		if c != "@set" && !slices.Contains(others, c) {
+			others = append(others, c)
+		}
+	}
+	valid := len(others) <= 1
+	switch {
+	case slices.Contains(others, "@list"):
+		valid = len(container) == 1
+	case slices.Contains(others, "@graph"):
+		valid = len(others) == 1 ||
+			(len(others) == 2 && (slices.Contains(others, "@id") || slices.Contains(others, "@index")))
+	}
+	if !valid {
+		return nil, newError(InvalidContainerMapping, "%v", container)
+	}
+	return container, nil
+}
+
+// expandIRI implements the IRI expansion algorithm. Returns false if the value expands to null.
+func (p *processor) expandIRI(
+	active *activeContext,
+	value string,
+	relative, vocab bool,
+	local map[string]any,
+	defined map[string]bool,
+) (string, bool, error) {
+	if isKeyword(value) {
+		return value, true, nil
+	}
+	if looksLikeKeyword(value) {
+		return "", false, nil
+	}
+	if local != nil {
+		if _, ok := local[value]; ok && !defined[value] {
+			if err := p.createTerm(active, local, value, defined, "", false, nil); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	if d, ok := active.terms[value]; ok {
+		if isKeyword(d.id) {
+			return d.id, true, nil
+		}
+		if vocab {
+			return d.id, d.id != "", nil
+		}
+	}
+	if i := strings.IndexByte(value, ':'); 0 < i {
+		prefix, suffix := value[:i], value[i+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value, true, nil
+		}
+		if local != nil {
+			if _, ok := local[prefix]; ok && !defined[prefix] {
+				if err := p.createTerm(active, local, prefix, defined, "", false, nil); err != nil {
+					return "", false, err
+				}
+			}
+		}
+		if d, ok := active.terms[prefix]; ok && d.id != "" && d.prefix {
+			return d.id + suffix, true, nil
+		}
+		if isAbsoluteIRI(value) {
+			return value, true, nil
+		}
+	}
+	if vocab && active.hasVocab {
+		return active.vocab + value, true, nil
+	}
+	if relative {
+		return iri.Resolve(active.base, value), true, nil
+	}
+	return value, true, nil
+}
+
+func asArray(v any) []any {
+	if a, ok := v.([]any); ok {
+		return a
+	}
+	return []any{v}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStrings(v []string) []string {
+	s := slices.Clone(v)
+	sort.Strings(s)
+	return s
+}
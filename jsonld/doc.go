@@ -0,0 +1,150 @@
+// Package jsonld implements the JSON-LD 1.1 processing algorithms and the framing algorithm.
+//
+// Documents are represented as generic JSON values, as returned by json.Unmarshal into an `any`: maps, slices,
+// strings, float64 numbers, booleans and nil.
+//
+// Reference: https://www.w3.org/TR/json-ld11-api/, https://www.w3.org/TR/json-ld11-framing/
+package jsonld
+
+import "fmt"
+
+const (
+	rdf           = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	rdfFirst      = rdf + "first"
+	rdfJSON       = rdf + "JSON"
+	rdfLangString = rdf + "langString"
+	rdfList       = rdf + "List"
+	rdfNil        = rdf + "nil"
+	rdfRest       = rdf + "rest"
+	rdfType       = rdf + "type"
+
+	xsd        = "http://www.w3.org/2001/XMLSchema#"
+	xsdBoolean = xsd + "boolean"
+	xsdDouble  = xsd + "double"
+	xsdInteger = xsd + "integer"
+	xsdString  = xsd + "string"
+
+	i18n = "https://www.w3.org/ns/i18n#"
+)
+
+// Processing modes.
+const (
+	JSONLD10 = "json-ld-1.0"
+	JSONLD11 = "json-ld-1.1"
+)
+
+// Options are the options of the JSON-LD processing algorithms. The zero value uses the defaults of the
+// specification.
+type Options struct {
+	// Base is the base IRI of the document, used to resolve relative IRIs.
+	Base string
+	// ExpandContext is an optional context that is applied to the input before expansion.
+	ExpandContext any
+	// DocumentLoader is used to load remote contexts. Loading remote contexts fails if none is specified.
+	DocumentLoader DocumentLoader
+	// ProcessingMode is either JSONLD10 or JSONLD11, defaults to JSONLD11.
+	ProcessingMode string
+
+	// UseNativeTypes converts xsd:boolean, xsd:integer and xsd:double literals to native JSON values (fromRdf).
+	UseNativeTypes bool
+	// UseRdfType keeps rdf:type as a regular property instead of converting it to @type (fromRdf).
+	UseRdfType bool
+	// RdfDirection determines how the base direction of strings is represented in RDF, only "i18n-datatype" is
+	// supported. The base direction is dropped if empty (toRdf and fromRdf).
+	RdfDirection string
+
+	// NoCompactArrays keeps arrays with a single element, instead of replacing them by that element (compaction).
+	NoCompactArrays bool
+	// NoCompactToRelative keeps IRIs absolute, instead of making them relative to the base IRI (compaction).
+	NoCompactToRelative bool
+
+	// Embed is the default value of @embed, either "@once", "@always" or "@never". Defaults to "@once" (framing).
+	Embed string
+	// Explicit is the default value of @explicit, only properties of the frame are included in the output (framing).
+	Explicit bool
+	// OmitDefault is the default value of @omitDefault, no defaults are added for missing properties (framing).
+	OmitDefault bool
+	// OmitGraph omits the top-level @graph entry if the result consists of a single node (framing).
+	OmitGraph bool
+	// RequireAll is the default value of @requireAll, all properties of the frame must match (framing).
+	RequireAll bool
+}
+
+func (o *Options) processingMode(mode string) bool {
+	if o.ProcessingMode == "" {
+		return mode == JSONLD11
+	}
+	return o.ProcessingMode == mode
+}
+
+// ErrorCode is a JSON-LD error code, as defined by the specification.
+type ErrorCode string
+
+const (
+	CollidingKeywords           ErrorCode = "colliding keywords"
+	ConflictingIndexes          ErrorCode = "conflicting indexes"
+	ContextOverflow             ErrorCode = "context overflow"
+	CyclicIRIMapping            ErrorCode = "cyclic IRI mapping"
+	IRIConfusedWithPrefix       ErrorCode = "IRI confused with prefix"
+	InvalidBaseDirection        ErrorCode = "invalid base direction"
+	InvalidBaseIRI              ErrorCode = "invalid base IRI"
+	InvalidContainerMapping     ErrorCode = "invalid container mapping"
+	InvalidContextEntry         ErrorCode = "invalid context entry"
+	InvalidContextNullification ErrorCode = "invalid context nullification"
+	InvalidDefaultLanguage      ErrorCode = "invalid default language"
+	InvalidEmbedValue           ErrorCode = "invalid @embed value"
+	InvalidFrame                ErrorCode = "invalid frame"
+	InvalidIRIMapping           ErrorCode = "invalid IRI mapping"
+	InvalidIdValue              ErrorCode = "invalid @id value"
+	InvalidImportValue          ErrorCode = "invalid @import value"
+	InvalidIncludedValue        ErrorCode = "invalid @included value"
+	InvalidJSONLiteral          ErrorCode = "invalid JSON literal"
+	InvalidIndexValue           ErrorCode = "invalid @index value"
+	InvalidKeywordAlias         ErrorCode = "invalid keyword alias"
+	InvalidLanguageMapValue     ErrorCode = "invalid language map value"
+	InvalidLanguageMapping      ErrorCode = "invalid language mapping"
+	InvalidLanguageTaggedString ErrorCode = "invalid language-tagged string"
+	InvalidLanguageTaggedValue  ErrorCode = "invalid language-tagged value"
+	InvalidLocalContext         ErrorCode = "invalid local context"
+	InvalidNestValue            ErrorCode = "invalid @nest value"
+	InvalidPrefixValue          ErrorCode = "invalid @prefix value"
+	InvalidPropagateValue       ErrorCode = "invalid @propagate value"
+	InvalidProtectedValue       ErrorCode = "invalid @protected value"
+	InvalidRemoteContext        ErrorCode = "invalid remote context"
+	InvalidReverseProperty      ErrorCode = "invalid reverse property"
+	InvalidReversePropertyMap   ErrorCode = "invalid reverse property map"
+	InvalidReversePropertyValue ErrorCode = "invalid reverse property value"
+	InvalidReverseValue         ErrorCode = "invalid @reverse value"
+	InvalidScopedContext        ErrorCode = "invalid scoped context"
+	InvalidSetOrListObject      ErrorCode = "invalid set or list object"
+	InvalidTermDefinition       ErrorCode = "invalid term definition"
+	InvalidTypeMapping          ErrorCode = "invalid type mapping"
+	InvalidTypeValue            ErrorCode = "invalid type value"
+	InvalidTypedValue           ErrorCode = "invalid typed value"
+	InvalidValueObject          ErrorCode = "invalid value object"
+	InvalidValueObjectValue     ErrorCode = "invalid value object value"
+	InvalidVersionValue         ErrorCode = "invalid @version value"
+	InvalidVocabMapping         ErrorCode = "invalid vocab mapping"
+	KeywordRedefinition         ErrorCode = "keyword redefinition"
+	LoadingDocumentFailed       ErrorCode = "loading document failed"
+	LoadingRemoteContextFailed  ErrorCode = "loading remote context failed"
+	ProcessingModeConflict      ErrorCode = "processing mode conflict"
+	ProtectedTermRedefinition   ErrorCode = "protected term redefinition"
+)
+
+// Error is an error raised by one of the JSON-LD processing algorithms.
+type Error struct {
+	Code    ErrorCode
+	Details string
+}
+
+func newError(code ErrorCode, format string, args ...any) *Error {
+	return &Error{Code: code, Details: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.Details == "" {
+		return fmt.Sprintf("jsonld: %s", e.Code)
+	}
+	return fmt.Sprintf("jsonld: %s: %s", e.Code, e.Details)
+}
@@ -0,0 +1,781 @@
+package jsonld
+
+import (
+	"strings"
+)
+
+// Expand expands the JSON-LD document, removing its context and representing all IRIs, types and values explicitly.
+// The result is always an array of node objects.
+func Expand(input any, options *Options) ([]any, error) {
+	return newProcessor(options).expandDocument(input, false)
+}
+
+func (p *processor) expandDocument(input any, frame bool) ([]any, error) {
+	active, err := p.initialContext()
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := p.expand(active, "", input, p.options.Base, frame, false)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := expanded.(map[string]any); ok && len(m) == 1 {
+		if g, ok := m["@graph"]; ok {
+			expanded = g
+		}
+	}
+	if expanded == nil {
+		return []any{}, nil
+	}
+	return asArray(expanded), nil
+}
+
+// expand implements the expansion algorithm, an empty active property represents null.
+func (p *processor) expand(
+	active *activeContext,
+	property string,
+	element any,
+	baseURL string,
+	frame, fromMap bool,
+) (any, error) {
+	if element == nil {
+		return nil, nil
+	}
+	if property == "@default" {
+		frame = false
+	}
+	var scoped *term
+	if d, ok := active.terms[property]; ok && d.hasContext {
+		scoped = d
+	}
+
+	switch e := element.(type) {
+	case []any:
+		result := []any{}
+		for _, item := range e {
+			v, err := p.expand(active, property, item, baseURL, frame, fromMap)
+			if err != nil {
+				return nil, err
+			}
+			if a, ok := v.([]any); ok && active.terms[property].hasContainer("@list") {
+				v = map[string]any{"@list": a}
+			}
+			switch v := v.(type) {
+			case nil:
+			case []any:
+				result = append(result, v...)
+			default:
+				result = append(result, v)
+			}
+		}
+		return result, nil
+	case map[string]any:
+		return p.expandMap(active, property, e, baseURL, frame, fromMap, scoped)
+	default:
+		if property == "" || property == "@graph" {
+			// Free-floating scalars are dropped.
+			return nil, nil
+		}
+		if scoped != nil {
+			var err error
+			active, err = p.processContext(active, scoped.context, scoped.baseURL, nil, false, true, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return p.expandValue(active, property, e)
+	}
+}
+
+func (p *processor) expandMap(
+	active *activeContext,
+	property string,
+	e map[string]any,
+	baseURL string,
+	frame, fromMap bool,
+	scoped *term,
+) (any, error) {
+	// Contexts that do not propagate are reverted when entering a new node object.
+	if active.previous != nil && !fromMap {
+		revert := true
+		for _, k := range sortedKeys(e) {
+			ek, _, err := p.expandIRI(active, k, false, true, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			if ek == "@value" || (ek == "@id" && len(e) == 1) {
+				revert = false
+				break
+			}
+		}
+		if revert {
+			active = active.previous
+		}
+	}
+	var err error
+	if scoped != nil {
+		if active, err = p.processContext(active, scoped.context, scoped.baseURL, nil, true, true, true); err != nil {
+			return nil, err
+		}
+	}
+	if c, ok := e["@context"]; ok {
+		if active, err = p.processContext(active, c, baseURL, nil, false, true, true); err != nil {
+			return nil, err
+		}
+	}
+
+	typeScoped := active
+	var inputType string
+	for _, k := range sortedKeys(e) {
+		ek, _, err := p.expandIRI(active, k, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if ek != "@type" {
+			continue
+		}
+		var types []string
+		for _, v := range asArray(e[k]) {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		for _, t := range sortedStrings(types) {
+			if d, ok := typeScoped.terms[t]; ok && d.hasContext {
+				if active, err = p.processContext(active, d.context, d.baseURL, nil, false, false, true); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if inputType == "" && 0 < len(types) {
+			if inputType, _, err = p.expandIRI(active, types[len(types)-1], false, true, nil, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make(map[string]any)
+	if err := p.expandObject(active, typeScoped, result, property, e, inputType, baseURL, frame); err != nil {
+		return nil, err
+	}
+
+	var expanded any = result
+	if v, ok := result["@value"]; ok {
+		for k := range result {
+			switch k {
+			case "@direction", "@index", "@language", "@type", "@value":
+			default:
+				return nil, newError(InvalidValueObject, "unexpected entry %s", k)
+			}
+		}
+		_, hasLanguage := result["@language"]
+		_, hasDirection := result["@direction"]
+		t, hasType := result["@type"]
+		if hasType && (hasLanguage || hasDirection) {
+			return nil, newError(InvalidValueObject, "@type combined with @language or @direction")
+		}
+		if t == "@json" {
+			return result, nil
+		}
+		if a, ok := v.([]any); v == nil || (ok && len(a) == 0) {
+			return nil, nil
+		}
+		if !isScalar(v) && !frame {
+			return nil, newError(InvalidValueObjectValue, "%v", v)
+		}
+		if _, ok := v.(string); hasLanguage && !ok && !frame {
+			return nil, newError(InvalidLanguageTaggedValue, "%v", v)
+		}
+		if s, ok := t.(string); hasType && !frame && (!ok || !isAbsoluteIRI(s) || isBlankNode(s)) {
+			return nil, newError(InvalidTypedValue, "%v", t)
+		}
+	} else if t, ok := result["@type"]; ok {
+		result["@type"] = asArray(t)
+	} else if _, ok := result["@set"]; ok || isListObject(result) {
+		if 2 < len(result) {
+			return nil, newError(InvalidSetOrListObject, "unexpected entries")
+		}
+		if _, ok := result["@index"]; len(result) == 2 && !ok {
+			return nil, newError(InvalidSetOrListObject, "unexpected entries")
+		}
+		if s, ok := result["@set"]; ok {
+			expanded = s
+		}
+	}
+
+	m, ok := expanded.(map[string]any)
+	if !ok {
+		return expanded, nil
+	}
+	if _, ok := m["@language"]; ok && len(m) == 1 {
+		return nil, nil
+	}
+	if (property == "" || property == "@graph") && !frame {
+		_, hasValue := m["@value"]
+		_, hasList := m["@list"]
+		_, hasID := m["@id"]
+		if len(m) == 0 || hasValue || hasList || (hasID && len(m) == 1) {
+			return nil, nil
+		}
+	}
+	return m, nil
+}
+
+// expandObject expands the entries of the element into the result, nested entries are expanded recursively.
+func (p *processor) expandObject(
+	active, typeScoped *activeContext,
+	result map[string]any,
+	property string,
+	e map[string]any,
+	inputType, baseURL string,
+	frame bool,
+) error {
+	var nests []string
+	for _, key := range sortedKeys(e) {
+		if key == "@context" {
+			continue
+		}
+		value := e[key]
+		ep, ok, err := p.expandIRI(active, key, false, true, nil, nil)
+		if err != nil {
+			return err
+		}
+		if !ok || (!isKeyword(ep) && !strings.Contains(ep, ":")) {
+			continue
+		}
+
+		if isKeyword(ep) {
+			if property == "@reverse" {
+				return newError(InvalidReversePropertyMap, "%s", key)
+			}
+			if _, ok := result[ep]; ok && ep != "@included" && ep != "@type" {
+				return newError(CollidingKeywords, "%s", ep)
+			}
+			var ev any
+			switch ep {
+			case "@id":
+				s, ok := value.(string)
+				if !ok {
+					if frame && isFrameID(value) {
+						result[ep] = asArray(value)
+						continue
+					}
+					return newError(InvalidIdValue, "%v", value)
+				}
+				id, ok, err := p.expandIRI(active, s, true, false, nil, nil)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				ev = id
+				if frame {
+					ev = []any{id}
+				}
+			case "@type":
+				if ev, err = p.expandType(typeScoped, value, frame); err != nil {
+					return err
+				}
+				if t, ok := result["@type"]; ok {
+					ev = append(asArray(t), asArray(ev)...)
+				}
+			case "@graph":
+				g, err := p.expand(active, "@graph", value, baseURL, frame, false)
+				if err != nil {
+					return err
+				}
+				ev = arrayOf(g)
+			case "@included":
+				if p.options.processingMode(JSONLD10) {
+					continue
+				}
+				included, err := p.expand(active, "", value, baseURL, frame, false)
+				if err != nil {
+					return err
+				}
+				items := arrayOf(included)
+				for _, item := range items {
+					if !isNodeObject(item) {
+						return newError(InvalidIncludedValue, "%v", item)
+					}
+				}
+				if v, ok := result["@included"]; ok {
+					items = append(asArray(v), items...)
+				}
+				ev = items
+			case "@value":
+				switch {
+				case inputType == "@json":
+					if p.options.processingMode(JSONLD10) {
+						return newError(InvalidValueObjectValue, "@json is not supported in %s", JSONLD10)
+					}
+					ev = value
+				case value == nil || isScalar(value) || (frame && isFrameValue(value)):
+					ev = value
+				default:
+					return newError(InvalidValueObjectValue, "%v", value)
+				}
+				if ev == nil {
+					result["@value"] = nil
+					continue
+				}
+			case "@language":
+				if _, ok := value.(string); !ok && !(frame && isFrameValue(value)) {
+					return newError(InvalidLanguageTaggedString, "%v", value)
+				}
+				ev = value
+			case "@direction":
+				if p.options.processingMode(JSONLD10) {
+					continue
+				}
+				if value != "ltr" && value != "rtl" && !(frame && isFrameValue(value)) {
+					return newError(InvalidBaseDirection, "%v", value)
+				}
+				ev = value
+			case "@index":
+				if _, ok := value.(string); !ok {
+					return newError(InvalidIndexValue, "%v", value)
+				}
+				ev = value
+			case "@list":
+				if property == "" || property == "@graph" {
+					continue
+				}
+				l, err := p.expand(active, property, value, baseURL, frame, false)
+				if err != nil {
+					return err
+				}
+				ev = arrayOf(l)
+			case "@set":
+				if ev, err = p.expand(active, property, value, baseURL, frame, false); err != nil {
+					return err
+				}
+			case "@reverse":
+				if err := p.expandReverse(active, result, value, baseURL, frame); err != nil {
+					return err
+				}
+				continue
+			case "@nest":
+				nests = append(nests, key)
+				continue
+			case "@default":
+				if !frame {
+					continue
+				}
+				if ev, err = p.expand(active, property, value, baseURL, frame, false); err != nil {
+					return err
+				}
+			case "@embed", "@explicit", "@omitDefault", "@requireAll":
+				if !frame {
+					continue
+				}
+				// Framing flags are kept as is, they are not values of the active property.
+				ev = asArray(value)
+			default:
+				continue
+			}
+			if ev != nil || ep != "@value" || inputType == "@json" {
+				result[ep] = ev
+			}
+			continue
+		}
+
+		def := active.terms[key]
+		var ev any
+		switch m, isMap := value.(map[string]any); {
+		case def != nil && def.typ == "@json":
+			ev = map[string]any{"@value": value, "@type": "@json"}
+		case isMap && def.hasContainer("@language"):
+			if ev, err = p.expandLanguageMap(active, def, m); err != nil {
+				return err
+			}
+		case isMap && (def.hasContainer("@index") || def.hasContainer("@type") || def.hasContainer("@id")):
+			if ev, err = p.expandIndexMap(active, def, key, m, baseURL, frame); err != nil {
+				return err
+			}
+		default:
+			if ev, err = p.expand(active, key, value, baseURL, frame, false); err != nil {
+				return err
+			}
+		}
+		if ev == nil {
+			continue
+		}
+		if def.hasContainer("@list") && !isListObject(ev) {
+			ev = map[string]any{"@list": arrayOf(ev)}
+		}
+		if def.hasContainer("@graph") && !def.hasContainer("@id") && !def.hasContainer("@index") {
+			var graphs []any
+			for _, item := range asArray(ev) {
+				graphs = append(graphs, map[string]any{"@graph": asArray(item)})
+			}
+			ev = graphs
+		}
+		if def != nil && def.reverse {
+			reverse, _ := result["@reverse"].(map[string]any)
+			if reverse == nil {
+				reverse = make(map[string]any)
+				result["@reverse"] = reverse
+			}
+			for _, item := range asArray(ev) {
+				if isValueObject(item) || isListObject(item) {
+					return newError(InvalidReversePropertyValue, "%v", item)
+				}
+				addValue(reverse, ep, item)
+			}
+			continue
+		}
+		addValue(result, ep, ev)
+	}
+
+	for _, nk := range nests {
+		nestActive := active
+		if d, ok := active.terms[nk]; ok && d.hasContext {
+			var err error
+			if nestActive, err = p.processContext(active, d.context, d.baseURL, nil, true, true, true); err != nil {
+				return err
+			}
+		}
+		for _, nv := range asArray(e[nk]) {
+			nm, ok := nv.(map[string]any)
+			if !ok {
+				return newError(InvalidNestValue, "%v", nv)
+			}
+			for k := range nm {
+				ek, _, err := p.expandIRI(nestActive, k, false, true, nil, nil)
+				if err != nil {
+					return err
+				}
+				if ek == "@value" {
+					return newError(InvalidNestValue, "nested value object")
+				}
+			}
+			if err := p.expandObject(nestActive, typeScoped, result, nk, nm, inputType, baseURL, frame); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *processor) expandType(active *activeContext, value any, frame bool) (any, error) {
+	switch v := value.(type) {
+	case string:
+		t, _, err := p.expandIRI(active, v, true, true, nil, nil)
+		return t, err
+	case []any:
+		types := make([]any, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, newError(InvalidTypeValue, "%v", value)
+			}
+			t, _, err := p.expandIRI(active, s, true, true, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, t)
+		}
+		return types, nil
+	case map[string]any:
+		if frame && len(v) == 0 {
+			return v, nil
+		}
+		if d, ok := v["@default"].(string); frame && ok && len(v) == 1 {
+			t, _, err := p.expandIRI(active, d, true, true, nil, nil)
+			return map[string]any{"@default": t}, err
+		}
+	}
+	return nil, newError(InvalidTypeValue, "%v", value)
+}
+
+func (p *processor) expandReverse(active *activeContext, result map[string]any, value any, baseURL string, frame bool) error {
+	if _, ok := value.(map[string]any); !ok {
+		return newError(InvalidReverseValue, "%v", value)
+	}
+	ev, err := p.expand(active, "@reverse", value, baseURL, frame, false)
+	if err != nil {
+		return err
+	}
+	m, _ := ev.(map[string]any)
+	if r, ok := m["@reverse"].(map[string]any); ok {
+		for _, k := range sortedKeys(r) {
+			addValue(result, k, r[k])
+		}
+	}
+	for _, k := range sortedKeys(m) {
+		if k == "@reverse" {
+			continue
+		}
+		reverse, _ := result["@reverse"].(map[string]any)
+		if reverse == nil {
+			reverse = make(map[string]any)
+			result["@reverse"] = reverse
+		}
+		for _, item := range asArray(m[k]) {
+			if isValueObject(item) || isListObject(item) {
+				return newError(InvalidReversePropertyValue, "%v", item)
+			}
+			addValue(reverse, k, item)
+		}
+	}
+	return nil
+}
+
+func (p *processor) expandLanguageMap(active *activeContext, def *term, value map[string]any) (any, error) {
+	direction := active.direction
+	if def.hasDirection {
+		direction = def.direction
+	}
+	items := []any{}
+	for _, language := range sortedKeys(value) {
+		el, _, err := p.expandIRI(active, language, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range asArray(value[language]) {
+			if item == nil {
+				continue
+			}
+			s, ok := item.(string)
+			if !ok {
+				return nil, newError(InvalidLanguageMapValue, "%v", item)
+			}
+			v := map[string]any{"@value": s}
+			if language != "@none" && el != "@none" {
+				v["@language"] = language
+			}
+			if direction != "" {
+				v["@direction"] = direction
+			}
+			items = append(items, v)
+		}
+	}
+	return items, nil
+}
+
+func (p *processor) expandIndexMap(
+	active *activeContext,
+	def *term,
+	key string,
+	value map[string]any,
+	baseURL string,
+	frame bool,
+) (any, error) {
+	indexKey := "@index"
+	if def.index != "" {
+		indexKey = def.index
+	}
+	items := []any{}
+	for _, index := range sortedKeys(value) {
+		mapContext := active
+		if (def.hasContainer("@id") || def.hasContainer("@type")) && active.previous != nil {
+			mapContext = active.previous
+		}
+		if d, ok := mapContext.terms[index]; ok && d.hasContext && def.hasContainer("@type") {
+			var err error
+			mapContext, err = p.processContext(mapContext, d.context, d.baseURL, nil, false, true, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		expandedIndex, _, err := p.expandIRI(active, index, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		iv, err := p.expand(mapContext, key, asArray(value[index]), baseURL, frame, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range asArray(iv) {
+			if def.hasContainer("@graph") && !isGraphObject(item) {
+				item = map[string]any{"@graph": asArray(item)}
+			}
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch {
+			case expandedIndex == "@none":
+			case def.hasContainer("@index") && indexKey != "@index":
+				if _, ok := m["@value"]; ok {
+					return nil, newError(InvalidValueObject, "property-valued index on value object")
+				}
+				reexpanded, err := p.expandValue(active, indexKey, index)
+				if err != nil {
+					return nil, err
+				}
+				eik, _, err := p.expandIRI(active, indexKey, false, true, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				values := []any{reexpanded}
+				if v, ok := m[eik]; ok {
+					values = append(values, asArray(v)...)
+				}
+				m[eik] = values
+			case def.hasContainer("@index"):
+				if _, ok := m["@index"]; !ok {
+					m["@index"] = index
+				}
+			case def.hasContainer("@id"):
+				if _, ok := m["@id"]; !ok {
+					id, _, err := p.expandIRI(active, index, true, false, nil, nil)
+					if err != nil {
+						return nil, err
+					}
+					m["@id"] = id
+				}
+			case def.hasContainer("@type"):
+				types := []any{expandedIndex}
+				if v, ok := m["@type"]; ok {
+					types = append(types, asArray(v)...)
+				}
+				m["@type"] = types
+			}
+			items = append(items, m)
+		}
+	}
+	return items, nil
+}
+
+// expandValue implements the value expansion algorithm.
+func (p *processor) expandValue(active *activeContext, property string, value any) (any, error) {
+	def := active.terms[property]
+	if s, ok := value.(string); ok && def != nil && (def.typ == "@id" || def.typ == "@vocab") {
+		id, _, err := p.expandIRI(active, s, true, def.typ == "@vocab", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"@id": id}, nil
+	}
+	result := map[string]any{"@value": value}
+	if def != nil && def.typ != "" && def.typ != "@id" && def.typ != "@vocab" && def.typ != "@none" {
+		result["@type"] = def.typ
+	} else if _, ok := value.(string); ok {
+		language, direction := active.language, active.direction
+		if def != nil && def.hasLanguage {
+			language = def.language
+		}
+		if def != nil && def.hasDirection {
+			direction = def.direction
+		}
+		if language != "" {
+			result["@language"] = language
+		}
+		if direction != "" {
+			result["@direction"] = direction
+		}
+	}
+	return result, nil
+}
+
+// addValue appends the value to the array of the given key, arrays are appended element by element.
+func addValue(m map[string]any, key string, value any) {
+	values, _ := m[key].([]any)
+	if values == nil {
+		values = []any{}
+	}
+	if a, ok := value.([]any); ok {
+		values = append(values, a...)
+	} else {
+		values = append(values, value)
+	}
+	m[key] = values
+}
+
+// arrayOf returns the value as an array, nil results in an empty array.
+func arrayOf(v any) []any {
+	if v == nil {
+		return []any{}
+	}
+	return asArray(v)
+}
+
+func isFrameID(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func isFrameValue(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		for _, item := range v {
+			if !isScalar(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func isGraphObject(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	if _, ok := m["@graph"]; !ok {
+		return false
+	}
+	for k := range m {
+		switch k {
+		case "@graph", "@id", "@index", "@context":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isListObject(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = m["@list"]
+	return ok
+}
+
+func isNodeObject(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	for _, k := range []string{"@value", "@list", "@set"} {
+		if _, ok := m[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+func isValueObject(v any) bool {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = m["@value"]
+	return ok
+}
@@ -0,0 +1,48 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	"testing"
+)
+
+func ExampleExpand() {
+	var doc any
+	_ = json.Unmarshal([]byte(`{
+	"@context": {"@vocab": "http://xmlns.com/foaf/0.1/"},
+	"@id": "http://example.com/alice",
+	"name": "Alice"
+}`), &doc)
+	expanded, _ := jsonld.Expand(doc, nil)
+	raw, _ := json.Marshal(expanded)
+	fmt.Println(string(raw))
+	// Output:
+	// [{"@id":"http://example.com/alice","http://xmlns.com/foaf/0.1/name":[{"@value":"Alice"}]}]
+}
+
+func TestExpand(t *testing.T) {
+	runJSONTests(t, "expand", func(t *testing.T, s *testSuite, e *testsuite.Test) (any, error) {
+		return jsonld.Expand(s.readJSON(t, e.Action), s.options(e))
+	})
+}
+
+// runJSONTests runs the tests of the manifest of the given kind (e.g. expand), the results of positive tests are
+// compared with the expected JSON-LD document.
+func runJSONTests(t *testing.T, kind string, run func(t *testing.T, s *testSuite, e *testsuite.Test) (any, error)) {
+	runManifests(t, kind, func(t *testing.T, s *testSuite, e *testsuite.Test) error {
+		actual, err := run(t, s, e)
+		if e.Type == "jld:NegativeEvaluationTest" {
+			return expectError(e, err)
+		}
+		if err != nil {
+			return err
+		}
+		if expected := s.readJSON(t, e.Result); !equalJSON(expected, normalize(t, actual)) {
+			raw, _ := json.Marshal(actual)
+			return fmt.Errorf("actual: %s", raw)
+		}
+		return nil
+	})
+}
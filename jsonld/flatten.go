@@ -0,0 +1,71 @@
+package jsonld
+
+// Flatten flattens the JSON-LD document, collecting all properties of a node in a single node object and labeling all
+// blank nodes. Nodes of named graphs are embedded in the node representing the graph. The result is an array of node
+// objects if no context is given, otherwise it is compacted with the context and always contains a @graph entry.
+func Flatten(input, context any, options *Options) (any, error) {
+	p := newProcessor(options)
+	expanded, err := p.expandDocument(input, false)
+	if err != nil {
+		return nil, err
+	}
+	nodes := nodeMap{"@default": make(map[string]map[string]any)}
+	if err := p.generateNodeMap(expanded, nodes, "@default", nil, "", nil, newIssuer()); err != nil {
+		return nil, err
+	}
+	flattened := nodes.flatten()
+	if context == nil {
+		return flattened, nil
+	}
+	return p.compactDocument(flattened, context, true)
+}
+
+// flatten returns the nodes of the default graph, ordered by their identifier. The nodes of a named graph are added
+// to the @graph entry of the node with the same identifier.
+func (nodes nodeMap) flatten() []any {
+	defaultGraph := nodes["@default"]
+	for _, name := range sortedKeys(nodes) {
+		if name == "@default" {
+			continue
+		}
+		node, ok := defaultGraph[name]
+		if !ok {
+			node = map[string]any{"@id": name}
+			defaultGraph[name] = node
+		}
+		node["@graph"] = nodesOf(nodes[name])
+	}
+	return nodesOf(defaultGraph)
+}
+
+// nodesOf returns the nodes of the graph ordered by their identifier, nodes that only consist of an identifier are
+// omitted.
+func nodesOf(graph map[string]map[string]any) []any {
+	result := []any{}
+	for _, id := range sortedKeys(graph) {
+		if node := graph[id]; len(node) != 1 {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// clone returns a deep copy of the JSON value.
+func clone(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = clone(e)
+		}
+		return m
+	case []any:
+		a := make([]any, len(v))
+		for i, e := range v {
+			a[i] = clone(e)
+		}
+		return a
+	default:
+		return v
+	}
+}
@@ -0,0 +1,33 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	"testing"
+)
+
+func ExampleFlatten() {
+	var doc any
+	_ = json.Unmarshal([]byte(`{
+	"@context": {"@vocab": "http://xmlns.com/foaf/0.1/"},
+	"@id": "http://example.com/alice",
+	"knows": {"name": "Bob"}
+}`), &doc)
+	flattened, _ := jsonld.Flatten(doc, nil, nil)
+	raw, _ := json.Marshal(flattened)
+	fmt.Println(string(raw))
+	// Output:
+	// [{"@id":"_:b0","http://xmlns.com/foaf/0.1/name":[{"@value":"Bob"}]},{"@id":"http://example.com/alice","http://xmlns.com/foaf/0.1/knows":[{"@id":"_:b0"}]}]
+}
+
+func TestFlatten(t *testing.T) {
+	runJSONTests(t, "flatten", func(t *testing.T, s *testSuite, e *testsuite.Test) (any, error) {
+		var context any
+		if e.Context != "" {
+			context = s.readJSON(t, e.Context)
+		}
+		return jsonld.Flatten(s.readJSON(t, e.Action), context, s.options(e))
+	})
+}
@@ -0,0 +1,584 @@
+package jsonld
+
+import (
+	"reflect"
+)
+
+// Frame frames the JSON-LD document, the nodes matching the frame are embedded into the tree described by the frame.
+// The result is compacted using the context of the frame.
+//
+// Reference: https://www.w3.org/TR/json-ld11-framing/
+func Frame(input, frame any, options *Options) (map[string]any, error) {
+	p := newProcessor(options)
+	expanded, err := p.expandDocument(input, false)
+	if err != nil {
+		return nil, err
+	}
+	expandedFrame, err := p.expandDocument(frame, true)
+	if err != nil {
+		return nil, err
+	}
+	var context any
+	merged := true
+	if m, ok := frame.(map[string]any); ok {
+		context = m["@context"]
+		active, err := p.processContext(newContext(p.options.Base), context, p.options.Base, nil, false, true, true)
+		if err != nil {
+			return nil, err
+		}
+		// Frames with a top-level @graph entry only match the default graph.
+		for k := range m {
+			if ek, _, err := p.expandIRI(active, k, false, true, nil, nil); err == nil && ek == "@graph" {
+				merged = false
+			}
+		}
+	}
+
+	nodes := nodeMap{"@default": make(map[string]map[string]any)}
+	if err := p.generateNodeMap(expanded, nodes, "@default", nil, "", nil, newIssuer()); err != nil {
+		return nil, err
+	}
+	f := &framer{
+		p:        p,
+		nodes:    nodes,
+		graph:    "@default",
+		embedded: make(map[string]map[string]bool),
+		bnodes:   make(map[string]int),
+	}
+	if merged {
+		nodes["@merged"] = nodes.merge()
+		f.graph = "@merged"
+	}
+	root := make(map[string]any)
+	if err := f.frame(sortedKeys(nodes[f.graph]), expandedFrame, root, ""); err != nil {
+		return nil, err
+	}
+
+	// Blank node identifiers that are only used once are removed.
+	prune := make(map[string]bool)
+	if !p.options.processingMode(JSONLD10) {
+		for id, n := range f.bnodes {
+			prune[id] = n == 1
+		}
+	}
+	framed := cleanupPreserve(arrayOf(root[""]), prune).([]any)
+	result, err := p.compactDocument(framed, context, !p.options.OmitGraph)
+	if err != nil {
+		return nil, err
+	}
+	return cleanupNull(result).(map[string]any), nil
+}
+
+// framer holds the state of the framing algorithm.
+type framer struct {
+	p     *processor
+	nodes nodeMap
+	// graph is the name of the graph that is currently being framed.
+	graph string
+	// stack contains the nodes that are currently being framed, to detect circular references.
+	stack []frameNode
+	// embedded contains the nodes that are embedded per graph, it is reset for every top-level node.
+	embedded map[string]map[string]bool
+	// bnodes counts the occurrences of blank node identifiers in the output.
+	bnodes map[string]int
+}
+
+type frameNode struct {
+	graph, id string
+}
+
+// frame implements the framing algorithm, the nodes that match the frame are added to the property of the parent. An
+// empty property represents the top-level.
+func (f *framer) frame(subjects []string, frame any, parent map[string]any, property string) error {
+	fm, err := validateFrame(frame)
+	if err != nil {
+		return err
+	}
+	embed, err := f.embed(fm)
+	if err != nil {
+		return err
+	}
+	explicit := flag(fm, "@explicit", f.p.options.Explicit)
+	requireAll := flag(fm, "@requireAll", f.p.options.RequireAll)
+
+	nodes := f.nodes[f.graph]
+	for _, id := range subjects {
+		node, ok := nodes[id]
+		if !ok {
+			continue
+		}
+		if match, err := f.match(node, fm, requireAll); err != nil {
+			return err
+		} else if !match {
+			continue
+		}
+
+		if property == "" {
+			f.embedded = map[string]map[string]bool{f.graph: {}}
+		} else if _, ok := f.embedded[f.graph]; !ok {
+			f.embedded[f.graph] = make(map[string]bool)
+		}
+		output := map[string]any{"@id": id}
+		if isBlankNode(id) {
+			f.bnodes[id]++
+		}
+		if embed == "@never" || f.circular(id) || (embed == "@once" && f.embedded[f.graph][id]) {
+			addValue(parent, property, output)
+			continue
+		}
+		f.embedded[f.graph][id] = true
+		f.stack = append(f.stack, frameNode{graph: f.graph, id: id})
+
+		if graph, ok := f.nodes[id]; ok {
+			var subframe any = map[string]any{}
+			recurse := f.graph != "@merged"
+			if g, ok := fm["@graph"]; ok {
+				if a := asArray(g); 0 < len(a) {
+					subframe = a[0]
+				}
+				recurse = id != "@merged" && id != "@default"
+			}
+			if recurse {
+				current := f.graph
+				f.graph = id
+				err := f.frame(sortedKeys(graph), subframe, output, "@graph")
+				f.graph = current
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if included, ok := fm["@included"]; ok {
+			if err := f.frame(subjects, included, output, "@included"); err != nil {
+				return err
+			}
+		}
+
+		for _, prop := range sortedKeys(node) {
+			objects := node[prop]
+			if isKeyword(prop) {
+				output[prop] = clone(objects)
+				if prop == "@type" {
+					for _, t := range asArray(objects) {
+						if s, ok := t.(string); ok && isBlankNode(s) {
+							f.bnodes[s]++
+						}
+					}
+				}
+				continue
+			}
+			subframe, ok := fm[prop]
+			if !ok {
+				if explicit {
+					continue
+				}
+				// Implicit frame, inheriting the flags of the current frame.
+				subframe = map[string]any{"@embed": embed, "@explicit": explicit, "@requireAll": requireAll}
+			}
+			for _, o := range asArray(objects) {
+				om, _ := o.(map[string]any)
+				switch {
+				case isListObject(o):
+					listFrame := any(map[string]any{"@embed": embed, "@explicit": explicit, "@requireAll": requireAll})
+					if sm, ok := first(subframe).(map[string]any); ok {
+						if l, ok := sm["@list"]; ok {
+							listFrame = l
+						}
+					}
+					list := map[string]any{"@list": []any{}}
+					addValue(output, prop, list)
+					for _, item := range om["@list"].([]any) {
+						if !isNodeReference(item) {
+							list["@list"] = append(list["@list"].([]any), clone(item))
+							continue
+						}
+						ref := item.(map[string]any)["@id"].(string)
+						if err := f.frame([]string{ref}, listFrame, list, "@list"); err != nil {
+							return err
+						}
+					}
+				case isNodeReference(o):
+					if err := f.frame([]string{om["@id"].(string)}, subframe, output, prop); err != nil {
+						return err
+					}
+				default:
+					if pattern, _ := first(subframe).(map[string]any); valueMatch(pattern, om) {
+						addValue(output, prop, clone(o))
+					}
+				}
+			}
+		}
+
+		// Defaults are added for the properties of the frame that are missing in the output.
+		for _, prop := range sortedKeys(fm) {
+			next, _ := first(fm[prop]).(map[string]any)
+			if prop == "@type" {
+				if _, ok := next["@default"]; !ok {
+					continue
+				}
+			} else if isKeyword(prop) {
+				continue
+			}
+			if _, ok := output[prop]; ok || flag(next, "@omitDefault", f.p.options.OmitDefault) {
+				continue
+			}
+			var preserve any = "@null"
+			if d, ok := next["@default"]; ok {
+				preserve = clone(d)
+			}
+			output[prop] = []any{map[string]any{"@preserve": asArray(preserve)}}
+		}
+
+		if reverse, ok := fm["@reverse"].(map[string]any); ok {
+			for _, rp := range sortedKeys(reverse) {
+				for _, sid := range sortedKeys(nodes) {
+					if !references(nodes[sid][rp], id) {
+						continue
+					}
+					r := mapEntry(output, "@reverse")
+					if _, ok := r[rp]; !ok {
+						r[rp] = []any{}
+					}
+					if err := f.frame([]string{sid}, reverse[rp], r, rp); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		addValue(parent, property, output)
+		f.stack = f.stack[:len(f.stack)-1]
+	}
+	return nil
+}
+
+// circular returns true if embedding the node would create a circular reference.
+func (f *framer) circular(id string) bool {
+	for _, n := range f.stack {
+		if n.graph == f.graph && n.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// embed returns the value of the @embed flag of the frame.
+func (f *framer) embed(frame map[string]any) (string, error) {
+	v := flagValue(frame, "@embed")
+	if v == nil {
+		if f.p.options.Embed == "" {
+			return "@once", nil
+		}
+		v = f.p.options.Embed
+	}
+	switch v {
+	case true:
+		return "@once", nil
+	case false:
+		return "@never", nil
+	case "@always", "@once", "@never":
+		return v.(string), nil
+	default:
+		return "", newError(InvalidEmbedValue, "%v", v)
+	}
+}
+
+// match implements the frame matching algorithm, it returns true if the node matches the frame.
+func (f *framer) match(node, frame map[string]any, requireAll bool) (bool, error) {
+	wildcard, matchesSome := true, false
+	for _, k := range sortedKeys(frame) {
+		values := valuesOf(node, k)
+		patterns := asArray(frame[k])
+		var matches bool
+		switch {
+		case k == "@id":
+			if m, ok := first(patterns).(map[string]any); ok && len(m) == 0 {
+				matches = true
+			} else {
+				matches = 0 < len(values) && contains(patterns, values[0])
+			}
+			if !requireAll {
+				return matches, nil
+			}
+		case k == "@type":
+			wildcard = false
+			switch {
+			case len(patterns) == 0:
+				if 0 < len(values) {
+					return false, nil
+				}
+				matches = true
+			case len(patterns) == 1 && isEmptyMap(patterns[0]):
+				matches = 0 < len(values)
+			default:
+				for _, t := range patterns {
+					if m, ok := t.(map[string]any); ok {
+						if _, ok := m["@default"]; ok {
+							matches = true
+						}
+					} else if contains(values, t) {
+						matches = true
+					}
+				}
+				if !requireAll {
+					return matches, nil
+				}
+			}
+		case isKeyword(k):
+			continue
+		default:
+			wildcard = false
+			pattern, _ := first(patterns).(map[string]any)
+			if pattern != nil {
+				if _, err := validateFrame(pattern); err != nil {
+					return false, err
+				}
+				if _, ok := pattern["@default"]; ok && len(values) == 0 {
+					continue
+				}
+			}
+			if len(patterns) == 0 {
+				// Match none, the node must not have the property.
+				if 0 < len(values) {
+					return false, nil
+				}
+				matches = true
+				break
+			}
+			switch {
+			case isListObject(pattern):
+				if l, ok := first(values).(map[string]any); ok && isListObject(l) {
+					for _, item := range l["@list"].([]any) {
+						matches = matches || f.matchValue(first(pattern["@list"]), item, requireAll)
+					}
+				}
+			case isValueObject(pattern):
+				for _, v := range values {
+					vm, _ := v.(map[string]any)
+					matches = matches || valueMatch(pattern, vm)
+				}
+			case isNodeReference(pattern):
+				for _, v := range values {
+					matches = matches || f.matchNode(pattern, v, requireAll)
+				}
+			default:
+				matches = 0 < len(values)
+			}
+		}
+		if !matches && requireAll {
+			return false, nil
+		}
+		matchesSome = matchesSome || matches
+	}
+	return wildcard || matchesSome, nil
+}
+
+// matchValue matches an item of a list against the list pattern.
+func (f *framer) matchValue(pattern, item any, requireAll bool) bool {
+	pm, _ := pattern.(map[string]any)
+	if isValueObject(pm) {
+		im, _ := item.(map[string]any)
+		return valueMatch(pm, im)
+	}
+	return isNodeObject(pm) && f.matchNode(pm, item, requireAll)
+}
+
+// matchNode matches the node referenced by the value against the frame.
+func (f *framer) matchNode(frame map[string]any, value any, requireAll bool) bool {
+	m, _ := value.(map[string]any)
+	id, ok := m["@id"].(string)
+	if !ok {
+		return false
+	}
+	node, ok := f.nodes[f.graph][id]
+	if !ok {
+		return false
+	}
+	matches, err := f.match(node, frame, requireAll)
+	return err == nil && matches
+}
+
+// valueMatch returns true if the value object matches the value pattern, empty maps in the pattern are wildcards.
+func valueMatch(pattern, value map[string]any) bool {
+	values, types, languages := valuesOf(pattern, "@value"), valuesOf(pattern, "@type"), valuesOf(pattern, "@language")
+	if len(values) == 0 && len(types) == 0 && len(languages) == 0 {
+		return true
+	}
+	if !contains(values, value["@value"]) && !isEmptyMap(first(values)) {
+		return false
+	}
+	return matchEntry(value, "@type", types) && matchEntry(value, "@language", languages)
+}
+
+// matchEntry returns true if the entry of the value matches one of the patterns. A value without the entry only
+// matches if there are no patterns, an empty map matches any value.
+func matchEntry(value map[string]any, key string, patterns []any) bool {
+	v, ok := value[key]
+	if !ok {
+		return len(patterns) == 0
+	}
+	return contains(patterns, v) || isEmptyMap(first(patterns))
+}
+
+// validateFrame returns the frame object of the expanded frame, which must consist of a single map.
+func validateFrame(frame any) (map[string]any, error) {
+	if a, ok := frame.([]any); ok {
+		if len(a) != 1 {
+			return nil, newError(InvalidFrame, "expected a single frame object")
+		}
+		frame = a[0]
+	}
+	m, ok := frame.(map[string]any)
+	if !ok {
+		return nil, newError(InvalidFrame, "%v", frame)
+	}
+	for _, k := range []string{"@id", "@type"} {
+		for _, v := range valuesOf(m, k) {
+			if s, ok := v.(string); ok && (!isAbsoluteIRI(s) || isBlankNode(s)) {
+				return nil, newError(InvalidFrame, "invalid %s value %s", k, s)
+			} else if !ok && !isMap(v) {
+				return nil, newError(InvalidFrame, "invalid %s value %v", k, v)
+			}
+		}
+	}
+	return m, nil
+}
+
+// flag returns the value of the boolean flag of the frame, or the given default.
+func flag(frame map[string]any, name string, def bool) bool {
+	if b, ok := flagValue(frame, name).(bool); ok {
+		return b
+	}
+	return def
+}
+
+// flagValue returns the value of the flag of the (expanded) frame, or nil if the frame does not contain it.
+func flagValue(frame map[string]any, name string) any {
+	v := first(frame[name])
+	if m, ok := v.(map[string]any); ok {
+		v = m["@value"]
+	}
+	return v
+}
+
+// cleanupPreserve replaces the @preserve entries of the default values by their value, and removes the blank node
+// identifiers that should be pruned.
+func cleanupPreserve(v any, prune map[string]bool) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = cleanupPreserve(v[i], prune)
+		}
+		return v
+	case map[string]any:
+		if p, ok := v["@preserve"]; ok {
+			return first(p)
+		}
+		if isValueObject(v) {
+			return v
+		}
+		for k, e := range v {
+			if id, ok := e.(string); ok && k == "@id" && prune[id] {
+				delete(v, k)
+				continue
+			}
+			v[k] = cleanupPreserve(e, prune)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// cleanupNull replaces the @null values of the compacted output by null, nulls are removed from arrays.
+func cleanupNull(v any) any {
+	switch v := v.(type) {
+	case []any:
+		result := []any{}
+		for _, e := range v {
+			if e := cleanupNull(e); e != nil {
+				result = append(result, e)
+			}
+		}
+		return result
+	case map[string]any:
+		for k, e := range v {
+			if k != "@context" {
+				v[k] = cleanupNull(e)
+			}
+		}
+		return v
+	case string:
+		if v == "@null" {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// references returns true if the values contain a reference to the node with the given identifier.
+func references(values any, id string) bool {
+	for _, v := range asArray(values) {
+		if m, ok := v.(map[string]any); ok && m["@id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []any, v any) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the first element of the value if it is an array, nil if the array is empty.
+func first(v any) any {
+	if a, ok := v.([]any); ok {
+		if len(a) == 0 {
+			return nil
+		}
+		return a[0]
+	}
+	return v
+}
+
+func isEmptyMap(v any) bool {
+	m, ok := v.(map[string]any)
+	return ok && len(m) == 0
+}
+
+func isMap(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+// merge merges the nodes of all graphs into a single graph.
+func (nodes nodeMap) merge() map[string]map[string]any {
+	result := make(map[string]map[string]any)
+	for _, name := range sortedKeys(nodes) {
+		for _, id := range sortedKeys(nodes[name]) {
+			merged, ok := result[id]
+			if !ok {
+				merged = map[string]any{"@id": id}
+				result[id] = merged
+			}
+			node := nodes[name][id]
+			for _, k := range sortedKeys(node) {
+				if k != "@type" && isKeyword(k) {
+					merged[k] = clone(node[k])
+					continue
+				}
+				for _, v := range asArray(node[k]) {
+					addUnique(merged, k, clone(v))
+				}
+			}
+		}
+	}
+	return result
+}
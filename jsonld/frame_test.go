@@ -0,0 +1,35 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	"testing"
+)
+
+func ExampleFrame() {
+	var doc, frame any
+	_ = json.Unmarshal([]byte(`{
+	"@context": {"@vocab": "http://xmlns.com/foaf/0.1/"},
+	"@graph": [
+		{"@id": "http://example.com/alice", "@type": "Person", "knows": {"@id": "http://example.com/bob"}},
+		{"@id": "http://example.com/bob", "name": "Bob"}
+	]
+}`), &doc)
+	_ = json.Unmarshal([]byte(`{
+	"@context": {"@vocab": "http://xmlns.com/foaf/0.1/"},
+	"@type": "Person"
+}`), &frame)
+	framed, _ := jsonld.Frame(doc, frame, &jsonld.Options{OmitGraph: true})
+	raw, _ := json.Marshal(framed)
+	fmt.Println(string(raw))
+	// Output:
+	// {"@context":{"@vocab":"http://xmlns.com/foaf/0.1/"},"@id":"http://example.com/alice","@type":"Person","knows":{"@id":"http://example.com/bob","name":"Bob"}}
+}
+
+func TestFrame(t *testing.T) {
+	runJSONTests(t, "frame", func(t *testing.T, s *testSuite, e *testsuite.Test) (any, error) {
+		return jsonld.Frame(s.readJSON(t, e.Action), s.readJSON(t, e.Frame), s.options(e))
+	})
+}
@@ -0,0 +1,248 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	integerRegex = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	doubleRegex  = regexp.MustCompile(`^(\+|-)?([0-9]+(\.[0-9]*)?|\.[0-9]+)([Ee](\+|-)?[0-9]+)?$`)
+)
+
+// FromRDF implements the serialization algorithm, it converts the RDF dataset into an expanded JSON-LD document.
+func FromRDF(doc nq.Document, options *Options) ([]any, error) {
+	p := newProcessor(options)
+	if d := p.options.RdfDirection; d != "" && d != "i18n-datatype" {
+		return nil, fmt.Errorf("jsonld: unsupported rdf direction: %s", d)
+	}
+
+	defaultGraph := make(map[string]map[string]any)
+	graphs := map[string]map[string]map[string]any{"@default": defaultGraph}
+	// usages keeps track of the nodes that use rdf:nil per graph, referencedOnce of the blank nodes that are only
+	// referenced once (nil if referenced more than once). Both are used to convert RDF lists into list objects.
+	usages := make(map[string][]*usage)
+	referencedOnce := make(map[string]*usage)
+	for _, q := range doc {
+		name := "@default"
+		if q.GraphLabel != nil {
+			name = identifier(q.GraphLabel)
+			if _, ok := defaultGraph[name]; !ok {
+				defaultGraph[name] = map[string]any{"@id": name}
+			}
+		}
+		nodes, ok := graphs[name]
+		if !ok {
+			nodes = make(map[string]map[string]any)
+			graphs[name] = nodes
+		}
+
+		subject := identifier(q.Subject)
+		node, ok := nodes[subject]
+		if !ok {
+			node = map[string]any{"@id": subject}
+			nodes[subject] = node
+		}
+		predicate := escape.Unescape(string(q.Predicate))
+		literal, isLiteral := literalOf(q.Object)
+		var object string
+		if !isLiteral {
+			object = identifier(q.Object)
+			if _, ok := nodes[object]; !ok {
+				nodes[object] = map[string]any{"@id": object}
+			}
+		}
+		if predicate == rdfType && !p.options.UseRdfType && !isLiteral {
+			addUnique(node, "@type", object)
+			continue
+		}
+
+		var value map[string]any
+		if isLiteral {
+			v, err := p.rdfToObject(literal)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		} else {
+			value = map[string]any{"@id": object}
+		}
+		addUnique(node, predicate, value)
+
+		switch {
+		case isLiteral:
+		case object == rdfNil:
+			usages[name] = append(usages[name], &usage{node: node, property: predicate, value: value})
+		case isBlankNode(object):
+			if _, ok := referencedOnce[object]; ok {
+				referencedOnce[object] = nil
+			} else {
+				referencedOnce[object] = &usage{node: node, property: predicate, value: value}
+			}
+		}
+	}
+
+	for name, nodes := range graphs {
+		for _, u := range usages[name] {
+			node, property, head := u.node, u.property, u.value
+			var list []any
+			var listNodes []string
+			for property == rdfRest && isListNode(node) {
+				id := node["@id"].(string)
+				ref, ok := referencedOnce[id]
+				if !ok || ref == nil {
+					break
+				}
+				list = append(list, node[rdfFirst].([]any)[0])
+				listNodes = append(listNodes, id)
+				node, property, head = ref.node, ref.property, ref.value
+				if !isBlankNode(node["@id"].(string)) {
+					break
+				}
+			}
+			delete(head, "@id")
+			for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+				list[i], list[j] = list[j], list[i]
+			}
+			if list == nil {
+				list = []any{}
+			}
+			head["@list"] = list
+			for _, id := range listNodes {
+				delete(nodes, id)
+			}
+		}
+	}
+
+	result := []any{}
+	for _, subject := range sortedKeys(defaultGraph) {
+		node := defaultGraph[subject]
+		if nodes, ok := graphs[subject]; ok {
+			var graph []any
+			for _, s := range sortedKeys(nodes) {
+				if n := nodes[s]; len(n) != 1 {
+					graph = append(graph, n)
+				}
+			}
+			node["@graph"] = arrayOf(graph)
+		}
+		if len(node) != 1 {
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+// usage is a reference to a node from the value of a property of another node.
+type usage struct {
+	node     map[string]any
+	property string
+	value    map[string]any
+}
+
+// isListNode returns true if the node is a well-formed list node, a blank node with exactly one rdf:first and one
+// rdf:rest value, and optionally rdf:List as type.
+func isListNode(node map[string]any) bool {
+	id, _ := node["@id"].(string)
+	if !isBlankNode(id) {
+		return false
+	}
+	first, _ := node[rdfFirst].([]any)
+	rest, _ := node[rdfRest].([]any)
+	if len(first) != 1 || len(rest) != 1 {
+		return false
+	}
+	for k, v := range node {
+		switch k {
+		case "@id", rdfFirst, rdfRest:
+		case "@type":
+			types, _ := v.([]any)
+			if len(types) != 1 || types[0] != rdfList {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// rdfToObject converts a literal into a value object.
+func (p *processor) rdfToObject(l nt.Literal) (map[string]any, error) {
+	value := escape.Unescape(l.Value)
+	result := map[string]any{"@value": value}
+	if l.Language != "" {
+		result["@language"] = l.Language
+		return result, nil
+	}
+	datatype := xsdString
+	if l.Reference != nil {
+		datatype = escape.Unescape(string(*l.Reference))
+	}
+	switch {
+	case p.options.UseNativeTypes && datatype == xsdString:
+		return result, nil
+	case p.options.UseNativeTypes && datatype == xsdBoolean && (value == "true" || value == "false"):
+		result["@value"] = value == "true"
+		return result, nil
+	case p.options.UseNativeTypes && datatype == xsdInteger && integerRegex.MatchString(value),
+		p.options.UseNativeTypes && datatype == xsdDouble && doubleRegex.MatchString(value):
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			result["@value"] = f
+			return result, nil
+		}
+	case p.options.RdfDirection == "i18n-datatype" && strings.HasPrefix(datatype, i18n):
+		language, direction, _ := strings.Cut(datatype[len(i18n):], "_")
+		if language != "" {
+			result["@language"] = language
+		}
+		if direction != "" {
+			result["@direction"] = direction
+		}
+		return result, nil
+	case datatype == rdfJSON && !p.options.processingMode(JSONLD10):
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, newError(InvalidJSONLiteral, "%s", err)
+		}
+		return map[string]any{"@value": v, "@type": "@json"}, nil
+	}
+	if datatype != xsdString {
+		result["@type"] = datatype
+	}
+	return result, nil
+}
+
+// identifier returns the JSON-LD identifier of an IRI or blank node.
+func identifier(v any) string {
+	switch v := v.(type) {
+	case nt.BlankNode:
+		return "_:" + string(v)
+	case *nt.BlankNode:
+		return "_:" + string(*v)
+	case nt.IRIReference:
+		return escape.Unescape(string(v))
+	case *nt.IRIReference:
+		return escape.Unescape(string(*v))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func literalOf(v any) (nt.Literal, bool) {
+	switch v := v.(type) {
+	case nt.Literal:
+		return v, true
+	case *nt.Literal:
+		return *v, true
+	default:
+		return nt.Literal{}, false
+	}
+}
@@ -0,0 +1,122 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	nq "github.com/0x51-dev/rdf/nquads"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func ExampleFromRDF() {
+	doc, _ := nq.ParseDocument(`
+<http://example.com/alice> <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://xmlns.com/foaf/0.1/Person> .
+`)
+	expanded, _ := jsonld.FromRDF(doc, &jsonld.Options{UseNativeTypes: true})
+	raw, _ := json.Marshal(expanded)
+	fmt.Println(string(raw))
+	// Output:
+	// [{"@id":"http://example.com/alice","@type":["http://xmlns.com/foaf/0.1/Person"],"http://xmlns.com/foaf/0.1/age":[{"@value":42}]}]
+}
+
+func TestFromRDF(t *testing.T) {
+	runManifests(t, "fromRdf", func(t *testing.T, s *testSuite, e *testsuite.Test) error {
+		raw, err := fs.ReadFile(s.fsys, e.Action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := nq.ParseDocument(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := jsonld.FromRDF(doc, s.options(e))
+		if e.Type == "jld:NegativeEvaluationTest" {
+			return expectError(e, err)
+		}
+		if err != nil {
+			return err
+		}
+		if expected := s.readJSON(t, e.Result); !equalJSON(expected, normalize(t, actual)) {
+			raw, _ := json.Marshal(actual)
+			return fmt.Errorf("actual: %s", raw)
+		}
+		return nil
+	})
+}
+
+// equalJSON compares two JSON-LD documents, arrays are compared as unordered sets, except for the values of @list.
+func equalJSON(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok {
+				return false
+			}
+			if k == "@list" {
+				if !equalList(v, w) {
+					return false
+				}
+				continue
+			}
+			if !equalJSON(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		used := make([]bool, len(b))
+	next:
+		for _, v := range a {
+			for i, w := range b {
+				if !used[i] && equalJSON(v, w) {
+					used[i] = true
+					continue next
+				}
+			}
+			return false
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func equalList(a, b any) bool {
+	la, ok := a.([]any)
+	lb, ok2 := b.([]any)
+	if !ok || !ok2 || len(la) != len(lb) {
+		return false
+	}
+	for i := range la {
+		if !equalJSON(la[i], lb[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize converts the value into its generic JSON representation.
+func normalize(t *testing.T, v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n any
+	if err := json.Unmarshal(raw, &n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
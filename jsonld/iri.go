@@ -0,0 +1,40 @@
+package jsonld
+
+import (
+	"github.com/0x51-dev/rdf/iri"
+	"regexp"
+	"strings"
+)
+
+var keywordRegex = regexp.MustCompile(`^@[a-zA-Z]+$`)
+
+// keywords are the JSON-LD keywords, including those of the framing algorithm.
+var keywords = map[string]bool{
+	"@base": true, "@container": true, "@context": true, "@default": true, "@direction": true, "@embed": true,
+	"@explicit": true, "@graph": true, "@id": true, "@import": true, "@included": true, "@index": true, "@json": true,
+	"@language": true, "@list": true, "@nest": true, "@none": true, "@omitDefault": true, "@prefix": true,
+	"@preserve": true, "@propagate": true, "@protected": true, "@requireAll": true, "@reverse": true, "@set": true,
+	"@type": true, "@value": true, "@version": true, "@vocab": true,
+}
+
+func isKeyword(v string) bool {
+	return keywords[v]
+}
+
+// looksLikeKeyword returns true if the value has the form of a keyword, these are reserved for future use.
+func looksLikeKeyword(v string) bool {
+	return keywordRegex.MatchString(v)
+}
+
+func isAbsoluteIRI(v string) bool {
+	return iri.IsAbsolute(v)
+}
+
+func isBlankNode(v string) bool {
+	return strings.HasPrefix(v, "_:")
+}
+
+// isWellFormedIRI returns true if the value is an absolute IRI that can be represented in N-Quads.
+func isWellFormedIRI(v string) bool {
+	return isAbsoluteIRI(v) && !strings.ContainsAny(v, " <>\"{}|\\^`\t\n\r")
+}
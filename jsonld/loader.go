@@ -0,0 +1,49 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"io/fs"
+	"strings"
+)
+
+// DocumentLoader loads remote documents, e.g. remote contexts.
+type DocumentLoader interface {
+	// LoadDocument loads the document with the given (absolute) URL.
+	LoadDocument(url string) (*RemoteDocument, error)
+}
+
+// RemoteDocument is a document that was retrieved by a DocumentLoader.
+type RemoteDocument struct {
+	// DocumentURL is the final URL of the document, after following redirects. It is used as the base IRI of the
+	// document.
+	DocumentURL string
+	// Document is the parsed JSON document.
+	Document any
+}
+
+// FSLoader is a document loader that serves documents from a file system, URLs starting with Base are mapped to the
+// files relative to the root of the file system.
+type FSLoader struct {
+	Base string
+	FS   fs.FS
+}
+
+// LoadDocument loads the document with the given URL from the file system.
+func (l *FSLoader) LoadDocument(url string) (*RemoteDocument, error) {
+	if i := strings.IndexByte(url, '#'); 0 <= i {
+		url = url[:i]
+	}
+	name, ok := strings.CutPrefix(url, l.Base)
+	if !ok {
+		return nil, newError(LoadingDocumentFailed, "%s is not located under %s", url, l.Base)
+	}
+	raw, err := fs.ReadFile(l.FS, name)
+	if err != nil {
+		return nil, newError(LoadingDocumentFailed, "%s", err)
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, newError(LoadingDocumentFailed, "%s", err)
+	}
+	return &RemoteDocument{DocumentURL: url, Document: doc}, nil
+}
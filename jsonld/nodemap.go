@@ -0,0 +1,185 @@
+package jsonld
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// nodeMap maps graph names to the nodes of the graph, indexed by their identifier. The default graph is named
+// "@default".
+type nodeMap map[string]map[string]map[string]any
+
+// issuer issues blank node identifiers.
+type issuer struct {
+	counter int
+	issued  map[string]string
+}
+
+func newIssuer() *issuer {
+	return &issuer{issued: make(map[string]string)}
+}
+
+// issue returns the new identifier of the given blank node identifier, an empty identifier always results in a new
+// identifier.
+func (i *issuer) issue(id string) string {
+	if v, ok := i.issued[id]; ok && id != "" {
+		return v
+	}
+	v := fmt.Sprintf("_:b%d", i.counter)
+	i.counter++
+	if id != "" {
+		i.issued[id] = v
+	}
+	return v
+}
+
+// generateNodeMap implements the node map generation algorithm. The active subject is either the identifier of a node
+// or, for reverse properties, a node reference.
+func (p *processor) generateNodeMap(
+	element any,
+	nodes nodeMap,
+	graph string,
+	subject any,
+	property string,
+	list map[string]any,
+	issuer *issuer,
+) error {
+	if a, ok := element.([]any); ok {
+		for _, item := range a {
+			if err := p.generateNodeMap(item, nodes, graph, subject, property, list, issuer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	e, ok := element.(map[string]any)
+	if !ok {
+		return nil
+	}
+	g, ok := nodes[graph]
+	if !ok {
+		g = make(map[string]map[string]any)
+		nodes[graph] = g
+	}
+	var subjectNode map[string]any
+	if s, ok := subject.(string); ok {
+		subjectNode = g[s]
+	}
+
+	if _, ok := e["@value"]; ok {
+		if list != nil {
+			list["@list"] = append(list["@list"].([]any), e)
+		} else {
+			addUnique(subjectNode, property, e)
+		}
+		return nil
+	}
+	if l, ok := e["@list"]; ok {
+		result := map[string]any{"@list": []any{}}
+		if err := p.generateNodeMap(l, nodes, graph, subject, property, result, issuer); err != nil {
+			return err
+		}
+		if list != nil {
+			list["@list"] = append(list["@list"].([]any), result)
+		} else {
+			addValue(subjectNode, property, result)
+		}
+		return nil
+	}
+
+	var types []any
+	for _, t := range asArray(e["@type"]) {
+		if s, ok := t.(string); ok && isBlankNode(s) {
+			t = issuer.issue(s)
+		}
+		if t != nil {
+			types = append(types, t)
+		}
+	}
+
+	var id string
+	if v, ok := e["@id"].(string); ok {
+		id = v
+		if isBlankNode(id) {
+			id = issuer.issue(id)
+		}
+	} else {
+		id = issuer.issue("")
+	}
+	node, ok := g[id]
+	if !ok {
+		node = map[string]any{"@id": id}
+		g[id] = node
+	}
+	switch s := subject.(type) {
+	case map[string]any:
+		addUnique(node, property, s)
+	case string:
+		if property == "" {
+			break
+		}
+		reference := map[string]any{"@id": id}
+		if list != nil {
+			list["@list"] = append(list["@list"].([]any), reference)
+		} else {
+			addUnique(subjectNode, property, reference)
+		}
+	}
+	for _, t := range types {
+		addUnique(node, "@type", t)
+	}
+	if v, ok := e["@index"]; ok {
+		if i, ok := node["@index"]; ok && i != v {
+			return newError(ConflictingIndexes, "%s: %v and %v", id, i, v)
+		}
+		node["@index"] = v
+	}
+	if r, ok := e["@reverse"].(map[string]any); ok {
+		reference := map[string]any{"@id": id}
+		for _, k := range sortedKeys(r) {
+			for _, v := range asArray(r[k]) {
+				if err := p.generateNodeMap(v, nodes, graph, reference, k, nil, issuer); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if v, ok := e["@graph"]; ok {
+		if err := p.generateNodeMap(v, nodes, id, nil, "", nil, issuer); err != nil {
+			return err
+		}
+	}
+	if v, ok := e["@included"]; ok {
+		if err := p.generateNodeMap(v, nodes, graph, nil, "", nil, issuer); err != nil {
+			return err
+		}
+	}
+	for _, k := range sortedKeys(e) {
+		switch k {
+		case "@id", "@type", "@index", "@reverse", "@graph", "@included":
+			continue
+		}
+		prop := k
+		if isBlankNode(prop) {
+			prop = issuer.issue(prop)
+		}
+		if _, ok := node[prop]; !ok {
+			node[prop] = []any{}
+		}
+		if err := p.generateNodeMap(e[k], nodes, graph, id, prop, nil, issuer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addUnique appends the value to the array of the given key, unless the array already contains an equal value.
+func addUnique(m map[string]any, key string, value any) {
+	values, _ := m[key].([]any)
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return
+		}
+	}
+	m[key] = append(values, value)
+}
@@ -0,0 +1,266 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var languageRegex = regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`)
+
+// ToRDF implements the deserialization algorithm, it converts the JSON-LD document into an RDF dataset. Triples with
+// relative IRIs or blank node predicates are ignored.
+func ToRDF(input any, options *Options) (nq.Document, error) {
+	p := newProcessor(options)
+	if d := p.options.RdfDirection; d != "" && d != "i18n-datatype" {
+		return nil, fmt.Errorf("jsonld: unsupported rdf direction: %s", d)
+	}
+	expanded, err := p.expandDocument(input, false)
+	if err != nil {
+		return nil, err
+	}
+	nodes := nodeMap{"@default": {}}
+	issuer := newIssuer()
+	if err := p.generateNodeMap(expanded, nodes, "@default", nil, "", nil, issuer); err != nil {
+		return nil, err
+	}
+
+	var doc nq.Document
+	for _, name := range sortedKeys(nodes) {
+		var graph nt.Subject
+		if name != "@default" {
+			label, ok := resource(name)
+			if !ok {
+				continue
+			}
+			graph = label.(nt.Subject)
+		}
+		g := nodes[name]
+		for _, id := range sortedKeys(g) {
+			s, ok := resource(id)
+			if !ok {
+				continue
+			}
+			subject := s.(nt.Subject)
+			node := g[id]
+			for _, property := range sortedKeys(node) {
+				var triples []nt.Triple
+				switch {
+				case property == "@type":
+					for _, t := range asArray(node[property]) {
+						o, ok := resource(t.(string))
+						if !ok {
+							continue
+						}
+						triples = append(triples, nt.Triple{Subject: subject, Predicate: rdfType, Object: o})
+					}
+				case isKeyword(property), isBlankNode(property), !isWellFormedIRI(property):
+					// Generalized RDF is not supported.
+					continue
+				default:
+					for _, item := range asArray(node[property]) {
+						o, list := p.objectToRDF(item, issuer)
+						if o == nil {
+							continue
+						}
+						triple := nt.Triple{Subject: subject, Predicate: nt.IRIReference(property), Object: o}
+						triples = append(append(triples, triple), list...)
+					}
+				}
+				for _, t := range triples {
+					doc = append(doc, nq.NewQuadFromTriple(t, graph))
+				}
+			}
+		}
+	}
+	return doc, nil
+}
+
+// objectToRDF converts a node or value object into an RDF term, lists are converted into the triples that represent
+// them. Returns nil if the item can not be represented.
+func (p *processor) objectToRDF(item any, issuer *issuer) (nt.Object, []nt.Triple) {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	if isNodeObject(m) {
+		id, _ := m["@id"].(string)
+		o, ok := resource(id)
+		if !ok {
+			return nil, nil
+		}
+		return o, nil
+	}
+	if l, ok := m["@list"]; ok {
+		return p.listToRDF(asArray(l), issuer)
+	}
+
+	value := m["@value"]
+	datatype, _ := m["@type"].(string)
+	if datatype != "" && datatype != "@json" && !isWellFormedIRI(datatype) {
+		return nil, nil
+	}
+	language, _ := m["@language"].(string)
+	if language != "" && !languageRegex.MatchString(language) {
+		return nil, nil
+	}
+
+	var lexical string
+	switch v := value.(type) {
+	case bool:
+		lexical = strconv.FormatBool(v)
+		if datatype == "" {
+			datatype = xsdBoolean
+		}
+	case float64:
+		if datatype == "@json" {
+			lexical = canonicalJSON(v)
+			break
+		}
+		if v != math.Trunc(v) || 1e21 <= math.Abs(v) || datatype == xsdDouble {
+			lexical = canonicalDouble(v)
+			if datatype == "" {
+				datatype = xsdDouble
+			}
+		} else {
+			lexical = strconv.FormatFloat(v, 'f', -1, 64)
+			if datatype == "" {
+				datatype = xsdInteger
+			}
+		}
+	case string:
+		lexical = v
+		if datatype == "@json" {
+			lexical = canonicalJSON(v)
+		}
+	default:
+		if datatype != "@json" {
+			return nil, nil
+		}
+		lexical = canonicalJSON(v)
+	}
+	if datatype == "@json" {
+		datatype = rdfJSON
+	}
+
+	literal := nt.Literal{Value: escape.String(lexical)}
+	direction, _ := m["@direction"].(string)
+	switch {
+	case direction != "" && p.options.RdfDirection == "i18n-datatype":
+		reference := nt.IRIReference(i18n + strings.ToLower(language) + "_" + direction)
+		literal.Reference = &reference
+	case language != "":
+		literal.Language = language
+	case datatype != "":
+		reference := nt.IRIReference(datatype)
+		literal.Reference = &reference
+	}
+	return literal, nil
+}
+
+// listToRDF converts the list into a linked list of blank nodes, returns the head of the list.
+func (p *processor) listToRDF(list []any, issuer *issuer) (nt.Object, []nt.Triple) {
+	if len(list) == 0 {
+		return nt.IRIReference(rdfNil), nil
+	}
+	nodes := make([]nt.BlankNode, len(list))
+	for i := range list {
+		nodes[i] = nt.BlankNode(strings.TrimPrefix(issuer.issue(""), "_:"))
+	}
+	var triples []nt.Triple
+	for i, item := range list {
+		o, embedded := p.objectToRDF(item, issuer)
+		if o != nil {
+			triples = append(triples, nt.Triple{Subject: nodes[i], Predicate: rdfFirst, Object: o})
+		}
+		var rest nt.Object = nt.IRIReference(rdfNil)
+		if i+1 < len(list) {
+			rest = nodes[i+1]
+		}
+		triples = append(triples, nt.Triple{Subject: nodes[i], Predicate: rdfRest, Object: rest})
+		triples = append(triples, embedded...)
+	}
+	return nodes[0], triples
+}
+
+// resource returns the blank node or IRI with the given identifier, returns false if the identifier is not a blank node
+// nor a well-formed IRI.
+func resource(id string) (nt.Object, bool) {
+	if isBlankNode(id) {
+		return nt.BlankNode(id[2:]), true
+	}
+	if isWellFormedIRI(id) {
+		return nt.IRIReference(id), true
+	}
+	return nil, false
+}
+
+// canonicalDouble returns the canonical lexical representation of an xsd:double, e.g. 1.1E0.
+func canonicalDouble(v float64) string {
+	s := strconv.FormatFloat(v, 'E', -1, 64)
+	mantissa, exponent, _ := strings.Cut(s, "E")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	e, _ := strconv.Atoi(exponent)
+	return mantissa + "E" + strconv.Itoa(e)
+}
+
+// canonicalJSON returns the canonical serialization of a JSON literal, object keys are sorted and no whitespace is
+// used.
+func canonicalJSON(v any) string {
+	var b strings.Builder
+	writeCanonicalJSON(&b, v)
+	return b.String()
+}
+
+func writeCanonicalJSON(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if 0 < i {
+				b.WriteByte(',')
+			}
+			writeCanonicalJSON(b, k)
+			b.WriteByte(':')
+			writeCanonicalJSON(b, v[k])
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+		for i, item := range v {
+			if 0 < i {
+				b.WriteByte(',')
+			}
+			writeCanonicalJSON(b, item)
+		}
+		b.WriteByte(']')
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e21 {
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			return
+		}
+		b.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	default:
+		e := json.NewEncoder(b)
+		e.SetEscapeHTML(false)
+		_ = e.Encode(v)
+		// Encode terminates every value with a newline.
+		s := b.String()
+		b.Reset()
+		b.WriteString(strings.TrimSuffix(s, "\n"))
+	}
+}
@@ -0,0 +1,209 @@
+package jsonld_test
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	"github.com/0x51-dev/rdf/jsonld"
+	nq "github.com/0x51-dev/rdf/nquads"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	// curatedBase is the base IRI of the curated tests, documents are resolved relative to it.
+	curatedBase = "https://github.com/0x51-dev/rdf/jsonld/testdata/curated/"
+	// apiBase and framingBase are the base IRIs of the W3C test suites of the JSON-LD API and JSON-LD framing.
+	apiBase     = "https://w3c.github.io/json-ld-api/tests/"
+	framingBase = "https://w3c.github.io/json-ld-framing/tests/"
+)
+
+//go:embed testdata/curated
+var curated embed.FS
+
+func ExampleToRDF() {
+	var doc any
+	_ = json.Unmarshal([]byte(`{
+	"@context": {"@vocab": "http://xmlns.com/foaf/0.1/"},
+	"@id": "http://example.com/alice",
+	"name": "Alice",
+	"age": 42
+}`), &doc)
+	d, _ := jsonld.ToRDF(doc, nil)
+	fmt.Print(d)
+	// Output:
+	// <http://example.com/alice> <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+	// <http://example.com/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+}
+
+func TestToRDF(t *testing.T) {
+	runManifests(t, "toRdf", func(t *testing.T, s *testSuite, e *testsuite.Test) error {
+		actual, err := jsonld.ToRDF(s.readJSON(t, e.Action), s.options(e))
+		if e.Type == "jld:NegativeEvaluationTest" {
+			return expectError(e, err)
+		}
+		if err != nil {
+			return err
+		}
+		raw, err := fs.ReadFile(s.fsys, e.Result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := nq.ParseDocument(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !expected.Equal(actual) {
+			return fmt.Errorf("expected:\n%s\nactual:\n%s", expected, actual)
+		}
+		return nil
+	})
+}
+
+// testSuite is a directory of test manifests, its documents are loaded as if they were published at the base IRI.
+type testSuite struct {
+	name   string
+	dir    string
+	base   string
+	fsys   fs.FS
+	loader *jsonld.FSLoader
+	// w3c is true for the W3C test suites, only their results are written to EARL reports.
+	w3c bool
+}
+
+// testSuites returns the curated tests of this repository and the W3C test suite of the manifest of the given kind.
+// The W3C suite is only returned if it has been downloaded by `make download`.
+func testSuites(t *testing.T, kind string) []*testSuite {
+	sub, err := fs.Sub(curated, "testdata/curated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suites := []*testSuite{{name: "curated", dir: "testdata/curated", base: curatedBase, fsys: sub}}
+	if _, err := os.Stat("testdata/suite/" + kind + "-manifest.jsonld"); err == nil {
+		base := apiBase
+		if kind == "frame" {
+			base = framingBase
+		}
+		suites = append(suites, &testSuite{
+			name: "w3c", dir: "testdata/suite", base: base, fsys: os.DirFS("testdata/suite"), w3c: true,
+		})
+	}
+	for _, s := range suites {
+		s.loader = &jsonld.FSLoader{Base: s.base, FS: s.fsys}
+	}
+	return suites
+}
+
+// runManifests runs the tests of the manifest of the given kind (e.g. toRdf) in every test suite, a test fails if run
+// returns an error. Tests of other types than positive and negative evaluation tests, and tests that are specific to
+// JSON-LD 1.0, are skipped.
+func runManifests(t *testing.T, kind string, run func(t *testing.T, s *testSuite, e *testsuite.Test) error) {
+	for _, s := range testSuites(t, kind) {
+		t.Run(s.name, func(t *testing.T) {
+			raw, err := fs.ReadFile(s.fsys, kind+"-manifest.jsonld")
+			if err != nil {
+				t.Fatal(err)
+			}
+			manifest, err := testsuite.LoadJSONManifest(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := project.NewReport(ttl.IRI{Value: s.base + kind + "-manifest#"})
+			for _, k := range manifest.Keys {
+				e := manifest.Entries[k]
+				name := strings.TrimPrefix(k, "#")
+				t.Run(name, func(t *testing.T) {
+					if e.Type != "jld:PositiveEvaluationTest" && e.Type != "jld:NegativeEvaluationTest" {
+						report.AddTest(name, testsuite.Untested)
+						t.Skip("unsupported test type", e.Type)
+					}
+					if e.Option["specVersion"] == "json-ld-1.0" {
+						report.AddTest(name, testsuite.Inapplicable)
+						t.Skip("JSON-LD 1.0 only")
+					}
+					if err := run(t, s, e); err != nil {
+						report.AddTest(name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					report.AddTest(name, testsuite.Passed)
+				})
+			}
+
+			t.Log("Total tests:", report.Len())
+			if s.w3c && os.Getenv("TEST_SUITE_REPORT") == "true" {
+				_ = os.WriteFile(s.dir+"/"+kind+"-report.ttl", []byte(report.String()), 0644)
+			}
+		})
+	}
+}
+
+// expectError returns an error if err is not a JSON-LD error with the expected error code of the negative test.
+func expectError(e *testsuite.Test, err error) error {
+	var jsonldErr *jsonld.Error
+	if !errors.As(err, &jsonldErr) || string(jsonldErr.Code) != e.Result {
+		return fmt.Errorf("expected %q, got %v", e.Result, err)
+	}
+	return nil
+}
+
+// options returns the options of the test, the document URL of the input is used as base IRI.
+func (s *testSuite) options(e *testsuite.Test) *jsonld.Options {
+	o := &jsonld.Options{
+		Base:           s.base + e.Action,
+		DocumentLoader: s.loader,
+	}
+	if v, ok := e.Option["base"].(string); ok {
+		o.Base = v
+	}
+	if v, ok := e.Option["processingMode"].(string); ok {
+		o.ProcessingMode = v
+	}
+	if v, ok := e.Option["rdfDirection"].(string); ok {
+		o.RdfDirection = v
+	}
+	if v, ok := e.Option["useNativeTypes"].(bool); ok {
+		o.UseNativeTypes = v
+	}
+	if v, ok := e.Option["useRdfType"].(bool); ok {
+		o.UseRdfType = v
+	}
+	if v, ok := e.Option["expandContext"].(string); ok {
+		o.ExpandContext = s.base + v
+	}
+	if v, ok := e.Option["compactArrays"].(bool); ok {
+		o.NoCompactArrays = !v
+	}
+	if v, ok := e.Option["compactToRelative"].(bool); ok {
+		o.NoCompactToRelative = !v
+	}
+	if v, ok := e.Option["embed"].(string); ok {
+		o.Embed = v
+	}
+	if v, ok := e.Option["explicit"].(bool); ok {
+		o.Explicit = v
+	}
+	if v, ok := e.Option["omitDefault"].(bool); ok {
+		o.OmitDefault = v
+	}
+	if v, ok := e.Option["omitGraph"].(bool); ok {
+		o.OmitGraph = v
+	}
+	if v, ok := e.Option["requireAll"].(bool); ok {
+		o.RequireAll = v
+	}
+	return o
+}
+
+func (s *testSuite) readJSON(t *testing.T, name string) any {
+	doc, err := s.loader.LoadDocument(s.base + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Document
+}
@@ -56,11 +56,14 @@ type Literal struct {
 	Datatype DataType
 	// Language can only be present if Datatype is XSDNSString.
 	Language string
+	// Direction is the base direction ("ltr" or "rtl"), it can only be present if Datatype is XSDNSDirString.
+	Direction string
 }
 
 func (l *Literal) Equal(other Node) bool {
 	if other, ok := other.(*Literal); ok {
-		return l.Value == other.Value && l.Datatype == other.Datatype && l.Language == other.Language
+		return l.Value == other.Value && l.Datatype == other.Datatype && l.Language == other.Language &&
+			l.Direction == other.Direction
 	}
 	return false
 }
@@ -71,13 +74,17 @@ func (l *Literal) GetValue() string {
 
 func (l *Literal) toObject(nativeTypes bool) (map[string]any, error) {
 	if l.Language != "" {
-		if l.Datatype != "" && l.Datatype != XSDNSString {
+		if l.Datatype != "" && l.Datatype != XSDNSString && l.Datatype != XSDNSDirString {
 			return nil, fmt.Errorf("invalid datatype for language literal: %s", l.Datatype)
 		}
-		return map[string]any{
+		obj := map[string]any{
 			"@value":    l.Value,
 			"@language": l.Language,
-		}, nil
+		}
+		if l.Direction != "" {
+			obj["@direction"] = l.Direction
+		}
+		return obj, nil
 	}
 	if l.Datatype != XSDString {
 		if nativeTypes {
@@ -163,3 +170,77 @@ func fromObject(obj any, acceptString bool) (Node, error) {
 	}
 	return &IRIReference{Value: id}, nil
 }
+
+// TripleTerm is a triple that is used as node of another triple, e.g. to make statements about statements (RDF-star).
+// The triple itself is not asserted. Components of a triple term can be nil when used as pattern, see Graph.Find.
+type TripleTerm struct {
+	Triple
+}
+
+// Equal returns true if the other node is a triple term with equal components, a nil component only equals nil.
+func (t *TripleTerm) Equal(other Node) bool {
+	if other, ok := other.(*TripleTerm); ok {
+		return equalComponent(t.Subject, other.Subject) && equalComponent(t.Predicate, other.Predicate) &&
+			equalComponent(t.Object, other.Object)
+	}
+	return false
+}
+
+// GetValue returns the triple term in the syntax of Turtle-star, nil components are written as *.
+func (t *TripleTerm) GetValue() string {
+	return fmt.Sprintf("<< %s %s %s >>", componentValue(t.Subject), componentValue(t.Predicate), componentValue(t.Object))
+}
+
+// toObject returns the triple term as embedded node (JSON-LD-star), patterns can not be converted.
+func (t *TripleTerm) toObject(nativeTypes bool) (map[string]any, error) {
+	if t.isPattern() {
+		return nil, fmt.Errorf("invalid triple term: %s is a pattern", t.GetValue())
+	}
+	s, err := t.Subject.toObject(nativeTypes)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := t.Predicate.(*IRIReference)
+	if !ok {
+		return nil, fmt.Errorf("invalid predicate for triple term: %s", t.Predicate.GetValue())
+	}
+	o, err := t.Object.toObject(nativeTypes)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"@id": map[string]any{
+			"@id":   s["@id"],
+			p.Value: []any{o},
+		},
+	}, nil
+}
+
+// equalComponent returns true if both components of a triple term are equal, nil only equals nil.
+func equalComponent(a, b Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(b)
+}
+
+// componentValue returns the value of a component of a triple term, * if it is nil.
+func componentValue(n Node) string {
+	if n == nil {
+		return "*"
+	}
+	return n.GetValue()
+}
+
+// isPattern returns true if one of the (nested) components of the triple term is nil.
+func (t *TripleTerm) isPattern() bool {
+	for _, n := range []Node{t.Subject, t.Predicate, t.Object} {
+		if n == nil {
+			return true
+		}
+		if tt, ok := n.(*TripleTerm); ok && tt.isPattern() {
+			return true
+		}
+	}
+	return false
+}
@@ -56,3 +56,28 @@ func TestFromObject_iriReference(t *testing.T) {
 		}
 	}
 }
+
+func TestTripleTerm_pattern(t *testing.T) {
+	alice := &IRIReference{Value: exampleURI + "alice"}
+	knows := &IRIReference{Value: exampleURI + "knows"}
+	pattern := &TripleTerm{Triple{Subject: alice, Object: &TripleTerm{Triple{Predicate: knows}}}}
+	if !pattern.Equal(&TripleTerm{Triple{Subject: alice, Object: &TripleTerm{Triple{Predicate: knows}}}}) {
+		t.Error("expected equal patterns")
+	}
+	if pattern.Equal(&TripleTerm{Triple{Subject: alice, Predicate: knows, Object: &TripleTerm{}}}) {
+		t.Error("expected nil to only equal nil")
+	}
+	if (&TripleTerm{Triple{alice, knows, alice}}).Equal(&TripleTerm{Triple{Subject: alice}}) {
+		t.Error("expected nil to only equal nil")
+	}
+	expected := "<< https://example.org/alice * << * https://example.org/knows * >> >>"
+	if v := pattern.GetValue(); v != expected {
+		t.Errorf("expected %q, got %q", expected, v)
+	}
+	if _, err := pattern.toObject(false); err == nil {
+		t.Error("expected a pattern to be rejected")
+	}
+	if _, err := (&TripleTerm{Triple{alice, knows, &TripleTerm{Triple{Predicate: knows}}}}).toObject(false); err == nil {
+		t.Error("expected a nested pattern to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package nquads
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/internal/isomorphism"
 	"github.com/0x51-dev/rdf/nquads/grammar"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	"github.com/0x51-dev/upeg/parser"
@@ -27,7 +28,13 @@ func ParseGraphLabel(n *parser.Node) (nt.Subject, error) {
 
 type Document []Quad
 
+// ParseDocument parses the N-Quads document within the RDF-star mode, see ParseDocumentMode.
 func ParseDocument(doc string) (Document, error) {
+	return ParseDocumentMode(doc, nt.RDFStar)
+}
+
+// ParseDocumentMode parses the N-Quads document, the quads must be supported by the given version of RDF.
+func ParseDocumentMode(doc string, m nt.Mode) (Document, error) {
 	if len(doc) == 0 {
 		return nil, nil
 	}
@@ -42,10 +49,10 @@ func ParseDocument(doc string) (Document, error) {
 	if err != nil {
 		return nil, err
 	}
-	return parseDocument(n)
+	return parseDocument(n, m)
 }
 
-func parseDocument(n *parser.Node) (Document, error) {
+func parseDocument(n *parser.Node, m nt.Mode) (Document, error) {
 	if n.Name != "Document" {
 		return nil, fmt.Errorf("document: unknown %s", n.Name)
 	}
@@ -55,27 +62,20 @@ func parseDocument(n *parser.Node) (Document, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := quad.Triple.Validate(m); err != nil {
+			return nil, err
+		}
 		document = append(document, *quad)
 	}
 	sort.Sort(document)
 	return document, nil
 }
 
+// Equal returns true if the document is equal to the given value.
+// NOTE: blank nodes will be compared, not by value, but by relation in the document (see Isomorphic).
 func (d Document) Equal(other Document) bool {
-	if len(d) != len(other) {
-		return false
-	}
-	g0, g1 := d.Graphs(), other.Graphs()
-	for g, q0 := range g0 {
-		q1, ok := g1[g]
-		if !ok {
-			return false
-		}
-		if !q0.Equal(q1) {
-			return false
-		}
-	}
-	return true
+	_, ok := d.Isomorphic(other)
+	return ok
 }
 
 func (d Document) Graphs() map[string]nt.Document {
@@ -90,6 +90,21 @@ func (d Document) Graphs() map[string]nt.Document {
 	return g
 }
 
+// Isomorphic returns true if both documents describe the same dataset, i.e. there is a bijection between the blank
+// nodes of both documents so that their quads are equal. Blank nodes are shared between the graphs of a dataset, the
+// mapping also includes blank node graph labels. Duplicate quads are ignored.
+func (d Document) Isomorphic(other Document) (map[nt.BlankNode]nt.BlankNode, bool) {
+	m, ok := isomorphism.Find(d.statements(), other.statements())
+	if !ok {
+		return nil, false
+	}
+	mapping := make(map[nt.BlankNode]nt.BlankNode, len(m))
+	for k, v := range m {
+		mapping[nt.BlankNode(k)] = nt.BlankNode(v)
+	}
+	return mapping, true
+}
+
 func (d Document) Len() int {
 	return len(d)
 }
@@ -141,6 +156,24 @@ func (d Document) Swap(i, j int) {
 	d[i], d[j] = d[j], d[i]
 }
 
+func (d Document) statements() []isomorphism.Statement {
+	statements := make([]isomorphism.Statement, len(d))
+	for i, q := range d {
+		// The default graph is represented by an empty term.
+		var g isomorphism.Term
+		if q.GraphLabel != nil {
+			g = isomorphism.FromNTriples(q.GraphLabel.String())
+		}
+		statements[i] = isomorphism.Statement{
+			isomorphism.FromNTriples(q.Subject.String()),
+			isomorphism.FromNTriples(q.Predicate.String()),
+			isomorphism.FromNTriples(q.Object.String()),
+			g,
+		}
+	}
+	return statements
+}
+
 type Quad struct {
 	nt.Triple
 	GraphLabel nt.Subject
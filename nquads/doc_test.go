@@ -9,6 +9,7 @@ import (
 	nq "github.com/0x51-dev/rdf/nquads"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	ttl "github.com/0x51-dev/rdf/turtle"
+	"maps"
 	"os"
 	"testing"
 )
@@ -78,6 +79,47 @@ func TestDocument_Equal(t *testing.T) {
 	}
 }
 
+func TestDocument_Isomorphic(t *testing.T) {
+	a, err := nq.ParseDocument(`_:x <http://example.com/p> _:y _:g .
+_:y <http://example.com/p> "y" <http://example.com/g> .
+_:g <http://example.com/p> "g" .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := nq.ParseDocument(`_:g2 <http://example.com/p> "g" .
+_:y2 <http://example.com/p> "y" <http://example.com/g> .
+_:x2 <http://example.com/p> _:y2 _:g2 .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping, ok := a.Isomorphic(b)
+	if !ok {
+		t.Fatal("expected isomorphic documents")
+	}
+	if !maps.Equal(mapping, map[nt.BlankNode]nt.BlankNode{"g": "g2", "x": "x2", "y": "y2"}) {
+		t.Error(mapping)
+	}
+
+	// Blank nodes are shared between graphs.
+	c, err := nq.ParseDocument(`_:x <http://example.com/p> _:y <http://example.com/g1> .
+_:x <http://example.com/p> _:y <http://example.com/g2> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := nq.ParseDocument(`_:x <http://example.com/p> _:y <http://example.com/g1> .
+_:a <http://example.com/p> _:b <http://example.com/g2> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Equal(d) {
+		t.Error("expected different documents")
+	}
+}
+
 func TestExamples(t *testing.T) {
 	for _, test := range []struct {
 		doc   string
@@ -0,0 +1,70 @@
+package nquads
+
+import (
+	"bufio"
+	"github.com/0x51-dev/rdf/internal/lines"
+	"github.com/0x51-dev/rdf/nquads/grammar"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	ntg "github.com/0x51-dev/rdf/ntriples/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"io"
+)
+
+// Reader reads quads from an N-Quads document, one line at a time. Quads are returned in the order in which they
+// appear in the document.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, lines.MaxSize)
+	s.Split(lines.Scan)
+	return &Reader{s: s}
+}
+
+// Read returns the next quad in the document. Returns io.EOF if there are no more quads.
+func (r *Reader) Read() (Quad, error) {
+	for r.s.Scan() {
+		r.line++
+		line := r.s.Text()
+		if lines.IsBlank(line) {
+			continue
+		}
+		p, err := parser.New([]rune(line))
+		if err != nil {
+			return Quad{}, err
+		}
+		n, err := p.Parse(op.And{grammar.Statement, ntg.OWhitespace, op.EOF{}})
+		if err != nil {
+			return Quad{}, nt.NewSyntaxError(r.line, err)
+		}
+		q, err := ParseQuad(n)
+		if err != nil {
+			return Quad{}, nt.NewSyntaxError(r.line, err)
+		}
+		return *q, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return Quad{}, err
+	}
+	return Quad{}, io.EOF
+}
+
+// ReadAll reads all the remaining quads from the document.
+func (r *Reader) ReadAll() (Document, error) {
+	var document Document
+	for {
+		q, err := r.Read()
+		if err == io.EOF {
+			return document, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		document = append(document, q)
+	}
+}
@@ -0,0 +1,81 @@
+package nquads_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nq "github.com/0x51-dev/rdf/nquads"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func ExampleWriter() {
+	r := nq.NewReader(strings.NewReader(example1))
+	var b bytes.Buffer
+	w := nq.NewWriter(&b)
+	for {
+		q, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		_ = w.Write(q)
+	}
+	_ = w.Flush()
+	fmt.Print(b.String())
+	// Output:
+	// <http://one.example/subject1> <http://one.example/predicate1> <http://one.example/object1> <http://example.org/graph3> .
+	// _:subject1 <http://an.example/predicate1> "object1" <http://example.org/graph1> .
+	// _:subject2 <http://an.example/predicate2> "object2" <http://example.org/graph5> .
+}
+
+func TestReader_suite(t *testing.T) {
+	manifest, err := testsuite.LoadManifest(rawManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s", e.Action))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := nq.NewReader(bytes.NewReader(raw)).ReadAll()
+		switch e.Type {
+		case "rdft:TestNQuadsPositiveSyntax":
+			t.Run(e.Name, func(t *testing.T) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				doc2, err := nq.ParseDocument(string(raw))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sort.Sort(doc)
+				if !doc.Equal(doc2) {
+					t.Fatal(doc, doc2)
+				}
+
+				// Writer
+				var b bytes.Buffer
+				if err := nq.NewWriter(&b).WriteAll(doc); err != nil {
+					t.Fatal(err)
+				}
+				doc3, err := nq.NewReader(&b).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !doc.Equal(doc3) {
+					t.Fatal(doc, doc3)
+				}
+			})
+		case "rdft:TestNQuadsNegativeSyntax":
+			t.Run(e.Name, func(t *testing.T) {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package nquads
+
+import (
+	"bufio"
+	"io"
+)
+
+// Writer writes quads to an N-Quads document, one line per quad. Writes are buffered, Flush must be called to ensure
+// that all data has been written to the underlying io.Writer.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Write writes a single quad, followed by a newline.
+func (w *Writer) Write(q Quad) error {
+	if _, err := w.w.WriteString(q.String()); err != nil {
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// WriteAll writes all the quads of the document and flushes the writer.
+func (w *Writer) WriteAll(d Document) error {
+	for _, q := range d {
+		if err := w.Write(q); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -2,6 +2,7 @@ package ntriples
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/internal/isomorphism"
 	"github.com/0x51-dev/rdf/ntriples/grammar"
 	"github.com/0x51-dev/rids/iri"
 	"github.com/0x51-dev/upeg/parser"
@@ -18,6 +19,40 @@ func ToggleValidation(enabled bool) {
 	validation = enabled
 }
 
+// Mode is the version of RDF that is used to parse and evaluate documents.
+type Mode int
+
+const (
+	// RDF11 only supports RDF 1.1, quoted triples, triple terms and base directions are rejected.
+	RDF11 Mode = iota
+	// RDFStar supports the quoted triples of the RDF-star community group report (default).
+	RDFStar
+	// RDF12 supports the triple terms, reifiers and base directions of RDF 1.2.
+	RDF12
+)
+
+func (m Mode) String() string {
+	switch m {
+	case RDF11:
+		return "RDF 1.1"
+	case RDFStar:
+		return "RDF-star"
+	case RDF12:
+		return "RDF 1.2"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ValidateDirection returns an error if the base direction is not "ltr" or "rtl", or if the mode does not support
+// base directions.
+func (m Mode) ValidateDirection(direction string) error {
+	if m != RDF12 {
+		return fmt.Errorf("literal: base direction %q is not supported by %s", direction, m)
+	}
+	return validateDirection(direction)
+}
+
 type BlankNode string
 
 func ParseBlankNodeLabel(n *parser.Node) (*BlankNode, error) {
@@ -53,7 +88,14 @@ func (n BlankNode) subject() {}
 
 type Document []Triple
 
+// ParseDocument parses the N-Triples document within the RDF-star mode, see ParseDocumentMode.
 func ParseDocument(doc string) (Document, error) {
+	return ParseDocumentMode(doc, RDFStar)
+}
+
+// ParseDocumentMode parses the N-Triples document, the triples must be supported by the given version of RDF (e.g.
+// base directions are only supported by RDF 1.2).
+func ParseDocumentMode(doc string, m Mode) (Document, error) {
 	if len(doc) == 0 {
 		return nil, nil
 	}
@@ -68,10 +110,10 @@ func ParseDocument(doc string) (Document, error) {
 	if err != nil {
 		return nil, err
 	}
-	return parseDocument(n)
+	return parseDocument(n, m)
 }
 
-func parseDocument(n *parser.Node) (Document, error) {
+func parseDocument(n *parser.Node, m Mode) (Document, error) {
 	if n.Name != "Document" {
 		return nil, fmt.Errorf("document: unknown %s", n.Name)
 	}
@@ -81,6 +123,9 @@ func parseDocument(n *parser.Node) (Document, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := t.Validate(m); err != nil {
+			return nil, err
+		}
 		document = append(document, *t)
 	}
 	sort.Sort(document)
@@ -88,19 +133,25 @@ func parseDocument(n *parser.Node) (Document, error) {
 }
 
 // Equal returns true if the document is equal to the given value.
-// NOTE: blank nodes will be compared, not by value, but by relation in the document.
+// NOTE: blank nodes will be compared, not by value, but by relation in the document (see Isomorphic).
 func (d Document) Equal(other Document) bool {
-	if len(d) != len(other) {
-		return false
+	_, ok := d.Isomorphic(other)
+	return ok
+}
+
+// Isomorphic returns true if both documents describe the same graph, i.e. there is a bijection between the blank
+// nodes of both documents so that their triples are equal. The returned mapping maps the blank nodes of the document
+// to those of the other document. Duplicate triples are ignored.
+func (d Document) Isomorphic(other Document) (map[BlankNode]BlankNode, bool) {
+	m, ok := isomorphism.Find(d.statements(), other.statements())
+	if !ok {
+		return nil, false
 	}
-	d, o := d.NormalizeBlankNodes(), other.NormalizeBlankNodes()
-	for i, t0 := range d {
-		t1 := o[i]
-		if !t0.equal(t1, true) {
-			return false
-		}
+	mapping := make(map[BlankNode]BlankNode, len(m))
+	for k, v := range m {
+		mapping[BlankNode(k)] = BlankNode(v)
 	}
-	return true
+	return mapping, true
 }
 
 func (d Document) Len() int {
@@ -156,6 +207,18 @@ func (d Document) Swap(i, j int) {
 	d[i], d[j] = d[j], d[i]
 }
 
+func (d Document) statements() []isomorphism.Statement {
+	statements := make([]isomorphism.Statement, len(d))
+	for i, t := range d {
+		statements[i] = isomorphism.Statement{
+			isomorphism.FromNTriples(t.Subject.String()),
+			isomorphism.FromNTriples(t.Predicate.String()),
+			isomorphism.FromNTriples(t.Object.String()),
+		}
+	}
+	return statements
+}
+
 type IRIReference string
 
 func ParseIRIReference(n *parser.Node) (*IRIReference, error) {
@@ -231,6 +294,8 @@ type Literal struct {
 	Value     string
 	Reference *IRIReference
 	Language  string
+	// Direction is the base direction ("ltr" or "rtl") of a language-tagged string, only supported by RDF 1.2.
+	Direction string
 }
 
 func ParseLiteral(n *parser.Node) (*Literal, error) {
@@ -250,6 +315,11 @@ func ParseLiteral(n *parser.Node) (*Literal, error) {
 			literal.Reference = ref
 		case "LanguageTag":
 			literal.Language = n.Value()
+		case "Direction":
+			if err := validateDirection(n.Value()); err != nil {
+				return nil, err
+			}
+			literal.Direction = n.Value()
 		default:
 			return nil, fmt.Errorf("literal: unknown child: %s", n.Name)
 		}
@@ -272,6 +342,9 @@ func (l Literal) String() string {
 	if l.Reference != nil {
 		return fmt.Sprintf(`"%s"^^%s`, l.Value, l.Reference)
 	}
+	if len(l.Direction) > 0 {
+		return fmt.Sprintf(`"%s"@%s--%s`, l.Value, l.Language, l.Direction)
+	}
 	if len(l.Language) > 0 {
 		return fmt.Sprintf(`"%s"@%s`, l.Value, l.Language)
 	}
@@ -285,11 +358,18 @@ func (l Literal) equal(other Literal) bool {
 	if l.Reference == nil && other.Reference != nil {
 		return false
 	}
-	return l.Value == other.Value && l.Language == other.Language
+	return l.Value == other.Value && l.Language == other.Language && l.Direction == other.Direction
 }
 
 func (l Literal) object() {}
 
+func validateDirection(direction string) error {
+	if direction != "ltr" && direction != "rtl" {
+		return fmt.Errorf("literal: invalid base direction %q", direction)
+	}
+	return nil
+}
+
 // Object is either an IRI, a blank node, or a literal.
 type Object interface {
 	object()
@@ -385,6 +465,23 @@ func (t Triple) String() string {
 	return fmt.Sprintf("%s %s %s .", t.Subject, t.Predicate, t.Object)
 }
 
+// Validate returns an error if the triple is not supported by the given version of RDF.
+func (t Triple) Validate(m Mode) error {
+	var l Literal
+	switch o := t.Object.(type) {
+	case Literal:
+		l = o
+	case *Literal:
+		l = *o
+	default:
+		return nil
+	}
+	if l.Direction != "" {
+		return m.ValidateDirection(l.Direction)
+	}
+	return nil
+}
+
 func (t Triple) equal(other Triple, checkBlankNode bool) bool {
 	switch t.Subject.(type) {
 	case BlankNode, *BlankNode:
@@ -8,6 +8,7 @@ import (
 	"github.com/0x51-dev/rdf/internal/testsuite"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	ttl "github.com/0x51-dev/rdf/turtle"
+	"maps"
 	"os"
 	"testing"
 )
@@ -93,6 +94,75 @@ func TestDocument_Equal(t *testing.T) {
 	})
 }
 
+func TestDocument_Isomorphic(t *testing.T) {
+	for _, test := range []struct {
+		a, b    string
+		mapping map[nt.BlankNode]nt.BlankNode // nil if not isomorphic.
+	}{
+		{ // Labels are assigned in a different order when normalized.
+			a: `_:x <http://example.com/p> _:y .
+_:y <http://example.com/q> "y" .
+_:z <http://example.com/p> _:x .
+`,
+			b: `_:a <http://example.com/p> _:c .
+_:b <http://example.com/p> _:a .
+_:c <http://example.com/q> "y" .
+`,
+			mapping: map[nt.BlankNode]nt.BlankNode{"x": "a", "y": "c", "z": "b"},
+		},
+		{ // Escaped and unescaped characters.
+			a:       `_:x <http://example.com/p> "\u00E9t\u00e9"^^<http://www.w3.org/2001/XMLSchema#string> .`,
+			b:       `_:y <http://example.com/p> "été" .`,
+			mapping: map[nt.BlankNode]nt.BlankNode{"x": "y"},
+		},
+		{ // Quotes in the lexical form, language tags are case-insensitive.
+			a:       `_:x <http://example.com/p> "\"a\" \u0022b\u0022"@EN .`,
+			b:       `_:y <http://example.com/p> "\"a\" \"b\""@en .`,
+			mapping: map[nt.BlankNode]nt.BlankNode{"x": "y"},
+		},
+		{ // Literals with the same lexical form, but a different datatype.
+			a: `_:x <http://example.com/p> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+			b: `_:y <http://example.com/p> "1" .`,
+		},
+		{ // Two cycles of three versus one cycle of six, can not be distinguished by colour refinement.
+			a: `_:a <http://example.com/p> _:b .
+_:b <http://example.com/p> _:c .
+_:c <http://example.com/p> _:a .
+_:d <http://example.com/p> _:e .
+_:e <http://example.com/p> _:f .
+_:f <http://example.com/p> _:d .
+`,
+			b: `_:a <http://example.com/p> _:b .
+_:b <http://example.com/p> _:c .
+_:c <http://example.com/p> _:d .
+_:d <http://example.com/p> _:e .
+_:e <http://example.com/p> _:f .
+_:f <http://example.com/p> _:a .
+`,
+		},
+		{ // Same blank node used twice.
+			a: `_:a <http://example.com/p> _:a .`,
+			b: `_:a <http://example.com/p> _:b .`,
+		},
+	} {
+		a, err := nt.ParseDocument(test.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := nt.ParseDocument(test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mapping, ok := a.Isomorphic(b)
+		if ok != (test.mapping != nil) {
+			t.Fatal(test.a, test.b)
+		}
+		if !maps.Equal(mapping, test.mapping) {
+			t.Error(mapping, test.mapping)
+		}
+	}
+}
+
 func TestExamples(t *testing.T) {
 	for _, test := range []struct {
 		doc     string
@@ -177,3 +247,27 @@ func TestSuite(t *testing.T) {
 		_ = os.WriteFile("testdata/suite/report.ttl", []byte(report.String()), 0644)
 	}
 }
+
+func TestParseDocumentMode(t *testing.T) {
+	const doc = `<http://example/s> <http://example/p> "مرحبا"@ar--rtl .`
+	for _, m := range []nt.Mode{nt.RDF11, nt.RDFStar} {
+		if _, err := nt.ParseDocumentMode(doc, m); err == nil {
+			t.Errorf("expected base direction to be rejected by %s", m)
+		}
+	}
+
+	triples, err := nt.ParseDocumentMode(doc, nt.RDF12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := triples[0].Object.(*nt.Literal)
+	if l.Language != "ar" || l.Direction != "rtl" {
+		t.Error(l)
+	}
+	if s := triples.String(); s != doc+"\n" {
+		t.Error(s)
+	}
+	if _, err := nt.ParseDocumentMode(`<http://example/s> <http://example/p> "a"@en--up .`, nt.RDF12); err == nil {
+		t.Error("expected invalid base direction")
+	}
+}
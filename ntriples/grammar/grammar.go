@@ -61,6 +61,14 @@ var (
 				}},
 			},
 		},
+		// Base direction of RDF 1.2, e.g. "@ar--rtl".
+		op.Optional{Value: op.And{
+			"--",
+			op.Capture{
+				Name:  "Direction",
+				Value: op.OneOrMore{Value: op.Or{op.RuneRange{Min: 'a', Max: 'z'}, op.RuneRange{Min: 'A', Max: 'Z'}}},
+			},
+		}},
 	}
 	IRIReference = op.And{
 		'<',
@@ -0,0 +1,117 @@
+package ntriples
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/lines"
+	"github.com/0x51-dev/rdf/ntriples/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"io"
+)
+
+// Reader reads triples from an N-Triples document, one line at a time. Triples are returned in the order in which they
+// appear in the document.
+type Reader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, lines.MaxSize)
+	s.Split(lines.Scan)
+	return &Reader{s: s}
+}
+
+// Read returns the next triple in the document. Returns io.EOF if there are no more triples.
+func (r *Reader) Read() (Triple, error) {
+	for r.s.Scan() {
+		r.line++
+		line := r.s.Text()
+		if lines.IsBlank(line) {
+			continue
+		}
+		p, err := parser.New([]rune(line))
+		if err != nil {
+			return Triple{}, err
+		}
+		n, err := p.Parse(op.And{grammar.Triple, grammar.OWhitespace, op.EOF{}})
+		if err != nil {
+			return Triple{}, NewSyntaxError(r.line, err)
+		}
+		t, err := ParseTriple(n)
+		if err != nil {
+			return Triple{}, NewSyntaxError(r.line, err)
+		}
+		return *t, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return Triple{}, err
+	}
+	return Triple{}, io.EOF
+}
+
+// ReadAll reads all the remaining triples from the document.
+func (r *Reader) ReadAll() (Document, error) {
+	var document Document
+	for {
+		t, err := r.Read()
+		if err == io.EOF {
+			return document, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		document = append(document, t)
+	}
+}
+
+// SyntaxError is returned when a line of a document could not be parsed.
+type SyntaxError struct {
+	// Line is the line number, starting at 1.
+	Line int
+	// Column is the column number, starting at 1. Zero if unknown.
+	Column int
+	Err    error
+}
+
+// NewSyntaxError wraps the given (parser) error, the column is derived from the furthest point the parser reached.
+func NewSyntaxError(line int, err error) *SyntaxError {
+	var column int
+	var stack *parser.ErrorStack
+	if errors.As(err, &stack) {
+		for _, err := range stack.Errors {
+			var e *parser.NoMatchError
+			if errors.As(err, &e) {
+				if _, c := e.End.Line(); column < c+1 {
+					column = c + 1
+				}
+			}
+		}
+	} else {
+		var e *parser.NoMatchError
+		if errors.As(err, &e) {
+			_, c := e.End.Line()
+			column = c + 1
+		}
+	}
+	return &SyntaxError{
+		Line:   line,
+		Column: column,
+		Err:    err,
+	}
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
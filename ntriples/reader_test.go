@@ -0,0 +1,79 @@
+package ntriples_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func ExampleReader() {
+	r := nt.NewReader(strings.NewReader(example1))
+	for {
+		t, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		fmt.Println(t)
+	}
+	// Output:
+	// <http://one.example/subject1> <http://one.example/predicate1> <http://one.example/object1> .
+	// _:subject1 <http://an.example/predicate1> "object1" .
+	// _:subject2 <http://an.example/predicate2> "object2" .
+}
+
+func TestReader(t *testing.T) {
+	r := nt.NewReader(strings.NewReader("# comment\r\n<http://example.com/s> <http://example.com/p> \"o\" .\r\r\n<http://example.com/s> <http://example.com/p> <http://example.com/o"))
+	if _, err := r.Read(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := r.Read()
+	var e *nt.SyntaxError
+	if !errors.As(err, &e) {
+		t.Fatal(err)
+	}
+	if e.Line != 4 || e.Column != 47 {
+		t.Error(e.Line, e.Column)
+	}
+}
+
+func TestReader_suite(t *testing.T) {
+	manifest, err := testsuite.LoadManifest(rawManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s", e.Action))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := nt.NewReader(strings.NewReader(string(raw))).ReadAll()
+		switch e.Type {
+		case "rdft:TestNTriplesPositiveSyntax":
+			t.Run(e.Name, func(t *testing.T) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				doc2, err := nt.ParseDocument(string(raw))
+				if err != nil {
+					t.Fatal(err)
+				}
+				sort.Sort(doc)
+				if !doc.Equal(doc2) {
+					t.Fatal(doc, doc2)
+				}
+			})
+		case "rdft:TestNTriplesNegativeSyntax":
+			t.Run(e.Name, func(t *testing.T) {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			})
+		}
+	}
+}
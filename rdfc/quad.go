@@ -0,0 +1,125 @@
+package rdfc
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"strings"
+)
+
+const xsdString = "http://www.w3.org/2001/XMLSchema#string"
+
+// quad is a quad of which all terms are in canonical N-Quads form, except for the blank nodes. The graph term is
+// empty for quads in the default graph.
+type quad [4]term
+
+func newQuad(q nq.Quad) (*quad, error) {
+	var v quad
+	for i, n := range []any{q.Subject, q.Predicate, q.Object, q.GraphLabel} {
+		t, err := newTerm(n)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = t
+	}
+	return &v, nil
+}
+
+// serialize returns the canonical N-Quads serialization of the quad, including the trailing newline. Blank node
+// identifiers are replaced by the result of the given label function.
+func (q quad) serialize(label func(id string) string) string {
+	var b strings.Builder
+	for i, t := range q {
+		if t.value == "" {
+			continue
+		}
+		if 0 < i {
+			b.WriteByte(' ')
+		}
+		if t.blank {
+			b.WriteString("_:" + label(t.value))
+		} else {
+			b.WriteString(t.value)
+		}
+	}
+	b.WriteString(" .\n")
+	return b.String()
+}
+
+// term is either a blank node identifier, or the canonical N-Quads serialization of an IRI or literal.
+type term struct {
+	value string
+	blank bool
+}
+
+func newTerm(n any) (term, error) {
+	switch n := n.(type) {
+	case nil:
+		return term{}, nil
+	case nt.BlankNode:
+		return term{value: string(n), blank: true}, nil
+	case *nt.BlankNode:
+		return term{value: string(*n), blank: true}, nil
+	case nt.IRIReference:
+		return iri(n), nil
+	case *nt.IRIReference:
+		return iri(*n), nil
+	case nt.Literal:
+		return literal(n), nil
+	case *nt.Literal:
+		return literal(*n), nil
+	default:
+		return term{}, fmt.Errorf("rdfc: unknown term type %T", n)
+	}
+}
+
+func iri(r nt.IRIReference) term {
+	return term{value: "<" + escape.Unescape(string(r)) + ">"}
+}
+
+func literal(l nt.Literal) term {
+	s := `"` + canonical(escape.Unescape(l.Value)) + `"`
+	if l.Direction != "" {
+		return term{value: s + "@" + l.Language + "--" + l.Direction}
+	}
+	if l.Language != "" {
+		return term{value: s + "@" + l.Language}
+	}
+	if l.Reference != nil {
+		if dt := iri(*l.Reference); dt.value != "<"+xsdString+">" {
+			s += "^^" + dt.value
+		}
+	}
+	return term{value: s}
+}
+
+// canonical escapes a literal value according to the canonical form of N-Triples.
+func canonical(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r <= 0x1F || r == 0x7F {
+				_, _ = fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,370 @@
+// Package rdfc implements the RDF Dataset Canonicalization algorithm (RDFC-1.0).
+//
+// The algorithm assigns deterministic identifiers to the blank nodes of a dataset, so that two isomorphic datasets
+// result in the same canonical N-Quads document.
+//
+// Reference: https://www.w3.org/TR/rdf-canon/
+package rdfc
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	nq "github.com/0x51-dev/rdf/nquads"
+	"hash"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrWorkLimit is returned if the canonicalization of a dataset exceeds the work limit of the canonicalizer. This
+// protects against poison datasets, which take an exponential amount of time to canonicalize.
+var ErrWorkLimit = errors.New("rdfc: work limit exceeded")
+
+// DefaultWorkFactor is the work factor used if none is specified.
+const DefaultWorkFactor = 3
+
+// Canonicalize returns the canonical N-Quads serialization of the document, using the default canonicalizer.
+func Canonicalize(d nq.Document) (string, error) {
+	return new(Canonicalizer).Canonicalize(d)
+}
+
+// Canonicalizer holds the options of the canonicalization algorithm. The zero value is ready to use.
+type Canonicalizer struct {
+	// Hash is the hash algorithm, either crypto.SHA256 or crypto.SHA384. Defaults to crypto.SHA256.
+	Hash crypto.Hash
+	// WorkFactor limits the number of times the hash N-degree quads algorithm can be run for a single blank node to
+	// n^WorkFactor, where n is the number of blank nodes without a unique first degree hash. Defaults to
+	// DefaultWorkFactor, a negative value disables the limit.
+	WorkFactor int
+}
+
+// Canonicalize returns the canonical N-Quads serialization of the document. Each quad is written on its own line,
+// lines are sorted in code point order and duplicate quads are removed.
+func (c *Canonicalizer) Canonicalize(d nq.Document) (string, error) {
+	s, err := c.canonicalize(d)
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, 0, len(s.quads))
+	for _, q := range s.quads {
+		lines = append(lines, q.serialize(func(id string) string {
+			return s.canonical.issued[id]
+		}))
+	}
+	sort.Strings(lines)
+	var b strings.Builder
+	for i, l := range lines {
+		if 0 < i && lines[i-1] == l {
+			continue
+		}
+		b.WriteString(l)
+	}
+	return b.String(), nil
+}
+
+// IssuedIdentifiers returns the mapping from the blank node identifiers of the document to their canonical
+// identifiers, e.g. "b0" to "c14n0".
+func (c *Canonicalizer) IssuedIdentifiers(d nq.Document) (map[string]string, error) {
+	s, err := c.canonicalize(d)
+	if err != nil {
+		return nil, err
+	}
+	issued := make(map[string]string, len(s.canonical.issued))
+	for k, v := range s.canonical.issued {
+		issued[k] = v
+	}
+	return issued, nil
+}
+
+func (c *Canonicalizer) canonicalize(d nq.Document) (*state, error) {
+	var h func() hash.Hash
+	switch c.Hash {
+	case 0, crypto.SHA256:
+		h = sha256.New
+	case crypto.SHA384:
+		h = sha512.New384
+	default:
+		return nil, fmt.Errorf("rdfc: unsupported hash algorithm: %s", c.Hash)
+	}
+
+	s := &state{
+		hash:         h,
+		blankNodes:   make(map[string][]*quad),
+		canonical:    newIssuer("c14n"),
+		deepIterated: make(map[string]int),
+		maxDeep:      -1,
+	}
+	seen := make(map[quad]bool)
+	for _, q := range d {
+		q, err := newQuad(q)
+		if err != nil {
+			return nil, err
+		}
+		// Datasets are sets, duplicate quads are ignored.
+		if seen[*q] {
+			continue
+		}
+		seen[*q] = true
+		s.quads = append(s.quads, q)
+		for _, t := range q {
+			if t.blank && !slices.Contains(s.blankNodes[t.value], q) {
+				s.blankNodes[t.value] = append(s.blankNodes[t.value], q)
+			}
+		}
+	}
+
+	// Issue canonical identifiers for blank nodes with a unique first degree hash.
+	hashes := make(map[string][]string)
+	for _, id := range sortedKeys(s.blankNodes) {
+		h := s.hashFirstDegreeQuads(id)
+		hashes[h] = append(hashes[h], id)
+	}
+	var nonUnique []string
+	for _, h := range sortedKeys(hashes) {
+		if ids := hashes[h]; len(ids) == 1 {
+			s.canonical.issue(ids[0])
+			continue
+		}
+		nonUnique = append(nonUnique, h)
+	}
+
+	if factor := c.WorkFactor; 0 <= factor {
+		if factor == 0 {
+			factor = DefaultWorkFactor
+		}
+		var n int
+		for _, h := range nonUnique {
+			n += len(hashes[h])
+		}
+		s.maxDeep = 1
+		for i := 0; i < factor; i++ {
+			s.maxDeep *= n
+		}
+	}
+
+	// Issue canonical identifiers for the remaining blank nodes, based on their N-degree hashes.
+	for _, h := range nonUnique {
+		var results []result
+		for _, id := range hashes[h] {
+			if _, ok := s.canonical.issued[id]; ok {
+				continue
+			}
+			tmp := newIssuer("b")
+			tmp.issue(id)
+			r, err := s.hashNDegreeQuads(id, tmp)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, r)
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].hash < results[j].hash
+		})
+		for _, r := range results {
+			for _, id := range r.issuer.order {
+				s.canonical.issue(id)
+			}
+		}
+	}
+	return s, nil
+}
+
+type issuer struct {
+	prefix  string
+	counter int
+	issued  map[string]string
+	order   []string
+}
+
+func newIssuer(prefix string) *issuer {
+	return &issuer{prefix: prefix, issued: make(map[string]string)}
+}
+
+func (i *issuer) clone() *issuer {
+	c := &issuer{
+		prefix:  i.prefix,
+		counter: i.counter,
+		issued:  make(map[string]string, len(i.issued)),
+		order:   append([]string(nil), i.order...),
+	}
+	for k, v := range i.issued {
+		c.issued[k] = v
+	}
+	return c
+}
+
+// issue returns the identifier issued for the given blank node identifier, a new one is issued if none exists yet.
+func (i *issuer) issue(id string) string {
+	if v, ok := i.issued[id]; ok {
+		return v
+	}
+	v := fmt.Sprintf("%s%d", i.prefix, i.counter)
+	i.counter++
+	i.issued[id] = v
+	i.order = append(i.order, id)
+	return v
+}
+
+type result struct {
+	hash   string
+	issuer *issuer
+}
+
+type state struct {
+	hash       func() hash.Hash
+	quads      []*quad
+	blankNodes map[string][]*quad
+	canonical  *issuer
+
+	// deepIterated counts the number of times hashNDegreeQuads was run for each blank node.
+	deepIterated map[string]int
+	// maxDeep is the maximum of deepIterated for a single blank node, -1 if unlimited.
+	maxDeep int
+}
+
+func (s *state) hashFirstDegreeQuads(id string) string {
+	var nquads []string
+	for _, q := range s.blankNodes[id] {
+		nquads = append(nquads, q.serialize(func(v string) string {
+			if v == id {
+				return "a"
+			}
+			return "z"
+		}))
+	}
+	sort.Strings(nquads)
+	return s.sum(strings.Join(nquads, ""))
+}
+
+func (s *state) hashNDegreeQuads(id string, i *issuer) (result, error) {
+	if 0 <= s.maxDeep {
+		s.deepIterated[id]++
+		if s.maxDeep < s.deepIterated[id] {
+			return result{}, ErrWorkLimit
+		}
+	}
+
+	related := make(map[string][]string)
+	for _, q := range s.blankNodes[id] {
+		for p, t := range q {
+			if !t.blank || t.value == id {
+				continue
+			}
+			h := s.hashRelatedBlankNode(t.value, q, i, positions[p])
+			if !slices.Contains(related[h], t.value) {
+				related[h] = append(related[h], t.value)
+			}
+		}
+	}
+
+	var data strings.Builder
+	for _, h := range sortedKeys(related) {
+		data.WriteString(h)
+		var chosenPath string
+		var chosenIssuer *issuer
+		err := permute(related[h], func(p []string) error {
+			ic := i.clone()
+			var path strings.Builder
+			var recursion []string
+			for _, r := range p {
+				if v, ok := s.canonical.issued[r]; ok {
+					path.WriteString("_:" + v)
+				} else {
+					if _, ok := ic.issued[r]; !ok {
+						recursion = append(recursion, r)
+					}
+					path.WriteString("_:" + ic.issue(r))
+				}
+				if worse(path.String(), chosenPath) {
+					return nil
+				}
+			}
+			for _, r := range recursion {
+				res, err := s.hashNDegreeQuads(r, ic)
+				if err != nil {
+					return err
+				}
+				path.WriteString("_:" + ic.issue(r))
+				path.WriteString("<" + res.hash + ">")
+				ic = res.issuer
+				if worse(path.String(), chosenPath) {
+					return nil
+				}
+			}
+			if chosenPath == "" || path.String() < chosenPath {
+				chosenPath, chosenIssuer = path.String(), ic
+			}
+			return nil
+		})
+		if err != nil {
+			return result{}, err
+		}
+		data.WriteString(chosenPath)
+		i = chosenIssuer
+	}
+	return result{hash: s.sum(data.String()), issuer: i}, nil
+}
+
+func (s *state) hashRelatedBlankNode(related string, q *quad, i *issuer, position byte) string {
+	var id string
+	if v, ok := s.canonical.issued[related]; ok {
+		id = "_:" + v
+	} else if v, ok := i.issued[related]; ok {
+		id = "_:" + v
+	} else {
+		id = s.hashFirstDegreeQuads(related)
+	}
+	input := string(position)
+	if position != 'g' {
+		input += q[1].value
+	}
+	return s.sum(input + id)
+}
+
+func (s *state) sum(v string) string {
+	h := s.hash()
+	h.Write([]byte(v))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// positions maps the index of a term within a quad to its position character.
+var positions = [4]byte{'s', 'p', 'o', 'g'}
+
+// permute calls f for every permutation of the given values.
+func permute(values []string, f func(p []string) error) error {
+	p := append([]string(nil), values...)
+	sort.Strings(p)
+	var rec func(k int) error
+	rec = func(k int) error {
+		if k == len(p) {
+			return f(p)
+		}
+		for i := k; i < len(p); i++ {
+			p[k], p[i] = p[i], p[k]
+			if err := rec(k + 1); err != nil {
+				return err
+			}
+			p[k], p[i] = p[i], p[k]
+		}
+		return nil
+	}
+	return rec(0)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// worse returns true if the path can never become smaller than the chosen path.
+func worse(path, chosen string) bool {
+	return chosen != "" && len(chosen) <= len(path) && chosen < path
+}
@@ -0,0 +1,240 @@
+package rdfc_test
+
+import (
+	"crypto"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfc"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io/fs"
+	"maps"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+//go:embed testdata/curated
+var curated embed.FS
+
+//go:embed testdata/urdna2015
+var urdna2015 embed.FS
+
+// urdna2015Inapplicable contains the URDNA2015 tests whose expected results differ from RDFC-1.0.
+var urdna2015Inapplicable = map[string]string{
+	// RDFC-1.0 uses the canonical form of N-Quads 1.2, which escapes backspace, tab and form feed.
+	"test060": "URDNA2015 does not escape \\b, \\t and \\f",
+}
+
+func ExampleCanonicalize() {
+	doc, _ := nq.ParseDocument(`
+_:e0 <http://example.com/#s> <http://example.com/#u> .
+_:e1 <http://example.com/#t> <http://example.com/#u> .
+<http://example.com/#p> <http://example.com/#q> _:e0 .
+<http://example.com/#p> <http://example.com/#r> _:e1 .
+`)
+	s, _ := rdfc.Canonicalize(doc)
+	fmt.Print(s)
+	// Output:
+	// <http://example.com/#p> <http://example.com/#q> _:c14n0 .
+	// <http://example.com/#p> <http://example.com/#r> _:c14n1 .
+	// _:c14n0 <http://example.com/#s> <http://example.com/#u> .
+	// _:c14n1 <http://example.com/#t> <http://example.com/#u> .
+}
+
+func TestCanonicalize_isomorphic(t *testing.T) {
+	raw, err := curated.ReadFile("testdata/curated/test009-in.nq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := nq.ParseDocument(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := rdfc.Canonicalize(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(0))
+	relabel := func(v any, labels map[string]string) any {
+		if bn, ok := v.(nt.BlankNode); ok {
+			if _, ok := labels[string(bn)]; !ok {
+				labels[string(bn)] = fmt.Sprintf("n%d", r.Intn(1000))
+			}
+			return nt.BlankNode(labels[string(bn)])
+		}
+		return v
+	}
+	for i := 0; i < 10; i++ {
+		labels := make(map[string]string)
+		var other nq.Document
+		for _, q := range doc {
+			q.Subject = relabel(q.Subject, labels).(nt.Subject)
+			q.Object = relabel(q.Object, labels).(nt.Object)
+			other = append(other, q)
+		}
+		r.Shuffle(len(other), func(i, j int) {
+			other[i], other[j] = other[j], other[i]
+		})
+		actual, err := rdfc.Canonicalize(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf("expected:\n%s\nactual:\n%s", expected, actual)
+		}
+	}
+}
+
+func TestCanonicalizer_WorkFactor(t *testing.T) {
+	raw, err := curated.ReadFile("testdata/curated/test010-in.nq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := nq.ParseDocument(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&rdfc.Canonicalizer{WorkFactor: 1}).Canonicalize(doc); !errors.Is(err, rdfc.ErrWorkLimit) {
+		t.Error(err)
+	}
+	if _, err := (&rdfc.Canonicalizer{WorkFactor: -1}).Canonicalize(doc); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCurated runs the curated tests of this repository, see testdata/curated/manifest.ttl.
+func TestCurated(t *testing.T) {
+	fsys, err := fs.Sub(curated, "testdata/curated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := project.NewReport(ttl.IRI{Value: "https://github.com/0x51-dev/rdf/rdfc/testdata/curated/manifest#"})
+	runManifest(t, fsys, "manifest.ttl", report)
+}
+
+// TestURDNA2015 runs the URDNA2015 tests of the W3C Credentials Community Group RDF Dataset Normalization suite, the
+// predecessor of the rdf-canon suite. RDFC-1.0 is URDNA2015, the suite does not contain the poison graph tests of
+// rdf-canon. The tests are vendored from gonum (graph/formats/rdf/testdata), see testdata/urdna2015/LICENSE.md.
+func TestURDNA2015(t *testing.T) {
+	fsys, err := fs.Sub(urdna2015, "testdata/urdna2015")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := project.NewReport(ttl.IRI{Value: "https://json-ld.github.io/normalization/tests/manifest-urdna2015#"})
+	runManifest(t, fsys, "manifest-urdna2015.ttl", report)
+	if os.Getenv("TEST_SUITE_REPORT") == "true" {
+		_ = os.WriteFile("testdata/urdna2015/report.ttl", []byte(report.String()), 0644)
+	}
+}
+
+// TestSuite runs the W3C rdf-canon test suite, it is skipped unless the suite is downloaded by `make download`.
+func TestSuite(t *testing.T) {
+	if _, err := os.Stat("testdata/suite/manifest.ttl"); err != nil {
+		t.Skip("W3C test suite not downloaded:", err)
+	}
+	report := project.NewReport(ttl.IRI{Value: "https://w3c.github.io/rdf-canon/tests/manifest#"})
+	runManifest(t, os.DirFS("testdata/suite"), "manifest.ttl", report)
+	if os.Getenv("TEST_SUITE_REPORT") == "true" {
+		_ = os.WriteFile("testdata/suite/report.ttl", []byte(report.String()), 0644)
+	}
+}
+
+// runManifest runs the tests of the manifest file in fsys. Tests are reported by the fragment of their IRI, tests of an
+// unknown type are reported as untested.
+func runManifest(t *testing.T, fsys fs.FS, name string, report *project.Report) {
+	rawManifest, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := testsuite.LoadManifest(string(rawManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		id := k[strings.LastIndexAny(k, "#:")+1:]
+		raw, err := fs.ReadFile(fsys, e.Action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := nq.ParseDocument(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c rdfc.Canonicalizer
+		if e.HashAlgorithm == "SHA384" {
+			c.Hash = crypto.SHA384
+		}
+		switch e.Type {
+		case "rdfc:RDFC10EvalTest", "rdfn:Urdna2015EvalTest":
+			t.Run(e.Name, func(t *testing.T) {
+				if reason, ok := urdna2015Inapplicable[id]; ok && e.Type == "rdfn:Urdna2015EvalTest" {
+					report.AddTest(id, testsuite.Inapplicable)
+					t.Skip(reason)
+				}
+				expected, err := fs.ReadFile(fsys, e.Result)
+				if err != nil {
+					t.Fatal(err)
+				}
+				actual, err := c.Canonicalize(doc)
+				if err != nil {
+					report.AddTest(id, testsuite.Failed)
+					t.Fatal(err)
+				}
+				if actual != string(expected) {
+					report.AddTest(id, testsuite.Failed)
+					t.Fatalf("expected:\n%s\nactual:\n%s", expected, actual)
+				}
+
+				report.AddTest(id, testsuite.Passed)
+			})
+		case "rdfc:RDFC10MapTest":
+			t.Run(e.Name, func(t *testing.T) {
+				raw, err := fs.ReadFile(fsys, e.Result)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var expected map[string]string
+				if err := json.Unmarshal(raw, &expected); err != nil {
+					t.Fatal(err)
+				}
+				actual, err := c.IssuedIdentifiers(doc)
+				if err != nil {
+					report.AddTest(id, testsuite.Failed)
+					t.Fatal(err)
+				}
+				if !maps.Equal(expected, actual) {
+					report.AddTest(id, testsuite.Failed)
+					t.Fatal(expected, actual)
+				}
+
+				report.AddTest(id, testsuite.Passed)
+			})
+		case "rdfc:RDFC10NegativeEvalTest":
+			t.Run(e.Name, func(t *testing.T) {
+				if _, err := c.Canonicalize(doc); !errors.Is(err, rdfc.ErrWorkLimit) {
+					report.AddTest(id, testsuite.Failed)
+					t.Fatal("expected work limit error", err)
+				}
+
+				report.AddTest(id, testsuite.Passed)
+			})
+		default:
+			t.Run(e.Name, func(t *testing.T) {
+				report.AddTest(id, testsuite.Untested)
+				t.Skip("unknown test type", e.Type)
+			})
+		}
+	}
+
+	t.Log("Total tests:", report.Len())
+}
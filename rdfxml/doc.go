@@ -0,0 +1,410 @@
+// Package rdfxml implements a parser for the RDF/XML syntax, producing N-Triples documents.
+//
+// Reference: https://www.w3.org/TR/rdf-syntax-grammar/
+package rdfxml
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	"github.com/0x51-dev/rdf/iri"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const rdf = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+var (
+	// coreSyntaxTerms can not be used as node element, property element or property attribute.
+	coreSyntaxTerms = map[string]bool{
+		"RDF": true, "ID": true, "about": true, "parseType": true, "resource": true, "nodeID": true, "datatype": true,
+	}
+	// oldTerms are no longer part of the syntax, using them is an error.
+	oldTerms = map[string]bool{"aboutEach": true, "aboutEachPrefix": true, "bagID": true}
+)
+
+// ParseDocument parses the RDF/XML document, relative IRIs are resolved against the base IRI. Blank nodes are labeled
+// in document order, the triples are returned in the order in which they are generated.
+func ParseDocument(r io.Reader, base string) (nt.Document, error) {
+	root, err := parseXML(r, base)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		bnodes: make(map[string]nt.BlankNode),
+		ids:    make(map[string]bool),
+	}
+	if root.space == rdf && root.local == "RDF" {
+		elements, err := root.elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range elements {
+			if _, err := p.nodeElement(e); err != nil {
+				return nil, err
+			}
+		}
+	} else if _, err := p.nodeElement(root); err != nil {
+		return nil, err
+	}
+	return p.triples, nil
+}
+
+type parser struct {
+	triples nt.Document
+	// bnodes maps the values of rdf:nodeID to blank nodes.
+	bnodes map[string]nt.BlankNode
+	index  int
+	// ids contains the IRIs created with rdf:ID, which must be unique within a document.
+	ids map[string]bool
+}
+
+// attributes returns the property attributes of the element, i.e. all attributes that are not part of the syntax.
+func (p *parser) attributes(e *element) ([]attribute, error) {
+	var attributes []attribute
+	for _, a := range e.attributes {
+		switch {
+		case a.space == xmlNS || strings.HasPrefix(strings.ToLower(a.prefix), "xml"):
+			// Reserved for XML, xml:base and xml:lang are processed when parsing the document.
+		case a.space == "":
+			if strings.HasPrefix(strings.ToLower(a.local), "xml") {
+				continue
+			}
+			return nil, fmt.Errorf("rdfxml: unqualified attribute %s", a.local)
+		case a.space == rdf && coreSyntaxTerms[a.local]:
+			// Syntax attributes, processed by the node and property elements.
+		case a.space == rdf && (oldTerms[a.local] || a.local == "li" || a.local == "Description"):
+			return nil, fmt.Errorf("rdfxml: rdf:%s is not allowed as property attribute", a.local)
+		default:
+			attributes = append(attributes, a)
+		}
+	}
+	return attributes, nil
+}
+
+// id returns the IRI of the value of rdf:ID, which must be a unique NCName.
+func (p *parser) id(e *element, v string) (nt.IRIReference, error) {
+	if !isNCName(v) {
+		return "", fmt.Errorf("rdfxml: invalid rdf:ID %q", v)
+	}
+	// Resolving a fragment replaces the fragment of the base IRI.
+	id := iri.Resolve(e.base, "#"+v)
+	if p.ids[id] {
+		return "", fmt.Errorf("rdfxml: duplicate rdf:ID %q", v)
+	}
+	p.ids[id] = true
+	return nt.IRIReference(id), nil
+}
+
+// nodeElement generates the triples of the node element and returns its subject.
+func (p *parser) nodeElement(e *element) (nt.Subject, error) {
+	if e.space == "" {
+		return nil, fmt.Errorf("rdfxml: unqualified element %s", e.local)
+	}
+	if e.space == rdf && (coreSyntaxTerms[e.local] || oldTerms[e.local] || e.local == "li") {
+		return nil, fmt.Errorf("rdfxml: rdf:%s is not allowed as node element", e.local)
+	}
+	var subject nt.Subject
+	for _, local := range []string{"ID", "nodeID", "about"} {
+		v, ok := e.attribute(rdf, local)
+		if !ok {
+			continue
+		}
+		if subject != nil {
+			return nil, fmt.Errorf("rdfxml: rdf:%s conflicts with the identifier of %s", local, e.name())
+		}
+		switch local {
+		case "ID":
+			id, err := p.id(e, v)
+			if err != nil {
+				return nil, err
+			}
+			subject = id
+		case "nodeID":
+			bn, err := p.nodeID(v)
+			if err != nil {
+				return nil, err
+			}
+			subject = bn
+		case "about":
+			subject = nt.IRIReference(iri.Resolve(e.base, v))
+		}
+	}
+	if subject == nil {
+		subject = p.bn()
+	}
+	if e.space != rdf || e.local != "Description" {
+		p.add(subject, nt.IRIReference(rdf+"type"), nt.IRIReference(e.space+e.local))
+	}
+	if err := p.propertyAttributes(e, subject); err != nil {
+		return nil, err
+	}
+	if err := p.propertyElements(e, subject); err != nil {
+		return nil, err
+	}
+	return subject, nil
+}
+
+// propertyAttributes generates the triples of the property attributes of the element, with the given subject.
+func (p *parser) propertyAttributes(e *element, subject nt.Subject) error {
+	attributes, err := p.attributes(e)
+	if err != nil {
+		return err
+	}
+	for _, a := range attributes {
+		if a.space == rdf && a.local == "type" {
+			p.add(subject, nt.IRIReference(rdf+"type"), nt.IRIReference(iri.Resolve(e.base, a.value)))
+			continue
+		}
+		p.add(subject, nt.IRIReference(a.space+a.local), &nt.Literal{Value: escape.String(a.value), Language: e.lang})
+	}
+	return nil
+}
+
+// propertyElements generates the triples of the property elements of the element, with the given subject.
+func (p *parser) propertyElements(e *element, subject nt.Subject) error {
+	elements, err := e.elements()
+	if err != nil {
+		return err
+	}
+	var li int
+	for _, e := range elements {
+		predicate := e.space + e.local
+		switch {
+		case e.space == "":
+			return fmt.Errorf("rdfxml: unqualified element %s", e.local)
+		case e.space == rdf && e.local == "li":
+			li++
+			predicate = rdf + "_" + strconv.Itoa(li)
+		case e.space == rdf && (coreSyntaxTerms[e.local] || oldTerms[e.local] || e.local == "Description"):
+			return fmt.Errorf("rdfxml: rdf:%s is not allowed as property element", e.local)
+		}
+		if err := p.propertyElement(e, subject, nt.IRIReference(predicate)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// propertyElement generates the triples of the property element, the kind of property element is determined by its
+// rdf:parseType attribute and content.
+func (p *parser) propertyElement(e *element, subject nt.Subject, predicate nt.IRIReference) error {
+	var object nt.Object
+	if parseType, ok := e.attribute(rdf, "parseType"); ok {
+		for _, local := range []string{"resource", "nodeID", "datatype"} {
+			if _, ok := e.attribute(rdf, local); ok {
+				return fmt.Errorf("rdfxml: rdf:%s conflicts with rdf:parseType", local)
+			}
+		}
+		attributes, err := p.attributes(e)
+		if err != nil {
+			return err
+		}
+		if len(attributes) != 0 {
+			return fmt.Errorf("rdfxml: property attributes conflict with rdf:parseType")
+		}
+		switch parseType {
+		case "Resource":
+			bn := p.bn()
+			p.add(subject, predicate, bn)
+			if err := p.reify(e, subject, predicate, bn); err != nil {
+				return err
+			}
+			return p.propertyElements(e, bn)
+		case "Collection":
+			elements, err := e.elements()
+			if err != nil {
+				return err
+			}
+			object, err = p.collection(elements)
+			if err != nil {
+				return err
+			}
+		default: // Literal, other values are treated as Literal.
+			datatype := nt.IRIReference(rdf + "XMLLiteral")
+			object = &nt.Literal{Value: escape.String(e.canonicalize()), Reference: &datatype}
+		}
+		p.add(subject, predicate, object)
+		return p.reify(e, subject, predicate, object)
+	}
+
+	elements, err := e.elements()
+	if err != nil {
+		// Content other than whitespace, a literal.
+		return p.literalPropertyElement(e, subject, predicate)
+	}
+	switch {
+	case len(elements) == 1:
+		for _, local := range []string{"resource", "nodeID", "datatype"} {
+			if _, ok := e.attribute(rdf, local); ok {
+				return fmt.Errorf("rdfxml: rdf:%s is not allowed on a property element with content", local)
+			}
+		}
+		if attributes, err := p.attributes(e); err != nil {
+			return err
+		} else if len(attributes) != 0 {
+			return fmt.Errorf("rdfxml: property attributes are not allowed on a property element with content")
+		}
+		o, err := p.nodeElement(elements[0])
+		if err != nil {
+			return err
+		}
+		object = o.(nt.Object)
+	case 1 < len(elements):
+		return fmt.Errorf("rdfxml: property element %s contains multiple node elements", e.name())
+	case len(e.children) != 0:
+		return p.literalPropertyElement(e, subject, predicate)
+	default:
+		if _, ok := e.attribute(rdf, "datatype"); ok {
+			return p.literalPropertyElement(e, subject, predicate)
+		}
+		return p.emptyPropertyElement(e, subject, predicate)
+	}
+	p.add(subject, predicate, object)
+	return p.reify(e, subject, predicate, object)
+}
+
+// literalPropertyElement generates the triple of a property element containing text, which is either a typed or a
+// language-tagged literal.
+func (p *parser) literalPropertyElement(e *element, subject nt.Subject, predicate nt.IRIReference) error {
+	text, err := e.text()
+	if err != nil {
+		return err
+	}
+	for _, local := range []string{"resource", "nodeID"} {
+		if _, ok := e.attribute(rdf, local); ok {
+			return fmt.Errorf("rdfxml: rdf:%s is not allowed on a literal property element", local)
+		}
+	}
+	if attributes, err := p.attributes(e); err != nil {
+		return err
+	} else if len(attributes) != 0 {
+		return fmt.Errorf("rdfxml: property attributes are not allowed on a literal property element")
+	}
+	literal := &nt.Literal{Value: escape.String(text)}
+	if datatype, ok := e.attribute(rdf, "datatype"); ok {
+		ref := nt.IRIReference(iri.Resolve(e.base, datatype))
+		literal.Reference = &ref
+	} else {
+		literal.Language = e.lang
+	}
+	p.add(subject, predicate, literal)
+	return p.reify(e, subject, predicate, literal)
+}
+
+// emptyPropertyElement generates the triples of a property element without content. The object is an empty literal
+// if there are no other attributes than rdf:ID, otherwise it is a resource described by the property attributes.
+func (p *parser) emptyPropertyElement(e *element, subject nt.Subject, predicate nt.IRIReference) error {
+	attributes, err := p.attributes(e)
+	if err != nil {
+		return err
+	}
+	resource, hasResource := e.attribute(rdf, "resource")
+	nodeID, hasNodeID := e.attribute(rdf, "nodeID")
+	var object nt.Object
+	switch {
+	case hasResource && hasNodeID:
+		return fmt.Errorf("rdfxml: rdf:resource conflicts with rdf:nodeID")
+	case hasResource:
+		object = nt.IRIReference(iri.Resolve(e.base, resource))
+	case hasNodeID:
+		bn, err := p.nodeID(nodeID)
+		if err != nil {
+			return err
+		}
+		object = bn
+	case len(attributes) == 0:
+		object = &nt.Literal{Language: e.lang}
+	default:
+		object = p.bn()
+	}
+	p.add(subject, predicate, object)
+	if err := p.reify(e, subject, predicate, object); err != nil {
+		return err
+	}
+	if s, ok := object.(nt.Subject); ok {
+		return p.propertyAttributes(e, s)
+	}
+	return nil
+}
+
+// collection generates the triples of a collection of node elements and returns its head.
+func (p *parser) collection(elements []*element) (nt.Object, error) {
+	var (
+		head nt.Object = nt.IRIReference(rdf + "nil")
+		last nt.Subject
+	)
+	for _, e := range elements {
+		item, err := p.nodeElement(e)
+		if err != nil {
+			return nil, err
+		}
+		bn := p.bn()
+		if last == nil {
+			head = bn
+		} else {
+			p.add(last, nt.IRIReference(rdf+"rest"), bn)
+		}
+		p.add(bn, nt.IRIReference(rdf+"first"), item.(nt.Object))
+		last = bn
+	}
+	if last != nil {
+		p.add(last, nt.IRIReference(rdf+"rest"), nt.IRIReference(rdf+"nil"))
+	}
+	return head, nil
+}
+
+// reify generates the reification triples of the statement if the property element has an rdf:ID.
+func (p *parser) reify(e *element, subject nt.Subject, predicate nt.IRIReference, object nt.Object) error {
+	v, ok := e.attribute(rdf, "ID")
+	if !ok {
+		return nil
+	}
+	id, err := p.id(e, v)
+	if err != nil {
+		return err
+	}
+	p.add(id, nt.IRIReference(rdf+"type"), nt.IRIReference(rdf+"Statement"))
+	p.add(id, nt.IRIReference(rdf+"subject"), subject.(nt.Object))
+	p.add(id, nt.IRIReference(rdf+"predicate"), predicate)
+	p.add(id, nt.IRIReference(rdf+"object"), object)
+	return nil
+}
+
+func (p *parser) add(subject nt.Subject, predicate nt.IRIReference, object nt.Object) {
+	p.triples = append(p.triples, nt.Triple{Subject: subject, Predicate: predicate, Object: object})
+}
+
+func (p *parser) bn() nt.BlankNode {
+	p.index++
+	return nt.BlankNode(fmt.Sprintf("b%d", p.index))
+}
+
+// nodeID returns the blank node of the rdf:nodeID value, which must be an NCName.
+func (p *parser) nodeID(v string) (nt.BlankNode, error) {
+	if !isNCName(v) {
+		return "", fmt.Errorf("rdfxml: invalid rdf:nodeID %q", v)
+	}
+	bn, ok := p.bnodes[v]
+	if !ok {
+		bn = p.bn()
+		p.bnodes[v] = bn
+	}
+	return bn, nil
+}
+
+// isNCName returns true if the value is a non-colonized name, as defined by Namespaces in XML.
+func isNCName(v string) bool {
+	for i, r := range v {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case 0 < i && (unicode.IsDigit(r) || r == '.' || r == '-' || unicode.Is(unicode.Mn, r) ||
+			unicode.Is(unicode.Mc, r) || r == '·'):
+		default:
+			return false
+		}
+	}
+	return v != ""
+}
@@ -0,0 +1,137 @@
+package rdfxml_test
+
+import (
+	"bytes"
+	"embed"
+	_ "embed"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfxml"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	// curatedBase is the base IRI of the curated tests, documents are resolved relative to it.
+	curatedBase = "https://github.com/0x51-dev/rdf/rdfxml/testdata/curated/"
+	// suiteBase is the base IRI of the W3C test suite.
+	suiteBase = "http://www.w3.org/2013/RDFXMLTests/"
+)
+
+//go:embed testdata/curated
+var curated embed.FS
+
+func ExampleParseDocument() {
+	doc, _ := rdfxml.ParseDocument(strings.NewReader(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:foaf="http://xmlns.com/foaf/0.1/">
+  <foaf:Person rdf:about="#alice">
+    <foaf:name xml:lang="en">Alice</foaf:name>
+  </foaf:Person>
+</rdf:RDF>`), "http://example.com/")
+	fmt.Print(doc)
+	// Output:
+	// <http://example.com/#alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://xmlns.com/foaf/0.1/Person> .
+	// <http://example.com/#alice> <http://xmlns.com/foaf/0.1/name> "Alice"@en .
+}
+
+// TestCurated runs the curated tests of this repository, see testdata/curated/manifest.ttl.
+func TestCurated(t *testing.T) {
+	report := project.NewReport(ttl.IRI{Value: curatedBase + "manifest.ttl#"})
+	runManifest(t, curatedFS(t), curatedBase, report)
+}
+
+// TestSuite runs the W3C test suite, it is skipped unless the suite is downloaded by `make download`.
+func TestSuite(t *testing.T) {
+	if _, err := os.Stat("testdata/suite/manifest.ttl"); err != nil {
+		t.Skip("W3C test suite not downloaded:", err)
+	}
+	report := project.NewReport(ttl.IRI{Value: suiteBase + "manifest.ttl#"})
+	runManifest(t, os.DirFS("testdata/suite"), suiteBase, report)
+	if os.Getenv("TEST_SUITE_REPORT") == "true" {
+		_ = os.WriteFile("testdata/suite/report.ttl", []byte(report.String()), 0644)
+	}
+}
+
+func curatedFS(t *testing.T) fs.FS {
+	fsys, err := fs.Sub(curated, "testdata/curated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys
+}
+
+// loadManifest returns the manifest.ttl file of fsys.
+func loadManifest(t *testing.T, fsys fs.FS) *testsuite.Manifest {
+	raw, err := fs.ReadFile(fsys, "manifest.ttl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := testsuite.LoadManifest(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+// runManifest runs the tests of the manifest.ttl file in fsys, the documents are resolved against the base IRI. Tests
+// of an unknown type are reported as untested.
+func runManifest(t *testing.T, fsys fs.FS, base string, report *project.Report) {
+	manifest := loadManifest(t, fsys)
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		raw, err := fs.ReadFile(fsys, e.Action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := rdfxml.ParseDocument(bytes.NewReader(raw), base+e.Action)
+		switch e.Type {
+		case "rdft:TestXMLEval":
+			t.Run(e.Name, func(t *testing.T) {
+				if err != nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal(err)
+				}
+
+				raw, err := fs.ReadFile(fsys, e.Result)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected, err := nt.ParseDocument(string(raw))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !expected.Equal(doc) {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatalf("expected:\n%s\nactual:\n%s", expected, doc)
+				}
+				if _, err := nt.ParseDocument(doc.String()); err != nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal(err)
+				}
+
+				report.AddTest(e.Name, testsuite.Passed)
+			})
+		case "rdft:TestXMLNegativeSyntax":
+			t.Run(e.Name, func(t *testing.T) {
+				if err == nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal("expected error")
+				}
+
+				report.AddTest(e.Name, testsuite.Passed)
+			})
+		default:
+			t.Run(e.Name, func(t *testing.T) {
+				report.AddTest(e.Name, testsuite.Untested)
+				t.Skip("unknown test type", e.Type)
+			})
+		}
+	}
+
+	t.Log("Total tests:", report.Len())
+}
@@ -0,0 +1,397 @@
+package rdfxml
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/escape"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	rdfType = rdf + "type"
+
+	// indentation of one nesting level.
+	indentation = "    "
+)
+
+// Encode serializes the triples of the document as striped RDF/XML. IRIs are abbreviated using the given prefixes
+// (e.g. "foaf" -> "http://xmlns.com/foaf/0.1/"), namespaces are generated for predicates and types that do not match
+// any prefix. Subjects with a type are written as typed node elements, plain literals as property attributes if the
+// predicate is used once, blank nodes that are only referenced once are nested and all others get an rdf:nodeID.
+func Encode(doc nt.Document, prefixes map[string]string) (string, error) {
+	e := &encoder{
+		namespaces: make(map[string]string),
+		used:       map[string]bool{"rdf": true},
+		subjects:   make(map[string][]nt.Triple),
+		refs:       make(map[string]int),
+		nested:     make(map[string]bool),
+		nodeIDs:    make(map[string]string),
+	}
+	for name, ns := range prefixes {
+		name = strings.TrimSuffix(name, ":")
+		if !isNCName(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+			return "", fmt.Errorf("rdfxml: invalid prefix name %q", name)
+		}
+		if name == "rdf" && ns != rdf {
+			return "", fmt.Errorf("rdfxml: prefix rdf is reserved for %s", rdf)
+		}
+		e.names = append(e.names, name)
+		e.namespaces[name] = ns
+	}
+	if _, ok := e.namespaces["rdf"]; !ok {
+		e.names = append(e.names, "rdf")
+		e.namespaces["rdf"] = rdf
+	}
+	sort.Strings(e.names)
+	if err := e.index(doc); err != nil {
+		return "", err
+	}
+
+	var roots []string
+	for _, k := range e.keys {
+		if !e.nested[k] && len(e.subjects[k]) != 0 {
+			roots = append(roots, k)
+		}
+	}
+	sort.Strings(roots)
+	var body strings.Builder
+	for _, k := range roots {
+		if err := e.nodeElement(&body, k, 1); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<rdf:RDF")
+	var names []string
+	for name := range e.used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i != 0 {
+			b.WriteString("\n        ")
+		}
+		b.WriteString(fmt.Sprintf(` xmlns:%s="%s"`, name, escapeAttribute(e.namespaces[name])))
+	}
+	b.WriteString(">\n")
+	b.WriteString(body.String())
+	b.WriteString("</rdf:RDF>\n")
+	return b.String(), nil
+}
+
+func isBlankNode(v any) bool {
+	switch v.(type) {
+	case nt.BlankNode, *nt.BlankNode:
+		return true
+	default:
+		return false
+	}
+}
+
+// split splits the IRI into a namespace and the longest local name that is an NCName, the local name is empty if the
+// IRI can not be split.
+func split(iri string) (string, string) {
+	runes := []rune(iri)
+	i := len(runes)
+	for 0 < i && (isNameStart(runes[i-1]) || isNameChar(runes[i-1])) {
+		i--
+	}
+	for i < len(runes) && !isNameStart(runes[i]) {
+		i++
+	}
+	return string(runes[:i]), string(runes[i:])
+}
+
+func isNameChar(r rune) bool {
+	return unicode.IsDigit(r) || r == '.' || r == '-' || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r) ||
+		r == '·'
+}
+
+func isNameStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// encoder contains the state of a single Encode call.
+type encoder struct {
+	// names of the namespaces, including the generated ones.
+	names []string
+	// namespaces maps the names to their IRI.
+	namespaces map[string]string
+	// used contains the names of the namespaces that need to be declared.
+	used map[string]bool
+
+	// keys of all the subjects and blank nodes, in order of the document.
+	keys     []string
+	subjects map[string][]nt.Triple
+	// refs is the amount of times a blank node is referenced as object.
+	refs map[string]int
+	// nested blank nodes are written in place of their (single) reference.
+	nested map[string]bool
+	// nodeIDs are the values of rdf:nodeID of the blank nodes that are not nested.
+	nodeIDs map[string]string
+}
+
+// attributes returns the triples that can be written as property attributes, those have a plain literal as object and
+// their predicate is used only once.
+func (e *encoder) attributes(ts []nt.Triple) map[string]bool {
+	count := make(map[nt.IRIReference]int)
+	for _, t := range ts {
+		count[t.Predicate]++
+	}
+	attributes := make(map[string]bool)
+	for _, t := range ts {
+		l, ok := literal(t.Object)
+		if !ok || l.Reference != nil || l.Language != "" || count[t.Predicate] != 1 || t.Predicate == rdfType {
+			continue
+		}
+		if _, err := e.qname(t.Predicate, false); err == nil {
+			attributes[t.String()] = true
+		}
+	}
+	return attributes
+}
+
+// index groups the triples by subject and counts the blank node references.
+func (e *encoder) index(d nt.Document) error {
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if _, ok := e.subjects[k]; !ok {
+			e.keys = append(e.keys, k)
+			e.subjects[k] = nil
+		}
+	}
+	for _, t := range d {
+		if t.Subject == nil || t.Object == nil {
+			return fmt.Errorf("rdfxml: incomplete triple: %s", t)
+		}
+		if s := t.String(); seen[s] {
+			continue
+		} else {
+			seen[s] = true
+		}
+		k := t.Subject.String()
+		add(k)
+		e.subjects[k] = append(e.subjects[k], t)
+		if isBlankNode(t.Object) {
+			k := t.Object.String()
+			add(k)
+			e.refs[k]++
+		}
+	}
+	for _, k := range e.keys {
+		if strings.HasPrefix(k, "_:") {
+			e.nested[k] = e.refs[k] == 1
+		}
+	}
+
+	// Nested blank nodes that are not reachable from any root are part of a cycle, these need a node identifier.
+	for {
+		reached := make(map[string]bool)
+		for _, k := range e.keys {
+			if !e.nested[k] {
+				e.reach(k, reached)
+			}
+		}
+		var cycle string
+		for _, k := range e.keys {
+			if e.nested[k] && !reached[k] {
+				cycle = k
+				break
+			}
+		}
+		if cycle == "" {
+			break
+		}
+		e.nested[cycle] = false
+	}
+	for _, k := range e.keys {
+		if strings.HasPrefix(k, "_:") && !e.nested[k] && 0 < e.refs[k] {
+			e.nodeIDs[k] = fmt.Sprintf("b%d", len(e.nodeIDs)+1)
+		}
+	}
+	return nil
+}
+
+// nodeElement writes the node element of the subject, including its property elements.
+func (e *encoder) nodeElement(b *strings.Builder, k string, level int) error {
+	ts := e.subjects[k]
+	sortTriples(ts)
+
+	// The first type that can be abbreviated is used as name of the node element.
+	name, typ := "rdf:Description", -1
+	for i, t := range ts {
+		if t.Predicate != rdfType {
+			continue
+		}
+		if o, ok := iriReference(t.Object); ok {
+			if n, err := e.qname(o, false); err == nil {
+				name, typ = n, i
+				break
+			}
+		}
+	}
+
+	line := strings.Repeat(indentation, level)
+	b.WriteString(line + "<" + name)
+	if strings.HasPrefix(k, "_:") {
+		if id, ok := e.nodeIDs[k]; ok {
+			b.WriteString(fmt.Sprintf(` rdf:nodeID="%s"`, id))
+		}
+	} else {
+		b.WriteString(fmt.Sprintf(` rdf:about="%s"`, escapeAttribute(strings.TrimSuffix(k[1:], ">"))))
+	}
+	attributes := e.attributes(ts)
+	for _, t := range ts {
+		if attributes[t.String()] {
+			l, _ := literal(t.Object)
+			n, _ := e.qname(t.Predicate, true)
+			b.WriteString(fmt.Sprintf(` %s="%s"`, n, escapeAttribute(escape.Unescape(l.Value))))
+		}
+	}
+	if len(ts)-len(attributes) == 0 || (len(ts)-len(attributes) == 1 && 0 <= typ) {
+		b.WriteString("/>\n")
+		return nil
+	}
+	b.WriteString(">\n")
+	for i, t := range ts {
+		if i == typ || attributes[t.String()] {
+			continue
+		}
+		if err := e.propertyElement(b, t, level+1); err != nil {
+			return err
+		}
+	}
+	b.WriteString(line + "</" + name + ">\n")
+	return nil
+}
+
+// propertyElement writes the property element of the triple.
+func (e *encoder) propertyElement(b *strings.Builder, t nt.Triple, level int) error {
+	name, err := e.qname(t.Predicate, true)
+	if err != nil {
+		return err
+	}
+	line := strings.Repeat(indentation, level)
+	if l, ok := literal(t.Object); ok {
+		var attribute string
+		switch {
+		case l.Reference != nil:
+			attribute = fmt.Sprintf(` rdf:datatype="%s"`, escapeAttribute(string(*l.Reference)))
+		case l.Direction != "":
+			return fmt.Errorf("rdfxml: base direction of %s can not be expressed", l)
+		case l.Language != "":
+			attribute = fmt.Sprintf(` xml:lang="%s"`, escapeAttribute(l.Language))
+		}
+		b.WriteString(fmt.Sprintf("%s<%s%s>%s</%s>\n", line, name, attribute, escapeText(escape.Unescape(l.Value)), name))
+		return nil
+	}
+	if o, ok := iriReference(t.Object); ok {
+		b.WriteString(fmt.Sprintf("%s<%s rdf:resource=\"%s\"/>\n", line, name, escapeAttribute(string(o))))
+		return nil
+	}
+	k := t.Object.String()
+	if !e.nested[k] {
+		b.WriteString(fmt.Sprintf("%s<%s rdf:nodeID=\"%s\"/>\n", line, name, e.nodeIDs[k]))
+		return nil
+	}
+	b.WriteString(line + "<" + name + ">\n")
+	if len(e.subjects[k]) == 0 {
+		b.WriteString(line + indentation + "<rdf:Description/>\n")
+	} else if err := e.nodeElement(b, k, level+1); err != nil {
+		return err
+	}
+	b.WriteString(line + "</" + name + ">\n")
+	return nil
+}
+
+// qname returns the qualified name of the IRI. If no prefix matches, a namespace is generated if generate is true.
+// An error is returned if the IRI can not be split into a namespace and a local name.
+func (e *encoder) qname(r nt.IRIReference, generate bool) (string, error) {
+	if r == rdf+"li" || (strings.HasPrefix(string(r), rdf) && (coreSyntaxTerms[string(r[len(rdf):])] ||
+		oldTerms[string(r[len(rdf):])] || r == rdf+"Description")) {
+		return "", fmt.Errorf("rdfxml: %s can not be used as property", r)
+	}
+	var name, local string
+	for _, n := range e.names {
+		ns := e.namespaces[n]
+		if !strings.HasPrefix(string(r), ns) || (name != "" && len(ns) <= len(e.namespaces[name])) {
+			continue
+		}
+		if l := strings.TrimPrefix(string(r), ns); isNCName(l) {
+			name, local = n, l
+		}
+	}
+	if name == "" {
+		ns, l := split(string(r))
+		if l == "" {
+			return "", fmt.Errorf("rdfxml: %s can not be split into a namespace and a local name", r)
+		}
+		if !generate {
+			return "", fmt.Errorf("rdfxml: no prefix for %s", r)
+		}
+		for i := 1; name == ""; i++ {
+			if n := fmt.Sprintf("ns%d", i); e.namespaces[n] == "" {
+				name = n
+			}
+		}
+		e.names = append(e.names, name)
+		sort.Strings(e.names)
+		e.namespaces[name] = ns
+		local = l
+	}
+	e.used[name] = true
+	return name + ":" + local, nil
+}
+
+// reach marks the subject and all the blank nodes that are nested within it.
+func (e *encoder) reach(k string, reached map[string]bool) {
+	if reached[k] {
+		return
+	}
+	reached[k] = true
+	for _, t := range e.subjects[k] {
+		if isBlankNode(t.Object) {
+			if o := t.Object.String(); e.nested[o] {
+				e.reach(o, reached)
+			}
+		}
+	}
+}
+
+func iriReference(o nt.Object) (nt.IRIReference, bool) {
+	switch o := o.(type) {
+	case nt.IRIReference:
+		return o, true
+	case *nt.IRIReference:
+		return *o, true
+	default:
+		return "", false
+	}
+}
+
+func literal(o nt.Object) (nt.Literal, bool) {
+	switch o := o.(type) {
+	case nt.Literal:
+		return o, true
+	case *nt.Literal:
+		return *o, true
+	default:
+		return nt.Literal{}, false
+	}
+}
+
+// sortTriples sorts the triples by predicate and object, rdf:type comes first.
+func sortTriples(ts []nt.Triple) {
+	sort.SliceStable(ts, func(i, j int) bool {
+		if (ts[i].Predicate == rdfType) != (ts[j].Predicate == rdfType) {
+			return ts[i].Predicate == rdfType
+		}
+		if ts[i].Predicate != ts[j].Predicate {
+			return ts[i].Predicate < ts[j].Predicate
+		}
+		return ts[i].Object.String() < ts[j].Object.String()
+	})
+}
@@ -0,0 +1,103 @@
+package rdfxml_test
+
+import (
+	"bytes"
+	"fmt"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfxml"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func ExampleEncode() {
+	doc, _ := nt.ParseDocument(`
+<http://example.com/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://xmlns.com/foaf/0.1/Person> .
+<http://example.com/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+<http://example.com/alice> <http://xmlns.com/foaf/0.1/knows> _:bob .
+<http://example.com/alice> <http://example.com/vocab#1age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:bob <http://xmlns.com/foaf/0.1/name> "Bob"@en .
+`)
+	raw, _ := rdfxml.Encode(doc, map[string]string{
+		"foaf": "http://xmlns.com/foaf/0.1/",
+	})
+	fmt.Print(raw)
+	// Output:
+	// <?xml version="1.0" encoding="utf-8"?>
+	// <rdf:RDF xmlns:foaf="http://xmlns.com/foaf/0.1/"
+	//          xmlns:ns1="http://example.com/vocab#1"
+	//          xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+	//     <foaf:Person rdf:about="http://example.com/alice" foaf:name="Alice">
+	//         <ns1:age rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">42</ns1:age>
+	//         <foaf:knows>
+	//             <rdf:Description>
+	//                 <foaf:name xml:lang="en">Bob</foaf:name>
+	//             </rdf:Description>
+	//         </foaf:knows>
+	//     </foaf:Person>
+	// </rdf:RDF>
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("shared", func(t *testing.T) {
+		doc, err := nt.ParseDocument(`
+_:a <http://example.com/p> _:b .
+_:b <http://example.com/p> _:a .
+<http://example.com/s> <http://example.com/p> _:b .
+<http://example.com/s> <http://example.com/p> "a\nb" .
+<http://example.com/s> <http://example.com/q> "x" .
+<http://example.com/s> <http://example.com/q> "y" .
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testEncode(t, doc)
+	})
+	t.Run("unsplittable", func(t *testing.T) {
+		doc := nt.Document{{
+			Subject:   nt.IRIReference("http://example.com/s"),
+			Predicate: nt.IRIReference("http://example.com/1/"),
+			Object:    nt.IRIReference("http://example.com/o"),
+		}}
+		if _, err := rdfxml.Encode(doc, nil); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	fsys := curatedFS(t)
+	manifest := loadManifest(t, fsys)
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		if e.Type != "rdft:TestXMLEval" {
+			continue
+		}
+		t.Run(e.Name, func(t *testing.T) {
+			raw, err := fs.ReadFile(fsys, e.Action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := rdfxml.ParseDocument(bytes.NewReader(raw), curatedBase+e.Action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testEncode(t, doc)
+		})
+	}
+}
+
+func testEncode(t *testing.T, doc nt.Document) {
+	raw, err := rdfxml.Encode(doc, map[string]string{
+		"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		"ex":  "http://example.org/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := rdfxml.ParseDocument(strings.NewReader(raw), "")
+	if err != nil {
+		t.Fatalf("%s\n%s", err, raw)
+	}
+	if !doc.Equal(actual) {
+		t.Fatalf("expected:\n%s\nactual:\n%s\nencoded:\n%s", doc, actual, raw)
+	}
+}
@@ -0,0 +1,4 @@
+package rdfxml
+
+// MediaType is the media type of RDF/XML documents.
+const MediaType = "application/rdf+xml"
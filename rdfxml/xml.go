@@ -0,0 +1,298 @@
+package rdfxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/0x51-dev/rdf/iri"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const xmlNS = "http://www.w3.org/XML/1998/namespace"
+
+// entityRegex matches the internal entity declarations of a document type declaration.
+var entityRegex = regexp.MustCompile(`<!ENTITY\s+([^\s%]+)\s+(?:"([^"]*)"|'([^']*)')\s*>`)
+
+// attribute is an attribute of an element, the namespace is resolved from the prefix.
+type attribute struct {
+	prefix, local, space string
+	value                string
+}
+
+// element is an element of the XML document. The prefixes of the element and its attributes are kept, so XML literals
+// can be serialized with the prefixes of the document.
+type element struct {
+	prefix, local, space string
+	attributes           []attribute
+	// children are either elements or character data (string), comments and processing instructions are dropped.
+	children []any
+	// namespaces are the namespaces in scope of the element, the default namespace has an empty prefix.
+	namespaces map[string]string
+	// base and lang are the values of xml:base and xml:lang in scope of the element.
+	base, lang string
+}
+
+// attribute returns the value of the attribute with the given namespace and local name.
+func (e *element) attribute(space, local string) (string, bool) {
+	for _, a := range e.attributes {
+		if a.space == space && a.local == local {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// elements returns the child elements, an error is returned if the element contains non-whitespace text.
+func (e *element) elements() ([]*element, error) {
+	var elements []*element
+	for _, c := range e.children {
+		switch c := c.(type) {
+		case *element:
+			elements = append(elements, c)
+		case string:
+			if strings.TrimSpace(c) != "" {
+				return nil, fmt.Errorf("rdfxml: unexpected text in %s", e.name())
+			}
+		}
+	}
+	return elements, nil
+}
+
+func (e *element) name() string {
+	if e.prefix == "" {
+		return e.local
+	}
+	return e.prefix + ":" + e.local
+}
+
+// text returns the character data of the element, an error is returned if it contains elements.
+func (e *element) text() (string, error) {
+	var b strings.Builder
+	for _, c := range e.children {
+		switch c := c.(type) {
+		case *element:
+			return "", fmt.Errorf("rdfxml: unexpected element %s in %s", c.name(), e.name())
+		case string:
+			b.WriteString(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// parseXML parses the XML document into a tree of elements, resolving namespaces, xml:base and xml:lang.
+func parseXML(r io.Reader, base string) (*element, error) {
+	d := xml.NewDecoder(r)
+	d.Entity = make(map[string]string)
+	var (
+		root  *element
+		stack []*element
+	)
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rdfxml: %w", err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			parent := &element{namespaces: map[string]string{"xml": xmlNS}, base: base}
+			if len(stack) != 0 {
+				parent = stack[len(stack)-1]
+			} else if root != nil {
+				return nil, fmt.Errorf("rdfxml: multiple root elements")
+			}
+			e, err := newElement(t, parent)
+			if err != nil {
+				return nil, err
+			}
+			if len(stack) == 0 {
+				root = e
+			} else {
+				parent.children = append(parent.children, e)
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("rdfxml: unexpected end element %s", t.Name.Local)
+			}
+			e := stack[len(stack)-1]
+			if e.prefix != t.Name.Space || e.local != t.Name.Local {
+				return nil, fmt.Errorf("rdfxml: element %s closed by %s:%s", e.name(), t.Name.Space, t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(t)) != "" {
+					return nil, fmt.Errorf("rdfxml: text outside of the root element")
+				}
+				continue
+			}
+			e := stack[len(stack)-1]
+			if i := len(e.children) - 1; 0 <= i {
+				if s, ok := e.children[i].(string); ok {
+					e.children[i] = s + string(t)
+					continue
+				}
+			}
+			e.children = append(e.children, string(t))
+		case xml.Directive:
+			for _, m := range entityRegex.FindAllStringSubmatch(string(t), -1) {
+				d.Entity[m[1]] = m[2] + m[3]
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("rdfxml: no root element")
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("rdfxml: unclosed element %s", stack[len(stack)-1].name())
+	}
+	return root, nil
+}
+
+// newElement creates an element from the start element, inheriting the namespaces, base and language of the parent.
+func newElement(t xml.StartElement, parent *element) (*element, error) {
+	e := &element{
+		prefix:     t.Name.Space,
+		local:      t.Name.Local,
+		namespaces: parent.namespaces,
+		base:       parent.base,
+		lang:       parent.lang,
+	}
+	var attributes []attribute
+	for _, a := range t.Attr {
+		switch {
+		case a.Name.Space == "xmlns":
+			e.declare(a.Name.Local, a.Value)
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			e.declare("", a.Value)
+		default:
+			attributes = append(attributes, attribute{prefix: a.Name.Space, local: a.Name.Local, value: a.Value})
+		}
+	}
+	space, ok := e.namespaces[e.prefix]
+	if !ok && e.prefix != "" {
+		return nil, fmt.Errorf("rdfxml: undeclared prefix %q", e.prefix)
+	}
+	e.space = space
+	for _, a := range attributes {
+		if a.prefix != "" {
+			space, ok := e.namespaces[a.prefix]
+			if !ok {
+				return nil, fmt.Errorf("rdfxml: undeclared prefix %q", a.prefix)
+			}
+			a.space = space
+		}
+		if a.space == xmlNS {
+			switch a.local {
+			case "base":
+				e.base = iri.Resolve(e.base, a.value)
+			case "lang":
+				e.lang = a.value
+			}
+		}
+		e.attributes = append(e.attributes, a)
+	}
+	return e, nil
+}
+
+// declare declares the namespace prefix, the namespaces of the parent are copied on the first declaration.
+func (e *element) declare(prefix, space string) {
+	namespaces := make(map[string]string, len(e.namespaces)+1)
+	for k, v := range e.namespaces {
+		namespaces[k] = v
+	}
+	namespaces[prefix] = space
+	e.namespaces = namespaces
+}
+
+// canonicalize serializes the content of the element in exclusive canonical XML form (without comments), which is
+// the lexical form of rdf:XMLLiteral values.
+func (e *element) canonicalize() string {
+	var b strings.Builder
+	for _, c := range e.children {
+		canonicalize(&b, c, map[string]string{"": ""})
+	}
+	return b.String()
+}
+
+// canonicalize writes the node in exclusive canonical XML form, rendered contains the namespace declarations of the
+// output ancestors.
+func canonicalize(b *strings.Builder, n any, rendered map[string]string) {
+	e, ok := n.(*element)
+	if !ok {
+		b.WriteString(escapeText(n.(string)))
+		return
+	}
+	// Only visibly utilized namespaces are declared, if not already declared by an output ancestor.
+	utilized := map[string]bool{e.prefix: true}
+	for _, a := range e.attributes {
+		if a.prefix != "" && a.prefix != "xml" {
+			utilized[a.prefix] = true
+		}
+	}
+	var prefixes []string
+	scope := rendered
+	for prefix := range utilized {
+		if space, ok := rendered[prefix]; ok && space == e.namespaces[prefix] {
+			continue
+		}
+		if len(prefixes) == 0 {
+			scope = make(map[string]string, len(rendered)+1)
+			for k, v := range rendered {
+				scope[k] = v
+			}
+		}
+		prefixes = append(prefixes, prefix)
+		scope[prefix] = e.namespaces[prefix]
+	}
+	sort.Strings(prefixes)
+
+	b.WriteString("<" + e.name())
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			b.WriteString(` xmlns="` + escapeAttribute(scope[prefix]) + `"`)
+		} else {
+			b.WriteString(" xmlns:" + prefix + `="` + escapeAttribute(scope[prefix]) + `"`)
+		}
+	}
+	attributes := make([]attribute, len(e.attributes))
+	copy(attributes, e.attributes)
+	sort.Slice(attributes, func(i, j int) bool {
+		if attributes[i].space != attributes[j].space {
+			return attributes[i].space < attributes[j].space
+		}
+		return attributes[i].local < attributes[j].local
+	})
+	for _, a := range attributes {
+		name := a.local
+		if a.prefix != "" {
+			name = a.prefix + ":" + a.local
+		}
+		b.WriteString(" " + name + `="` + escapeAttribute(a.value) + `"`)
+	}
+	b.WriteString(">")
+	for _, c := range e.children {
+		canonicalize(b, c, scope)
+	}
+	b.WriteString("</" + e.name() + ">")
+}
+
+var (
+	textEscaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attributeEscaper = strings.NewReplacer(
+		"&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;",
+	)
+)
+
+func escapeAttribute(v string) string {
+	return attributeEscaper.Replace(v)
+}
+
+func escapeText(v string) string {
+	return textEscaper.Replace(v)
+}
@@ -0,0 +1,147 @@
+package sparql
+
+import (
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"math/big"
+	"strings"
+)
+
+// aggregate evaluates the aggregate over the solutions of a group. Returns nil if the result is an error, i.e. the
+// variable of the aggregate remains unbound.
+func (e *evaluator) aggregate(a algebra.Aggregate, group []Solution, g graph) rdf.Node {
+	if a.Expression == nil {
+		// COUNT(*)
+		if a.Distinct {
+			group = collect(distinct(fromSlice(group)))
+		}
+		return integer(big.NewInt(int64(len(group))))
+	}
+	var values []rdf.Node
+	var failed bool
+	seen := make(map[string]bool)
+	for _, s := range group {
+		n, err := e.expression(a.Expression, s, g)
+		if err != nil {
+			failed = true
+			continue
+		}
+		if a.Distinct {
+			k := algebra.FormatNode(n)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		values = append(values, n)
+	}
+	switch a.Name {
+	case "COUNT":
+		return integer(big.NewInt(int64(len(values))))
+	case "SAMPLE":
+		if len(values) == 0 {
+			return nil
+		}
+		return values[0]
+	}
+	if failed {
+		return nil
+	}
+	switch a.Name {
+	case "SUM", "AVG":
+		sum := numeric{kind: integerKind, r: new(big.Rat)}
+		for _, v := range values {
+			n, err := toNumeric(v)
+			if err != nil {
+				return nil
+			}
+			if sum, err = calculate('+', sum, n); err != nil {
+				return nil
+			}
+		}
+		if a.Name == "AVG" && len(values) != 0 {
+			count := numeric{kind: integerKind, r: big.NewRat(int64(len(values)), 1)}
+			avg, err := calculate('/', sum, count)
+			if err != nil {
+				return nil
+			}
+			return avg.literal()
+		}
+		return sum.literal()
+	case "MIN", "MAX":
+		if len(values) == 0 {
+			return nil
+		}
+		result := values[0]
+		for _, v := range values[1:] {
+			if n := order(v, result); a.Name == "MIN" && n < 0 || a.Name == "MAX" && 0 < n {
+				result = v
+			}
+		}
+		return result
+	case "GROUP_CONCAT":
+		s := make([]string, len(values))
+		var language string
+		for i, v := range values {
+			l, err := stringLiteral(v)
+			if err != nil {
+				return nil
+			}
+			if i == 0 {
+				language = l.Language
+			} else if language != l.Language {
+				language = ""
+			}
+			s[i] = l.Value
+		}
+		return withLanguage(strings.Join(s, a.Separator), language)
+	default:
+		return nil
+	}
+}
+
+// group partitions the solutions by the values of the keys and evaluates the aggregates per group. The solutions of
+// the groups bind the keys that are variables and the variables of the aggregates. Without keys, there is exactly one
+// group, even if there are no solutions.
+func (e *evaluator) group(op *algebra.Group, solutions []Solution, g graph) []Solution {
+	var keys []string
+	groups := make(map[string][]Solution)
+	values := make(map[string][]rdf.Node)
+	if len(op.Keys) == 0 {
+		keys = []string{""}
+		groups[""] = nil
+	}
+	for _, s := range solutions {
+		var k strings.Builder
+		ns := make([]rdf.Node, len(op.Keys))
+		for i, key := range op.Keys {
+			// Keys that raise an error are unbound.
+			if n, err := e.expression(key, s, g); err == nil {
+				ns[i] = n
+				k.WriteString(algebra.FormatNode(n))
+			}
+			k.WriteByte(0)
+		}
+		if _, ok := groups[k.String()]; !ok {
+			keys = append(keys, k.String())
+			values[k.String()] = ns
+		}
+		groups[k.String()] = append(groups[k.String()], s)
+	}
+	results := make([]Solution, 0, len(keys))
+	for _, k := range keys {
+		result := make(Solution)
+		for i, key := range op.Keys {
+			if v, ok := key.(algebra.Var); ok && values[k][i] != nil {
+				result[v] = values[k][i]
+			}
+		}
+		for _, a := range op.Aggregates {
+			if n := e.aggregate(a, groups[k], g); n != nil {
+				result[a.Var] = n
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
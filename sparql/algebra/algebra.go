@@ -0,0 +1,457 @@
+// Package algebra contains the SPARQL algebra, as defined in section 18 of the SPARQL 1.1 Query Language. Operators and
+// expressions are printed as S-expressions, e.g. "(filter (> ?x 1) (bgp (triple ?s ?p ?x)))".
+package algebra
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/internal/escape"
+	"strings"
+)
+
+// Aggregate is a set function that is evaluated per group, the result is bound to the variable.
+type Aggregate struct {
+	// Var is the (internal) variable the result of the aggregate is bound to.
+	Var Var
+	// Name is the upper case name of the aggregate, e.g. "COUNT".
+	Name     string
+	Distinct bool
+	// Expression is nil for COUNT(*).
+	Expression Expression
+	// Separator is the separator of GROUP_CONCAT.
+	Separator string
+}
+
+func (a Aggregate) String() string {
+	var args []string
+	if a.Distinct {
+		args = append(args, "distinct")
+	}
+	if a.Expression != nil {
+		args = append(args, a.Expression.String())
+	}
+	if a.Name == "GROUP_CONCAT" {
+		args = append(args, fmt.Sprintf("%q", a.Separator))
+	}
+	return fmt.Sprintf("(%s (%s))", a.Var, strings.TrimSpace(strings.ToLower(a.Name)+" "+strings.Join(args, " ")))
+}
+
+// BGP is a basic graph pattern, a set of triple patterns. The empty BGP is the identity of the join.
+type BGP []TriplePattern
+
+func (b BGP) String() string {
+	s := "(bgp"
+	for _, t := range b {
+		s += " " + t.String()
+	}
+	return s + ")"
+}
+
+func (b BGP) operator() {}
+
+// Call is a call of an operator or function, e.g. (+ ?x 1), (str ?x) or (<http://example.org/f> ?x). The function
+// is either an operator, the upper case name of a built-in function or the IRI of a function.
+type Call struct {
+	Function string
+	Args     []Expression
+	// Distinct is only used by functions that are aggregates.
+	Distinct bool
+}
+
+// IsIRI returns true if the function is identified by an IRI.
+func (c Call) IsIRI() bool {
+	return strings.Contains(c.Function, ":")
+}
+
+func (c Call) String() string {
+	name := strings.ToLower(c.Function)
+	if c.IsIRI() {
+		name = fmt.Sprintf("<%s>", c.Function)
+	}
+	s := "(" + name
+	if c.Distinct {
+		s += " distinct"
+	}
+	for _, a := range c.Args {
+		s += " " + a.String()
+	}
+	return s + ")"
+}
+
+func (c Call) expression() {}
+
+// Constant is an RDF term.
+type Constant struct {
+	rdf.Node
+}
+
+func (c Constant) String() string {
+	return FormatNode(c.Node)
+}
+
+func (c Constant) expression() {}
+
+func (c Constant) term() {}
+
+// Distinct eliminates duplicate solutions.
+type Distinct struct {
+	Operator Operator
+}
+
+func (d Distinct) String() string {
+	return fmt.Sprintf("(distinct %s)", d.Operator)
+}
+
+func (d Distinct) operator() {}
+
+// Exists tests whether the pattern has a solution, given the current solution.
+type Exists struct {
+	Not     bool
+	Pattern Operator
+}
+
+func (e Exists) String() string {
+	if e.Not {
+		return fmt.Sprintf("(notexists %s)", e.Pattern)
+	}
+	return fmt.Sprintf("(exists %s)", e.Pattern)
+}
+
+func (e Exists) expression() {}
+
+// Expression is either a Var, Constant, Call or Exists.
+type Expression interface {
+	expression()
+
+	fmt.Stringer
+}
+
+// Extend binds the value of the expression to the variable.
+type Extend struct {
+	Operator   Operator
+	Var        Var
+	Expression Expression
+}
+
+func (e Extend) String() string {
+	return fmt.Sprintf("(extend ((%s %s)) %s)", e.Var, e.Expression, e.Operator)
+}
+
+func (e Extend) operator() {}
+
+// Filter removes the solutions for which any of the expressions is not true.
+type Filter struct {
+	Expressions []Expression
+	Operator    Operator
+}
+
+func (f Filter) String() string {
+	if len(f.Expressions) == 1 {
+		return fmt.Sprintf("(filter %s %s)", f.Expressions[0], f.Operator)
+	}
+	return fmt.Sprintf("(filter (exprlist %s) %s)", expressionsString(f.Expressions), f.Operator)
+}
+
+func (f Filter) operator() {}
+
+// Graph evaluates the pattern against the named graph(s).
+type Graph struct {
+	// Name is either a Var or a Constant (IRI).
+	Name     Term
+	Operator Operator
+}
+
+func (g Graph) String() string {
+	return fmt.Sprintf("(graph %s %s)", g.Name, g.Operator)
+}
+
+func (g Graph) operator() {}
+
+// Group groups the solutions by the keys and evaluates the aggregates per group. If there are no keys, all
+// solutions are in a single group.
+type Group struct {
+	Keys       []Expression
+	Aggregates []Aggregate
+	Operator   Operator
+}
+
+func (g Group) String() string {
+	aggregates := make([]string, len(g.Aggregates))
+	for i, a := range g.Aggregates {
+		aggregates[i] = a.String()
+	}
+	return fmt.Sprintf("(group (%s) (%s) %s)", expressionsString(g.Keys), strings.Join(aggregates, " "), g.Operator)
+}
+
+func (g Group) operator() {}
+
+// Join is the join of the solutions of both operators.
+type Join struct {
+	Left, Right Operator
+}
+
+func (j Join) String() string {
+	return fmt.Sprintf("(join %s %s)", j.Left, j.Right)
+}
+
+func (j Join) operator() {}
+
+// LeftJoin is the join of both operators, which keeps the solutions of the left operator without a (compatible)
+// solution on the right.
+type LeftJoin struct {
+	Left, Right Operator
+	// Expression is nil if there is no filter.
+	Expression Expression
+}
+
+func (j LeftJoin) String() string {
+	if j.Expression == nil {
+		return fmt.Sprintf("(leftjoin %s %s)", j.Left, j.Right)
+	}
+	return fmt.Sprintf("(leftjoin %s %s %s)", j.Left, j.Right, j.Expression)
+}
+
+func (j LeftJoin) operator() {}
+
+// Minus removes the solutions that are compatible with a solution of the right operator.
+type Minus struct {
+	Left, Right Operator
+}
+
+func (m Minus) String() string {
+	return fmt.Sprintf("(minus %s %s)", m.Left, m.Right)
+}
+
+func (m Minus) operator() {}
+
+// Operator is an operator of the algebra, it evaluates to a multiset of solutions.
+type Operator interface {
+	operator()
+
+	fmt.Stringer
+}
+
+// OrderBy sorts the solutions.
+type OrderBy struct {
+	Conditions []OrderCondition
+	Operator   Operator
+}
+
+func (o OrderBy) String() string {
+	conditions := make([]string, len(o.Conditions))
+	for i, c := range o.Conditions {
+		conditions[i] = c.String()
+	}
+	return fmt.Sprintf("(order (%s) %s)", strings.Join(conditions, " "), o.Operator)
+}
+
+func (o OrderBy) operator() {}
+
+type OrderCondition struct {
+	Expression Expression
+	Descending bool
+}
+
+func (c OrderCondition) String() string {
+	if c.Descending {
+		return fmt.Sprintf("(desc %s)", c.Expression)
+	}
+	return c.Expression.String()
+}
+
+// Project restricts the solutions to the variables.
+type Project struct {
+	Vars     []Var
+	Operator Operator
+}
+
+func (p Project) String() string {
+	vars := make([]string, len(p.Vars))
+	for i, v := range p.Vars {
+		vars[i] = v.String()
+	}
+	return fmt.Sprintf("(project (%s) %s)", strings.Join(vars, " "), p.Operator)
+}
+
+func (p Project) operator() {}
+
+// Query is a translated query, the result of the query form is computed from the solutions of the operator.
+type Query struct {
+	Type     QueryType
+	Operator Operator
+	// Vars are the projected variables of a SELECT query.
+	Vars []Var
+	// Template contains the triple patterns of a CONSTRUCT query.
+	Template []TriplePattern
+	// Describe contains the described resources of a DESCRIBE query.
+	Describe []Term
+	// From and FromNamed contain the IRIs of the graphs of the dataset clauses.
+	From, FromNamed []string
+}
+
+func (q Query) String() string {
+	return q.Operator.String()
+}
+
+type QueryType int
+
+const (
+	Select QueryType = iota
+	Construct
+	Ask
+	Describe
+)
+
+func (t QueryType) String() string {
+	switch t {
+	case Select:
+		return "SELECT"
+	case Construct:
+		return "CONSTRUCT"
+	case Ask:
+		return "ASK"
+	case Describe:
+		return "DESCRIBE"
+	default:
+		return fmt.Sprintf("QueryType(%d)", int(t))
+	}
+}
+
+// Reduced permits the elimination of duplicate solutions.
+type Reduced struct {
+	Operator Operator
+}
+
+func (r Reduced) String() string {
+	return fmt.Sprintf("(reduced %s)", r.Operator)
+}
+
+func (r Reduced) operator() {}
+
+// Slice returns the solutions from offset, at most limit if limit is not -1.
+type Slice struct {
+	Offset, Limit int
+	Operator      Operator
+}
+
+func (s Slice) String() string {
+	limit := "_"
+	if s.Limit != -1 {
+		limit = fmt.Sprint(s.Limit)
+	}
+	return fmt.Sprintf("(slice %d %s %s)", s.Offset, limit, s.Operator)
+}
+
+func (s Slice) operator() {}
+
+// Table is a multiset of solutions (VALUES), a nil value is unbound. The table with no variables and a single row is
+// the identity of the join.
+type Table struct {
+	Vars []Var
+	Rows [][]rdf.Node
+}
+
+func (t Table) String() string {
+	if len(t.Vars) == 0 && len(t.Rows) == 1 {
+		return "(table unit)"
+	}
+	vars := make([]string, len(t.Vars))
+	for i, v := range t.Vars {
+		vars[i] = v.String()
+	}
+	s := fmt.Sprintf("(table (vars %s)", strings.Join(vars, " "))
+	for _, row := range t.Rows {
+		s += " (row"
+		for i, n := range row {
+			if n != nil {
+				s += fmt.Sprintf(" [%s %s]", t.Vars[i], FormatNode(n))
+			}
+		}
+		s += ")"
+	}
+	return s + ")"
+}
+
+func (t Table) operator() {}
+
+// Term is either a Var or a Constant.
+type Term interface {
+	term()
+
+	fmt.Stringer
+}
+
+type TriplePattern struct {
+	Subject, Predicate, Object Term
+}
+
+func (t TriplePattern) String() string {
+	return fmt.Sprintf("(triple %s %s %s)", t.Subject, t.Predicate, t.Object)
+}
+
+// Union is the union of the solutions of both operators.
+type Union struct {
+	Left, Right Operator
+}
+
+func (u Union) String() string {
+	return fmt.Sprintf("(union %s %s)", u.Left, u.Right)
+}
+
+func (u Union) operator() {}
+
+// Var is a variable. Blank nodes of patterns are variables with a "_:" prefix, they are not part of SELECT *.
+type Var string
+
+// IsBlankNode returns true if the variable represents a blank node of a pattern.
+func (v Var) IsBlankNode() bool {
+	return strings.HasPrefix(string(v), "_:")
+}
+
+func (v Var) String() string {
+	if v.IsBlankNode() {
+		return string(v)
+	}
+	return "?" + string(v)
+}
+
+func (v Var) expression() {}
+
+func (v Var) term() {}
+
+// FormatNode formats the node in N-Triples (star) syntax.
+func FormatNode(n rdf.Node) string {
+	switch n := n.(type) {
+	case *rdf.IRIReference:
+		return fmt.Sprintf("<%s>", n.Value)
+	case *rdf.BlankNode:
+		if strings.HasPrefix(n.Attribute, "_:") {
+			return n.Attribute
+		}
+		return "_:" + n.Attribute
+	case *rdf.Literal:
+		s := fmt.Sprintf(`"%s"`, escape.String(n.Value))
+		switch {
+		case n.Language != "" && n.Direction != "":
+			return fmt.Sprintf("%s@%s--%s", s, n.Language, n.Direction)
+		case n.Language != "":
+			return fmt.Sprintf("%s@%s", s, n.Language)
+		case n.Datatype != "" && n.Datatype != rdf.XSDString:
+			return fmt.Sprintf("%s^^<%s>", s, n.Datatype)
+		default:
+			return s
+		}
+	case *rdf.TripleTerm:
+		return fmt.Sprintf("<<( %s %s %s )>>", FormatNode(n.Subject), FormatNode(n.Predicate), FormatNode(n.Object))
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+func expressionsString(es []Expression) string {
+	s := make([]string, len(es))
+	for i, e := range es {
+		s[i] = e.String()
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,118 @@
+package algebra
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"strings"
+)
+
+// Alternative matches either of both paths.
+type Alternative struct {
+	Left, Right PathExpression
+}
+
+func (a Alternative) String() string {
+	return fmt.Sprintf("(alt %s %s)", a.Left, a.Right)
+}
+
+func (a Alternative) path() {}
+
+// Inverse matches the path in the reverse direction, i.e. from object to subject.
+type Inverse struct {
+	Path PathExpression
+}
+
+func (i Inverse) String() string {
+	return fmt.Sprintf("(reverse %s)", i.Path)
+}
+
+func (i Inverse) path() {}
+
+// Link matches a single triple with the predicate.
+type Link struct {
+	Predicate *rdf.IRIReference
+}
+
+func (l Link) String() string {
+	return FormatNode(l.Predicate)
+}
+
+func (l Link) path() {}
+
+// NegatedPropertySet matches a single triple with a predicate that is not in the set.
+type NegatedPropertySet []*rdf.IRIReference
+
+func (n NegatedPropertySet) String() string {
+	s := make([]string, len(n))
+	for i, p := range n {
+		s[i] = FormatNode(p)
+	}
+	return strings.TrimSpace("(notoneof "+strings.Join(s, " ")) + ")"
+}
+
+func (n NegatedPropertySet) path() {}
+
+// OneOrMore matches the path repeated one or more times, nodes are visited at most once.
+type OneOrMore struct {
+	Path PathExpression
+}
+
+func (o OneOrMore) String() string {
+	return fmt.Sprintf("(path+ %s)", o.Path)
+}
+
+func (o OneOrMore) path() {}
+
+// Path matches the subject and object that are connected by the path expression.
+type Path struct {
+	Subject Term
+	Path    PathExpression
+	Object  Term
+}
+
+func (p Path) String() string {
+	return fmt.Sprintf("(path %s %s %s)", p.Subject, p.Path, p.Object)
+}
+
+func (p Path) operator() {}
+
+// PathExpression is a property path: Link, Inverse, Sequence, Alternative, ZeroOrOne, ZeroOrMore, OneOrMore or
+// NegatedPropertySet.
+type PathExpression interface {
+	path()
+
+	fmt.Stringer
+}
+
+// Sequence matches the left path followed by the right path.
+type Sequence struct {
+	Left, Right PathExpression
+}
+
+func (s Sequence) String() string {
+	return fmt.Sprintf("(seq %s %s)", s.Left, s.Right)
+}
+
+func (s Sequence) path() {}
+
+// ZeroOrMore matches the path repeated zero or more times, nodes are visited at most once.
+type ZeroOrMore struct {
+	Path PathExpression
+}
+
+func (z ZeroOrMore) String() string {
+	return fmt.Sprintf("(path* %s)", z.Path)
+}
+
+func (z ZeroOrMore) path() {}
+
+// ZeroOrOne matches the path zero times or once.
+type ZeroOrOne struct {
+	Path PathExpression
+}
+
+func (z ZeroOrOne) String() string {
+	return fmt.Sprintf("(path? %s)", z.Path)
+}
+
+func (z ZeroOrOne) path() {}
@@ -0,0 +1,107 @@
+package algebra
+
+// InScope returns the in-scope variables of the operator, as defined in section 18.2.1 of the specification. Variables
+// that represent blank nodes or aggregates are not included.
+func InScope(op Operator) []Var {
+	var vars []Var
+	seen := make(map[Var]bool)
+	add := func(vs ...Var) {
+		for _, v := range vs {
+			if !seen[v] && !v.IsBlankNode() && !v.isAggregate() {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	var visit func(op Operator)
+	visit = func(op Operator) {
+		switch op := op.(type) {
+		case BGP:
+			for _, t := range op {
+				add(termVars(t.Subject, t.Predicate, t.Object)...)
+			}
+		case *Path:
+			add(termVars(op.Subject, op.Object)...)
+		case *Join:
+			visit(op.Left)
+			visit(op.Right)
+		case *LeftJoin:
+			visit(op.Left)
+			visit(op.Right)
+		case *Union:
+			visit(op.Left)
+			visit(op.Right)
+		case *Minus:
+			visit(op.Left)
+		case *Filter:
+			visit(op.Operator)
+		case *Extend:
+			visit(op.Operator)
+			add(op.Var)
+		case *Graph:
+			add(termVars(op.Name)...)
+			visit(op.Operator)
+		case *Group:
+			for _, k := range op.Keys {
+				if v, ok := k.(Var); ok {
+					add(v)
+				}
+			}
+		case *Table:
+			add(op.Vars...)
+		case *Project:
+			add(op.Vars...)
+		case *OrderBy:
+			visit(op.Operator)
+		case *Distinct:
+			visit(op.Operator)
+		case *Reduced:
+			visit(op.Operator)
+		case *Slice:
+			visit(op.Operator)
+		}
+	}
+	visit(op)
+	return vars
+}
+
+// Vars returns the variables that are used in the expression.
+func Vars(e Expression) []Var {
+	var vars []Var
+	seen := make(map[Var]bool)
+	var visit func(e Expression)
+	visit = func(e Expression) {
+		switch e := e.(type) {
+		case Var:
+			if !seen[e] {
+				seen[e] = true
+				vars = append(vars, e)
+			}
+		case *Call:
+			for _, a := range e.Args {
+				visit(a)
+			}
+		case *Exists:
+			for _, v := range InScope(e.Pattern) {
+				visit(v)
+			}
+		}
+	}
+	visit(e)
+	return vars
+}
+
+// isAggregate returns true if the variable is bound to the result of an aggregate.
+func (v Var) isAggregate() bool {
+	return len(v) != 0 && v[0] == '.'
+}
+
+func termVars(ts ...Term) []Var {
+	var vars []Var
+	for _, t := range ts {
+		if v, ok := t.(Var); ok {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
@@ -0,0 +1,227 @@
+package algebra
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"strings"
+)
+
+// Add adds the triples of the source graph to the destination graph.
+type Add struct {
+	Silent bool
+	// Source and Destination are nil for the default graph.
+	Source, Destination rdf.Node
+}
+
+func (a Add) String() string {
+	return fmt.Sprintf("(add%s %s %s)", silentString(a.Silent), graphString(a.Source), graphString(a.Destination))
+}
+
+func (a Add) update() {}
+
+// Clear removes all the triples of the target graph(s).
+type Clear struct {
+	Silent bool
+	Target Target
+}
+
+func (c Clear) String() string {
+	return fmt.Sprintf("(clear%s %s)", silentString(c.Silent), c.Target)
+}
+
+func (c Clear) update() {}
+
+// Copy replaces the triples of the destination graph with the triples of the source graph.
+type Copy struct {
+	Silent bool
+	// Source and Destination are nil for the default graph.
+	Source, Destination rdf.Node
+}
+
+func (c Copy) String() string {
+	return fmt.Sprintf("(copy%s %s %s)", silentString(c.Silent), graphString(c.Source), graphString(c.Destination))
+}
+
+func (c Copy) update() {}
+
+// Create creates an empty graph.
+type Create struct {
+	Silent bool
+	Graph  *rdf.IRIReference
+}
+
+func (c Create) String() string {
+	return fmt.Sprintf("(create%s %s)", silentString(c.Silent), FormatNode(c.Graph))
+}
+
+func (c Create) update() {}
+
+// DeleteData removes the quads from the dataset, the quads contain no variables or blank nodes.
+type DeleteData []Quad
+
+func (d DeleteData) String() string {
+	return fmt.Sprintf("(deleteData %s)", quadsString(d))
+}
+
+func (d DeleteData) update() {}
+
+// Drop removes the target graph(s), dropping the default graph removes its triples.
+type Drop struct {
+	Silent bool
+	Target Target
+}
+
+func (d Drop) String() string {
+	return fmt.Sprintf("(drop%s %s)", silentString(d.Silent), d.Target)
+}
+
+func (d Drop) update() {}
+
+// InsertData adds the quads to the dataset, the quads contain no variables.
+type InsertData []Quad
+
+func (i InsertData) String() string {
+	return fmt.Sprintf("(insertData %s)", quadsString(i))
+}
+
+func (i InsertData) update() {}
+
+// Load adds the triples of the document to the destination graph.
+type Load struct {
+	Silent bool
+	IRI    *rdf.IRIReference
+	// Into is nil for the default graph.
+	Into *rdf.IRIReference
+}
+
+func (l Load) String() string {
+	if l.Into == nil {
+		return fmt.Sprintf("(load%s %s)", silentString(l.Silent), FormatNode(l.IRI))
+	}
+	return fmt.Sprintf("(load%s %s %s)", silentString(l.Silent), FormatNode(l.IRI), FormatNode(l.Into))
+}
+
+func (l Load) update() {}
+
+// Modify removes and adds the instantiations of the templates for every solution of the operator. DELETE WHERE is a
+// modify operation of which the pattern is also the delete template.
+type Modify struct {
+	Delete, Insert []Quad
+	// With is the graph that is used as default graph, if not overridden by the USING clauses.
+	With *rdf.IRIReference
+	// Using and UsingNamed contain the IRIs of the graphs of the USING (NAMED) clauses.
+	Using, UsingNamed []string
+	Operator          Operator
+}
+
+func (m Modify) String() string {
+	s := []string{"(modify"}
+	if m.With != nil {
+		s = append(s, fmt.Sprintf("(with %s)", FormatNode(m.With)))
+	}
+	for _, u := range m.Using {
+		s = append(s, fmt.Sprintf("(using <%s>)", u))
+	}
+	for _, u := range m.UsingNamed {
+		s = append(s, fmt.Sprintf("(using named <%s>)", u))
+	}
+	if len(m.Delete) != 0 {
+		s = append(s, fmt.Sprintf("(delete %s)", quadsString(m.Delete)))
+	}
+	if len(m.Insert) != 0 {
+		s = append(s, fmt.Sprintf("(insert %s)", quadsString(m.Insert)))
+	}
+	return strings.Join(append(s, m.Operator.String()), " ") + ")"
+}
+
+func (m Modify) update() {}
+
+// Move replaces the triples of the destination graph with the triples of the source graph, after which the source
+// graph is removed.
+type Move struct {
+	Silent bool
+	// Source and Destination are nil for the default graph.
+	Source, Destination rdf.Node
+}
+
+func (m Move) String() string {
+	return fmt.Sprintf("(move%s %s %s)", silentString(m.Silent), graphString(m.Source), graphString(m.Destination))
+}
+
+func (m Move) update() {}
+
+// Quad is a triple pattern within a graph, the graph is nil for the default graph.
+type Quad struct {
+	Graph Term
+	TriplePattern
+}
+
+func (q Quad) String() string {
+	if q.Graph == nil {
+		return q.TriplePattern.String()
+	}
+	return fmt.Sprintf("(quad %s %s %s %s)", q.Graph, q.Subject, q.Predicate, q.Object)
+}
+
+// Target identifies the graphs of CLEAR and DROP: a single graph, the default graph, all named graphs or all graphs.
+type Target struct {
+	// Graph is nil for the default graph, unless Named or All is true.
+	Graph      *rdf.IRIReference
+	Named, All bool
+}
+
+func (t Target) String() string {
+	switch {
+	case t.All:
+		return "all"
+	case t.Named:
+		return "named"
+	default:
+		return graphString(t.Graph)
+	}
+}
+
+// Update is a translated update request, the operations are applied in order.
+type Update []UpdateOperation
+
+func (u Update) String() string {
+	s := make([]string, len(u))
+	for i, o := range u {
+		s[i] = o.String()
+	}
+	return strings.TrimSpace("(update "+strings.Join(s, " ")) + ")"
+}
+
+// UpdateOperation is an operation of an update request: Load, Clear, Drop, Create, Add, Move, Copy, InsertData,
+// DeleteData or Modify.
+type UpdateOperation interface {
+	update()
+
+	fmt.Stringer
+}
+
+// graphString returns the graph name, or "default" for the default graph.
+func graphString(n rdf.Node) string {
+	if n == nil {
+		return "default"
+	}
+	if i, ok := n.(*rdf.IRIReference); ok && i == nil {
+		return "default"
+	}
+	return FormatNode(n)
+}
+
+func quadsString(qs []Quad) string {
+	s := make([]string, len(qs))
+	for i, q := range qs {
+		s[i] = q.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func silentString(silent bool) string {
+	if silent {
+		return " silent"
+	}
+	return ""
+}
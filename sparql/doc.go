@@ -0,0 +1,1370 @@
+// Package sparql implements the SPARQL 1.1 Query Language and SPARQL 1.1 Update. Queries and updates are parsed into a
+// typed syntax tree, which is translated into a SPARQL algebra expression (see package algebra).
+package sparql
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/sparql/grammar"
+	"github.com/0x51-dev/rdf/turtle"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"strconv"
+	"strings"
+)
+
+// builtInArity contains the minimum and maximum number of arguments of the built-in functions, -1 if unbounded.
+var builtInArity = map[string][2]int{
+	"STR": {1, 1}, "LANG": {1, 1}, "LANGMATCHES": {2, 2}, "DATATYPE": {1, 1}, "BOUND": {1, 1}, "IRI": {1, 1},
+	"URI": {1, 1}, "BNODE": {0, 1}, "RAND": {0, 0}, "ABS": {1, 1}, "CEIL": {1, 1}, "FLOOR": {1, 1},
+	"ROUND": {1, 1}, "CONCAT": {0, -1}, "SUBSTR": {2, 3}, "STRLEN": {1, 1}, "REPLACE": {3, 4}, "UCASE": {1, 1},
+	"LCASE": {1, 1}, "ENCODE_FOR_URI": {1, 1}, "CONTAINS": {2, 2}, "STRSTARTS": {2, 2}, "STRENDS": {2, 2},
+	"STRBEFORE": {2, 2}, "STRAFTER": {2, 2}, "YEAR": {1, 1}, "MONTH": {1, 1}, "DAY": {1, 1}, "HOURS": {1, 1},
+	"MINUTES": {1, 1}, "SECONDS": {1, 1}, "TIMEZONE": {1, 1}, "TZ": {1, 1}, "NOW": {0, 0}, "UUID": {0, 0},
+	"STRUUID": {0, 0}, "MD5": {1, 1}, "SHA1": {1, 1}, "SHA256": {1, 1}, "SHA384": {1, 1}, "SHA512": {1, 1},
+	"COALESCE": {0, -1}, "IF": {3, 3}, "STRLANG": {2, 2}, "STRDT": {2, 2}, "SAMETERM": {2, 2}, "ISIRI": {1, 1},
+	"ISURI": {1, 1}, "ISBLANK": {1, 1}, "ISLITERAL": {1, 1}, "ISNUMERIC": {1, 1}, "REGEX": {2, 3},
+}
+
+// Aggregate is a set function, e.g. COUNT(DISTINCT ?x) or GROUP_CONCAT(?x ; SEPARATOR = ",").
+type Aggregate struct {
+	// Name is the upper case name of the aggregate, e.g. "COUNT".
+	Name     string
+	Distinct bool
+	// Expression is nil for COUNT(*).
+	Expression Expression
+	Separator  *turtle.StringLiteral
+}
+
+func ParseAggregate(n *parser.Node) (*Aggregate, error) {
+	if n.Name != "Aggregate" {
+		return nil, fmt.Errorf("aggregate: unknown %s", n.Name)
+	}
+	children := n.Children()
+	a := &Aggregate{Name: strings.ToUpper(children[0].Value())}
+	for _, n := range children[1:] {
+		switch n.Name {
+		case "Distinct":
+			a.Distinct = true
+		case "Star":
+			if a.Name != "COUNT" {
+				return nil, fmt.Errorf("aggregate: %s(*) is not allowed", a.Name)
+			}
+		case "Separator":
+			if a.Name != "GROUP_CONCAT" {
+				return nil, fmt.Errorf("aggregate: separator is not allowed in %s", a.Name)
+			}
+			s, err := turtle.ParseStringLiteral(n.Children()[0])
+			if err != nil {
+				return nil, err
+			}
+			a.Separator = s
+		default:
+			e, err := ParseExpression(n)
+			if err != nil {
+				return nil, err
+			}
+			a.Expression = e
+		}
+	}
+	return a, nil
+}
+
+func (a Aggregate) String() string {
+	var s string
+	if a.Distinct {
+		s += "DISTINCT "
+	}
+	if a.Expression == nil {
+		s += "*"
+	} else {
+		s += a.Expression.String()
+	}
+	if a.Separator != nil {
+		s += fmt.Sprintf(" ; SEPARATOR = %s", a.Separator)
+	}
+	return fmt.Sprintf("%s(%s)", a.Name, s)
+}
+
+// BinaryExpression is an expression with a binary operator, e.g. "?x + 1" or "?x && ?y".
+type BinaryExpression struct {
+	Operator    string
+	Left, Right Expression
+}
+
+func (e BinaryExpression) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Operator, e.Right)
+}
+
+// Bind assigns the value of an expression to a variable.
+type Bind struct {
+	Expression Expression
+	Var        Var
+}
+
+func ParseBind(n *parser.Node) (*Bind, error) {
+	if n.Name != "Bind" {
+		return nil, fmt.Errorf("bind: unknown %s", n.Name)
+	}
+	e, err := ParseExpression(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	v, err := ParseVar(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Bind{Expression: e, Var: *v}, nil
+}
+
+func (b Bind) String() string {
+	return fmt.Sprintf("BIND (%s AS %s)", b.Expression, b.Var)
+}
+
+func (b Bind) pattern() {}
+
+// BlankNodePropertyList is a blank node that is the subject of the triples of the property list.
+type BlankNodePropertyList []PredicateObject
+
+func ParseBlankNodePropertyList(n *parser.Node) (BlankNodePropertyList, error) {
+	if n.Name != "BlankNodePropertyList" {
+		return nil, fmt.Errorf("blank node property list: unknown %s", n.Name)
+	}
+	pl, err := ParsePropertyList(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	return BlankNodePropertyList(pl), nil
+}
+
+func (b BlankNodePropertyList) String() string {
+	return fmt.Sprintf("[ %s ]", propertyListString(b))
+}
+
+// BuiltInCall is a call of a built-in function, e.g. STR(?x).
+type BuiltInCall struct {
+	// Name is the upper case name of the function, e.g. "STR".
+	Name string
+	Args []Expression
+}
+
+func ParseBuiltInCall(n *parser.Node) (*BuiltInCall, error) {
+	if n.Name != "BuiltInCall" {
+		return nil, fmt.Errorf("built-in call: unknown %s", n.Name)
+	}
+	name := strings.ToUpper(n.Children()[0].Value())
+	args, err := ParseExpressionList(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	arity := builtInArity[name]
+	if len(args) < arity[0] || arity[1] != -1 && arity[1] < len(args) {
+		return nil, fmt.Errorf("built-in call: invalid number of arguments for %s: %d", name, len(args))
+	}
+	if name == "BOUND" {
+		if _, ok := args[0].(*Var); !ok {
+			return nil, fmt.Errorf("built-in call: BOUND expects a variable: %s", args[0])
+		}
+	}
+	return &BuiltInCall{Name: name, Args: args}, nil
+}
+
+func (c BuiltInCall) String() string {
+	return fmt.Sprintf("%s(%s)", c.Name, expressionsString(c.Args))
+}
+
+// Collection is a RDF collection of terms, e.g. "(1 ?x 3)".
+type Collection []Term
+
+func ParseCollection(n *parser.Node) (Collection, error) {
+	if n.Name != "Collection" {
+		return nil, fmt.Errorf("collection: unknown %s", n.Name)
+	}
+	var c Collection
+	for _, n := range n.Children() {
+		t, err := ParseTerm(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, t)
+	}
+	return c, nil
+}
+
+func (c Collection) String() string {
+	s := make([]string, len(c))
+	for i, t := range c {
+		s[i] = t.String()
+	}
+	return fmt.Sprintf("( %s )", strings.Join(s, " "))
+}
+
+// DatasetClause is a FROM or FROM NAMED clause.
+type DatasetClause struct {
+	Named bool
+	IRI   *turtle.IRI
+}
+
+func ParseDatasetClause(n *parser.Node) (*DatasetClause, error) {
+	var named bool
+	switch n.Name {
+	case "DefaultGraphClause":
+	case "NamedGraphClause":
+		named = true
+	default:
+		return nil, fmt.Errorf("dataset clause: unknown %s", n.Name)
+	}
+	i, err := turtle.ParseIRI(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	return &DatasetClause{Named: named, IRI: i}, nil
+}
+
+func (d DatasetClause) String() string {
+	if d.Named {
+		return fmt.Sprintf("FROM NAMED %s", d.IRI)
+	}
+	return fmt.Sprintf("FROM %s", d.IRI)
+}
+
+// ExistsExpression tests whether the pattern matches, e.g. "NOT EXISTS { ?x ?p ?o }".
+type ExistsExpression struct {
+	Not     bool
+	Pattern *GroupGraphPattern
+}
+
+func (e ExistsExpression) String() string {
+	if e.Not {
+		return fmt.Sprintf("NOT EXISTS %s", e.Pattern)
+	}
+	return fmt.Sprintf("EXISTS %s", e.Pattern)
+}
+
+// Expression is either a Var, IRI, Literal, BinaryExpression, UnaryExpression, InExpression, BuiltInCall,
+// FunctionCall, Aggregate or ExistsExpression.
+type Expression interface {
+	fmt.Stringer
+}
+
+func ParseExpression(n *parser.Node) (Expression, error) {
+	switch n.Name {
+	case "ConditionalOrExpression":
+		return parseBinaryExpression(n, "||")
+	case "ConditionalAndExpression":
+		return parseBinaryExpression(n, "&&")
+	case "RelationalExpression":
+		children := n.Children()
+		left, err := ParseExpression(children[0])
+		if err != nil || len(children) == 1 {
+			return left, err
+		}
+		operator := strings.ToUpper(strings.Join(strings.Fields(children[1].Value()), " "))
+		if operator == "IN" || operator == "NOT IN" {
+			list, err := ParseExpressionList(children[2])
+			if err != nil {
+				return nil, err
+			}
+			return &InExpression{Not: operator == "NOT IN", Expression: left, List: list}, nil
+		}
+		right, err := ParseExpression(children[2])
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpression{Operator: operator, Left: left, Right: right}, nil
+	case "AdditiveExpression", "MultiplicativeExpression":
+		return parseBinaryExpression(n, "")
+	case "UnaryExpression":
+		e, err := ParseExpression(n.Children()[1])
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpression{Operator: n.Children()[0].Value(), Expression: e}, nil
+	case "BuiltInCall":
+		return ParseBuiltInCall(n)
+	case "Aggregate":
+		return ParseAggregate(n)
+	case "ExistsFunc", "NotExistsFunc":
+		p, err := ParseGroupGraphPattern(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		return &ExistsExpression{Not: n.Name == "NotExistsFunc", Pattern: p}, nil
+	case "FunctionCall":
+		return ParseFunctionCall(n)
+	case "IRI":
+		return turtle.ParseIRI(n)
+	case "Literal":
+		return turtle.ParseLiteral(n)
+	case "Var":
+		return ParseVar(n)
+	default:
+		return nil, fmt.Errorf("expression: unknown %s", n.Name)
+	}
+}
+
+// ParseExpressionList parses a (possibly empty) list of expressions, e.g. the arguments of a function.
+func ParseExpressionList(n *parser.Node) ([]Expression, error) {
+	if n.Name != "ExpressionList" && n.Name != "ArgList" {
+		return nil, fmt.Errorf("expression list: unknown %s", n.Name)
+	}
+	var list []Expression
+	for _, n := range n.Children() {
+		if n.Name == "Nil" || n.Name == "Distinct" {
+			continue
+		}
+		e, err := ParseExpression(n)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+// Filter restricts the solutions of the group to those for which the expression is true.
+type Filter struct {
+	Expression Expression
+}
+
+func ParseFilter(n *parser.Node) (*Filter, error) {
+	if n.Name != "Filter" {
+		return nil, fmt.Errorf("filter: unknown %s", n.Name)
+	}
+	e, err := ParseExpression(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{Expression: e}, nil
+}
+
+func (f Filter) String() string {
+	return fmt.Sprintf("FILTER (%s)", f.Expression)
+}
+
+func (f Filter) pattern() {}
+
+// FunctionCall is a call of a function that is identified by an IRI, e.g. xsd:integer(?x).
+type FunctionCall struct {
+	IRI      *turtle.IRI
+	Distinct bool
+	Args     []Expression
+}
+
+func ParseFunctionCall(n *parser.Node) (*FunctionCall, error) {
+	if n.Name != "FunctionCall" {
+		return nil, fmt.Errorf("function call: unknown %s", n.Name)
+	}
+	i, err := turtle.ParseIRI(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	args, err := ParseExpressionList(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	distinct := len(n.Children()[1].Children()) != 0 && n.Children()[1].Children()[0].Name == "Distinct"
+	return &FunctionCall{IRI: i, Distinct: distinct, Args: args}, nil
+}
+
+func (c FunctionCall) String() string {
+	if c.Distinct {
+		return fmt.Sprintf("%s(DISTINCT %s)", c.IRI, expressionsString(c.Args))
+	}
+	return fmt.Sprintf("%s(%s)", c.IRI, expressionsString(c.Args))
+}
+
+// GraphGraphPattern matches the pattern against the named graph(s) of the dataset.
+type GraphGraphPattern struct {
+	// Name is either a Var or an IRI.
+	Name    Term
+	Pattern *GroupGraphPattern
+}
+
+func ParseGraphGraphPattern(n *parser.Node) (*GraphGraphPattern, error) {
+	if n.Name != "GraphGraphPattern" {
+		return nil, fmt.Errorf("graph graph pattern: unknown %s", n.Name)
+	}
+	name, err := ParseTerm(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	p, err := ParseGroupGraphPattern(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	return &GraphGraphPattern{Name: name, Pattern: p}, nil
+}
+
+func (g GraphGraphPattern) String() string {
+	return fmt.Sprintf("GRAPH %s %s", g.Name, g.Pattern)
+}
+
+func (g GraphGraphPattern) pattern() {}
+
+// GroupCondition is an expression of the GROUP BY clause, optionally bound to a variable.
+type GroupCondition struct {
+	Expression Expression
+	// Var is empty if the expression is not bound.
+	Var Var
+}
+
+func ParseGroupCondition(n *parser.Node) (*GroupCondition, error) {
+	if n.Name != "GroupCondition" {
+		return nil, fmt.Errorf("group condition: unknown %s", n.Name)
+	}
+	e, err := ParseExpression(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	c := &GroupCondition{Expression: e}
+	if len(n.Children()) == 2 {
+		v, err := ParseVar(n.Children()[1])
+		if err != nil {
+			return nil, err
+		}
+		c.Var = *v
+	}
+	return c, nil
+}
+
+func (c GroupCondition) String() string {
+	if c.Var != "" {
+		return fmt.Sprintf("(%s AS %s)", c.Expression, c.Var)
+	}
+	if _, ok := c.Expression.(*Var); ok {
+		return c.Expression.String()
+	}
+	return fmt.Sprintf("(%s)", c.Expression)
+}
+
+// GroupGraphPattern is a group of patterns, delimited by braces. A group contains either patterns or a sub-select.
+type GroupGraphPattern struct {
+	Patterns  []Pattern
+	SubSelect *Query
+}
+
+func ParseGroupGraphPattern(n *parser.Node) (*GroupGraphPattern, error) {
+	if n.Name != "GroupGraphPattern" {
+		return nil, fmt.Errorf("group graph pattern: unknown %s", n.Name)
+	}
+	g := new(GroupGraphPattern)
+	for _, n := range n.Children() {
+		if n.Name == "SubSelect" {
+			q, err := parseSelectQuery(n)
+			if err != nil {
+				return nil, err
+			}
+			g.SubSelect = q
+			continue
+		}
+		p, err := ParsePattern(n)
+		if err != nil {
+			return nil, err
+		}
+		g.Patterns = append(g.Patterns, p)
+	}
+	return g, nil
+}
+
+func (g GroupGraphPattern) String() string {
+	if g.SubSelect != nil {
+		return fmt.Sprintf("{ %s }", g.SubSelect)
+	}
+	if len(g.Patterns) == 0 {
+		return "{ }"
+	}
+	s := make([]string, len(g.Patterns))
+	for i, p := range g.Patterns {
+		s[i] = p.String()
+	}
+	return fmt.Sprintf("{ %s }", strings.Join(s, " "))
+}
+
+func (g GroupGraphPattern) pattern() {}
+
+// InExpression tests whether the value of the expression is (NOT) IN the list.
+type InExpression struct {
+	Not        bool
+	Expression Expression
+	List       []Expression
+}
+
+func (e InExpression) String() string {
+	if e.Not {
+		return fmt.Sprintf("(%s NOT IN (%s))", e.Expression, expressionsString(e.List))
+	}
+	return fmt.Sprintf("(%s IN (%s))", e.Expression, expressionsString(e.List))
+}
+
+// InlineData is a block of VALUES, a nil value represents UNDEF.
+type InlineData struct {
+	Vars []Var
+	Rows [][]Term
+}
+
+func ParseInlineData(n *parser.Node) (*InlineData, error) {
+	if n.Name != "InlineData" && n.Name != "ValuesClause" {
+		return nil, fmt.Errorf("inline data: unknown %s", n.Name)
+	}
+	d := new(InlineData)
+	switch n = n.Children()[0]; n.Name {
+	case "InlineDataOneVar":
+		v, err := ParseVar(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		d.Vars = []Var{*v}
+		for _, n := range n.Children()[1:] {
+			t, err := parseDataBlockValue(n)
+			if err != nil {
+				return nil, err
+			}
+			d.Rows = append(d.Rows, []Term{t})
+		}
+	case "InlineDataFull":
+		for _, n := range n.Children()[0].Children() {
+			v, err := ParseVar(n)
+			if err != nil {
+				return nil, err
+			}
+			d.Vars = append(d.Vars, *v)
+		}
+		for _, n := range n.Children()[1:] {
+			var row []Term
+			for _, n := range n.Children() {
+				t, err := parseDataBlockValue(n)
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, t)
+			}
+			if len(row) != len(d.Vars) {
+				return nil, fmt.Errorf("inline data: expected %d values, got %d", len(d.Vars), len(row))
+			}
+			d.Rows = append(d.Rows, row)
+		}
+	default:
+		return nil, fmt.Errorf("inline data: unknown %s", n.Name)
+	}
+	return d, nil
+}
+
+func (d InlineData) String() string {
+	vars := make([]string, len(d.Vars))
+	for i, v := range d.Vars {
+		vars[i] = v.String()
+	}
+	rows := make([]string, len(d.Rows))
+	for i, row := range d.Rows {
+		values := make([]string, len(row))
+		for j, t := range row {
+			if t == nil {
+				values[j] = "UNDEF"
+			} else {
+				values[j] = t.String()
+			}
+		}
+		rows[i] = fmt.Sprintf("(%s)", strings.Join(values, " "))
+	}
+	return fmt.Sprintf("VALUES (%s) { %s }", strings.Join(vars, " "), strings.Join(rows, " "))
+}
+
+func (d InlineData) pattern() {}
+
+// MinusGraphPattern removes the solutions that are compatible with the solutions of the pattern.
+type MinusGraphPattern struct {
+	Pattern *GroupGraphPattern
+}
+
+func (m MinusGraphPattern) String() string {
+	return fmt.Sprintf("MINUS %s", m.Pattern)
+}
+
+func (m MinusGraphPattern) pattern() {}
+
+// Nil is the empty collection "()", i.e. rdf:nil.
+type Nil struct{}
+
+func (Nil) String() string {
+	return "()"
+}
+
+// OptionalGraphPattern extends the solutions with the solutions of the pattern, if any.
+type OptionalGraphPattern struct {
+	Pattern *GroupGraphPattern
+}
+
+func (o OptionalGraphPattern) String() string {
+	return fmt.Sprintf("OPTIONAL %s", o.Pattern)
+}
+
+func (o OptionalGraphPattern) pattern() {}
+
+// OrderCondition is a condition of the ORDER BY clause.
+type OrderCondition struct {
+	Expression Expression
+	Descending bool
+}
+
+func ParseOrderCondition(n *parser.Node) (*OrderCondition, error) {
+	if n.Name != "OrderCondition" {
+		return nil, fmt.Errorf("order condition: unknown %s", n.Name)
+	}
+	c := new(OrderCondition)
+	children := n.Children()
+	if children[0].Name == "Order" {
+		c.Descending = strings.ToUpper(children[0].Value()) == "DESC"
+		children = children[1:]
+	}
+	e, err := ParseExpression(children[0])
+	if err != nil {
+		return nil, err
+	}
+	c.Expression = e
+	return c, nil
+}
+
+func (c OrderCondition) String() string {
+	if c.Descending {
+		return fmt.Sprintf("DESC(%s)", c.Expression)
+	}
+	if _, ok := c.Expression.(*Var); ok {
+		return c.Expression.String()
+	}
+	return fmt.Sprintf("ASC(%s)", c.Expression)
+}
+
+// PathAlternative is a choice between paths, e.g. ":p | :q".
+type PathAlternative []Term
+
+func (p PathAlternative) String() string {
+	return pathsString(p, " | ", 1)
+}
+
+// PathElt is a path with a modifier, e.g. ":p*".
+type PathElt struct {
+	Path Term
+	// Modifier is either "?", "*" or "+".
+	Modifier string
+}
+
+func (p PathElt) String() string {
+	return pathString(p.Path, 4) + p.Modifier
+}
+
+// PathInverse is the inverse of a path, e.g. "^:p".
+type PathInverse struct {
+	Path Term
+}
+
+func (p PathInverse) String() string {
+	return "^" + pathString(p.Path, 3)
+}
+
+// PathNegatedPropertySet matches any predicate that is not in the set, e.g. "!(:p | ^:q)". The elements are either
+// IRIs or inverted IRIs.
+type PathNegatedPropertySet []Term
+
+func (p PathNegatedPropertySet) String() string {
+	if len(p) == 1 {
+		return "!" + p[0].String()
+	}
+	return "!(" + pathsString(p, " | ", 0) + ")"
+}
+
+// PathSequence is a sequence of paths, e.g. ":p / :q".
+type PathSequence []Term
+
+func (p PathSequence) String() string {
+	return pathsString(p, " / ", 2)
+}
+
+// ParsePath parses a property path. Paths that consist of a single IRI result in the IRI itself.
+func ParsePath(n *parser.Node) (Term, error) {
+	switch n.Name {
+	case "PathAlternative", "PathSequence":
+		var paths []Term
+		for _, n := range n.Children() {
+			p, err := ParsePath(n)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, p)
+		}
+		if len(paths) == 1 {
+			return paths[0], nil
+		}
+		if n.Name == "PathAlternative" {
+			return PathAlternative(paths), nil
+		}
+		return PathSequence(paths), nil
+	case "PathElt":
+		p, err := ParsePath(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(n.Children()) == 1 {
+			return p, nil
+		}
+		return PathElt{Path: p, Modifier: n.Children()[1].Value()}, nil
+	case "PathInverse":
+		p, err := ParsePath(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		return PathInverse{Path: p}, nil
+	case "PathNegatedPropertySet":
+		set := PathNegatedPropertySet{}
+		for _, n := range n.Children() {
+			p, err := ParsePath(n)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, p)
+		}
+		return set, nil
+	case "IRI", "a":
+		return ParseTerm(n)
+	default:
+		return nil, fmt.Errorf("path: unknown %s", n.Name)
+	}
+}
+
+// Pattern is an element of a group graph pattern.
+type Pattern interface {
+	pattern()
+
+	fmt.Stringer
+}
+
+func ParsePattern(n *parser.Node) (Pattern, error) {
+	switch n.Name {
+	case "TriplesBlock":
+		return ParseTriplesBlock(n)
+	case "OptionalGraphPattern":
+		p, err := ParseGroupGraphPattern(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		return &OptionalGraphPattern{Pattern: p}, nil
+	case "MinusGraphPattern":
+		p, err := ParseGroupGraphPattern(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		return &MinusGraphPattern{Pattern: p}, nil
+	case "GraphGraphPattern":
+		return ParseGraphGraphPattern(n)
+	case "GroupOrUnionGraphPattern":
+		var patterns []*GroupGraphPattern
+		for _, n := range n.Children() {
+			p, err := ParseGroupGraphPattern(n)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, p)
+		}
+		if len(patterns) == 1 {
+			return patterns[0], nil
+		}
+		return UnionGraphPattern(patterns), nil
+	case "Filter":
+		return ParseFilter(n)
+	case "Bind":
+		return ParseBind(n)
+	case "InlineData":
+		return ParseInlineData(n)
+	default:
+		return nil, fmt.Errorf("pattern: unknown %s", n.Name)
+	}
+}
+
+// PredicateObject is a verb with its objects.
+type PredicateObject struct {
+	// Verb is either a Var, IRI, turtle.A or a property path (in WHERE clauses).
+	Verb    Term
+	Objects []Term
+}
+
+func ParsePredicateObject(n *parser.Node) (*PredicateObject, error) {
+	if n.Name != "PredicateObject" {
+		return nil, fmt.Errorf("predicate object: unknown %s", n.Name)
+	}
+	var verb Term
+	var err error
+	if v := n.Children()[0].Children()[0]; v.Name == "PathAlternative" {
+		verb, err = ParsePath(v)
+	} else {
+		verb, err = ParseTerm(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	po := &PredicateObject{Verb: verb}
+	for _, n := range n.Children()[1].Children() {
+		o, err := ParseTerm(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		po.Objects = append(po.Objects, o)
+	}
+	return po, nil
+}
+
+func (po PredicateObject) String() string {
+	s := make([]string, len(po.Objects))
+	for i, o := range po.Objects {
+		s[i] = o.String()
+	}
+	return fmt.Sprintf("%s %s", po.Verb, strings.Join(s, " , "))
+}
+
+func ParsePropertyList(n *parser.Node) ([]PredicateObject, error) {
+	if n.Name != "PropertyList" {
+		return nil, fmt.Errorf("property list: unknown %s", n.Name)
+	}
+	var pl []PredicateObject
+	for _, n := range n.Children() {
+		po, err := ParsePredicateObject(n)
+		if err != nil {
+			return nil, err
+		}
+		pl = append(pl, *po)
+	}
+	return pl, nil
+}
+
+// Projection is a selected variable, optionally bound to an expression, e.g. "(?x + 1 AS ?y)".
+type Projection struct {
+	// Expression is nil if the variable is not bound.
+	Expression Expression
+	Var        Var
+}
+
+func ParseProjection(n *parser.Node) (*Projection, error) {
+	switch n.Name {
+	case "Var":
+		v, err := ParseVar(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Projection{Var: *v}, nil
+	case "Projection":
+		e, err := ParseExpression(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := ParseVar(n.Children()[1])
+		if err != nil {
+			return nil, err
+		}
+		return &Projection{Expression: e, Var: *v}, nil
+	default:
+		return nil, fmt.Errorf("projection: unknown %s", n.Name)
+	}
+}
+
+func (p Projection) String() string {
+	if p.Expression == nil {
+		return p.Var.String()
+	}
+	return fmt.Sprintf("(%s AS %s)", p.Expression, p.Var)
+}
+
+// Query is a SPARQL query. Sub-selects are also represented by a (SELECT) query, without a prologue.
+type Query struct {
+	Prologue []turtle.Directive
+	Type     algebra.QueryType
+
+	// Modifier is either "DISTINCT", "REDUCED" or empty (SELECT only).
+	Modifier string
+	// Projection contains the selected variables, SELECT * if empty (SELECT only).
+	Projection []Projection
+	// Template contains the triples of a CONSTRUCT query.
+	Template []*TriplesSameSubject
+	// ConstructWhere is true for the short form "CONSTRUCT WHERE { ... }", the template is the pattern.
+	ConstructWhere bool
+	// Describe contains the described variables and IRIs, DESCRIBE * if empty (DESCRIBE only).
+	Describe []Term
+
+	Dataset []DatasetClause
+	// Where can only be nil for DESCRIBE queries.
+	Where   *GroupGraphPattern
+	GroupBy []GroupCondition
+	Having  []Expression
+	OrderBy []OrderCondition
+	// Limit is -1 if there is no limit.
+	Limit  int
+	Offset int
+	Values *InlineData
+}
+
+// ParseQuery parses a SPARQL query.
+func ParseQuery(query string) (*Query, error) {
+	p, err := grammar.NewParser([]rune(query))
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.Parse(op.And{grammar.QueryUnit, op.EOF{}})
+	if err != nil {
+		return nil, err
+	}
+	return parseQuery(n)
+}
+
+func parseQuery(n *parser.Node) (*Query, error) {
+	if n.Name != "Query" {
+		return nil, fmt.Errorf("query: unknown %s", n.Name)
+	}
+	var prologue []turtle.Directive
+	var q *Query
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Base", "Prefix":
+			d, err := parseDirective(n)
+			if err != nil {
+				return nil, err
+			}
+			prologue = append(prologue, d)
+		case "SelectQuery":
+			var err error
+			if q, err = parseSelectQuery(n); err != nil {
+				return nil, err
+			}
+		case "ConstructQuery", "DescribeQuery", "AskQuery":
+			q = &Query{Type: queryTypes[n.Name], Limit: -1}
+			if err := q.parseClauses(n.Children()); err != nil {
+				return nil, err
+			}
+		case "ValuesClause":
+			d, err := ParseInlineData(n)
+			if err != nil {
+				return nil, err
+			}
+			q.Values = d
+		default:
+			return nil, fmt.Errorf("query: unknown %s", n.Name)
+		}
+	}
+	q.Prologue = prologue
+	return q, nil
+}
+
+// parseSelectQuery parses a SELECT query or sub-select.
+func parseSelectQuery(n *parser.Node) (*Query, error) {
+	if n.Name != "SelectQuery" && n.Name != "SubSelect" {
+		return nil, fmt.Errorf("select query: unknown %s", n.Name)
+	}
+	q := &Query{Type: algebra.Select, Limit: -1}
+	for _, n := range n.Children()[0].Children() {
+		switch n.Name {
+		case "Modifier":
+			q.Modifier = strings.ToUpper(n.Value())
+		case "Star":
+		default:
+			p, err := ParseProjection(n)
+			if err != nil {
+				return nil, err
+			}
+			q.Projection = append(q.Projection, *p)
+		}
+	}
+	if err := q.parseClauses(n.Children()[1:]); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// parseClauses parses the clauses that are shared by all query forms.
+func (q *Query) parseClauses(nodes []*parser.Node) error {
+	for _, n := range nodes {
+		switch n.Name {
+		case "ConstructTemplate", "ConstructWhere":
+			for _, n := range n.Children() {
+				t, err := ParseTriplesSameSubject(n)
+				if err != nil {
+					return err
+				}
+				q.Template = append(q.Template, t)
+			}
+			if n.Name == "ConstructWhere" {
+				q.ConstructWhere = true
+				q.Where = &GroupGraphPattern{Patterns: []Pattern{TriplesBlock(q.Template)}}
+				if len(q.Template) == 0 {
+					q.Where = new(GroupGraphPattern)
+				}
+			}
+		case "Star":
+		case "Var", "IRI":
+			t, err := ParseTerm(n)
+			if err != nil {
+				return err
+			}
+			q.Describe = append(q.Describe, t)
+		case "DefaultGraphClause", "NamedGraphClause":
+			d, err := ParseDatasetClause(n)
+			if err != nil {
+				return err
+			}
+			q.Dataset = append(q.Dataset, *d)
+		case "GroupGraphPattern":
+			g, err := ParseGroupGraphPattern(n)
+			if err != nil {
+				return err
+			}
+			q.Where = g
+		case "GroupClause":
+			for _, n := range n.Children() {
+				c, err := ParseGroupCondition(n)
+				if err != nil {
+					return err
+				}
+				q.GroupBy = append(q.GroupBy, *c)
+			}
+		case "HavingClause":
+			for _, n := range n.Children() {
+				e, err := ParseExpression(n)
+				if err != nil {
+					return err
+				}
+				q.Having = append(q.Having, e)
+			}
+		case "OrderClause":
+			for _, n := range n.Children() {
+				c, err := ParseOrderCondition(n)
+				if err != nil {
+					return err
+				}
+				q.OrderBy = append(q.OrderBy, *c)
+			}
+		case "LimitClause", "OffsetClause":
+			i, err := strconv.Atoi(n.Children()[0].Value())
+			if err != nil {
+				return err
+			}
+			if n.Name == "LimitClause" {
+				q.Limit = i
+			} else {
+				q.Offset = i
+			}
+		case "ValuesClause":
+			d, err := ParseInlineData(n)
+			if err != nil {
+				return err
+			}
+			q.Values = d
+		default:
+			return fmt.Errorf("query: unknown %s", n.Name)
+		}
+	}
+	return nil
+}
+
+func (q Query) String() string {
+	s := prologueStrings(q.Prologue)
+	switch q.Type {
+	case algebra.Select:
+		clause := "SELECT"
+		if q.Modifier != "" {
+			clause += " " + q.Modifier
+		}
+		if len(q.Projection) == 0 {
+			clause += " *"
+		}
+		for _, p := range q.Projection {
+			clause += " " + p.String()
+		}
+		s = append(s, clause)
+	case algebra.Construct:
+		if q.ConstructWhere {
+			s = append(s, "CONSTRUCT")
+		} else {
+			s = append(s, fmt.Sprintf("CONSTRUCT { %s }", templateString(q.Template)))
+		}
+	case algebra.Ask:
+		s = append(s, "ASK")
+	case algebra.Describe:
+		clause := "DESCRIBE"
+		if len(q.Describe) == 0 {
+			clause += " *"
+		}
+		for _, t := range q.Describe {
+			clause += " " + t.String()
+		}
+		s = append(s, clause)
+	}
+	for _, d := range q.Dataset {
+		s = append(s, d.String())
+	}
+	if q.Where != nil {
+		s = append(s, fmt.Sprintf("WHERE %s", q.Where))
+	}
+	if len(q.GroupBy) != 0 {
+		conditions := make([]string, len(q.GroupBy))
+		for i, c := range q.GroupBy {
+			conditions[i] = c.String()
+		}
+		s = append(s, fmt.Sprintf("GROUP BY %s", strings.Join(conditions, " ")))
+	}
+	if len(q.Having) != 0 {
+		conditions := make([]string, len(q.Having))
+		for i, e := range q.Having {
+			conditions[i] = fmt.Sprintf("(%s)", e)
+		}
+		s = append(s, fmt.Sprintf("HAVING %s", strings.Join(conditions, " ")))
+	}
+	if len(q.OrderBy) != 0 {
+		conditions := make([]string, len(q.OrderBy))
+		for i, c := range q.OrderBy {
+			conditions[i] = c.String()
+		}
+		s = append(s, fmt.Sprintf("ORDER BY %s", strings.Join(conditions, " ")))
+	}
+	if q.Limit != -1 {
+		s = append(s, fmt.Sprintf("LIMIT %d", q.Limit))
+	}
+	if q.Offset != 0 {
+		s = append(s, fmt.Sprintf("OFFSET %d", q.Offset))
+	}
+	if q.Values != nil {
+		s = append(s, q.Values.String())
+	}
+	return strings.Join(s, "\n")
+}
+
+var queryTypes = map[string]algebra.QueryType{
+	"ConstructQuery": algebra.Construct,
+	"DescribeQuery":  algebra.Describe,
+	"AskQuery":       algebra.Ask,
+}
+
+// Term is a term of a triple pattern: Var, IRI, BlankNode, Literal, Nil, Collection, BlankNodePropertyList or
+// turtle.A (as verb).
+type Term interface {
+	fmt.Stringer
+}
+
+func ParseTerm(n *parser.Node) (Term, error) {
+	switch n.Name {
+	case "Var":
+		return ParseVar(n)
+	case "IRI":
+		return turtle.ParseIRI(n)
+	case "Literal":
+		return turtle.ParseLiteral(n)
+	case "BlankNode":
+		return turtle.ParseBlankNode(n)
+	case "Nil":
+		return Nil{}, nil
+	case "a":
+		return turtle.A{}, nil
+	case "Collection":
+		return ParseCollection(n)
+	case "BlankNodePropertyList":
+		return ParseBlankNodePropertyList(n)
+	default:
+		return nil, fmt.Errorf("term: unknown %s", n.Name)
+	}
+}
+
+// TriplesBlock is a sequence of triple patterns.
+type TriplesBlock []*TriplesSameSubject
+
+func ParseTriplesBlock(n *parser.Node) (TriplesBlock, error) {
+	if n.Name != "TriplesBlock" {
+		return nil, fmt.Errorf("triples block: unknown %s", n.Name)
+	}
+	var b TriplesBlock
+	for _, n := range n.Children() {
+		t, err := ParseTriplesSameSubject(n)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, t)
+	}
+	return b, nil
+}
+
+func (b TriplesBlock) String() string {
+	return templateString(b)
+}
+
+func (b TriplesBlock) pattern() {}
+
+// TriplesSameSubject is a subject with its property list. The property list can only be empty if the subject is a
+// collection or blank node property list.
+type TriplesSameSubject struct {
+	Subject      Term
+	PropertyList []PredicateObject
+}
+
+func ParseTriplesSameSubject(n *parser.Node) (*TriplesSameSubject, error) {
+	if n.Name != "TriplesSameSubject" {
+		return nil, fmt.Errorf("triples same subject: unknown %s", n.Name)
+	}
+	s, err := ParseTerm(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	t := &TriplesSameSubject{Subject: s}
+	if len(n.Children()) == 2 {
+		if t.PropertyList, err = ParsePropertyList(n.Children()[1]); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t TriplesSameSubject) String() string {
+	if len(t.PropertyList) == 0 {
+		return t.Subject.String()
+	}
+	return fmt.Sprintf("%s %s", t.Subject, propertyListString(t.PropertyList))
+}
+
+// UnaryExpression is an expression with a unary operator, e.g. "!?x" or "-?x".
+type UnaryExpression struct {
+	Operator   string
+	Expression Expression
+}
+
+func (e UnaryExpression) String() string {
+	return fmt.Sprintf("%s%s", e.Operator, e.Expression)
+}
+
+// UnionGraphPattern matches either of the patterns.
+type UnionGraphPattern []*GroupGraphPattern
+
+func (u UnionGraphPattern) String() string {
+	s := make([]string, len(u))
+	for i, p := range u {
+		s[i] = p.String()
+	}
+	return strings.Join(s, " UNION ")
+}
+
+func (u UnionGraphPattern) pattern() {}
+
+// Var is a query variable, without the leading '?' or '$'.
+type Var string
+
+func ParseVar(n *parser.Node) (*Var, error) {
+	if n.Name != "Var" {
+		return nil, fmt.Errorf("var: unknown %s", n.Name)
+	}
+	v := Var(n.Value()[1:])
+	return &v, nil
+}
+
+func (v Var) String() string {
+	return "?" + string(v)
+}
+
+func expressionsString(es []Expression) string {
+	s := make([]string, len(es))
+	for i, e := range es {
+		s[i] = e.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func parseBinaryExpression(n *parser.Node, operator string) (Expression, error) {
+	children := n.Children()
+	e, err := ParseExpression(children[0])
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(children); i++ {
+		op := operator
+		if op == "" {
+			op = children[i].Value()
+			i++
+		}
+		right, err := ParseExpression(children[i])
+		if err != nil {
+			return nil, err
+		}
+		e = &BinaryExpression{Operator: op, Left: e, Right: right}
+	}
+	return e, nil
+}
+
+func parseDirective(n *parser.Node) (turtle.Directive, error) {
+	if n.Name == "Base" {
+		return turtle.ParseBase(n)
+	}
+	return turtle.ParsePrefix(n)
+}
+
+func parseDataBlockValue(n *parser.Node) (Term, error) {
+	switch n.Name {
+	case "IRI":
+		return turtle.ParseIRI(n)
+	case "Literal":
+		return turtle.ParseLiteral(n)
+	case "Undef":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("data block value: unknown %s", n.Name)
+	}
+}
+
+// pathString returns the string of the path, enclosed in parentheses if the precedence of the path is lower than the
+// given precedence: alternatives (0), sequences (1), inverse paths (2), paths with a modifier (3) and primaries (4).
+func pathString(t Term, precedence int) string {
+	var p int
+	switch t.(type) {
+	case PathAlternative:
+		p = 0
+	case PathSequence:
+		p = 1
+	case PathInverse:
+		p = 2
+	case PathElt:
+		p = 3
+	default:
+		p = 4
+	}
+	if p < precedence {
+		return "(" + t.String() + ")"
+	}
+	return t.String()
+}
+
+func pathsString(ts []Term, separator string, precedence int) string {
+	s := make([]string, len(ts))
+	for i, t := range ts {
+		s[i] = pathString(t, precedence)
+	}
+	return strings.Join(s, separator)
+}
+
+func prologueStrings(prologue []turtle.Directive) []string {
+	var s []string
+	for _, d := range prologue {
+		switch d := d.(type) {
+		case *turtle.Base:
+			s = append(s, fmt.Sprintf("BASE <%s>", string(*d)))
+		case *turtle.Prefix:
+			s = append(s, fmt.Sprintf("PREFIX %s <%s>", d.Name, d.IRI))
+		}
+	}
+	return s
+}
+
+func propertyListString(pl []PredicateObject) string {
+	s := make([]string, len(pl))
+	for i, po := range pl {
+		s[i] = po.String()
+	}
+	return strings.Join(s, " ; ")
+}
+
+func templateString(ts []*TriplesSameSubject) string {
+	s := make([]string, len(ts))
+	for i, t := range ts {
+		s[i] = t.String()
+	}
+	return strings.Join(s, " . ")
+}
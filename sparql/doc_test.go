@@ -0,0 +1,48 @@
+package sparql_test
+
+import (
+	"github.com/0x51-dev/rdf/sparql"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, test := range []string{
+		`PREFIX ex: <http://example.org/> SELECT ?s WHERE { ?s ex:p ?o . FILTER(?o > 1) }`,
+		`SELECT DISTINCT ?s (COUNT(?o) AS ?c) WHERE { ?s ?p ?o } GROUP BY ?s HAVING (COUNT(?o) > 1) ORDER BY DESC(?c) LIMIT 10 OFFSET 2`,
+		`SELECT * { ?s ?p ?o OPTIONAL { ?o ?q ?r FILTER(?r != "x") } { ?a ?b ?c } UNION { ?d ?e ?f } MINUS { ?s a ?t } }`,
+		`SELECT ?x { BIND(1 + 2 AS ?x) VALUES ?y { 1 UNDEF } }`,
+		`SELECT ?x { { SELECT ?x { ?x ?p ?o } LIMIT 1 } }`,
+		`CONSTRUCT { ?s <http://e/p> [ <http://e/q> ?o ] } WHERE { ?s ?p (1 2) }`,
+		`CONSTRUCT WHERE { ?s ?p ?o }`,
+		`ASK FROM <http://e/g> FROM NAMED <http://e/h> { GRAPH ?g { ?s ?p ?o } FILTER NOT EXISTS { ?s ?p 1 } }`,
+		`DESCRIBE <http://e/x> ?y`,
+		`SELECT * { ?s (<http://e/p>|^<http://e/q>)*/!(a|^<http://e/r>)?/^(<http://e/s>/<http://e/t>)+ ?o ; a? ?t }`,
+		`SELECT (GROUP_CONCAT(DISTINCT ?o; SEPARATOR=",") AS ?c) { ?s ?p ?o FILTER(?o IN (1, 2) && BOUND(?s)) }`,
+	} {
+		q, err := sparql.ParseQuery(test)
+		if err != nil {
+			t.Fatal(test, err)
+		}
+		// The string representation of the query must result in the same query.
+		q2, err := sparql.ParseQuery(q.String())
+		if err != nil {
+			t.Fatal(q, err)
+		}
+		if q.String() != q2.String() {
+			t.Errorf("expected:\n%s\ngot:\n%s", q, q2)
+		}
+	}
+}
+
+func TestParseQuery_invalid(t *testing.T) {
+	for _, test := range []string{
+		`SELECT ?s { FILTER(BOUND(1)) }`,
+		`SELECT ?s { FILTER(STR(?s, ?o)) }`,
+		`SELECT (SUM(*) AS ?x) { }`,
+		`SELECT ?s { ?s ?p ?o`,
+	} {
+		if _, err := sparql.ParseQuery(test); err == nil {
+			t.Errorf("expected error for %q", test)
+		}
+	}
+}
@@ -0,0 +1,705 @@
+package sparql
+
+import (
+	"context"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Evaluate evaluates the query against the dataset. The dataset clauses of the query select the graphs of the dataset
+// that are used: the default graph is the merge of the FROM graphs and the named graphs are the FROM NAMED graphs. A
+// single graph can be queried by using it as the default graph of a dataset, i.e. &rdf.Dataset{Default: g}.
+func Evaluate(ctx context.Context, q *algebra.Query, d *rdf.Dataset) (*Result, error) {
+	e := newEvaluator(ctx, q, d)
+	solutions := &Solutions{next: e.eval(q.Operator, e.defaultGraph), ctx: ctx}
+	switch q.Type {
+	case algebra.Select:
+		return &Result{Type: q.Type, Vars: q.Vars, Solutions: solutions}, nil
+	case algebra.Ask:
+		_, ok := solutions.Next()
+		if err := solutions.Err(); err != nil {
+			return nil, err
+		}
+		return &Result{Type: q.Type, Boolean: ok}, nil
+	case algebra.Construct:
+		g, err := e.construct(q.Template, solutions)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := g.ToNTriples()
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Type: q.Type, Graph: doc}, nil
+	case algebra.Describe:
+		g, err := e.describe(q.Describe, solutions)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := g.ToNTriples()
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Type: q.Type, Graph: doc}, nil
+	default:
+		return nil, fmt.Errorf("unknown query type %s", q.Type)
+	}
+}
+
+// Execute parses, translates and evaluates the query against the dataset, relative IRIs are resolved against the base.
+func Execute(ctx context.Context, query string, base string, d *rdf.Dataset) (*Result, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	a, err := Translate(q, base)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(ctx, a, d)
+}
+
+// Result is the result of a query, the fields that are set depend on the query form.
+type Result struct {
+	Type algebra.QueryType
+	// Vars are the projected variables of a SELECT query.
+	Vars []algebra.Var
+	// Solutions are the solutions of a SELECT query, they are computed while iterating.
+	Solutions *Solutions
+	// Boolean is the result of an ASK query.
+	Boolean bool
+	// Graph contains the triples of a CONSTRUCT or DESCRIBE query.
+	Graph nt.Document
+}
+
+// Solution maps variables to RDF terms, unbound variables are not part of the solution.
+type Solution map[algebra.Var]rdf.Node
+
+// String returns the bindings of the solution, ordered by variable.
+func (s Solution) String() string {
+	vars := make([]string, 0, len(s))
+	for v := range s {
+		vars = append(vars, string(v))
+	}
+	sort.Strings(vars)
+	bindings := make([]string, len(vars))
+	for i, v := range vars {
+		bindings[i] = fmt.Sprintf("[%s %s]", algebra.Var(v), algebra.FormatNode(s[algebra.Var(v)]))
+	}
+	return "(" + strings.Join(bindings, " ") + ")"
+}
+
+// compatible returns true if the variables bound by both solutions are bound to the same terms.
+func (s Solution) compatible(other Solution) bool {
+	for v, n := range s {
+		if m, ok := other[v]; ok && !sameTerm(n, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// disjoint returns true if both solutions have no variables in common.
+func (s Solution) disjoint(other Solution) bool {
+	for v := range s {
+		if _, ok := other[v]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Solution) merge(other Solution) Solution {
+	m := make(Solution, len(s)+len(other))
+	for v, n := range s {
+		m[v] = n
+	}
+	for v, n := range other {
+		m[v] = n
+	}
+	return m
+}
+
+// Solutions is an iterator over the solutions of a query. Iteration stops if the context of the evaluation is done.
+type Solutions struct {
+	next iterator
+	ctx  context.Context
+	done bool
+}
+
+// NewSolutions returns an iterator over the given solutions.
+func NewSolutions(solutions ...Solution) *Solutions {
+	return &Solutions{next: fromSlice(solutions), ctx: context.Background()}
+}
+
+// All returns the remaining solutions.
+func (s *Solutions) All() ([]Solution, error) {
+	var solutions []Solution
+	for {
+		solution, ok := s.Next()
+		if !ok {
+			return solutions, s.Err()
+		}
+		solutions = append(solutions, solution)
+	}
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (s *Solutions) Err() error {
+	return s.ctx.Err()
+}
+
+// Next returns the next solution, false if there are no more solutions.
+func (s *Solutions) Next() (Solution, bool) {
+	if s.done || s.ctx.Err() != nil {
+		return nil, false
+	}
+	solution, ok := s.next()
+	if !ok {
+		s.done = true
+	}
+	return solution, ok
+}
+
+// evaluator evaluates the operators of a query against a dataset.
+type evaluator struct {
+	ctx          context.Context
+	dataset      *rdf.Dataset
+	defaultGraph graph
+	// named contains the names of the named graphs of the dataset of the query.
+	named []rdf.Node
+	// now is the time of the evaluation, it is the same for all calls of NOW.
+	now time.Time
+	// bnodes allocates the blank nodes created during the evaluation, it is shared by all the copies of the evaluator.
+	bnodes *bnodeAllocator
+	// env contains the bindings that are substituted in the pattern of (NOT) EXISTS.
+	env Solution
+	// bnodeStrings contains the blank nodes returned by BNODE for a string, by lexical form. It only lives for the
+	// evaluation of one solution, so that BNODE returns the same blank node for the same string within a solution.
+	bnodeStrings map[string]*rdf.BlankNode
+}
+
+func newEvaluator(ctx context.Context, q *algebra.Query, d *rdf.Dataset) *evaluator {
+	e := &evaluator{
+		ctx:          ctx,
+		dataset:      d,
+		defaultGraph: d.Default,
+		named:        d.Names(),
+		now:          time.Now(),
+		bnodes:       &bnodeAllocator{dataset: d},
+	}
+	if d.Default == nil {
+		e.defaultGraph = rdf.NewGraph()
+	}
+	if len(q.From) != 0 || len(q.FromNamed) != 0 {
+		// If there is no FROM clause, the default graph is empty.
+		names := make([]rdf.Node, len(q.From))
+		for i, iri := range q.From {
+			names[i] = &rdf.IRIReference{Value: iri}
+		}
+		e.defaultGraph = d.Union(names...)
+		if len(names) == 0 {
+			e.defaultGraph = rdf.NewGraph()
+		}
+		e.named = make([]rdf.Node, len(q.FromNamed))
+		for i, iri := range q.FromNamed {
+			e.named[i] = &rdf.IRIReference{Value: iri}
+		}
+	}
+	return e
+}
+
+// bnodeAllocator allocates fresh blank nodes, with labels that are not used in the dataset.
+type bnodeAllocator struct {
+	dataset *rdf.Dataset
+	// n is the amount of blank nodes that are allocated.
+	n int
+	// labels contains the blank node labels that are in use, nil until the first blank node is allocated.
+	labels map[string]bool
+}
+
+// bnode returns a fresh blank node.
+func (a *bnodeAllocator) bnode() *rdf.BlankNode {
+	if a.labels == nil {
+		a.labels = make(map[string]bool)
+		var add func(n rdf.Node)
+		add = func(n rdf.Node) {
+			switch n := n.(type) {
+			case *rdf.BlankNode:
+				a.labels[algebra.FormatNode(n)] = true
+			case *rdf.TripleTerm:
+				add(n.Subject)
+				add(n.Object)
+			}
+		}
+		for _, q := range a.dataset.FindAll(nil, nil, nil, nil) {
+			add(q.Subject)
+			add(q.Object)
+			add(q.Graph)
+		}
+	}
+	for {
+		a.n++
+		label := fmt.Sprintf("_:b%d", a.n)
+		if !a.labels[label] {
+			a.labels[label] = true
+			return &rdf.BlankNode{Attribute: label}
+		}
+	}
+}
+
+// construct instantiates the template for every solution, triples with unbound variables or invalid terms are
+// skipped. Blank nodes of the template are replaced by fresh blank nodes per solution.
+func (e *evaluator) construct(template []algebra.TriplePattern, solutions *Solutions) (*rdf.Graph, error) {
+	g := rdf.NewGraph()
+	for {
+		solution, ok := solutions.Next()
+		if !ok {
+			return g, solutions.Err()
+		}
+		bnodes := make(map[string]rdf.Node)
+		instantiate := func(t algebra.Term) rdf.Node {
+			switch t := t.(type) {
+			case algebra.Var:
+				return solution[t]
+			case algebra.Constant:
+				if b, ok := t.Node.(*rdf.BlankNode); ok {
+					if _, ok := bnodes[b.Attribute]; !ok {
+						bnodes[b.Attribute] = e.bnodes.bnode()
+					}
+					return bnodes[b.Attribute]
+				}
+				return t.Node
+			default:
+				return nil
+			}
+		}
+		for _, t := range template {
+			s, p, o := instantiate(t.Subject), instantiate(t.Predicate), instantiate(t.Object)
+			if validTriple(s, p, o) {
+				g.Add(s, p, o)
+			}
+		}
+	}
+}
+
+// describe returns the concise bounded description of the resources, i.e. the triples of the default graph with the
+// resource as subject, including the descriptions of the blank nodes in object position.
+func (e *evaluator) describe(resources []algebra.Term, solutions *Solutions) (*rdf.Graph, error) {
+	var nodes []rdf.Node
+	for _, r := range resources {
+		if c, ok := r.(algebra.Constant); ok {
+			nodes = append(nodes, c.Node)
+		}
+	}
+	for {
+		solution, ok := solutions.Next()
+		if !ok {
+			break
+		}
+		for _, r := range resources {
+			if v, ok := r.(algebra.Var); ok && solution[v] != nil {
+				nodes = append(nodes, solution[v])
+			}
+		}
+	}
+	if err := solutions.Err(); err != nil {
+		return nil, err
+	}
+	g := rdf.NewGraph()
+	seen := make(map[string]bool)
+	for len(nodes) != 0 {
+		n := nodes[0]
+		nodes = nodes[1:]
+		if k := algebra.FormatNode(n); seen[k] {
+			continue
+		} else {
+			seen[k] = true
+		}
+		for _, t := range e.defaultGraph.FindAll(n, nil, nil) {
+			g.Add(t.Subject, t.Predicate, t.Object)
+			if _, ok := t.Object.(*rdf.BlankNode); ok {
+				nodes = append(nodes, t.Object)
+			}
+		}
+	}
+	return g, nil
+}
+
+// eval returns an iterator over the solutions of the operator, evaluated against the active graph.
+func (e *evaluator) eval(op algebra.Operator, g graph) iterator {
+	switch op := op.(type) {
+	case algebra.BGP:
+		return e.evalBGP(op, g)
+	case *algebra.Path:
+		return e.join(fromSlice([]Solution{{}}), func(s Solution) []Solution {
+			return e.matchPath(op, g, s)
+		})
+	case *algebra.Join:
+		if p, ok := op.Right.(*algebra.Path); ok {
+			// The path is evaluated per solution of the left operator, starting from the end that is bound.
+			return e.join(e.eval(op.Left, g), func(s Solution) []Solution {
+				return e.matchPath(p, g, s)
+			})
+		}
+		right := collect(e.eval(op.Right, g))
+		return e.join(e.eval(op.Left, g), func(l Solution) []Solution {
+			var solutions []Solution
+			for _, r := range right {
+				if l.compatible(r) {
+					solutions = append(solutions, l.merge(r))
+				}
+			}
+			return solutions
+		})
+	case *algebra.LeftJoin:
+		right := collect(e.eval(op.Right, g))
+		return e.join(e.eval(op.Left, g), func(l Solution) []Solution {
+			var solutions []Solution
+			for _, r := range right {
+				if !l.compatible(r) {
+					continue
+				}
+				m := l.merge(r)
+				if op.Expression == nil || e.test(op.Expression, m, g) {
+					solutions = append(solutions, m)
+				}
+			}
+			if len(solutions) == 0 {
+				return []Solution{l}
+			}
+			return solutions
+		})
+	case *algebra.Filter:
+		return e.filter(e.eval(op.Operator, g), func(s Solution) bool {
+			e.bnodeStrings = nil
+			for _, x := range op.Expressions {
+				if !e.test(x, s, g) {
+					return false
+				}
+			}
+			return true
+		})
+	case *algebra.Union:
+		left, right := e.eval(op.Left, g), e.eval(op.Right, g)
+		return func() (Solution, bool) {
+			if s, ok := left(); ok {
+				return s, true
+			}
+			return right()
+		}
+	case *algebra.Minus:
+		right := collect(e.eval(op.Right, g))
+		return e.filter(e.eval(op.Left, g), func(l Solution) bool {
+			for _, r := range right {
+				if l.compatible(r) && !l.disjoint(r) {
+					return false
+				}
+			}
+			return true
+		})
+	case *algebra.Graph:
+		return e.evalGraph(op)
+	case *algebra.Extend:
+		in := e.eval(op.Operator, g)
+		return func() (Solution, bool) {
+			s, ok := in()
+			if !ok {
+				return nil, false
+			}
+			if _, ok := op.Operator.(*algebra.Extend); !ok {
+				// Consecutive extensions, e.g. of the projection, belong to the same solution.
+				e.bnodeStrings = nil
+			}
+			if n, err := e.expression(op.Expression, s, g); err == nil {
+				s = s.merge(Solution{op.Var: n})
+			}
+			return s, true
+		}
+	case *algebra.Group:
+		return fromSlice(e.group(op, collect(e.eval(op.Operator, g)), g))
+	case *algebra.Table:
+		solutions := make([]Solution, len(op.Rows))
+		for i, row := range op.Rows {
+			solutions[i] = make(Solution)
+			for j, n := range row {
+				if n != nil {
+					solutions[i][op.Vars[j]] = n
+				}
+			}
+		}
+		return fromSlice(solutions)
+	case *algebra.OrderBy:
+		solutions := collect(e.eval(op.Operator, g))
+		sort.SliceStable(solutions, func(i, j int) bool {
+			for _, c := range op.Conditions {
+				a, _ := e.expression(c.Expression, solutions[i], g)
+				b, _ := e.expression(c.Expression, solutions[j], g)
+				if n := order(a, b); n != 0 {
+					return n < 0 != c.Descending
+				}
+			}
+			return false
+		})
+		return fromSlice(solutions)
+	case *algebra.Project:
+		in := e.eval(op.Operator, g)
+		return func() (Solution, bool) {
+			s, ok := in()
+			if !ok {
+				return nil, false
+			}
+			p := make(Solution, len(op.Vars))
+			for _, v := range op.Vars {
+				if n, ok := s[v]; ok {
+					p[v] = n
+				}
+			}
+			return p, true
+		}
+	case *algebra.Distinct:
+		return distinct(e.eval(op.Operator, g))
+	case *algebra.Reduced:
+		return distinct(e.eval(op.Operator, g))
+	case *algebra.Slice:
+		in := e.eval(op.Operator, g)
+		var i int
+		return func() (Solution, bool) {
+			for ; i < op.Offset; i++ {
+				if _, ok := in(); !ok {
+					return nil, false
+				}
+			}
+			if op.Limit != -1 && op.Offset+op.Limit <= i {
+				return nil, false
+			}
+			i++
+			return in()
+		}
+	default:
+		return fromSlice(nil)
+	}
+}
+
+// evalBGP matches the triple patterns one after the other, every pattern is matched using the indices of the graph.
+// Patterns are ordered so that the most selective patterns (with the most bound terms) are matched first.
+func (e *evaluator) evalBGP(b algebra.BGP, g graph) iterator {
+	it := fromSlice([]Solution{{}})
+	for _, t := range e.orderPatterns(b) {
+		it = e.match(t, g, it)
+	}
+	return it
+}
+
+// evalGraph evaluates the pattern against the named graph, or against every named graph if the name is a variable.
+func (e *evaluator) evalGraph(op *algebra.Graph) iterator {
+	name := e.resolve(op.Name, nil)
+	if name != nil {
+		for _, n := range e.named {
+			if g := e.dataset.Graph(n); g != nil && sameTerm(n, name) {
+				return e.eval(op.Operator, g)
+			}
+		}
+		return fromSlice(nil)
+	}
+	v := op.Name.(algebra.Var)
+	var solutions []Solution
+	for _, n := range e.named {
+		g := e.dataset.Graph(n)
+		if g == nil {
+			continue
+		}
+		for _, s := range collect(e.eval(op.Operator, g)) {
+			if m, ok := s[v]; ok && !sameTerm(m, n) {
+				continue
+			}
+			solutions = append(solutions, s.merge(Solution{v: n}))
+		}
+	}
+	return fromSlice(solutions)
+}
+
+// filter returns the solutions for which f returns true.
+func (e *evaluator) filter(in iterator, f func(s Solution) bool) iterator {
+	return func() (Solution, bool) {
+		for e.ctx.Err() == nil {
+			s, ok := in()
+			if !ok {
+				return nil, false
+			}
+			if f(s) {
+				return s, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// join returns the solutions that are returned by f for every solution of the iterator.
+func (e *evaluator) join(in iterator, f func(s Solution) []Solution) iterator {
+	var buffer []Solution
+	return func() (Solution, bool) {
+		for len(buffer) == 0 {
+			if e.ctx.Err() != nil {
+				return nil, false
+			}
+			s, ok := in()
+			if !ok {
+				return nil, false
+			}
+			buffer = f(s)
+		}
+		s := buffer[0]
+		buffer = buffer[1:]
+		return s, true
+	}
+}
+
+// match extends every solution of the iterator with the matches of the triple pattern.
+func (e *evaluator) match(t algebra.TriplePattern, g graph, in iterator) iterator {
+	return e.join(in, func(s Solution) []Solution {
+		var solutions []Solution
+		triples := g.FindAll(e.resolve(t.Subject, s), e.resolve(t.Predicate, s), e.resolve(t.Object, s))
+		for _, tr := range triples {
+			m := s
+			copied := false
+			ok := true
+			for i, n := range []rdf.Node{tr.Subject, tr.Predicate, tr.Object} {
+				v, isVar := [3]algebra.Term{t.Subject, t.Predicate, t.Object}[i].(algebra.Var)
+				if !isVar || e.env[v] != nil {
+					continue
+				}
+				if b, bound := m[v]; bound {
+					// The variable occurs multiple times in the pattern.
+					if !sameTerm(b, n) {
+						ok = false
+						break
+					}
+					continue
+				}
+				if !copied {
+					m, copied = m.merge(nil), true
+				}
+				m[v] = n
+			}
+			if ok {
+				solutions = append(solutions, m)
+			}
+		}
+		return solutions
+	})
+}
+
+// orderPatterns orders the triple patterns by the amount of terms that are bound when the pattern is matched.
+func (e *evaluator) orderPatterns(b algebra.BGP) []algebra.TriplePattern {
+	patterns := append([]algebra.TriplePattern(nil), b...)
+	bound := make(map[algebra.Var]bool)
+	for v := range e.env {
+		bound[v] = true
+	}
+	score := func(t algebra.TriplePattern) int {
+		var n int
+		for _, term := range []algebra.Term{t.Subject, t.Predicate, t.Object} {
+			if v, ok := term.(algebra.Var); !ok || bound[v] {
+				n++
+			}
+		}
+		return n
+	}
+	for i := range patterns {
+		best := i
+		for j := i + 1; j < len(patterns); j++ {
+			if score(patterns[best]) < score(patterns[j]) {
+				best = j
+			}
+		}
+		patterns[i], patterns[best] = patterns[best], patterns[i]
+		for _, term := range []algebra.Term{patterns[i].Subject, patterns[i].Predicate, patterns[i].Object} {
+			if v, ok := term.(algebra.Var); ok {
+				bound[v] = true
+			}
+		}
+	}
+	return patterns
+}
+
+// resolve returns the term the variable is bound to, nil if unbound.
+func (e *evaluator) resolve(t algebra.Term, s Solution) rdf.Node {
+	switch t := t.(type) {
+	case algebra.Constant:
+		return t.Node
+	case algebra.Var:
+		if n, ok := e.env[t]; ok {
+			return n
+		}
+		return s[t]
+	default:
+		return nil
+	}
+}
+
+// graph is the active graph of the evaluation, either a graph or a union of graphs of the dataset.
+type graph interface {
+	FindAll(s, p, o rdf.Node) []*rdf.Triple
+}
+
+// iterator returns the next solution, false if there are no more solutions.
+type iterator func() (Solution, bool)
+
+func collect(it iterator) []Solution {
+	var solutions []Solution
+	for {
+		s, ok := it()
+		if !ok {
+			return solutions
+		}
+		solutions = append(solutions, s)
+	}
+}
+
+// distinct removes duplicate solutions.
+func distinct(in iterator) iterator {
+	seen := make(map[string]bool)
+	return func() (Solution, bool) {
+		for {
+			s, ok := in()
+			if !ok {
+				return nil, false
+			}
+			if k := s.String(); !seen[k] {
+				seen[k] = true
+				return s, true
+			}
+		}
+	}
+}
+
+func fromSlice(solutions []Solution) iterator {
+	return func() (Solution, bool) {
+		if len(solutions) == 0 {
+			return nil, false
+		}
+		s := solutions[0]
+		solutions = solutions[1:]
+		return s, true
+	}
+}
+
+// validTriple returns true if the terms form a valid RDF triple.
+func validTriple(s, p, o rdf.Node) bool {
+	if s == nil || p == nil || o == nil {
+		return false
+	}
+	switch s.(type) {
+	case *rdf.IRIReference, *rdf.BlankNode:
+	default:
+		return false
+	}
+	_, ok := p.(*rdf.IRIReference)
+	return ok
+}
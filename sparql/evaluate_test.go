@@ -0,0 +1,420 @@
+package sparql_test
+
+import (
+	"context"
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const (
+	// curatedBase is the base IRI of the curated tests, documents are resolved relative to it.
+	curatedBase = "https://github.com/0x51-dev/rdf/sparql/testdata/curated/"
+	// suiteBase is the base IRI of the W3C test suite, documents are resolved relative to it.
+	suiteBase = "http://www.w3.org/2009/sparql/docs/tests/data-sparql11/"
+)
+
+//go:embed testdata/curated
+var curated embed.FS
+
+func ExampleExecute() {
+	g := rdf.NewGraph()
+	for i, name := range []string{"Alice", "Bob", "Carol"} {
+		s := &rdf.IRIReference{Value: fmt.Sprintf("http://example.org/%d", i)}
+		g.Add(s, &rdf.IRIReference{Value: "http://xmlns.com/foaf/0.1/name"}, &rdf.Literal{
+			Value:    name,
+			Datatype: rdf.XSDString,
+		})
+	}
+	result, _ := sparql.Execute(context.Background(), `PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+SELECT ?name WHERE { ?s foaf:name ?name FILTER(?name != "Bob") } ORDER BY DESC(?name)`, "", &rdf.Dataset{Default: g})
+	solutions, _ := result.Solutions.All()
+	for _, s := range solutions {
+		fmt.Println(s)
+	}
+	// Output:
+	// ([?name "Carol"])
+	// ([?name "Alice"])
+}
+
+func TestEvaluate_bnode(t *testing.T) {
+	result, err := sparql.Execute(context.Background(), `SELECT ?s (BNODE(?s) AS ?a) (BNODE(?s) AS ?b) (BNODE("x") AS ?c)
+{ VALUES ?s { "x" "y" } }`, "", rdf.NewDataset())
+	if err != nil {
+		t.Fatal(err)
+	}
+	solutions, err := result.Solutions.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solutions) != 2 {
+		t.Fatal(solutions)
+	}
+	x, y := solutions[0], solutions[1]
+	// The same string gives the same blank node within a solution, but not across solutions.
+	if x["a"] != x["b"] || x["a"] != x["c"] || y["a"] != y["b"] || y["a"] == y["c"] {
+		t.Error(x, y)
+	}
+	if x["a"] == y["a"] || x["c"] == y["c"] {
+		t.Error(x, y)
+	}
+}
+
+func TestEvaluate_canceled(t *testing.T) {
+	g := rdf.NewGraph()
+	p := &rdf.IRIReference{Value: "http://example.org/p"}
+	for i := 0; i < 10; i++ {
+		g.Add(&rdf.IRIReference{Value: fmt.Sprintf("http://example.org/%d", i)}, p, p)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	result, err := sparql.Execute(ctx, `SELECT * { ?a ?p ?o . ?b ?q ?r }`, "", &rdf.Dataset{Default: g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Solutions.Next(); !ok {
+		t.Fatal("expected a solution")
+	}
+	cancel()
+	if _, err := result.Solutions.All(); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+	d := &rdf.Dataset{Default: g}
+	if _, err := sparql.Execute(ctx, `ASK { ?s ?p ?o }`, "", d); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestEvaluate_construct(t *testing.T) {
+	g := rdf.NewGraph()
+	r := &rdf.IRIReference{Value: "http://example.org/r"}
+	g.Add(&rdf.BlankNode{Attribute: "_:b1"}, r, &rdf.Literal{Value: "x", Datatype: rdf.XSDString})
+	g.Add(&rdf.BlankNode{Attribute: "_:b2"}, r, &rdf.Literal{Value: "y", Datatype: rdf.XSDString})
+	result, err := sparql.Execute(context.Background(), `PREFIX : <http://example.org/>
+CONSTRUCT { ?s :p [ :q ?o ] } WHERE { ?s :r ?o }`, "", &rdf.Dataset{Default: g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(result.Graph); n != 4 {
+		t.Fatalf("expected 4 triples, got %d", n)
+	}
+	// The blank nodes of the template are fresh, they do not refer to blank nodes of the dataset.
+	labels := make(map[nt.Object]bool)
+	for _, triple := range result.Graph {
+		if triple.Predicate != nt.IRIReference("http://example.org/p") {
+			continue
+		}
+		if o := triple.Object; o == nt.BlankNode("b1") || o == nt.BlankNode("b2") || labels[o] {
+			t.Errorf("expected a fresh blank node for %s, got %s", triple.Subject, o)
+		}
+		labels[triple.Object] = true
+	}
+}
+
+// TestCurated runs the curated tests, they follow the layout of the W3C test suite but were written for this
+// repository.
+func TestCurated(t *testing.T) {
+	fsys, err := fs.Sub(curated, "testdata/curated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{
+		"aggregates",
+		"bind",
+		"bindings",
+		"cast",
+		"construct",
+		"dataset",
+		"exists",
+		"functions",
+		"grouping",
+		"negation",
+		"optional",
+		"project-expression",
+		"property-path",
+		"solution-seq",
+		"subquery",
+	} {
+		report := project.NewReport(ttl.IRI{Value: fmt.Sprintf("%s%s/manifest#", curatedBase, kind)})
+		runManifest(t, fsys, curatedBase, kind, report)
+	}
+}
+
+// TestSuite runs the query tests of the W3C test suite, it is skipped unless the suite is downloaded by
+// `make download`.
+func TestSuite(t *testing.T) {
+	if _, err := os.Stat("testdata/suite/aggregates/manifest.ttl"); err != nil {
+		t.Skip("W3C test suite not downloaded:", err)
+	}
+	fsys := os.DirFS("testdata/suite")
+	for _, kind := range []string{
+		"aggregates",
+		"bind",
+		"bindings",
+		"cast",
+		"construct",
+		"exists",
+		"functions",
+		"grouping",
+		"negation",
+		"project-expression",
+		"property-path",
+		"subquery",
+	} {
+		report := project.NewReport(ttl.IRI{Value: fmt.Sprintf("%s%s/manifest#", suiteBase, kind)})
+		runManifest(t, fsys, suiteBase, kind, report)
+		if os.Getenv("TEST_SUITE_REPORT") == "true" {
+			_ = os.WriteFile(fmt.Sprintf("testdata/suite/%s/report.ttl", kind), []byte(report.String()), 0644)
+		}
+	}
+}
+
+// runManifest runs the tests of the manifest of the kind in fsys, the documents are resolved against the base IRI.
+// Tests of an unknown type or with results in an unsupported format are reported as untested.
+func runManifest(t *testing.T, fsys fs.FS, base, kind string, report *project.Report) {
+	raw, err := fs.ReadFile(fsys, fmt.Sprintf("%s/manifest.ttl", kind))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := testsuite.LoadManifest(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		raw, err := fs.ReadFile(fsys, fmt.Sprintf("%s/%s", kind, e.Action))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cwd := fmt.Sprintf("%s%s/%s", base, kind, e.Action)
+		var query *algebra.Query
+		q, err := sparql.ParseQuery(string(raw))
+		if err == nil {
+			query, err = sparql.Translate(q, cwd)
+		}
+		switch e.Type {
+		case "mf:PositiveSyntaxTest11":
+			t.Run(e.Name, func(t *testing.T) {
+				if err != nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal(err)
+				}
+				report.AddTest(e.Name, testsuite.Passed)
+			})
+		case "mf:NegativeSyntaxTest11":
+			t.Run(e.Name, func(t *testing.T) {
+				if err == nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal("expected error")
+				}
+				report.AddTest(e.Name, testsuite.Passed)
+			})
+		case "mf:QueryEvaluationTest":
+			t.Run(e.Name, func(t *testing.T) {
+				switch path.Ext(e.Result) {
+				case ".srx", ".ttl":
+				default:
+					report.AddTest(e.Name, testsuite.Untested)
+					t.Skip("unsupported result format", e.Result)
+				}
+				if err != nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal(err)
+				}
+				d, err := loadDataset(fsys, base, kind, e, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := sparql.Evaluate(context.Background(), query, d)
+				if err != nil {
+					report.AddTest(e.Name, testsuite.Failed)
+					t.Fatal(err)
+				}
+				raw, err := fs.ReadFile(fsys, fmt.Sprintf("%s/%s", kind, e.Result))
+				if err != nil {
+					t.Fatal(err)
+				}
+				switch result.Type {
+				case algebra.Select:
+					solutions, err := result.Solutions.All()
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					expected, err := parseSRX(raw)
+					if err != nil {
+						t.Fatal(err)
+					}
+					ordered := strings.Contains(q.String(), "ORDER BY")
+					a, b := solutionStrings(solutions, ordered), solutionStrings(expected.solutions, ordered)
+					if a != b {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatalf("expected:\n%s\nactual:\n%s", b, a)
+					}
+				case algebra.Ask:
+					expected, err := parseSRX(raw)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if expected.boolean == nil || *expected.boolean != result.Boolean {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatalf("expected %v, got %v", *expected.boolean, result.Boolean)
+					}
+				default:
+					expected, err := loadTurtle(raw, base+kind+"/"+e.Result)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, ok := expected.Isomorphic(result.Graph); !ok {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatalf("expected:\n%s\nactual:\n%s", expected, result.Graph)
+					}
+				}
+				report.AddTest(e.Name, testsuite.Passed)
+			})
+		default:
+			t.Run(e.Name, func(t *testing.T) {
+				report.AddTest(e.Name, testsuite.Untested)
+				t.Skip("unknown test type", e.Type)
+			})
+		}
+	}
+
+	t.Log(kind, "total tests:", report.Len())
+}
+
+// loadDataset loads the default graph and the named graphs of the test. The graphs of the dataset clauses of the query
+// are loaded as named graphs, named by their IRI.
+func loadDataset(fsys fs.FS, base, kind string, e *testsuite.Test, query *algebra.Query) (*rdf.Dataset, error) {
+	d := rdf.NewDataset()
+	if e.Data != "" {
+		g, err := loadGraph(fsys, base, kind, e.Data)
+		if err != nil {
+			return nil, err
+		}
+		d.Default = g
+	}
+	names := append(append([]string{}, e.GraphData...), query.From...)
+	for _, iri := range query.FromNamed {
+		names = append(names, iri)
+	}
+	for _, name := range names {
+		file := strings.TrimPrefix(name, base+kind+"/")
+		g, err := loadGraph(fsys, base, kind, file)
+		if err != nil {
+			return nil, err
+		}
+		iri := &rdf.IRIReference{Value: base + kind + "/" + file}
+		d.CreateGraph(iri)
+		for _, t := range g.Triples() {
+			d.Add(t.Subject, t.Predicate, t.Object, iri)
+		}
+	}
+	return d, nil
+}
+
+func loadGraph(fsys fs.FS, base, kind, file string) (*rdf.Graph, error) {
+	raw, err := fs.ReadFile(fsys, fmt.Sprintf("%s/%s", kind, file))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := loadTurtle(raw, base+kind+"/"+file)
+	if err != nil {
+		return nil, err
+	}
+	return rdf.FromNTriples(doc), nil
+}
+
+func loadTurtle(raw []byte, cwd string) (nt.Document, error) {
+	doc, err := ttl.ParseDocument(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	return ttl.EvaluateDocument(doc, cwd)
+}
+
+// solutionStrings returns the solutions as a string, blank nodes are replaced by a placeholder. The solutions are
+// sorted if their order is not significant.
+func solutionStrings(solutions []sparql.Solution, ordered bool) string {
+	lines := make([]string, len(solutions))
+	for i, s := range solutions {
+		m := make(sparql.Solution, len(s))
+		for v, n := range s {
+			if _, ok := n.(*rdf.BlankNode); ok {
+				n = &rdf.BlankNode{Attribute: "_:b"}
+			}
+			m[v] = n
+		}
+		lines[i] = m.String()
+	}
+	if !ordered {
+		sort.Strings(lines)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type srxResult struct {
+	solutions []sparql.Solution
+	boolean   *bool
+}
+
+// parseSRX parses a document in the SPARQL Query Results XML Format.
+func parseSRX(raw []byte) (*srxResult, error) {
+	var doc struct {
+		Boolean *bool `xml:"boolean"`
+		Results []struct {
+			Bindings []struct {
+				Name    string  `xml:"name,attr"`
+				URI     *string `xml:"uri"`
+				BNode   *string `xml:"bnode"`
+				Literal *struct {
+					Value    string `xml:",chardata"`
+					Datatype string `xml:"datatype,attr"`
+					Language string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+				} `xml:"literal"`
+			} `xml:"binding"`
+		} `xml:"results>result"`
+	}
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	result := &srxResult{boolean: doc.Boolean}
+	for _, r := range doc.Results {
+		s := make(sparql.Solution)
+		for _, b := range r.Bindings {
+			switch {
+			case b.URI != nil:
+				s[algebra.Var(b.Name)] = &rdf.IRIReference{Value: *b.URI}
+			case b.BNode != nil:
+				s[algebra.Var(b.Name)] = &rdf.BlankNode{Attribute: "_:" + *b.BNode}
+			case b.Literal != nil:
+				l := &rdf.Literal{Value: b.Literal.Value, Datatype: rdf.DataType(b.Literal.Datatype)}
+				if b.Literal.Language != "" {
+					l.Language = b.Literal.Language
+					l.Datatype = rdf.XSDNSString
+				} else if l.Datatype == "" {
+					l.Datatype = rdf.XSDString
+				}
+				s[algebra.Var(b.Name)] = l
+			default:
+				return nil, fmt.Errorf("srx: invalid binding %s", b.Name)
+			}
+		}
+		result.solutions = append(result.solutions, s)
+	}
+	return result, nil
+}
@@ -0,0 +1,1292 @@
+package sparql
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"hash"
+	"math"
+	"math/big"
+	mrand "math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	xsdDateTime        rdf.DataType = rdf.XSD + "dateTime"
+	xsdDayTimeDuration rdf.DataType = rdf.XSD + "dayTimeDuration"
+	xsdFloat           rdf.DataType = rdf.XSD + "float"
+)
+
+var (
+	errType    = errors.New("type error")
+	errUnbound = errors.New("unbound variable")
+
+	// functions contains the built-in functions, operators and casts of which all arguments are evaluated.
+	functions map[string]func(e *evaluator, args []rdf.Node) (rdf.Node, error)
+
+	// integerTypes contains the datatypes derived from xsd:integer.
+	integerTypes = map[rdf.DataType]bool{
+		rdf.XSDInteger:                 true,
+		rdf.XSD + "nonPositiveInteger": true,
+		rdf.XSD + "negativeInteger":    true,
+		rdf.XSD + "long":               true,
+		rdf.XSD + "int":                true,
+		rdf.XSD + "short":              true,
+		rdf.XSD + "byte":               true,
+		rdf.XSD + "nonNegativeInteger": true,
+		rdf.XSD + "unsignedLong":       true,
+		rdf.XSD + "unsignedInt":        true,
+		rdf.XSD + "unsignedShort":      true,
+		rdf.XSD + "unsignedByte":       true,
+		rdf.XSD + "positiveInteger":    true,
+	}
+)
+
+func init() {
+	functions = map[string]func(e *evaluator, args []rdf.Node) (rdf.Node, error){
+		"!": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			b, err := ebv(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return boolean(!b), nil
+		},
+		"=": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			b, err := equal(args[0], args[1])
+			return boolean(b), err
+		},
+		"!=": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			b, err := equal(args[0], args[1])
+			return boolean(!b), err
+		},
+		"<":  comparison(func(n int) bool { return n < 0 }),
+		">":  comparison(func(n int) bool { return n > 0 }),
+		"<=": comparison(func(n int) bool { return n <= 0 }),
+		">=": comparison(func(n int) bool { return n >= 0 }),
+		"+":  arithmetic('+'),
+		"-":  arithmetic('-'),
+		"*":  arithmetic('*'),
+		"/":  arithmetic('/'),
+
+		"STR": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			switch n := args[0].(type) {
+			case *rdf.IRIReference:
+				return simple(n.Value), nil
+			case *rdf.Literal:
+				return simple(n.Value), nil
+			default:
+				return nil, errType
+			}
+		},
+		"LANG": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, ok := args[0].(*rdf.Literal)
+			if !ok {
+				return nil, errType
+			}
+			return simple(l.Language), nil
+		},
+		"LANGMATCHES": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			tag, err := simpleString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			r, err := simpleString(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if r == "*" {
+				return boolean(tag != ""), nil
+			}
+			tag, r = strings.ToLower(tag), strings.ToLower(r)
+			return boolean(tag == r || strings.HasPrefix(tag, r+"-")), nil
+		},
+		"DATATYPE": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, ok := args[0].(*rdf.Literal)
+			if !ok {
+				return nil, errType
+			}
+			return &rdf.IRIReference{Value: string(datatype(l))}, nil
+		},
+		"IRI": toIRI,
+		"URI": toIRI,
+		"BNODE": func(e *evaluator, args []rdf.Node) (rdf.Node, error) {
+			if len(args) == 0 {
+				return e.bnodes.bnode(), nil
+			}
+			str, err := simpleString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if e.bnodeStrings == nil {
+				e.bnodeStrings = make(map[string]*rdf.BlankNode)
+			}
+			if _, ok := e.bnodeStrings[str]; !ok {
+				e.bnodeStrings[str] = e.bnodes.bnode()
+			}
+			return e.bnodeStrings[str], nil
+		},
+		"RAND": func(_ *evaluator, _ []rdf.Node) (rdf.Node, error) {
+			return double(mrand.Float64()), nil
+		},
+		"ABS":   rounding(math.Abs, func(r *big.Rat) *big.Rat { return new(big.Rat).Abs(r) }),
+		"CEIL":  rounding(math.Ceil, ceil),
+		"FLOOR": rounding(math.Floor, floor),
+		"ROUND": rounding(func(f float64) float64 { return math.Floor(f + 0.5) }, func(r *big.Rat) *big.Rat {
+			return floor(new(big.Rat).Add(r, big.NewRat(1, 2)))
+		}),
+		"CONCAT": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			var s strings.Builder
+			var language string
+			for i, arg := range args {
+				l, err := stringLiteral(arg)
+				if err != nil {
+					return nil, err
+				}
+				if i == 0 {
+					language = l.Language
+				} else if language != l.Language {
+					language = ""
+				}
+				s.WriteString(l.Value)
+			}
+			return withLanguage(s.String(), language), nil
+		},
+		"SUBSTR": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, err := stringLiteral(args[0])
+			if err != nil {
+				return nil, err
+			}
+			start, err := toFloat(args[1])
+			if err != nil {
+				return nil, err
+			}
+			end := math.Inf(1)
+			if len(args) == 3 {
+				length, err := toFloat(args[2])
+				if err != nil {
+					return nil, err
+				}
+				end = math.Floor(start+0.5) + math.Floor(length+0.5)
+			}
+			start = math.Floor(start + 0.5)
+			var s strings.Builder
+			i := 1.
+			for _, r := range l.Value {
+				if start <= i && i < end {
+					s.WriteRune(r)
+				}
+				i++
+			}
+			return withLanguage(s.String(), l.Language), nil
+		},
+		"STRLEN": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, err := stringLiteral(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return integer(big.NewInt(int64(utf8.RuneCountInString(l.Value)))), nil
+		},
+		"REPLACE": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, err := stringLiteral(args[0])
+			if err != nil {
+				return nil, err
+			}
+			re, err := compile(args[1], args[3:])
+			if err != nil {
+				return nil, err
+			}
+			replacement, err := simpleString(args[2])
+			if err != nil {
+				return nil, err
+			}
+			if re.MatchString("") {
+				return nil, fmt.Errorf("pattern matches the empty string")
+			}
+			return withLanguage(re.ReplaceAllString(l.Value, replacementTemplate(replacement)), l.Language), nil
+		},
+		"UCASE": stringFunction(strings.ToUpper),
+		"LCASE": stringFunction(strings.ToLower),
+		"ENCODE_FOR_URI": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, err := stringLiteral(args[0])
+			if err != nil {
+				return nil, err
+			}
+			var s strings.Builder
+			for _, b := range []byte(l.Value) {
+				switch {
+				case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9',
+					b == '-', b == '_', b == '.', b == '~':
+					s.WriteByte(b)
+				default:
+					_, _ = fmt.Fprintf(&s, "%%%02X", b)
+				}
+			}
+			return simple(s.String()), nil
+		},
+		"CONTAINS":  stringPredicate(strings.Contains),
+		"STRSTARTS": stringPredicate(strings.HasPrefix),
+		"STRENDS":   stringPredicate(strings.HasSuffix),
+		"STRBEFORE": stringSplit(func(s, sep string) (string, bool) {
+			before, _, ok := strings.Cut(s, sep)
+			return before, ok
+		}),
+		"STRAFTER": stringSplit(func(s, sep string) (string, bool) {
+			_, after, ok := strings.Cut(s, sep)
+			return after, ok
+		}),
+		"YEAR":  dateTimeFunction(func(t dateTime) rdf.Node { return integer(big.NewInt(int64(t.Year()))) }),
+		"MONTH": dateTimeFunction(func(t dateTime) rdf.Node { return integer(big.NewInt(int64(t.Month()))) }),
+		"DAY":   dateTimeFunction(func(t dateTime) rdf.Node { return integer(big.NewInt(int64(t.Day()))) }),
+		"HOURS": dateTimeFunction(func(t dateTime) rdf.Node { return integer(big.NewInt(int64(t.Hour()))) }),
+		"MINUTES": dateTimeFunction(func(t dateTime) rdf.Node {
+			return integer(big.NewInt(int64(t.Minute())))
+		}),
+		"SECONDS": dateTimeFunction(func(t dateTime) rdf.Node {
+			r := new(big.Rat).SetFrac64(int64(t.Second())*1e9+int64(t.Nanosecond()), 1e9)
+			return decimal(r)
+		}),
+		"TIMEZONE": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			t, err := toDateTime(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if !t.timezone {
+				return nil, fmt.Errorf("no timezone: %s", algebra.FormatNode(args[0]))
+			}
+			_, offset := t.Zone()
+			return &rdf.Literal{Value: dayTimeDuration(offset), Datatype: xsdDayTimeDuration}, nil
+		},
+		"TZ": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			t, err := toDateTime(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return simple(t.tz()), nil
+		},
+		"NOW": func(e *evaluator, _ []rdf.Node) (rdf.Node, error) {
+			return &rdf.Literal{Value: e.now.Format(time.RFC3339Nano), Datatype: xsdDateTime}, nil
+		},
+		"UUID": func(_ *evaluator, _ []rdf.Node) (rdf.Node, error) {
+			return &rdf.IRIReference{Value: "urn:uuid:" + uuid()}, nil
+		},
+		"STRUUID": func(_ *evaluator, _ []rdf.Node) (rdf.Node, error) {
+			return simple(uuid()), nil
+		},
+		"MD5":    hashFunction(md5.New),
+		"SHA1":   hashFunction(sha1.New),
+		"SHA256": hashFunction(sha256.New),
+		"SHA384": hashFunction(sha512.New384),
+		"SHA512": hashFunction(sha512.New),
+		"STRLANG": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			s, err := simpleString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			language, err := simpleString(args[1])
+			if err != nil || language == "" {
+				return nil, errType
+			}
+			return withLanguage(s, language), nil
+		},
+		"STRDT": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			s, err := simpleString(args[0])
+			if err != nil {
+				return nil, err
+			}
+			dt, ok := args[1].(*rdf.IRIReference)
+			if !ok {
+				return nil, errType
+			}
+			return &rdf.Literal{Value: s, Datatype: rdf.DataType(dt.Value)}, nil
+		},
+		"SAMETERM": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			return boolean(sameTerm(args[0], args[1])), nil
+		},
+		"ISIRI": isIRI,
+		"ISURI": isIRI,
+		"ISBLANK": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			_, ok := args[0].(*rdf.BlankNode)
+			return boolean(ok), nil
+		},
+		"ISLITERAL": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			_, ok := args[0].(*rdf.Literal)
+			return boolean(ok), nil
+		},
+		"ISNUMERIC": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			_, err := toNumeric(args[0])
+			return boolean(err == nil), nil
+		},
+		"REGEX": func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+			l, err := stringLiteral(args[0])
+			if err != nil {
+				return nil, err
+			}
+			re, err := compile(args[1], args[2:])
+			if err != nil {
+				return nil, err
+			}
+			return boolean(re.MatchString(l.Value)), nil
+		},
+
+		// XPath constructor functions (casts).
+		string(rdf.XSDString): cast(func(n rdf.Node) (rdf.Node, error) {
+			switch n := n.(type) {
+			case *rdf.IRIReference:
+				return simple(n.Value), nil
+			case *rdf.Literal:
+				if n.Language != "" {
+					return nil, errType
+				}
+				return simple(n.Value), nil
+			default:
+				return nil, errType
+			}
+		}),
+		string(rdf.XSDBoolean): cast(func(n rdf.Node) (rdf.Node, error) {
+			l, ok := n.(*rdf.Literal)
+			if !ok {
+				return nil, errType
+			}
+			if v, err := toNumeric(l); err == nil {
+				return boolean(!v.isZero() && !v.isNaN()), nil
+			}
+			if !isString(l) && datatype(l) != rdf.XSDBoolean {
+				return nil, errType
+			}
+			switch strings.TrimSpace(l.Value) {
+			case "true", "1":
+				return boolean(true), nil
+			case "false", "0":
+				return boolean(false), nil
+			default:
+				return nil, errType
+			}
+		}),
+		string(rdf.XSDInteger): castNumeric(integerKind),
+		string(rdf.XSDDecimal): castNumeric(decimalKind),
+		string(xsdFloat):       castNumeric(floatKind),
+		string(rdf.XSDDouble):  castNumeric(doubleKind),
+		string(xsdDateTime): cast(func(n rdf.Node) (rdf.Node, error) {
+			l, ok := n.(*rdf.Literal)
+			if !ok || !isString(l) && datatype(l) != xsdDateTime {
+				return nil, errType
+			}
+			if _, err := parseDateTime(strings.TrimSpace(l.Value)); err != nil {
+				return nil, err
+			}
+			return &rdf.Literal{Value: strings.TrimSpace(l.Value), Datatype: xsdDateTime}, nil
+		}),
+	}
+}
+
+// call evaluates the function call, the arguments of the functional forms (e.g. IF and COALESCE) are only evaluated if
+// needed.
+func (e *evaluator) call(c *algebra.Call, s Solution, g graph) (rdf.Node, error) {
+	switch c.Function {
+	case "||", "&&":
+		// An error is only returned if the result is not determined by the other operand.
+		left, lerr := e.ebv(c.Args[0], s, g)
+		right, rerr := e.ebv(c.Args[1], s, g)
+		or := c.Function == "||"
+		switch {
+		case lerr == nil && left == or, rerr == nil && right == or:
+			return boolean(or), nil
+		case lerr != nil:
+			return nil, lerr
+		case rerr != nil:
+			return nil, rerr
+		default:
+			return boolean(!or), nil
+		}
+	case "BOUND":
+		_, err := e.expression(c.Args[0], s, g)
+		return boolean(err == nil), nil
+	case "IF":
+		b, err := e.ebv(c.Args[0], s, g)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return e.expression(c.Args[1], s, g)
+		}
+		return e.expression(c.Args[2], s, g)
+	case "COALESCE":
+		for _, a := range c.Args {
+			if n, err := e.expression(a, s, g); err == nil {
+				return n, nil
+			}
+		}
+		return nil, errUnbound
+	case "IN", "NOT IN":
+		n, err := e.expression(c.Args[0], s, g)
+		if err != nil {
+			return nil, err
+		}
+		var errs error
+		for _, a := range c.Args[1:] {
+			m, err := e.expression(a, s, g)
+			if err == nil {
+				var eq bool
+				if eq, err = equal(n, m); err == nil && eq {
+					return boolean(c.Function == "IN"), nil
+				}
+			}
+			if err != nil {
+				errs = err
+			}
+		}
+		if errs != nil {
+			return nil, errs
+		}
+		return boolean(c.Function == "NOT IN"), nil
+	}
+	f, ok := functions[c.Function]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", c.Function)
+	}
+	args := make([]rdf.Node, len(c.Args))
+	for i, a := range c.Args {
+		n, err := e.expression(a, s, g)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = n
+	}
+	if len(args) == 1 && (c.Function == "+" || c.Function == "-") {
+		// Unary plus and minus.
+		args = []rdf.Node{integer(new(big.Int)), args[0]}
+		if c.Function == "+" {
+			if _, err := toNumeric(args[1]); err != nil {
+				return nil, err
+			}
+			return args[1], nil
+		}
+	}
+	return f(e, args)
+}
+
+// ebv returns the effective boolean value of the expression.
+func (e *evaluator) ebv(x algebra.Expression, s Solution, g graph) (bool, error) {
+	n, err := e.expression(x, s, g)
+	if err != nil {
+		return false, err
+	}
+	return ebv(n)
+}
+
+// expression evaluates the expression, returns an error if the expression raises an error (e.g. a type error).
+func (e *evaluator) expression(x algebra.Expression, s Solution, g graph) (rdf.Node, error) {
+	switch x := x.(type) {
+	case algebra.Var:
+		if n, ok := s[x]; ok {
+			return n, nil
+		}
+		if n, ok := e.env[x]; ok {
+			return n, nil
+		}
+		return nil, errUnbound
+	case algebra.Constant:
+		return x.Node, nil
+	case *algebra.Call:
+		return e.call(x, s, g)
+	case *algebra.Exists:
+		child := *e
+		child.env = e.env.merge(s)
+		_, ok := child.eval(x.Pattern, g)()
+		return boolean(ok != x.Not), nil
+	default:
+		return nil, fmt.Errorf("unknown expression %T", x)
+	}
+}
+
+// test returns true if the effective boolean value of the expression is true, errors are false.
+func (e *evaluator) test(x algebra.Expression, s Solution, g graph) bool {
+	b, err := e.ebv(x, s, g)
+	return err == nil && b
+}
+
+// dateTime is a parsed xsd:dateTime, it keeps track of whether the lexical form contains a timezone.
+type dateTime struct {
+	time.Time
+	timezone bool
+}
+
+func (t dateTime) tz() string {
+	if !t.timezone {
+		return ""
+	}
+	_, offset := t.Zone()
+	if offset == 0 {
+		return "Z"
+	}
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+type numericKind int
+
+const (
+	integerKind numericKind = iota
+	decimalKind
+	floatKind
+	doubleKind
+)
+
+// numeric is a numeric value, integers and decimals are exact.
+type numeric struct {
+	kind numericKind
+	// r contains the value of integers and decimals.
+	r *big.Rat
+	// f contains the value of floats and doubles.
+	f float64
+}
+
+func (n numeric) float() float64 {
+	if n.kind < floatKind {
+		f, _ := n.r.Float64()
+		return f
+	}
+	return n.f
+}
+
+func (n numeric) isNaN() bool {
+	return floatKind <= n.kind && math.IsNaN(n.f)
+}
+
+func (n numeric) isZero() bool {
+	if n.kind < floatKind {
+		return n.r.Sign() == 0
+	}
+	return n.f == 0
+}
+
+func (n numeric) literal() rdf.Node {
+	switch n.kind {
+	case integerKind:
+		return integer(n.r.Num())
+	case decimalKind:
+		return decimal(n.r)
+	case floatKind:
+		return &rdf.Literal{Value: formatDouble(n.f, 32), Datatype: xsdFloat}
+	default:
+		return double(n.f)
+	}
+}
+
+// to returns the value promoted to the given kind.
+func (n numeric) to(kind numericKind) numeric {
+	if kind <= decimalKind || floatKind <= n.kind {
+		return numeric{kind: kind, r: n.r, f: n.f}
+	}
+	f := n.float()
+	if kind == floatKind {
+		f = float64(float32(f))
+	}
+	return numeric{kind: kind, f: f}
+}
+
+func arithmetic(op rune) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		a, err := toNumeric(args[0])
+		if err != nil {
+			return nil, err
+		}
+		b, err := toNumeric(args[1])
+		if err != nil {
+			return nil, err
+		}
+		n, err := calculate(op, a, b)
+		if err != nil {
+			return nil, err
+		}
+		return n.literal(), nil
+	}
+}
+
+func boolean(b bool) *rdf.Literal {
+	return &rdf.Literal{Value: strconv.FormatBool(b), Datatype: rdf.XSDBoolean}
+}
+
+// calculate applies the arithmetic operator to both (promoted) values, the division of integers is a decimal.
+func calculate(op rune, a, b numeric) (numeric, error) {
+	kind := max(a.kind, b.kind)
+	if op == '/' && kind == integerKind {
+		kind = decimalKind
+	}
+	a, b = a.to(kind), b.to(kind)
+	if kind <= decimalKind {
+		r := new(big.Rat)
+		switch op {
+		case '+':
+			r.Add(a.r, b.r)
+		case '-':
+			r.Sub(a.r, b.r)
+		case '*':
+			r.Mul(a.r, b.r)
+		case '/':
+			if b.r.Sign() == 0 {
+				return numeric{}, fmt.Errorf("division by zero")
+			}
+			r.Quo(a.r, b.r)
+		}
+		return numeric{kind: kind, r: r}, nil
+	}
+	var f float64
+	switch op {
+	case '+':
+		f = a.f + b.f
+	case '-':
+		f = a.f - b.f
+	case '*':
+		f = a.f * b.f
+	case '/':
+		f = a.f / b.f
+	}
+	if kind == floatKind {
+		f = float64(float32(f))
+	}
+	return numeric{kind: kind, f: f}, nil
+}
+
+func cast(f func(n rdf.Node) (rdf.Node, error)) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("cast expects one argument")
+		}
+		return f(args[0])
+	}
+}
+
+// castNumeric casts numerics, booleans and strings (in the lexical form of the target type) to the numeric type.
+func castNumeric(kind numericKind) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return cast(func(n rdf.Node) (rdf.Node, error) {
+		l, ok := n.(*rdf.Literal)
+		if !ok {
+			return nil, errType
+		}
+		v, err := toNumeric(l)
+		switch {
+		case err == nil:
+			if kind <= decimalKind && v.kind >= floatKind {
+				if math.IsNaN(v.f) || math.IsInf(v.f, 0) {
+					return nil, errType
+				}
+				r, _ := new(big.Rat).SetString(strconv.FormatFloat(v.f, 'f', -1, 64))
+				v = numeric{kind: decimalKind, r: r}
+			}
+			if kind == integerKind && v.kind != integerKind {
+				// Truncate towards zero.
+				v = numeric{kind: integerKind, r: new(big.Rat).SetInt(new(big.Int).Quo(v.r.Num(), v.r.Denom()))}
+			}
+			return v.to(kind).literal(), nil
+		case datatype(l) == rdf.XSDBoolean:
+			if l.Value == "true" || l.Value == "1" {
+				return numeric{kind: integerKind, r: big.NewRat(1, 1)}.to(kind).literal(), nil
+			}
+			return numeric{kind: integerKind, r: new(big.Rat)}.to(kind).literal(), nil
+		case isString(l):
+			v, err := parseNumeric(strings.TrimSpace(l.Value), kind)
+			if err != nil {
+				return nil, err
+			}
+			return v.literal(), nil
+		default:
+			return nil, errType
+		}
+	})
+}
+
+func ceil(r *big.Rat) *big.Rat {
+	return new(big.Rat).Neg(floor(new(big.Rat).Neg(r)))
+}
+
+// compare compares the values of both terms, returns an error if they can not be compared.
+func compare(a, b rdf.Node) (int, error) {
+	la, ok := a.(*rdf.Literal)
+	if !ok {
+		return 0, errType
+	}
+	lb, ok := b.(*rdf.Literal)
+	if !ok {
+		return 0, errType
+	}
+	if na, err := toNumeric(la); err == nil {
+		nb, err := toNumeric(lb)
+		if err != nil {
+			return 0, errType
+		}
+		if na.isNaN() || nb.isNaN() {
+			return 0, fmt.Errorf("NaN is not comparable")
+		}
+		kind := max(na.kind, nb.kind)
+		na, nb = na.to(kind), nb.to(kind)
+		if kind <= decimalKind {
+			return na.r.Cmp(nb.r), nil
+		}
+		switch {
+		case na.f < nb.f:
+			return -1, nil
+		case na.f > nb.f:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+	switch {
+	case isString(la) && isString(lb):
+		return strings.Compare(la.Value, lb.Value), nil
+	case datatype(la) == rdf.XSDBoolean && datatype(lb) == rdf.XSDBoolean:
+		ba, err := parseBoolean(la.Value)
+		if err != nil {
+			return 0, err
+		}
+		bb, err := parseBoolean(lb.Value)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case ba == bb:
+			return 0, nil
+		case bb:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	case datatype(la) == xsdDateTime && datatype(lb) == xsdDateTime:
+		ta, err := parseDateTime(la.Value)
+		if err != nil {
+			return 0, err
+		}
+		tb, err := parseDateTime(lb.Value)
+		if err != nil {
+			return 0, err
+		}
+		if ta.timezone != tb.timezone {
+			return 0, fmt.Errorf("indeterminate comparison of date times")
+		}
+		return ta.Compare(tb.Time), nil
+	default:
+		return 0, errType
+	}
+}
+
+func comparison(f func(n int) bool) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		n, err := compare(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		return boolean(f(n)), nil
+	}
+}
+
+// compile compiles the pattern of REGEX and REPLACE, the flags are "i", "s", "m", "x" and "q".
+func compile(pattern rdf.Node, flags []rdf.Node) (*regexp.Regexp, error) {
+	p, err := simpleString(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var f string
+	if len(flags) != 0 {
+		if f, err = simpleString(flags[0]); err != nil {
+			return nil, err
+		}
+	}
+	var goFlags string
+	for _, c := range f {
+		switch c {
+		case 'i', 's', 'm':
+			goFlags += string(c)
+		case 'x':
+			p = strings.Join(strings.Fields(p), "")
+		case 'q':
+			p = regexp.QuoteMeta(p)
+		default:
+			return nil, fmt.Errorf("invalid regex flag %q", c)
+		}
+	}
+	if goFlags != "" {
+		p = fmt.Sprintf("(?%s)%s", goFlags, p)
+	}
+	return regexp.Compile(p)
+}
+
+func dateTimeFunction(f func(t dateTime) rdf.Node) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		t, err := toDateTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(t), nil
+	}
+}
+
+// datatype returns the datatype of the literal, simple literals have datatype xsd:string.
+func datatype(l *rdf.Literal) rdf.DataType {
+	switch {
+	case l.Direction != "":
+		return rdf.XSDNSDirString
+	case l.Language != "":
+		return rdf.XSDNSString
+	case l.Datatype == "":
+		return rdf.XSDString
+	default:
+		return l.Datatype
+	}
+}
+
+// dayTimeDuration formats the offset (in seconds) as xsd:dayTimeDuration, e.g. "-PT5H" or "PT0S".
+func dayTimeDuration(offset int) string {
+	if offset == 0 {
+		return "PT0S"
+	}
+	var s string
+	if offset < 0 {
+		s, offset = "-", -offset
+	}
+	s += "PT"
+	if h := offset / 3600; h != 0 {
+		s += fmt.Sprintf("%dH", h)
+	}
+	if m := offset % 3600 / 60; m != 0 {
+		s += fmt.Sprintf("%dM", m)
+	}
+	return s
+}
+
+func decimal(r *big.Rat) *rdf.Literal {
+	s := r.FloatString(20)
+	if r.IsInt() {
+		s = r.FloatString(1)
+	} else {
+		s = strings.TrimRight(s, "0")
+	}
+	return &rdf.Literal{Value: s, Datatype: rdf.XSDDecimal}
+}
+
+func double(f float64) *rdf.Literal {
+	return &rdf.Literal{Value: formatDouble(f, 64), Datatype: rdf.XSDDouble}
+}
+
+// ebv returns the effective boolean value of the term.
+func ebv(n rdf.Node) (bool, error) {
+	l, ok := n.(*rdf.Literal)
+	if !ok {
+		return false, errType
+	}
+	switch {
+	case datatype(l) == rdf.XSDBoolean:
+		b, err := parseBoolean(l.Value)
+		return err == nil && b, nil
+	case isString(l):
+		return l.Value != "", nil
+	case isNumericType(datatype(l)):
+		v, err := toNumeric(l)
+		return err == nil && !v.isZero() && !v.isNaN(), nil
+	default:
+		return false, errType
+	}
+}
+
+// equal compares both terms with the "=" operator: literals are compared by value if both datatypes are known,
+// otherwise the terms must be the same.
+func equal(a, b rdf.Node) (bool, error) {
+	if n, err := compare(a, b); err == nil {
+		return n == 0, nil
+	}
+	if sameTerm(a, b) {
+		return true, nil
+	}
+	la, ok := a.(*rdf.Literal)
+	if !ok {
+		return false, nil
+	}
+	lb, ok := b.(*rdf.Literal)
+	if !ok {
+		return false, nil
+	}
+	if la.Language != "" || lb.Language != "" {
+		return false, nil
+	}
+	if isNumericType(datatype(la)) && isNumericType(datatype(lb)) {
+		// One of both has an invalid lexical form, or one of both is NaN.
+		_, errA := toNumeric(la)
+		_, errB := toNumeric(lb)
+		if errA == nil && errB == nil {
+			return false, nil
+		}
+		return false, errType
+	}
+	if known(datatype(la)) && known(datatype(lb)) && datatype(la) != datatype(lb) {
+		return false, nil
+	}
+	return false, errType
+}
+
+func floor(r *big.Rat) *big.Rat {
+	q := new(big.Int).Div(r.Num(), r.Denom()) // Euclidean division, rounds towards negative infinity.
+	return new(big.Rat).SetInt(q)
+}
+
+// formatDouble formats the value in the canonical form of xsd:double, e.g. "1.0E0".
+func formatDouble(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	}
+	s := strconv.FormatFloat(f, 'E', -1, bitSize)
+	mantissa, exponent, _ := strings.Cut(s, "E")
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+	e, _ := strconv.Atoi(exponent)
+	return fmt.Sprintf("%sE%d", mantissa, e)
+}
+
+func hashFunction(h func() hash.Hash) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		s, err := simpleString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		hash := h()
+		hash.Write([]byte(s))
+		return simple(fmt.Sprintf("%x", hash.Sum(nil))), nil
+	}
+}
+
+func integer(i *big.Int) *rdf.Literal {
+	return &rdf.Literal{Value: i.String(), Datatype: rdf.XSDInteger}
+}
+
+func toIRI(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+	switch n := args[0].(type) {
+	case *rdf.IRIReference:
+		return n, nil
+	case *rdf.Literal:
+		if !isString(n) || n.Language != "" {
+			return nil, errType
+		}
+		return &rdf.IRIReference{Value: n.Value}, nil
+	default:
+		return nil, errType
+	}
+}
+
+func isIRI(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+	_, ok := args[0].(*rdf.IRIReference)
+	return boolean(ok), nil
+}
+
+func isNumericType(dt rdf.DataType) bool {
+	return integerTypes[dt] || dt == rdf.XSDDecimal || dt == xsdFloat || dt == rdf.XSDDouble
+}
+
+// isString returns true if the literal is a simple literal or has datatype xsd:string.
+func isString(l *rdf.Literal) bool {
+	return datatype(l) == rdf.XSDString
+}
+
+// known returns true if the values of the datatype can be compared.
+func known(dt rdf.DataType) bool {
+	return isNumericType(dt) || dt == rdf.XSDString || dt == rdf.XSDBoolean || dt == xsdDateTime ||
+		dt == rdf.XSDNSString || dt == rdf.XSDNSDirString
+}
+
+// order compares both terms according to the ordering of ORDER BY: unbound < blank nodes < IRIs < literals < triple
+// terms. Literals that can not be compared by value are ordered by their lexical form, datatype and language.
+func order(a, b rdf.Node) int {
+	rank := func(n rdf.Node) int {
+		switch n.(type) {
+		case nil:
+			return 0
+		case *rdf.BlankNode:
+			return 1
+		case *rdf.IRIReference:
+			return 2
+		case *rdf.Literal:
+			return 3
+		default:
+			return 4
+		}
+	}
+	if ra, rb := rank(a), rank(b); ra != rb || ra == 0 {
+		return ra - rb
+	}
+	if n, err := compare(a, b); err == nil && n != 0 {
+		return n
+	}
+	if n := strings.Compare(a.GetValue(), b.GetValue()); n != 0 {
+		return n
+	}
+	return strings.Compare(algebra.FormatNode(a), algebra.FormatNode(b))
+}
+
+func parseBoolean(s string) (bool, error) {
+	switch strings.TrimSpace(s) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", s)
+	}
+}
+
+// parseDateTime parses the lexical form of xsd:dateTime, e.g. "2010-06-21T11:28:01Z".
+func parseDateTime(s string) (dateTime, error) {
+	if t, err := time.Parse("2006-01-02T15:04:05.999999999Z07:00", s); err == nil {
+		return dateTime{Time: t, timezone: true}, nil
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.999999999", s)
+	if err != nil {
+		return dateTime{}, fmt.Errorf("invalid date time %q", s)
+	}
+	return dateTime{Time: t}, nil
+}
+
+// parseNumeric parses the lexical form of a numeric datatype.
+func parseNumeric(s string, kind numericKind) (numeric, error) {
+	switch kind {
+	case integerKind:
+		i, ok := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+		if !ok {
+			return numeric{}, fmt.Errorf("invalid integer %q", s)
+		}
+		return numeric{kind: kind, r: new(big.Rat).SetInt(i)}, nil
+	case decimalKind:
+		if strings.ContainsAny(s, "eE/") {
+			return numeric{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return numeric{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		return numeric{kind: kind, r: r}, nil
+	default:
+		var f float64
+		switch s {
+		case "INF", "+INF":
+			f = math.Inf(1)
+		case "-INF":
+			f = math.Inf(-1)
+		case "NaN":
+			f = math.NaN()
+		default:
+			if strings.ContainsAny(s, "xXpP_") || strings.EqualFold(s, "inf") || strings.EqualFold(s, "nan") {
+				return numeric{}, fmt.Errorf("invalid double %q", s)
+			}
+			var err error
+			if f, err = strconv.ParseFloat(s, 64); err != nil {
+				return numeric{}, fmt.Errorf("invalid double %q", s)
+			}
+		}
+		if kind == floatKind {
+			f = float64(float32(f))
+		}
+		return numeric{kind: kind, f: f}, nil
+	}
+}
+
+// replacementTemplate converts the replacement string of REPLACE ("$1") to the template of regexp ("${1}").
+func replacementTemplate(s string) string {
+	var t strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] == '$' {
+				t.WriteString("$$")
+			} else {
+				t.WriteByte(s[i])
+			}
+		case c == '$':
+			j := i + 1
+			for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+				j++
+			}
+			t.WriteString("${" + s[i+1:j] + "}")
+			i = j - 1
+		default:
+			t.WriteByte(c)
+		}
+	}
+	return t.String()
+}
+
+func rounding(f func(float64) float64, r func(*big.Rat) *big.Rat) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		n, err := toNumeric(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if n.kind <= decimalKind {
+			return numeric{kind: n.kind, r: r(n.r)}.literal(), nil
+		}
+		return numeric{kind: n.kind, f: f(n.f)}.literal(), nil
+	}
+}
+
+// sameTerm returns true if both terms are the same RDF term.
+func sameTerm(a, b rdf.Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if la, ok := a.(*rdf.Literal); ok {
+		lb, ok := b.(*rdf.Literal)
+		return ok && la.Value == lb.Value && datatype(la) == datatype(lb) && la.Language == lb.Language &&
+			la.Direction == lb.Direction
+	}
+	if ta, ok := a.(*rdf.TripleTerm); ok {
+		tb, ok := b.(*rdf.TripleTerm)
+		return ok && sameTerm(ta.Subject, tb.Subject) && sameTerm(ta.Predicate, tb.Predicate) &&
+			sameTerm(ta.Object, tb.Object)
+	}
+	return a.Equal(b)
+}
+
+func simple(s string) *rdf.Literal {
+	return &rdf.Literal{Value: s, Datatype: rdf.XSDString}
+}
+
+// simpleString returns the value of a simple literal or a literal with datatype xsd:string.
+func simpleString(n rdf.Node) (string, error) {
+	l, ok := n.(*rdf.Literal)
+	if !ok || !isString(l) {
+		return "", errType
+	}
+	return l.Value, nil
+}
+
+// stringLiteral returns the literal if it is a string literal, i.e. a simple literal, a literal with datatype
+// xsd:string or a language-tagged string.
+func stringLiteral(n rdf.Node) (*rdf.Literal, error) {
+	l, ok := n.(*rdf.Literal)
+	if !ok || !isString(l) && l.Language == "" {
+		return nil, errType
+	}
+	return l, nil
+}
+
+// stringArguments returns both string literals if they are argument compatible, i.e. the second argument is a simple
+// literal or has the same language tag as the first.
+func stringArguments(a, b rdf.Node) (*rdf.Literal, *rdf.Literal, error) {
+	la, err := stringLiteral(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	lb, err := stringLiteral(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lb.Language != "" && lb.Language != la.Language {
+		return nil, nil, errType
+	}
+	return la, lb, nil
+}
+
+func stringFunction(f func(string) string) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		l, err := stringLiteral(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return withLanguage(f(l.Value), l.Language), nil
+	}
+}
+
+func stringPredicate(f func(s, t string) bool) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		a, b, err := stringArguments(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		return boolean(f(a.Value, b.Value)), nil
+	}
+}
+
+// stringSplit returns the part of the string returned by f, or an empty simple literal if there is no match.
+func stringSplit(f func(s, sep string) (string, bool)) func(*evaluator, []rdf.Node) (rdf.Node, error) {
+	return func(_ *evaluator, args []rdf.Node) (rdf.Node, error) {
+		a, b, err := stringArguments(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		s, ok := f(a.Value, b.Value)
+		if !ok {
+			return simple(""), nil
+		}
+		return withLanguage(s, a.Language), nil
+	}
+}
+
+func toDateTime(n rdf.Node) (dateTime, error) {
+	l, ok := n.(*rdf.Literal)
+	if !ok || datatype(l) != xsdDateTime {
+		return dateTime{}, errType
+	}
+	return parseDateTime(l.Value)
+}
+
+func toFloat(n rdf.Node) (float64, error) {
+	v, err := toNumeric(n)
+	if err != nil {
+		return 0, err
+	}
+	return v.float(), nil
+}
+
+// toNumeric returns the value of a numeric literal, returns an error if the term is not a numeric literal or if the
+// lexical form is invalid.
+func toNumeric(n rdf.Node) (numeric, error) {
+	l, ok := n.(*rdf.Literal)
+	if !ok {
+		return numeric{}, errType
+	}
+	dt := datatype(l)
+	switch {
+	case integerTypes[dt]:
+		return parseNumeric(l.Value, integerKind)
+	case dt == rdf.XSDDecimal:
+		return parseNumeric(l.Value, decimalKind)
+	case dt == xsdFloat:
+		return parseNumeric(l.Value, floatKind)
+	case dt == rdf.XSDDouble:
+		return parseNumeric(l.Value, doubleKind)
+	default:
+		return numeric{}, errType
+	}
+}
+
+// uuid returns a random (version 4) UUID.
+func uuid() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func withLanguage(s, language string) *rdf.Literal {
+	if language == "" {
+		return simple(s)
+	}
+	return &rdf.Literal{Value: s, Datatype: rdf.XSDNSString, Language: language}
+}
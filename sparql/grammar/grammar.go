@@ -0,0 +1,840 @@
+package grammar
+
+import (
+	nt "github.com/0x51-dev/rdf/ntriples/grammar"
+	ttl "github.com/0x51-dev/rdf/turtle/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+)
+
+var (
+	QueryUnit = op.Capture{
+		Name: "Query",
+		Value: op.And{
+			Prologue, ttl.WSPLNC,
+			op.Or{SelectQuery, ConstructQuery, DescribeQuery, AskQuery},
+			ttl.WSPLNC,
+			op.Optional{Value: op.And{ValuesClause, ttl.WSPLNC}},
+		},
+	}
+	// UpdateUnit is a sequence of update operations, separated by ";". Every operation can be preceded by a prologue.
+	UpdateUnit = op.Capture{
+		Name: "Update",
+		Value: op.And{
+			Prologue, ttl.WSPLNC,
+			op.Optional{Value: op.And{
+				Update1,
+				op.ZeroOrMore{Value: op.And{ttl.WSPLNC, ';', Prologue, ttl.WSPLNC, Update1}},
+				op.Optional{Value: op.And{ttl.WSPLNC, ';', Prologue}},
+			}},
+			ttl.WSPLNC,
+		},
+	}
+	Prologue = op.ZeroOrMore{Value: op.And{
+		ttl.WSPLNC,
+		op.Or{ttl.SparqlBase, ttl.SparqlPrefix},
+	}}
+	SelectQuery = op.Capture{
+		Name: "SelectQuery",
+		Value: op.And{
+			SelectClause,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DatasetClause}},
+			ttl.WSPLNC, WhereClause,
+			SolutionModifier,
+		},
+	}
+	SubSelect = op.Capture{
+		Name: "SubSelect",
+		Value: op.And{
+			SelectClause,
+			ttl.WSPLNC, WhereClause,
+			SolutionModifier,
+			op.Optional{Value: op.And{ttl.WSPLNC, ValuesClause}},
+		},
+	}
+	SelectClause = op.Capture{
+		Name: "SelectClause",
+		Value: op.And{
+			keyword("SELECT"),
+			op.Optional{Value: op.And{
+				ttl.WSPLNC,
+				op.Capture{Name: "Modifier", Value: op.Or{keyword("DISTINCT"), keyword("REDUCED")}},
+			}},
+			ttl.WSPLNC,
+			op.Or{
+				Star,
+				op.OneOrMore{Value: op.And{ttl.WSPLNC, op.Or{Var, Projection}}},
+			},
+		},
+	}
+	// Projection is an expression that is bound to a variable, e.g. "(?x + 1 AS ?y)".
+	Projection = op.Capture{
+		Name: "Projection",
+		Value: op.And{
+			'(', ttl.WSPLNC,
+			Expression, ttl.WSPLNC,
+			keyword("AS"), ttl.WSPLNC,
+			Var, ttl.WSPLNC,
+			')',
+		},
+	}
+	ConstructQuery = op.Capture{
+		Name: "ConstructQuery",
+		Value: op.And{
+			keyword("CONSTRUCT"),
+			ttl.WSPLNC,
+			op.Or{
+				op.And{
+					ConstructTemplate,
+					op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DatasetClause}},
+					ttl.WSPLNC, WhereClause,
+					SolutionModifier,
+				},
+				op.And{
+					op.ZeroOrMore{Value: op.And{DatasetClause, ttl.WSPLNC}},
+					keyword("WHERE"), ttl.WSPLNC,
+					op.Capture{
+						Name:  "ConstructWhere",
+						Value: op.And{'{', ttl.WSPLNC, op.Optional{Value: TriplesTemplate}, ttl.WSPLNC, '}'},
+					},
+					SolutionModifier,
+				},
+			},
+		},
+	}
+	DescribeQuery = op.Capture{
+		Name: "DescribeQuery",
+		Value: op.And{
+			keyword("DESCRIBE"),
+			ttl.WSPLNC,
+			op.Or{
+				Star,
+				op.OneOrMore{Value: op.And{ttl.WSPLNC, VarOrIRI}},
+			},
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DatasetClause}},
+			op.Optional{Value: op.And{ttl.WSPLNC, WhereClause}},
+			SolutionModifier,
+		},
+	}
+	AskQuery = op.Capture{
+		Name: "AskQuery",
+		Value: op.And{
+			keyword("ASK"),
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DatasetClause}},
+			ttl.WSPLNC, WhereClause,
+			SolutionModifier,
+		},
+	}
+	DatasetClause = op.And{
+		keyword("FROM"), ttl.WSPLNC,
+		op.Or{
+			op.Capture{
+				Name:  "NamedGraphClause",
+				Value: op.And{keyword("NAMED"), ttl.WSPLNC, ttl.IRI},
+			},
+			op.Capture{
+				Name:  "DefaultGraphClause",
+				Value: ttl.IRI,
+			},
+		},
+	}
+	WhereClause = op.And{
+		op.Optional{Value: op.And{keyword("WHERE"), ttl.WSPLNC}},
+		GroupGraphPattern,
+	}
+	SolutionModifier = op.And{
+		op.Optional{Value: op.And{ttl.WSPLNC, GroupClause}},
+		op.Optional{Value: op.And{ttl.WSPLNC, HavingClause}},
+		op.Optional{Value: op.And{ttl.WSPLNC, OrderClause}},
+		op.Optional{Value: op.And{ttl.WSPLNC, LimitOffsetClauses}},
+	}
+	GroupClause = op.Capture{
+		Name: "GroupClause",
+		Value: op.And{
+			keyword("GROUP"), ttl.WSPLNC, keyword("BY"),
+			op.OneOrMore{Value: op.And{ttl.WSPLNC, GroupCondition}},
+		},
+	}
+	GroupCondition = op.Capture{
+		Name: "GroupCondition",
+		Value: op.Or{
+			BuiltInCall,
+			FunctionCall,
+			op.And{
+				'(', ttl.WSPLNC,
+				Expression,
+				op.Optional{Value: op.And{ttl.WSPLNC, keyword("AS"), ttl.WSPLNC, Var}},
+				ttl.WSPLNC, ')',
+			},
+			Var,
+		},
+	}
+	HavingClause = op.Capture{
+		Name: "HavingClause",
+		Value: op.And{
+			keyword("HAVING"),
+			op.OneOrMore{Value: op.And{ttl.WSPLNC, Constraint}},
+		},
+	}
+	OrderClause = op.Capture{
+		Name: "OrderClause",
+		Value: op.And{
+			keyword("ORDER"), ttl.WSPLNC, keyword("BY"),
+			op.OneOrMore{Value: op.And{ttl.WSPLNC, OrderCondition}},
+		},
+	}
+	OrderCondition = op.Capture{
+		Name: "OrderCondition",
+		Value: op.Or{
+			op.And{
+				op.Capture{Name: "Order", Value: op.Or{keyword("ASC"), keyword("DESC")}},
+				ttl.WSPLNC,
+				BrackettedExpression,
+			},
+			Constraint,
+			Var,
+		},
+	}
+	LimitOffsetClauses = op.Or{
+		op.And{LimitClause, op.Optional{Value: op.And{ttl.WSPLNC, OffsetClause}}},
+		op.And{OffsetClause, op.Optional{Value: op.And{ttl.WSPLNC, LimitClause}}},
+	}
+	LimitClause = op.Capture{
+		Name:  "LimitClause",
+		Value: op.And{keyword("LIMIT"), ttl.WSPLNC, INTEGER},
+	}
+	OffsetClause = op.Capture{
+		Name:  "OffsetClause",
+		Value: op.And{keyword("OFFSET"), ttl.WSPLNC, INTEGER},
+	}
+	ValuesClause = op.Capture{
+		Name:  "ValuesClause",
+		Value: op.And{keyword("VALUES"), ttl.WSPLNC, DataBlock},
+	}
+	Update1 = op.Or{
+		Load, Clear, Drop, Add, Move, Copy, Create,
+		InsertData, DeleteData, DeleteWhere, Modify,
+	}
+	Load = op.Capture{
+		Name: "Load",
+		Value: op.And{
+			keyword("LOAD"), op.Optional{Value: op.And{ttl.WSPLNC, Silent}},
+			ttl.WSPLNC, ttl.IRI,
+			op.Optional{Value: op.And{ttl.WSPLNC, keyword("INTO"), ttl.WSPLNC, GraphRef}},
+		},
+	}
+	Clear = op.Capture{
+		Name:  "Clear",
+		Value: op.And{keyword("CLEAR"), op.Optional{Value: op.And{ttl.WSPLNC, Silent}}, ttl.WSPLNC, GraphRefAll},
+	}
+	Drop = op.Capture{
+		Name:  "Drop",
+		Value: op.And{keyword("DROP"), op.Optional{Value: op.And{ttl.WSPLNC, Silent}}, ttl.WSPLNC, GraphRefAll},
+	}
+	Create = op.Capture{
+		Name:  "Create",
+		Value: op.And{keyword("CREATE"), op.Optional{Value: op.And{ttl.WSPLNC, Silent}}, ttl.WSPLNC, GraphRef},
+	}
+	Add        = transfer("Add", "ADD")
+	Move       = transfer("Move", "MOVE")
+	Copy       = transfer("Copy", "COPY")
+	InsertData = op.Capture{
+		Name:  "InsertData",
+		Value: op.And{keyword("INSERT"), ttl.WSPLNC, keyword("DATA"), ttl.WSPLNC, QuadPattern},
+	}
+	DeleteData = op.Capture{
+		Name:  "DeleteData",
+		Value: op.And{keyword("DELETE"), ttl.WSPLNC, keyword("DATA"), ttl.WSPLNC, QuadPattern},
+	}
+	DeleteWhere = op.Capture{
+		Name:  "DeleteWhere",
+		Value: op.And{keyword("DELETE"), ttl.WSPLNC, keyword("WHERE"), ttl.WSPLNC, QuadPattern},
+	}
+	Modify = op.Capture{
+		Name: "Modify",
+		Value: op.And{
+			op.Optional{Value: op.And{
+				op.Capture{Name: "With", Value: op.And{keyword("WITH"), ttl.WSPLNC, ttl.IRI}},
+				ttl.WSPLNC,
+			}},
+			op.Or{
+				op.And{DeleteClause, op.Optional{Value: op.And{ttl.WSPLNC, InsertClause}}},
+				InsertClause,
+			},
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, UsingClause}},
+			ttl.WSPLNC, keyword("WHERE"), ttl.WSPLNC, GroupGraphPattern,
+		},
+	}
+	DeleteClause = op.Capture{
+		Name:  "DeleteClause",
+		Value: op.And{keyword("DELETE"), ttl.WSPLNC, QuadPattern},
+	}
+	InsertClause = op.Capture{
+		Name:  "InsertClause",
+		Value: op.And{keyword("INSERT"), ttl.WSPLNC, QuadPattern},
+	}
+	UsingClause = op.And{
+		keyword("USING"), ttl.WSPLNC,
+		op.Or{
+			op.Capture{
+				Name:  "NamedGraphClause",
+				Value: op.And{keyword("NAMED"), ttl.WSPLNC, ttl.IRI},
+			},
+			op.Capture{
+				Name:  "DefaultGraphClause",
+				Value: ttl.IRI,
+			},
+		},
+	}
+	GraphOrDefault = op.Or{
+		op.Capture{Name: "Default", Value: keyword("DEFAULT")},
+		op.And{op.Optional{Value: op.And{keyword("GRAPH"), ttl.WSPLNC}}, ttl.IRI},
+	}
+	GraphRef    = op.And{keyword("GRAPH"), ttl.WSPLNC, ttl.IRI}
+	GraphRefAll = op.Or{
+		GraphRef,
+		op.Capture{Name: "Default", Value: keyword("DEFAULT")},
+		op.Capture{Name: "Named", Value: keyword("NAMED")},
+		op.Capture{Name: "All", Value: keyword("ALL")},
+	}
+	// QuadPattern contains the triples of the default graph and the triples of named graphs, e.g.
+	// "{ :s :p :o . GRAPH :g { :s :p :o } }".
+	QuadPattern = op.Capture{
+		Name: "Quads",
+		Value: op.And{
+			'{', ttl.WSPLNC,
+			op.Optional{Value: TriplesTemplate},
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, QuadsNotTriples,
+				op.Optional{Value: op.And{ttl.WSPLNC, '.'}},
+				op.Optional{Value: op.And{ttl.WSPLNC, TriplesTemplate}},
+			}},
+			ttl.WSPLNC, '}',
+		},
+	}
+	QuadsNotTriples = op.Capture{
+		Name: "QuadsNotTriples",
+		Value: op.And{
+			keyword("GRAPH"), ttl.WSPLNC, VarOrIRI, ttl.WSPLNC,
+			'{', ttl.WSPLNC, op.Optional{Value: TriplesTemplate}, ttl.WSPLNC, '}',
+		},
+	}
+	Silent          = op.Capture{Name: "Silent", Value: keyword("SILENT")}
+	TriplesTemplate = op.And{
+		TriplesSameSubject,
+		op.ZeroOrMore{Value: op.And{ttl.WSPLNC, '.', ttl.WSPLNC, TriplesSameSubject}},
+		op.Optional{Value: op.And{ttl.WSPLNC, '.'}},
+	}
+	ConstructTemplate = op.Capture{
+		Name:  "ConstructTemplate",
+		Value: op.And{'{', ttl.WSPLNC, op.Optional{Value: TriplesTemplate}, ttl.WSPLNC, '}'},
+	}
+	GroupGraphPattern = op.Capture{
+		Name: "GroupGraphPattern",
+		Value: op.And{
+			'{', ttl.WSPLNC,
+			op.Or{op.Reference{Name: "SubSelect"}, GroupGraphPatternSub},
+			ttl.WSPLNC, '}',
+		},
+	}
+	GroupGraphPatternSub = op.And{
+		op.Optional{Value: TriplesBlock},
+		op.ZeroOrMore{Value: op.And{
+			ttl.WSPLNC, GraphPatternNotTriples,
+			ttl.WSPLNC, op.Optional{Value: '.'},
+			ttl.WSPLNC, op.Optional{Value: TriplesBlock},
+		}},
+	}
+	TriplesBlock = op.Capture{
+		Name: "TriplesBlock",
+		Value: op.And{
+			TriplesSameSubjectPath,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, '.', ttl.WSPLNC, TriplesSameSubjectPath}},
+			op.Optional{Value: op.And{ttl.WSPLNC, '.'}},
+		},
+	}
+	GraphPatternNotTriples = op.Or{
+		GroupOrUnionGraphPattern,
+		OptionalGraphPattern,
+		MinusGraphPattern,
+		GraphGraphPattern,
+		Filter,
+		Bind,
+		InlineData,
+	}
+	OptionalGraphPattern = op.Capture{
+		Name:  "OptionalGraphPattern",
+		Value: op.And{keyword("OPTIONAL"), ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"}},
+	}
+	GraphGraphPattern = op.Capture{
+		Name:  "GraphGraphPattern",
+		Value: op.And{keyword("GRAPH"), ttl.WSPLNC, VarOrIRI, ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"}},
+	}
+	MinusGraphPattern = op.Capture{
+		Name:  "MinusGraphPattern",
+		Value: op.And{keyword("MINUS"), ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"}},
+	}
+	GroupOrUnionGraphPattern = op.Capture{
+		Name: "GroupOrUnionGraphPattern",
+		Value: op.And{
+			op.Reference{Name: "GroupGraphPattern"},
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, keyword("UNION"),
+				ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"},
+			}},
+		},
+	}
+	Filter = op.Capture{
+		Name:  "Filter",
+		Value: op.And{keyword("FILTER"), ttl.WSPLNC, Constraint},
+	}
+	Constraint = op.Or{BrackettedExpression, BuiltInCall, FunctionCall}
+	Bind       = op.Capture{
+		Name: "Bind",
+		Value: op.And{
+			keyword("BIND"), ttl.WSPLNC,
+			'(', ttl.WSPLNC,
+			Expression, ttl.WSPLNC,
+			keyword("AS"), ttl.WSPLNC,
+			Var, ttl.WSPLNC,
+			')',
+		},
+	}
+	InlineData = op.Capture{
+		Name:  "InlineData",
+		Value: op.And{keyword("VALUES"), ttl.WSPLNC, DataBlock},
+	}
+	DataBlock = op.Or{InlineDataOneVar, InlineDataFull}
+	// InlineDataOneVar is the short form of VALUES with a single variable, e.g. "VALUES ?x { :a :b }".
+	InlineDataOneVar = op.Capture{
+		Name: "InlineDataOneVar",
+		Value: op.And{
+			Var, ttl.WSPLNC,
+			'{',
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DataBlockValue}},
+			ttl.WSPLNC, '}',
+		},
+	}
+	InlineDataFull = op.Capture{
+		Name: "InlineDataFull",
+		Value: op.And{
+			op.Capture{
+				Name:  "Vars",
+				Value: op.And{'(', op.ZeroOrMore{Value: op.And{ttl.WSPLNC, Var}}, ttl.WSPLNC, ')'},
+			},
+			ttl.WSPLNC,
+			'{',
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC,
+				op.Capture{
+					Name:  "DataBlockRow",
+					Value: op.And{'(', op.ZeroOrMore{Value: op.And{ttl.WSPLNC, DataBlockValue}}, ttl.WSPLNC, ')'},
+				},
+			}},
+			ttl.WSPLNC, '}',
+		},
+	}
+	DataBlockValue = op.Or{
+		ttl.IRI,
+		ttl.Literal,
+		op.Capture{Name: "Undef", Value: keyword("UNDEF")},
+	}
+	TriplesSameSubject = op.Capture{
+		Name: "TriplesSameSubject",
+		Value: op.Or{
+			op.And{VarOrTerm, ttl.WSPLNC, PropertyList},
+			op.And{TriplesNode, op.Optional{Value: op.And{ttl.WSPLNC, PropertyList}}},
+		},
+	}
+	PropertyList = op.Capture{
+		Name: "PropertyList",
+		Value: op.And{
+			PredicateObject,
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, ';',
+				op.Optional{Value: op.And{ttl.WSPLNC, PredicateObject}},
+			}},
+		},
+	}
+	PredicateObject = op.Capture{
+		Name:  "PredicateObject",
+		Value: op.And{Verb, ttl.WSPLNC, ObjectList},
+	}
+	Verb = op.Capture{
+		Name:  "Verb",
+		Value: op.Or{Var, ttl.IRI, A},
+	}
+	ObjectList = op.Capture{
+		Name: "ObjectList",
+		Value: op.And{
+			Object,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, ',', ttl.WSPLNC, Object}},
+		},
+	}
+	Object = op.Capture{
+		Name: "Object",
+		Value: op.Or{
+			VarOrTerm,
+			op.Reference{Name: "Collection"},
+			op.Reference{Name: "BlankNodePropertyList"},
+		},
+	}
+	TriplesNode           = op.Or{Collection, BlankNodePropertyList}
+	BlankNodePropertyList = op.Capture{
+		Name:  "BlankNodePropertyList",
+		Value: op.And{'[', ttl.WSPLNC, PropertyList, ttl.WSPLNC, ']'},
+	}
+	Collection = op.Capture{
+		Name: "Collection",
+		Value: op.And{
+			'(',
+			op.OneOrMore{Value: op.And{ttl.WSPLNC, op.Reference{Name: "Object"}}},
+			ttl.WSPLNC, ')',
+		},
+	}
+	// The rules of triple patterns that allow property paths (WHERE clauses) result in the same captures as the rules
+	// of templates, only the verb can also be a path.
+	TriplesSameSubjectPath = op.Capture{
+		Name: "TriplesSameSubject",
+		Value: op.Or{
+			op.And{VarOrTerm, ttl.WSPLNC, PropertyListPath},
+			op.And{TriplesNodePath, op.Optional{Value: op.And{ttl.WSPLNC, PropertyListPath}}},
+		},
+	}
+	PropertyListPath = op.Capture{
+		Name: "PropertyList",
+		Value: op.And{
+			PredicateObjectPath,
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, ';',
+				op.Optional{Value: op.And{ttl.WSPLNC, PredicateObjectPath}},
+			}},
+		},
+	}
+	PredicateObjectPath = op.Capture{
+		Name:  "PredicateObject",
+		Value: op.And{VerbPath, ttl.WSPLNC, ObjectListPath},
+	}
+	VerbPath = op.Capture{
+		Name:  "Verb",
+		Value: op.Or{Var, Path},
+	}
+	ObjectListPath = op.Capture{
+		Name: "ObjectList",
+		Value: op.And{
+			ObjectPath,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, ',', ttl.WSPLNC, ObjectPath}},
+		},
+	}
+	ObjectPath = op.Capture{
+		Name: "Object",
+		Value: op.Or{
+			VarOrTerm,
+			op.Reference{Name: "CollectionPath"},
+			op.Reference{Name: "BlankNodePropertyListPath"},
+		},
+	}
+	TriplesNodePath           = op.Or{CollectionPath, BlankNodePropertyListPath}
+	BlankNodePropertyListPath = op.Capture{
+		Name:  "BlankNodePropertyList",
+		Value: op.And{'[', ttl.WSPLNC, PropertyListPath, ttl.WSPLNC, ']'},
+	}
+	CollectionPath = op.Capture{
+		Name: "Collection",
+		Value: op.And{
+			'(',
+			op.OneOrMore{Value: op.And{ttl.WSPLNC, op.Reference{Name: "ObjectPath"}}},
+			ttl.WSPLNC, ')',
+		},
+	}
+	// Path is a reference to PathAlternative to allow recursion, e.g. "(:p | :q)*".
+	Path            = op.Reference{Name: "Path"}
+	PathAlternative = op.Capture{
+		Name: "PathAlternative",
+		Value: op.And{
+			PathSequence,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, '|', ttl.WSPLNC, PathSequence}},
+		},
+	}
+	PathSequence = op.Capture{
+		Name: "PathSequence",
+		Value: op.And{
+			PathEltOrInverse,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, '/', ttl.WSPLNC, PathEltOrInverse}},
+		},
+	}
+	PathEltOrInverse = op.Or{
+		op.Capture{Name: "PathInverse", Value: op.And{'^', ttl.WSPLNC, PathElt}},
+		PathElt,
+	}
+	PathElt = op.Capture{
+		Name:  "PathElt",
+		Value: op.And{PathPrimary, op.Optional{Value: PathMod}},
+	}
+	// PathMod can not be preceded by whitespace and "?" can not be followed by a variable name, e.g. ":p ?x" is a
+	// predicate followed by a variable.
+	PathMod = op.Capture{
+		Name:  "PathMod",
+		Value: op.Or{'*', '+', op.And{'?', op.Not{Value: op.Or{nt.PN_CHARS_U, op.RuneRange{Min: '0', Max: '9'}}}}},
+	}
+	PathPrimary = op.Or{
+		ttl.IRI,
+		A,
+		op.And{'!', ttl.WSPLNC, PathNegatedPropertySet},
+		op.And{'(', ttl.WSPLNC, Path, ttl.WSPLNC, ')'},
+	}
+	PathNegatedPropertySet = op.Capture{
+		Name: "PathNegatedPropertySet",
+		Value: op.Or{
+			PathOneInPropertySet,
+			op.And{
+				'(', ttl.WSPLNC,
+				op.Optional{Value: op.And{
+					PathOneInPropertySet,
+					op.ZeroOrMore{Value: op.And{ttl.WSPLNC, '|', ttl.WSPLNC, PathOneInPropertySet}},
+				}},
+				ttl.WSPLNC, ')',
+			},
+		},
+	}
+	PathOneInPropertySet = op.Or{
+		ttl.IRI,
+		A,
+		op.Capture{Name: "PathInverse", Value: op.And{'^', ttl.WSPLNC, op.Or{ttl.IRI, A}}},
+	}
+	VarOrTerm = op.Or{Var, GraphTerm}
+	VarOrIRI  = op.Or{Var, ttl.IRI}
+	GraphTerm = op.Or{ttl.IRI, ttl.Literal, ttl.BlankNode, NIL}
+	Var       = op.Capture{
+		Name:  "Var",
+		Value: op.And{op.Or{'?', '$'}, VARNAME},
+	}
+	// Expression is the entry point of all expressions, it is a reference to ConditionalOrExpression to allow
+	// recursion.
+	Expression              = op.Reference{Name: "Expression"}
+	ConditionalOrExpression = op.Capture{
+		Name: "ConditionalOrExpression",
+		Value: op.And{
+			ConditionalAndExpression,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, "||", ttl.WSPLNC, ConditionalAndExpression}},
+		},
+	}
+	ConditionalAndExpression = op.Capture{
+		Name: "ConditionalAndExpression",
+		Value: op.And{
+			RelationalExpression,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, "&&", ttl.WSPLNC, RelationalExpression}},
+		},
+	}
+	RelationalExpression = op.Capture{
+		Name: "RelationalExpression",
+		Value: op.And{
+			AdditiveExpression,
+			op.Optional{Value: op.And{
+				ttl.WSPLNC,
+				op.Or{
+					op.And{
+						op.Capture{Name: "Operator", Value: op.Or{"=", "!=", "<=", ">=", "<", ">"}},
+						ttl.WSPLNC, AdditiveExpression,
+					},
+					op.And{
+						op.Capture{
+							Name:  "Operator",
+							Value: op.Or{keyword("IN"), op.And{keyword("NOT"), ttl.WSPLNC, keyword("IN")}},
+						},
+						ttl.WSPLNC, ExpressionList,
+					},
+				},
+			}},
+		},
+	}
+	AdditiveExpression = op.Capture{
+		Name: "AdditiveExpression",
+		Value: op.And{
+			MultiplicativeExpression,
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC,
+				op.Capture{Name: "Operator", Value: op.Or{'+', '-'}},
+				ttl.WSPLNC, MultiplicativeExpression,
+			}},
+		},
+	}
+	MultiplicativeExpression = op.Capture{
+		Name: "MultiplicativeExpression",
+		Value: op.And{
+			UnaryExpression,
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC,
+				op.Capture{Name: "Operator", Value: op.Or{'*', '/'}},
+				ttl.WSPLNC, UnaryExpression,
+			}},
+		},
+	}
+	UnaryExpression = op.Or{
+		PrimaryExpression,
+		op.Capture{
+			Name: "UnaryExpression",
+			Value: op.And{
+				op.Capture{Name: "Operator", Value: op.Or{'!', '+', '-'}},
+				ttl.WSPLNC, PrimaryExpression,
+			},
+		},
+	}
+	PrimaryExpression = op.Or{
+		BrackettedExpression,
+		BuiltInCall,
+		IRIOrFunction,
+		ttl.Literal,
+		Var,
+	}
+	BrackettedExpression = op.And{'(', ttl.WSPLNC, Expression, ttl.WSPLNC, ')'}
+	// BuiltInCall is a call of a built-in function, the number of arguments is validated when the call is parsed.
+	BuiltInCall = op.Or{
+		Aggregate,
+		ExistsFunc,
+		NotExistsFunc,
+		op.Capture{
+			Name: "BuiltInCall",
+			Value: op.And{
+				op.Capture{Name: "Name", Value: BuiltInName},
+				ttl.WSPLNC, ExpressionList,
+			},
+		},
+	}
+	BuiltInName = op.Or{
+		keyword("STR"), keyword("LANG"), keyword("LANGMATCHES"), keyword("DATATYPE"), keyword("BOUND"),
+		keyword("IRI"), keyword("URI"), keyword("BNODE"), keyword("RAND"), keyword("ABS"), keyword("CEIL"),
+		keyword("FLOOR"), keyword("ROUND"), keyword("CONCAT"), keyword("SUBSTR"), keyword("STRLEN"),
+		keyword("REPLACE"), keyword("UCASE"), keyword("LCASE"), keyword("ENCODE_FOR_URI"), keyword("CONTAINS"),
+		keyword("STRSTARTS"), keyword("STRENDS"), keyword("STRBEFORE"), keyword("STRAFTER"), keyword("YEAR"),
+		keyword("MONTH"), keyword("DAY"), keyword("HOURS"), keyword("MINUTES"), keyword("SECONDS"),
+		keyword("TIMEZONE"), keyword("TZ"), keyword("NOW"), keyword("UUID"), keyword("STRUUID"), keyword("MD5"),
+		keyword("SHA1"), keyword("SHA256"), keyword("SHA384"), keyword("SHA512"), keyword("COALESCE"),
+		keyword("IF"), keyword("STRLANG"), keyword("STRDT"), keyword("sameTerm"), keyword("isIRI"),
+		keyword("isURI"), keyword("isBLANK"), keyword("isLITERAL"), keyword("isNUMERIC"), keyword("REGEX"),
+	}
+	ExistsFunc = op.Capture{
+		Name:  "ExistsFunc",
+		Value: op.And{keyword("EXISTS"), ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"}},
+	}
+	NotExistsFunc = op.Capture{
+		Name: "NotExistsFunc",
+		Value: op.And{
+			keyword("NOT"), ttl.WSPLNC, keyword("EXISTS"),
+			ttl.WSPLNC, op.Reference{Name: "GroupGraphPattern"},
+		},
+	}
+	Aggregate = op.Capture{
+		Name: "Aggregate",
+		Value: op.And{
+			op.Capture{
+				Name: "Name",
+				Value: op.Or{
+					keyword("COUNT"), keyword("SUM"), keyword("MIN"), keyword("MAX"), keyword("AVG"),
+					keyword("SAMPLE"), keyword("GROUP_CONCAT"),
+				},
+			},
+			ttl.WSPLNC, '(', ttl.WSPLNC,
+			op.Optional{Value: op.And{Distinct, ttl.WSPLNC}},
+			op.Or{Star, Expression},
+			op.Optional{Value: op.And{
+				ttl.WSPLNC, ';',
+				ttl.WSPLNC, keyword("SEPARATOR"),
+				ttl.WSPLNC, '=',
+				ttl.WSPLNC, op.Capture{Name: "Separator", Value: ttl.String},
+			}},
+			ttl.WSPLNC, ')',
+		},
+	}
+	FunctionCall = op.Capture{
+		Name:  "FunctionCall",
+		Value: op.And{ttl.IRI, ttl.WSPLNC, ArgList},
+	}
+	// IRIOrFunction is either an IRI or, if followed by an argument list, a function call.
+	IRIOrFunction = op.Or{FunctionCall, ttl.IRI}
+	ArgList       = op.Capture{
+		Name: "ArgList",
+		Value: op.Or{
+			NIL,
+			op.And{
+				'(', ttl.WSPLNC,
+				op.Optional{Value: op.And{Distinct, ttl.WSPLNC}},
+				Expression,
+				op.ZeroOrMore{Value: op.And{ttl.WSPLNC, ',', ttl.WSPLNC, Expression}},
+				ttl.WSPLNC, ')',
+			},
+		},
+	}
+	ExpressionList = op.Capture{
+		Name: "ExpressionList",
+		Value: op.Or{
+			NIL,
+			op.And{
+				'(', ttl.WSPLNC,
+				Expression,
+				op.ZeroOrMore{Value: op.And{ttl.WSPLNC, ',', ttl.WSPLNC, Expression}},
+				ttl.WSPLNC, ')',
+			},
+		},
+	}
+	// A is the keyword "a", an abbreviation of rdf:type.
+	A        = op.Capture{Name: "a", Value: op.And{'a', boundary}}
+	Distinct = op.Capture{Name: "Distinct", Value: keyword("DISTINCT")}
+	Star     = op.Capture{Name: "Star", Value: '*'}
+	NIL      = op.Capture{
+		Name:  "Nil",
+		Value: op.And{'(', ttl.WSPLNC, ')'},
+	}
+	INTEGER = op.Capture{
+		Name:  "Integer",
+		Value: op.OneOrMore{Value: op.RuneRange{Min: '0', Max: '9'}},
+	}
+	VARNAME = op.And{
+		op.Or{nt.PN_CHARS_U, op.RuneRange{Min: '0', Max: '9'}},
+		op.ZeroOrMore{Value: op.Or{
+			nt.PN_CHARS_U,
+			op.RuneRange{Min: '0', Max: '9'},
+			rune(0x00B7),
+			op.RuneRange{Min: 0x0300, Max: 0x036F},
+			op.RuneRange{Min: 0x203F, Max: 0x2040},
+		}},
+	}
+	// boundary makes sure that a keyword is not the prefix of a longer name, e.g. "ASKED" or "in:x".
+	boundary = op.Not{Value: op.Or{nt.PN_CHARS, ':'}}
+)
+
+func NewParser(input []rune) (*parser.Parser, error) {
+	p, err := parser.New(input)
+	if err != nil {
+		return nil, err
+	}
+	p.Rules["BlankNodePropertyList"] = BlankNodePropertyList
+	p.Rules["BlankNodePropertyListPath"] = BlankNodePropertyListPath
+	p.Rules["Collection"] = Collection
+	p.Rules["CollectionPath"] = CollectionPath
+	p.Rules["Expression"] = ConditionalOrExpression
+	p.Rules["GroupGraphPattern"] = GroupGraphPattern
+	p.Rules["Object"] = Object
+	p.Rules["ObjectPath"] = ObjectPath
+	p.Rules["Path"] = PathAlternative
+	p.Rules["SubSelect"] = SubSelect
+	return p, nil
+}
+
+// transfer matches ADD, MOVE or COPY, which transfer the triples of a graph to another graph.
+func transfer(name, kw string) op.Capture {
+	return op.Capture{
+		Name: name,
+		Value: op.And{
+			keyword(kw), op.Optional{Value: op.And{ttl.WSPLNC, Silent}},
+			ttl.WSPLNC, GraphOrDefault,
+			ttl.WSPLNC, keyword("TO"),
+			ttl.WSPLNC, GraphOrDefault,
+		},
+	}
+}
+
+// keyword matches the given keyword, ignoring case.
+func keyword(s string) op.And {
+	return op.And{ttl.CaseInsensitiveString(s), boundary}
+}
@@ -0,0 +1,59 @@
+package grammar_test
+
+import (
+	. "github.com/0x51-dev/rdf/sparql/grammar"
+	"github.com/0x51-dev/upeg/parser/op"
+	"testing"
+)
+
+func TestQueryUnit(t *testing.T) {
+	for _, test := range []string{
+		"SELECT * WHERE { ?s ?p ?o }",
+		"select ?s { ?s ?p ?o . }",
+		`PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+SELECT ?name ?mbox
+WHERE {
+  ?x foaf:name ?name .
+  ?x foaf:mbox ?mbox .
+}`,
+		`BASE <http://example.org/>
+PREFIX : <http://example.org/ns#>
+SELECT DISTINCT ?x (STR(?y) AS ?z)
+FROM <g1> FROM NAMED <g2>
+WHERE {
+  # A comment.
+  ?x :p ?y ; a :C , :D .
+  OPTIONAL { ?x :q ?w FILTER(?w > 1 && ?w != 2) }
+  { ?x :r ?v } UNION { ?x :s ?v } UNION { ?x :t ?v }
+  MINUS { ?x :u "a"@en }
+  GRAPH ?g { ?x :v [ :w ( 1 2.5 3e1 ) ] }
+  BIND (?y + 1 AS ?b)
+  FILTER NOT EXISTS { ?x :z ?y }
+  VALUES (?x ?y) { (:a UNDEF) (:b "c"^^:d) }
+}
+ORDER BY DESC(?x) ?y
+LIMIT 10 OFFSET 5`,
+		`PREFIX : <http://example.org/>
+SELECT ?x (COUNT(DISTINCT ?y) AS ?c) (GROUP_CONCAT(?y ; separator=", ") AS ?s)
+WHERE { ?x :p ?y }
+GROUP BY ?x (LCASE(?y) AS ?l)
+HAVING (COUNT(*) > 1)`,
+		`SELECT ?x WHERE { { SELECT ?x WHERE { ?x ?p ?o } LIMIT 1 } }`,
+		`PREFIX : <http://example.org/>
+CONSTRUCT { ?s :q ?o } WHERE { ?s :p ?o FILTER (?o IN (1, 2) || ?o NOT IN (3)) }`,
+		`CONSTRUCT WHERE { ?s ?p ?o }`,
+		`ASK { ?s ?p ?o FILTER regex(?o, "^a", "i") }`,
+		`DESCRIBE ?x <http://example.org/> WHERE { ?x ?p ?o }`,
+		`DESCRIBE *`,
+		`PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+SELECT * { ?s ?p ?o FILTER(xsd:integer(?o) = -1 * +?o / 2) } VALUES ?s { <a> <b> }`,
+	} {
+		p, err := NewParser([]rune(test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(op.And{QueryUnit, op.EOF{}}); err != nil {
+			t.Error(test, err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package sparql
+
+import (
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+)
+
+// matchPath returns the extensions of the solution with the matches of the path pattern. If the subject or object is
+// bound, the path is evaluated starting from that end, so that only the reachable part of the graph is visited.
+func (e *evaluator) matchPath(p *algebra.Path, g graph, s Solution) []Solution {
+	subject, object := e.resolve(p.Subject, s), e.resolve(p.Object, s)
+	var solutions []Solution
+	bind := func(t algebra.Term, n rdf.Node, s Solution) Solution {
+		if v, ok := t.(algebra.Var); ok && e.env[v] == nil {
+			return s.merge(Solution{v: n})
+		}
+		return s
+	}
+	switch {
+	case subject != nil:
+		for _, n := range e.reachable(p.Path, g, subject, false) {
+			if object == nil {
+				solutions = append(solutions, bind(p.Object, n, s))
+			} else if sameTerm(n, object) {
+				solutions = append(solutions, s)
+			}
+		}
+	case object != nil:
+		for _, n := range e.reachable(p.Path, g, object, true) {
+			solutions = append(solutions, bind(p.Subject, n, s))
+		}
+	default:
+		same := p.Subject == p.Object
+		for _, start := range nodes(g) {
+			for _, n := range e.reachable(p.Path, g, start, false) {
+				if same && !sameTerm(start, n) {
+					continue
+				}
+				solutions = append(solutions, bind(p.Object, n, bind(p.Subject, start, s)))
+			}
+		}
+	}
+	return solutions
+}
+
+// reachable returns the nodes that are reachable from the start node by following the path, or the nodes from which
+// the start node is reachable if inverse is true. The result of the closures (*, + and ?) contains every node only
+// once, cycles are only followed once.
+func (e *evaluator) reachable(p algebra.PathExpression, g graph, start rdf.Node, inverse bool) []rdf.Node {
+	switch p := p.(type) {
+	case algebra.Link:
+		return step(g, start, inverse, p.Predicate, nil)
+	case algebra.NegatedPropertySet:
+		return step(g, start, inverse, nil, func(n rdf.Node) bool {
+			for _, i := range p {
+				if sameTerm(n, i) {
+					return false
+				}
+			}
+			return true
+		})
+	case *algebra.Inverse:
+		return e.reachable(p.Path, g, start, !inverse)
+	case *algebra.Sequence:
+		first, second := p.Left, p.Right
+		if inverse {
+			first, second = second, first
+		}
+		var result []rdf.Node
+		for _, n := range e.reachable(first, g, start, inverse) {
+			result = append(result, e.reachable(second, g, n, inverse)...)
+		}
+		return result
+	case *algebra.Alternative:
+		return append(e.reachable(p.Left, g, start, inverse), e.reachable(p.Right, g, start, inverse)...)
+	case *algebra.ZeroOrOne:
+		return e.closure(p.Path, g, []rdf.Node{start}, inverse, false)
+	case *algebra.ZeroOrMore:
+		return e.closure(p.Path, g, []rdf.Node{start}, inverse, true)
+	case *algebra.OneOrMore:
+		return e.closure(p.Path, g, e.reachable(p.Path, g, start, inverse), inverse, true)
+	default:
+		return nil
+	}
+}
+
+// closure returns the given nodes and the nodes that are reachable from them, by following the path once or
+// (transitively) if repeat is true. Every node is visited once.
+func (e *evaluator) closure(p algebra.PathExpression, g graph, from []rdf.Node, inverse, repeat bool) []rdf.Node {
+	var result []rdf.Node
+	seen := make(map[string]bool)
+	add := func(ns []rdf.Node) []rdf.Node {
+		var added []rdf.Node
+		for _, n := range ns {
+			if k := algebra.FormatNode(n); !seen[k] {
+				seen[k] = true
+				added = append(added, n)
+			}
+		}
+		result = append(result, added...)
+		return added
+	}
+	queue := add(from)
+	if !repeat {
+		for _, n := range queue {
+			add(e.reachable(p, g, n, inverse))
+		}
+		return result
+	}
+	for len(queue) != 0 && e.ctx.Err() == nil {
+		n := queue[0]
+		queue = append(queue[1:], add(e.reachable(p, g, n, inverse))...)
+	}
+	return result
+}
+
+// nodes returns the subjects and objects of the graph.
+func nodes(g graph) []rdf.Node {
+	var result []rdf.Node
+	seen := make(map[string]bool)
+	for _, t := range g.FindAll(nil, nil, nil) {
+		for _, n := range []rdf.Node{t.Subject, t.Object} {
+			if k := algebra.FormatNode(n); !seen[k] {
+				seen[k] = true
+				result = append(result, n)
+			}
+		}
+	}
+	return result
+}
+
+// step returns the objects of the triples with the start node as subject and the given predicate, or the subjects of
+// the triples with the start node as object if inverse is true. A nil predicate matches any predicate that is
+// accepted by the function.
+func step(g graph, start rdf.Node, inverse bool, predicate rdf.Node, accept func(p rdf.Node) bool) []rdf.Node {
+	var result []rdf.Node
+	if inverse {
+		for _, t := range g.FindAll(nil, predicate, start) {
+			if accept == nil || accept(t.Predicate) {
+				result = append(result, t.Subject)
+			}
+		}
+		return result
+	}
+	for _, t := range g.FindAll(start, predicate, nil) {
+		if accept == nil || accept(t.Predicate) {
+			result = append(result, t.Object)
+		}
+	}
+	return result
+}
@@ -0,0 +1,259 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/iri"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfxml"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/trig"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GraphStore implements the SPARQL 1.1 Graph Store HTTP Protocol for a dataset. A graph is identified indirectly by
+// the "graph" parameter (a named graph) or the "default" parameter (the default graph), otherwise the URL of the
+// request is the name of the graph (direct identification). Changes are applied as the equivalent SPARQL updates, the
+// graph store is read-only unless they are enabled.
+type GraphStore struct {
+	// Timeout limits the duration of the evaluation of a request, zero means no limit.
+	Timeout time.Duration
+	// MaxBodySize limits the size of the body of a request in bytes, larger bodies are rejected with 413 Request
+	// Entity Too Large. Zero means DefaultMaxBodySize, a negative value means no limit.
+	MaxBodySize int64
+	// Updates enables changes, otherwise they are rejected with 403 Forbidden.
+	Updates bool
+
+	dataset *rdf.Dataset
+	mu      *sync.RWMutex
+}
+
+// NewGraphStore returns a new graph store for the dataset, the dataset must not be modified by others while in use.
+func NewGraphStore(d *rdf.Dataset) *GraphStore {
+	return &GraphStore{dataset: d, mu: new(sync.RWMutex)}
+}
+
+// GraphStore returns a graph store for the dataset of the handler, the requests of both are synchronized.
+func (h *Handler) GraphStore() *GraphStore {
+	return &GraphStore{
+		Timeout:     h.Timeout,
+		MaxBodySize: h.MaxBodySize,
+		Updates:     h.Updates,
+		dataset:     h.dataset,
+		mu:          h.mu,
+	}
+}
+
+func (s *GraphStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r, s.MaxBodySize)
+	name, base, err := graphName(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	if s.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.get(w, r.Header.Get("Accept"), name)
+		return
+	case http.MethodPut, http.MethodPost, http.MethodDelete:
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.Updates {
+		http.Error(w, "changes are not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		s.delete(ctx, w, name)
+		return
+	}
+	doc, status, err := parseGraph(r, base)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	s.put(ctx, w, name, doc, r.Method == http.MethodPut)
+}
+
+// delete removes the named graph, or clears the default graph.
+func (s *GraphStore) delete(ctx context.Context, w http.ResponseWriter, name *rdf.IRIReference) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name != nil && s.dataset.Graph(name) == nil {
+		http.Error(w, fmt.Sprintf("graph %s does not exist", algebra.FormatNode(name)), http.StatusNotFound)
+		return
+	}
+	u := algebra.Update{&algebra.Drop{Target: algebra.Target{Graph: name}}}
+	if err := sparql.EvaluateUpdate(ctx, u, s.dataset); err != nil {
+		evaluationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// get writes the triples of the graph in the negotiated syntax.
+func (s *GraphStore) get(w http.ResponseWriter, accept string, name *rdf.IRIReference) {
+	mediaType := negotiate(accept, GraphMediaTypes)
+	if mediaType == "" {
+		msg := fmt.Sprintf("not acceptable, supported media types: %v", GraphMediaTypes)
+		http.Error(w, msg, http.StatusNotAcceptable)
+		return
+	}
+	s.mu.RLock()
+	g := s.dataset.Default
+	if name != nil {
+		if g = s.dataset.Graph(name); g == nil {
+			s.mu.RUnlock()
+			http.Error(w, fmt.Sprintf("graph %s does not exist", algebra.FormatNode(name)), http.StatusNotFound)
+			return
+		}
+	}
+	var doc nt.Document
+	var err error
+	if g != nil {
+		doc, err = g.ToNTriples()
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		evaluationError(w, err)
+		return
+	}
+	body, err := encodeGraph(doc, name, mediaType)
+	if err != nil {
+		evaluationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	_, _ = io.WriteString(w, body)
+}
+
+// put adds the triples to the graph, the graph is emptied first if replace is true. Responds with 201 Created if the
+// graph did not exist yet.
+func (s *GraphStore) put(
+	ctx context.Context, w http.ResponseWriter, name *rdf.IRIReference, doc nt.Document, replace bool,
+) {
+	var u algebra.Update
+	if replace {
+		u = append(u, &algebra.Drop{Silent: true, Target: algebra.Target{Graph: name}})
+	}
+	if name != nil {
+		u = append(u, &algebra.Create{Silent: true, Graph: name})
+	}
+	var graph algebra.Term
+	if name != nil {
+		graph = algebra.Constant{Node: name}
+	}
+	var quads algebra.InsertData
+	for _, t := range rdf.FromNTriples(doc).Triples() {
+		quads = append(quads, algebra.Quad{Graph: graph, TriplePattern: algebra.TriplePattern{
+			Subject:   algebra.Constant{Node: t.Subject},
+			Predicate: algebra.Constant{Node: t.Predicate},
+			Object:    algebra.Constant{Node: t.Object},
+		}})
+	}
+	u = append(u, quads)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	created := name != nil && s.dataset.Graph(name) == nil
+	if err := sparql.EvaluateUpdate(ctx, u, s.dataset); err != nil {
+		evaluationError(w, err)
+		return
+	}
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// graphName returns the name of the graph of the request, nil for the default graph, and the base IRI of the
+// payload.
+func graphName(r *http.Request) (*rdf.IRIReference, string, error) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	base := scheme + "://" + r.Host + r.URL.RequestURI()
+	params := r.URL.Query()
+	graphs := params["graph"]
+	switch {
+	case params.Has("default") && len(graphs) != 0:
+		return nil, "", fmt.Errorf("either the default or graph parameter is allowed")
+	case params.Has("default"):
+		return nil, base, nil
+	case len(graphs) > 1:
+		return nil, "", fmt.Errorf("exactly one graph parameter is allowed")
+	case len(graphs) == 1:
+		name := iri.Resolve(base, graphs[0])
+		if !iri.IsAbsolute(name) {
+			return nil, "", fmt.Errorf("invalid graph IRI %q", graphs[0])
+		}
+		return &rdf.IRIReference{Value: name}, name, nil
+	default:
+		return &rdf.IRIReference{Value: base}, base, nil
+	}
+}
+
+// parseGraph returns the triples of the payload of the request, or an error with the status code of the response.
+// Relative IRIs in Turtle, TriG and RDF/XML are resolved against the base. Quad-based syntaxes must only contain
+// triples of the default graph.
+func parseGraph(r *http.Request, base string) (nt.Document, int, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, http.StatusUnsupportedMediaType, err
+	}
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, bodyError(err), err
+	}
+	var doc nt.Document
+	var quads nq.Document
+	switch mediaType {
+	case nt.MediaType:
+		doc, err = nt.ParseDocument(string(raw))
+	case ttl.MediaType:
+		var d ttl.Document
+		if d, err = ttl.ParseDocument(string(raw)); err == nil {
+			doc, err = ttl.EvaluateDocument(d, base)
+		}
+	case rdfxml.MediaType:
+		doc, err = rdfxml.ParseDocument(bytes.NewReader(raw), base)
+	case nq.MediaType, nq.MediaTypeAlt:
+		quads, err = nq.ParseDocument(string(raw))
+	case trig.MediaType:
+		var d trig.Document
+		if d, err = trig.ParseDocument(string(raw)); err == nil {
+			quads, err = trig.EvaluateDocument(d, base)
+		}
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %s", mediaType)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	for _, q := range quads {
+		if q.GraphLabel != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("the payload must not contain named graphs")
+		}
+		doc = append(doc, q.Triple)
+	}
+	return doc, http.StatusOK, nil
+}
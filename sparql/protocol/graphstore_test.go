@@ -0,0 +1,187 @@
+package protocol_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/sparql/protocol"
+	"github.com/0x51-dev/rdf/sparql/results"
+	"github.com/0x51-dev/rdf/trig"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func ExampleGraphStore() {
+	s := protocol.NewGraphStore(rdf.NewDataset())
+	s.Updates = true
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/people", strings.NewReader(`
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+<#alice> foaf:name "Alice" .
+`))
+	req.Header.Set("Content-Type", ttl.MediaType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/people", nil)
+	req.Header.Set("Accept", nt.MediaType)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Print(strings.ReplaceAll(string(body), server.URL, "http://example.org"))
+	// Output:
+	// 201 Created
+	// <http://example.org/people#alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+}
+
+func TestGraphStore(t *testing.T) {
+	s := protocol.NewGraphStore(dataset(t))
+	const g = "/store?graph=http%3A%2F%2Fexample.org%2Fh"
+	serve(t, s, put(g, nt.MediaType, `<http://example.org/a> <http://example.org/p> "1" .`), http.StatusForbidden)
+	s.Updates = true
+
+	// PUT creates the graph, or replaces its triples.
+	serve(t, s, put(g, nt.MediaType, `<http://example.org/a> <http://example.org/p> "1" .`), http.StatusCreated)
+	serve(t, s, put(g, nt.MediaType, `<http://example.org/a> <http://example.org/p> "2" .`), http.StatusNoContent)
+	expected := "<http://example.org/a> <http://example.org/p> \"2\" <http://example.org/h> .\n"
+	if body := get(t, s, g, nq.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+
+	// POST merges the triples into the graph, blank nodes are not shared with the triples of earlier requests.
+	serve(t, s, post(g, nq.MediaType, `_:b <http://example.org/p> "3" .`), http.StatusNoContent)
+	serve(t, s, post(g, trig.MediaType, `{ _:b <http://example.org/p> "4" . }`), http.StatusNoContent)
+	labels := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(get(t, s, g, nt.MediaType)), "\n") {
+		if strings.HasPrefix(line, "_:") {
+			labels[strings.Fields(line)[0]] = true
+		}
+	}
+	if len(labels) != 2 {
+		t.Errorf("expected 2 distinct blank nodes, got %v", labels)
+	}
+
+	// DELETE removes the graph.
+	serve(t, s, httptest.NewRequest(http.MethodDelete, g, nil), http.StatusNoContent)
+	serve(t, s, httptest.NewRequest(http.MethodGet, g, nil), http.StatusNotFound)
+	serve(t, s, httptest.NewRequest(http.MethodDelete, g, nil), http.StatusNotFound)
+}
+
+func TestGraphStore_default(t *testing.T) {
+	s := protocol.NewGraphStore(dataset(t))
+	s.Updates = true
+	expected := "<http://example.org/alice> <http://xmlns.com/foaf/0.1/name> \"Alice\" .\n"
+	if body := get(t, s, "/?default", nt.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+	serve(t, s, put("/?default", ttl.MediaType, `<a> <b> <c> .`), http.StatusNoContent)
+	expected = "<http://example.com/a> <http://example.com/b> <http://example.com/c> .\n"
+	if body := get(t, s, "/?default", nt.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+	serve(t, s, httptest.NewRequest(http.MethodDelete, "/?default", nil), http.StatusNoContent)
+	if body := get(t, s, "/?default", nt.MediaType); body != "" {
+		t.Errorf("expected an empty graph, got %q", body)
+	}
+	// The named graph is not affected.
+	expected = "<http://example.org/bob> <http://xmlns.com/foaf/0.1/name> \"Bob\" .\n"
+	if body := get(t, s, "/?graph="+url.QueryEscape("http://example.org/g"), nt.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+}
+
+func TestGraphStore_direct(t *testing.T) {
+	s := protocol.NewGraphStore(rdf.NewDataset())
+	s.Updates = true
+	serve(t, s, put("/graphs/a", nt.MediaType, `<http://example.org/a> <http://example.org/p> "a" .`), http.StatusCreated)
+	expected := "<http://example.org/a> <http://example.org/p> \"a\" <http://example.com/graphs/a> .\n"
+	if body := get(t, s, "/graphs/a", nq.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+	if body := get(t, s, "/?graph=graphs/a", nq.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+
+	// Relative IRIs are resolved against the URL of the request.
+	serve(t, s, put("/graphs/b", trig.MediaType, `<a> <b> <c> .`), http.StatusCreated)
+	expected = "<http://example.com/graphs/a> <http://example.com/graphs/b> <http://example.com/graphs/c> .\n"
+	if body := get(t, s, "/graphs/b", nt.MediaType); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+}
+
+func TestGraphStore_protocol(t *testing.T) {
+	h := protocol.NewHandler(rdf.NewDataset())
+	h.Updates = true
+	serve(t, h.GraphStore(), put("/?graph=http://example.org/g", trig.MediaType, `
+PREFIX ex: <http://example.org/>
+ex:a ex:p ex:b .
+`), http.StatusCreated)
+	query := `SELECT ?s { GRAPH <http://example.org/g> { ?s ?p ?o } }`
+	req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(query), nil)
+	req.Header.Set("Accept", results.CSVMediaType)
+	expected := "s\r\nhttp://example.org/a\r\n"
+	if body := readBody(t, serve(t, h, req, http.StatusOK)); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+
+	h.Updates = false
+	serve(t, h.GraphStore(), httptest.NewRequest(http.MethodDelete, "/?graph=http://example.org/g", nil), http.StatusForbidden)
+	get(t, h.GraphStore(), "/?graph=http://example.org/g", ttl.MediaType)
+}
+
+func TestGraphStore_errors(t *testing.T) {
+	s := protocol.NewGraphStore(dataset(t))
+	s.Updates = true
+	for _, test := range []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"default and graph", httptest.NewRequest(http.MethodGet, "/?default&graph=http://example.org/g", nil), http.StatusBadRequest},
+		{"multiple graphs", httptest.NewRequest(http.MethodGet, "/?graph=a&graph=b", nil), http.StatusBadRequest},
+		{"method", httptest.NewRequest(http.MethodPatch, "/?default", nil), http.StatusMethodNotAllowed},
+		{"media type", put("/?default", "text/plain", ``), http.StatusUnsupportedMediaType},
+		{"syntax", put("/?default", nt.MediaType, `<a> <b> .`), http.StatusBadRequest},
+		{"named graph", put("/?default", nq.MediaType, `<http://example.org/a> <http://example.org/b> <http://example.org/c> <http://example.org/g> .`), http.StatusBadRequest},
+		{"missing graph", httptest.NewRequest(http.MethodGet, "/?graph=http://example.org/missing", nil), http.StatusNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			serve(t, s, test.req, test.status)
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?default", nil)
+	req.Header.Set("Accept", results.JSONMediaType)
+	serve(t, s, req, http.StatusNotAcceptable)
+}
+
+func get(t *testing.T, h http.Handler, target, mediaType string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", mediaType)
+	resp := serve(t, h, req, http.StatusOK)
+	if ct := resp.Header.Get("Content-Type"); ct != mediaType {
+		t.Errorf("expected content type %s, got %s", mediaType, ct)
+	}
+	return readBody(t, resp)
+}
+
+func put(target, contentType, body string) *http.Request {
+	req := post(target, contentType, body)
+	req.Method = http.MethodPut
+	return req
+}
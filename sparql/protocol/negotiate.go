@@ -0,0 +1,62 @@
+package protocol
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// negotiate returns the offered media type that is preferred by the Accept header, or an empty string if none of them
+// is acceptable. The quality of an offer is taken from the most specific media range that matches it, ties are broken
+// by the order of the offers.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	var best string
+	var bestQ float64
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			if s := matchRange(r.mediaType, offer); s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// matchRange returns the specificity of the media range if it matches the media type, -1 otherwise.
+func matchRange(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
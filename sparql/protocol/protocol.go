@@ -0,0 +1,338 @@
+// Package protocol implements the SPARQL 1.1 Protocol: an http.Handler that evaluates queries and updates, sent by GET
+// or POST requests, against a dataset. Query results are written in the format that is negotiated by the Accept
+// header of the request. The graphs of the dataset can also be managed directly by the SPARQL 1.1 Graph Store HTTP
+// Protocol, see GraphStore.
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfxml"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/sparql/results"
+	"github.com/0x51-dev/rdf/trig"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// QueryMediaType is the media type of a query that is sent unencoded in the body of a POST request.
+	QueryMediaType = "application/sparql-query"
+	// UpdateMediaType is the media type of an update that is sent unencoded in the body of a POST request.
+	UpdateMediaType = "application/sparql-update"
+
+	// DefaultMaxBodySize is the maximum size of the body of a request in bytes, unless configured otherwise.
+	DefaultMaxBodySize = 10 << 20
+
+	formMediaType = "application/x-www-form-urlencoded"
+)
+
+// GraphMediaTypes contains the media types of the results of CONSTRUCT and DESCRIBE queries and of the graphs of a
+// graph store, in order of preference.
+var GraphMediaTypes = []string{
+	ttl.MediaType, nt.MediaType, nq.MediaType, nq.MediaTypeAlt, trig.MediaType, rdfxml.MediaType,
+}
+
+// Handler is a SPARQL endpoint for a dataset. Queries are evaluated concurrently, updates are applied exclusively.
+// The handler is read-only unless updates are enabled.
+type Handler struct {
+	// Base is the IRI against which relative IRIs in queries and updates are resolved.
+	Base string
+	// Timeout limits the duration of the evaluation of a request, zero means no limit.
+	Timeout time.Duration
+	// MaxBodySize limits the size of the body of a request in bytes, larger bodies are rejected with 413 Request
+	// Entity Too Large. Zero means DefaultMaxBodySize, a negative value means no limit.
+	MaxBodySize int64
+	// Updates enables updates, otherwise they are rejected with 403 Forbidden.
+	Updates bool
+	// AllowLoad reports whether the document with the given IRI may be read by a LOAD operation. LOAD reads local
+	// files, so it is rejected with 403 Forbidden if AllowLoad is nil or returns false.
+	AllowLoad func(iri string) bool
+
+	dataset *rdf.Dataset
+	mu      *sync.RWMutex
+}
+
+// NewHandler returns a new handler for the dataset, the dataset must not be modified by others while in use.
+func NewHandler(d *rdf.Dataset) *Handler {
+	return &Handler{dataset: d, mu: new(sync.RWMutex)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r, h.MaxBodySize)
+	req, status, err := parseRequest(r)
+	if err != nil {
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", "GET, POST")
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	ctx := r.Context()
+	if h.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	if req.update {
+		h.serveUpdate(ctx, w, req)
+		return
+	}
+	h.serveQuery(ctx, w, r.Header.Get("Accept"), req)
+}
+
+func (h *Handler) serveQuery(ctx context.Context, w http.ResponseWriter, accept string, req *request) {
+	q, err := sparql.ParseQuery(req.operation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a, err := sparql.Translate(q, h.Base)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.defaultGraphs) != 0 || len(req.namedGraphs) != 0 {
+		// The dataset of the protocol overrides the dataset clauses of the query.
+		a.From, a.FromNamed = req.defaultGraphs, req.namedGraphs
+	}
+	offers := results.MediaTypes
+	if a.Type == algebra.Construct || a.Type == algebra.Describe {
+		offers = GraphMediaTypes
+	}
+	mediaType := negotiate(accept, offers)
+	if mediaType == "" {
+		http.Error(w, fmt.Sprintf("not acceptable, supported media types: %v", offers), http.StatusNotAcceptable)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	result, err := sparql.Evaluate(ctx, a, h.dataset)
+	if err != nil {
+		evaluationError(w, err)
+		return
+	}
+	rw := &responseWriter{ResponseWriter: w}
+	rw.Header().Set("Content-Type", mediaType)
+	switch result.Type {
+	case algebra.Select, algebra.Ask:
+		writer, err := results.NewWriter(rw, mediaType)
+		if err == nil {
+			err = results.WriteResult(writer, result)
+		}
+		if err != nil {
+			if rw.written {
+				// The status has already been sent, the response can only be aborted.
+				panic(http.ErrAbortHandler)
+			}
+			rw.Header().Del("Content-Type")
+			evaluationError(w, err)
+		}
+	default:
+		body, err := encodeGraph(result.Graph, nil, mediaType)
+		if err != nil {
+			rw.Header().Del("Content-Type")
+			evaluationError(w, err)
+			return
+		}
+		_, _ = io.WriteString(rw, body)
+	}
+}
+
+func (h *Handler) serveUpdate(ctx context.Context, w http.ResponseWriter, req *request) {
+	if !h.Updates {
+		http.Error(w, "updates are not allowed", http.StatusForbidden)
+		return
+	}
+	u, err := sparql.ParseUpdate(req.operation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	a, err := sparql.TranslateUpdate(u, h.Base)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, o := range a {
+		if l, ok := o.(*algebra.Load); ok && (h.AllowLoad == nil || !h.AllowLoad(l.IRI.Value)) {
+			http.Error(w, fmt.Sprintf("loading <%s> is not allowed", l.IRI.Value), http.StatusForbidden)
+			return
+		}
+	}
+	if len(req.defaultGraphs) != 0 || len(req.namedGraphs) != 0 {
+		for _, o := range a {
+			m, ok := o.(*algebra.Modify)
+			if !ok {
+				continue
+			}
+			if m.With != nil || len(m.Using) != 0 || len(m.UsingNamed) != 0 {
+				msg := "using-graph-uri and using-named-graph-uri can not be combined with USING or WITH"
+				http.Error(w, msg, http.StatusBadRequest)
+				return
+			}
+			m.Using, m.UsingNamed = req.defaultGraphs, req.namedGraphs
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := sparql.EvaluateUpdate(ctx, a, h.dataset); err != nil {
+		evaluationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// request is a query or update operation, with the dataset given by the parameters of the protocol.
+type request struct {
+	update    bool
+	operation string
+	// defaultGraphs and namedGraphs are the default-graph-uri and named-graph-uri parameters of a query, or the
+	// using-graph-uri and using-named-graph-uri parameters of an update.
+	defaultGraphs, namedGraphs []string
+}
+
+// parseRequest returns the operation of the request, or an error with the status code of the response.
+func parseRequest(r *http.Request) (*request, int, error) {
+	var params url.Values
+	switch r.Method {
+	case http.MethodGet:
+		params = r.URL.Query()
+		if params.Has("update") {
+			return nil, http.StatusMethodNotAllowed, fmt.Errorf("updates must be sent by POST")
+		}
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, http.StatusUnsupportedMediaType, err
+		}
+		switch mediaType {
+		case formMediaType:
+			if err := r.ParseForm(); err != nil {
+				return nil, bodyError(err), err
+			}
+			params = r.PostForm
+		case QueryMediaType, UpdateMediaType:
+			raw, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, bodyError(err), err
+			}
+			query := r.URL.Query()
+			if query.Has("query") || query.Has("update") {
+				return nil, http.StatusBadRequest, fmt.Errorf("the operation must only be sent in the body")
+			}
+			return newRequest(mediaType == UpdateMediaType, string(raw), query)
+		default:
+			return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %s", mediaType)
+		}
+	default:
+		return nil, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)
+	}
+	queries, updates := params["query"], params["update"]
+	switch {
+	case len(queries)+len(updates) == 0:
+		return nil, http.StatusBadRequest, fmt.Errorf("missing query or update parameter")
+	case len(queries)+len(updates) > 1:
+		return nil, http.StatusBadRequest, fmt.Errorf("exactly one query or update parameter is allowed")
+	case len(queries) == 1:
+		return newRequest(false, queries[0], params)
+	default:
+		return newRequest(true, updates[0], params)
+	}
+}
+
+// limitBody limits the size of the body of the request, see Handler.MaxBodySize.
+func limitBody(w http.ResponseWriter, r *http.Request, n int64) {
+	if n == 0 {
+		n = DefaultMaxBodySize
+	}
+	if n > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+	}
+}
+
+// bodyError returns the status code of the response for an error that occurred while reading the body of a request.
+func bodyError(err error) int {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// newRequest returns a request for the operation, the dataset is taken from the parameters.
+func newRequest(update bool, operation string, params url.Values) (*request, int, error) {
+	req := &request{update: update, operation: operation}
+	if update {
+		req.defaultGraphs, req.namedGraphs = params["using-graph-uri"], params["using-named-graph-uri"]
+		if params.Has("default-graph-uri") || params.Has("named-graph-uri") {
+			return nil, http.StatusBadRequest, fmt.Errorf("an update does not accept default-graph-uri or named-graph-uri")
+		}
+	} else {
+		req.defaultGraphs, req.namedGraphs = params["default-graph-uri"], params["named-graph-uri"]
+		if params.Has("using-graph-uri") || params.Has("using-named-graph-uri") {
+			return nil, http.StatusBadRequest, fmt.Errorf("a query does not accept using-graph-uri or using-named-graph-uri")
+		}
+	}
+	return req, http.StatusOK, nil
+}
+
+// responseWriter records whether the response has been started, until then errors can still be reported by status.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(p)
+}
+
+// encodeGraph serializes the triples in the syntax of the media type. The triples belong to the graph with the given
+// name in the quad-based syntaxes, nil refers to the default graph.
+func encodeGraph(doc nt.Document, name *rdf.IRIReference, mediaType string) (string, error) {
+	switch mediaType {
+	case ttl.MediaType:
+		return ttl.Encode(doc, nil)
+	case nt.MediaType:
+		return doc.String(), nil
+	case nq.MediaType, nq.MediaTypeAlt, trig.MediaType:
+		var label nt.Subject
+		if name != nil {
+			label = nt.IRIReference(name.Value)
+		}
+		quads := make(nq.Document, len(doc))
+		for i, t := range doc {
+			quads[i] = nq.NewQuadFromTriple(t, label)
+		}
+		if mediaType == trig.MediaType {
+			return trig.Encode(quads, nil)
+		}
+		return quads.String(), nil
+	case rdfxml.MediaType:
+		return rdfxml.Encode(doc, nil)
+	default:
+		return "", fmt.Errorf("unsupported media type %s", mediaType)
+	}
+}
+
+// evaluationError writes the error of an evaluation, exceeding the timeout results in 503 Service Unavailable.
+func evaluationError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusServiceUnavailable
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,283 @@
+package protocol_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/sparql/protocol"
+	"github.com/0x51-dev/rdf/sparql/results"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const data = `<http://example.org/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+<http://example.org/bob> <http://xmlns.com/foaf/0.1/name> "Bob" <http://example.org/g> .
+`
+
+func ExampleHandler() {
+	doc, _ := nq.ParseDocument(data)
+	server := httptest.NewServer(protocol.NewHandler(rdf.FromNQuads(doc)))
+	defer server.Close()
+
+	query := `SELECT ?name WHERE { ?s <http://xmlns.com/foaf/0.1/name> ?name }`
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?query="+url.QueryEscape(query), nil)
+	req.Header.Set("Accept", results.TSVMediaType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Print(string(body))
+	// Output:
+	// ?name
+	// "Alice"
+}
+
+func TestHandler_query(t *testing.T) {
+	const query = `SELECT ?name WHERE { ?s <http://xmlns.com/foaf/0.1/name> ?name } ORDER BY ?name`
+	for _, test := range []struct {
+		name     string
+		req      *http.Request
+		expected string
+	}{
+		{
+			"get",
+			httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape(query), nil),
+			"?name\n\"Alice\"\n",
+		},
+		{
+			"get default-graph-uri",
+			httptest.NewRequest(http.MethodGet, "/sparql?"+url.Values{
+				"query":             {query},
+				"default-graph-uri": {"http://example.org/g"},
+			}.Encode(), nil),
+			"?name\n\"Bob\"\n",
+		},
+		{
+			"post form",
+			form(url.Values{"query": {query}, "default-graph-uri": {"http://example.org/g", "http://example.org/missing"}}),
+			"?name\n\"Bob\"\n",
+		},
+		{
+			"post direct named-graph-uri",
+			post("/sparql?named-graph-uri=http://example.org/g", protocol.QueryMediaType,
+				`SELECT ?name WHERE { GRAPH ?g { ?s ?p ?name } }`),
+			"?name\n\"Bob\"\n",
+		},
+		{
+			"post direct ask",
+			post("/sparql", protocol.QueryMediaType+"; charset=utf-8", `ASK { ?s ?p "Carol" }`),
+			"?_askResult\nfalse\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.req.Header.Set("Accept", results.TSVMediaType)
+			resp := serve(t, protocol.NewHandler(dataset(t)), test.req, http.StatusOK)
+			if ct := resp.Header.Get("Content-Type"); ct != results.TSVMediaType {
+				t.Errorf("expected content type %s, got %s", results.TSVMediaType, ct)
+			}
+			if body := readBody(t, resp); body != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, body)
+			}
+		})
+	}
+}
+
+func TestHandler_negotiate(t *testing.T) {
+	h := protocol.NewHandler(dataset(t))
+	for _, test := range []struct {
+		query    string
+		accept   string
+		expected string
+	}{
+		{`ASK {}`, "", results.JSONMediaType},
+		{`ASK {}`, "*/*", results.JSONMediaType},
+		{`ASK {}`, "text/*", results.CSVMediaType},
+		{`ASK {}`, "text/csv;q=0.5, text/tab-separated-values", results.TSVMediaType},
+		{`ASK {}`, "application/*;q=0.2, application/sparql-results+xml", results.XMLMediaType},
+		{`ASK {}`, "*/*;q=0.1, application/sparql-results+json;q=0", results.XMLMediaType},
+		{`CONSTRUCT WHERE { ?s ?p ?o }`, "", ttl.MediaType},
+		{`CONSTRUCT WHERE { ?s ?p ?o }`, "application/n-triples, text/turtle;q=0.9", nt.MediaType},
+		{`DESCRIBE <http://example.org/alice>`, nq.MediaType, nq.MediaType},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(test.query), nil)
+		req.Header.Set("Accept", test.accept)
+		resp := serve(t, h, req, http.StatusOK)
+		if ct := resp.Header.Get("Content-Type"); ct != test.expected {
+			t.Errorf("%s: expected content type %s, got %s", test.accept, test.expected, ct)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(`ASK {}`), nil)
+	req.Header.Set("Accept", ttl.MediaType)
+	serve(t, h, req, http.StatusNotAcceptable)
+}
+
+func TestHandler_construct(t *testing.T) {
+	query := `CONSTRUCT { ?s <http://example.org/label> ?name } WHERE { ?s <http://xmlns.com/foaf/0.1/name> ?name }`
+	req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(query), nil)
+	req.Header.Set("Accept", nt.MediaType)
+	resp := serve(t, protocol.NewHandler(dataset(t)), req, http.StatusOK)
+	expected := "<http://example.org/alice> <http://example.org/label> \"Alice\" .\n"
+	if body := readBody(t, resp); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+}
+
+func TestHandler_update(t *testing.T) {
+	h := protocol.NewHandler(dataset(t))
+	update := `CLEAR ALL`
+	serve(t, h, post("/", protocol.UpdateMediaType, update), http.StatusForbidden)
+
+	h.Updates = true
+	update = `INSERT DATA { <http://example.org/carol> <http://xmlns.com/foaf/0.1/name> "Carol" }`
+	serve(t, h, post("/", protocol.UpdateMediaType, update), http.StatusNoContent)
+	serve(t, h, form(url.Values{
+		"update":          {`DELETE { GRAPH <http://example.org/g> { ?s ?p ?o } } WHERE { ?s ?p ?o }`},
+		"using-graph-uri": {"http://example.org/g"},
+	}), http.StatusNoContent)
+
+	query := `SELECT ?s { { ?s ?p ?o } UNION { GRAPH ?g { ?s ?p ?o } } } ORDER BY ?s`
+	req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(query), nil)
+	req.Header.Set("Accept", results.CSVMediaType)
+	expected := "s\r\nhttp://example.org/alice\r\nhttp://example.org/carol\r\n"
+	if body := readBody(t, serve(t, h, req, http.StatusOK)); body != expected {
+		t.Errorf("expected %q, got %q", expected, body)
+	}
+
+	h.Updates = false
+	serve(t, h, post("/", protocol.UpdateMediaType, `CLEAR ALL`), http.StatusForbidden)
+}
+
+func TestHandler_load(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.nt")
+	if err := os.WriteFile(file, []byte(`<http://example.org/a> <http://example.org/p> "a" .`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	iri := "file://" + filepath.ToSlash(file)
+	h := protocol.NewHandler(rdf.NewDataset())
+	h.Updates = true
+	for _, update := range []string{
+		fmt.Sprintf("LOAD <%s>", iri),
+		fmt.Sprintf("LOAD SILENT <%s> INTO GRAPH <http://example.org/g>", iri),
+		"LOAD <file:///etc/passwd>",
+	} {
+		serve(t, h, post("/", protocol.UpdateMediaType, update), http.StatusForbidden)
+	}
+
+	h.AllowLoad = func(v string) bool { return v == iri }
+	serve(t, h, post("/", protocol.UpdateMediaType, "LOAD <file:///etc/passwd>"), http.StatusForbidden)
+	serve(t, h, post("/", protocol.UpdateMediaType, fmt.Sprintf("LOAD <%s>", iri)), http.StatusNoContent)
+	req := httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(`SELECT ?o { ?s ?p ?o }`), nil)
+	req.Header.Set("Accept", results.CSVMediaType)
+	if body := readBody(t, serve(t, h, req, http.StatusOK)); body != "o\r\na\r\n" {
+		t.Errorf("expected the loaded triple, got %q", body)
+	}
+}
+
+func TestHandler_errors(t *testing.T) {
+	h := protocol.NewHandler(dataset(t))
+	h.Updates = true
+	for _, test := range []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"missing query", httptest.NewRequest(http.MethodGet, "/", nil), http.StatusBadRequest},
+		{"invalid query", httptest.NewRequest(http.MethodGet, "/?query=SELECT", nil), http.StatusBadRequest},
+		{"multiple queries", httptest.NewRequest(http.MethodGet, "/?query=ASK{}&query=ASK{}", nil), http.StatusBadRequest},
+		{"update by get", httptest.NewRequest(http.MethodGet, "/?update=CLEAR+ALL", nil), http.StatusMethodNotAllowed},
+		{"method", httptest.NewRequest(http.MethodPut, "/", nil), http.StatusMethodNotAllowed},
+		{"media type", post("/", "text/plain", `ASK {}`), http.StatusUnsupportedMediaType},
+		{"query and update", form(url.Values{"query": {`ASK {}`}, "update": {`CLEAR ALL`}}), http.StatusBadRequest},
+		{"invalid update", post("/", protocol.UpdateMediaType, `INSERT DATA { ?s ?p ?o }`), http.StatusBadRequest},
+		{"using and with", form(url.Values{
+			"update":          {`WITH <http://example.org/g> DELETE { ?s ?p ?o } WHERE { ?s ?p ?o }`},
+			"using-graph-uri": {"http://example.org/g"},
+		}), http.StatusBadRequest},
+		{"query with using", form(url.Values{
+			"query":           {`ASK {}`},
+			"using-graph-uri": {"http://example.org/g"},
+		}), http.StatusBadRequest},
+		{"failed update", post("/", protocol.UpdateMediaType, `CREATE GRAPH <http://example.org/g>`), http.StatusInternalServerError},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			serve(t, h, test.req, test.status)
+		})
+	}
+}
+
+func TestHandler_maxBodySize(t *testing.T) {
+	h := protocol.NewHandler(dataset(t))
+	h.Updates = true
+	h.MaxBodySize = 16
+	serve(t, h, post("/", protocol.QueryMediaType, `ASK {}`), http.StatusOK)
+	query := `ASK { ?s ?p ?o FILTER(?o != "x") }`
+	serve(t, h, post("/", protocol.QueryMediaType, query), http.StatusRequestEntityTooLarge)
+	serve(t, h, form(url.Values{"query": {query}}), http.StatusRequestEntityTooLarge)
+	serve(t, h.GraphStore(), put("/?default", nt.MediaType, `<http://example.org/a> <http://example.org/p> "a" .`),
+		http.StatusRequestEntityTooLarge)
+
+	h.MaxBodySize = -1
+	serve(t, h, post("/", protocol.QueryMediaType, query), http.StatusOK)
+}
+
+func TestHandler_timeout(t *testing.T) {
+	g := rdf.NewGraph()
+	p := &rdf.IRIReference{Value: "http://example.org/p"}
+	for i := 0; i < 100; i++ {
+		g.Add(&rdf.IRIReference{Value: fmt.Sprintf("http://example.org/%d", i)}, p, p)
+	}
+	h := protocol.NewHandler(&rdf.Dataset{Default: g})
+	h.Timeout = time.Nanosecond
+	query := `SELECT (COUNT(*) AS ?n) { ?a ?p ?o . ?b ?q ?r . ?c ?s ?t }`
+	serve(t, h, httptest.NewRequest(http.MethodGet, "/?query="+url.QueryEscape(query), nil), http.StatusServiceUnavailable)
+}
+
+func dataset(t *testing.T) *rdf.Dataset {
+	doc, err := nq.ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rdf.FromNQuads(doc)
+}
+
+func form(values url.Values) *http.Request {
+	return post("/", "application/x-www-form-urlencoded", values.Encode())
+}
+
+func post(target, contentType, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func serve(t *testing.T, h http.Handler, req *http.Request, status int) *http.Response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp := rec.Result()
+	if resp.StatusCode != status {
+		t.Fatalf("expected status %d, got %d: %s", status, resp.StatusCode, rec.Body.String())
+	}
+	return resp
+}
@@ -0,0 +1,129 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// CSVWriter writes results in the SPARQL 1.1 Query Results CSV Format. The format is lossy: literals are written as
+// their lexical form, without datatype or language. The result of an ASK query is written as the single binding of
+// the variable "_askResult".
+type CSVWriter struct {
+	w    *csv.Writer
+	vars []algebra.Var
+}
+
+// NewCSVWriter returns a new CSVWriter that writes to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	c := csv.NewWriter(w)
+	c.UseCRLF = true
+	return &CSVWriter{w: c}
+}
+
+func (w *CSVWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *CSVWriter) Write(s sparql.Solution) error {
+	record := make([]string, len(w.vars))
+	for i, v := range w.vars {
+		if n, ok := s[v]; ok {
+			record[i] = csvTerm(n)
+		}
+	}
+	return w.w.Write(record)
+}
+
+func (w *CSVWriter) WriteBoolean(b bool) error {
+	if err := w.w.Write([]string{askVar}); err != nil {
+		return err
+	}
+	if err := w.w.Write([]string{fmt.Sprint(b)}); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (w *CSVWriter) WriteHead(vars []algebra.Var) error {
+	w.vars = vars
+	record := make([]string, len(vars))
+	for i, v := range vars {
+		record[i] = string(v)
+	}
+	return w.w.Write(record)
+}
+
+// DecodeCSV reads results in the SPARQL 1.1 Query Results CSV Format. Since the format is lossy, the terms are
+// reconstructed: empty values are unbound, values starting with "_:" are blank nodes, absolute IRIs are IRIs and all
+// other values are simple literals.
+func DecodeCSV(r io.Reader) (*Results, error) {
+	c := csv.NewReader(r)
+	records, err := c.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("csv: missing header")
+	}
+	if b, ok := askResult(records[0], records[1:]); ok {
+		return &Results{Boolean: &b}, nil
+	}
+	results := &Results{Solutions: []sparql.Solution{}}
+	for _, v := range records[0] {
+		results.Vars = append(results.Vars, algebra.Var(v))
+	}
+	for _, record := range records[1:] {
+		s := make(sparql.Solution)
+		for i, value := range record {
+			if value == "" || i >= len(results.Vars) {
+				continue
+			}
+			s[results.Vars[i]] = csvNode(value)
+		}
+		results.Solutions = append(results.Solutions, s)
+	}
+	return results, nil
+}
+
+// askResult returns the result of an ASK query, if the records contain the single binding of "_askResult".
+func askResult(header []string, records [][]string) (bool, bool) {
+	if len(header) != 1 || strings.TrimLeft(header[0], "?$") != askVar || len(records) != 1 || len(records[0]) != 1 {
+		return false, false
+	}
+	switch records[0][0] {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func csvNode(value string) rdf.Node {
+	if strings.HasPrefix(value, "_:") {
+		return &rdf.BlankNode{Attribute: value}
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && !strings.ContainsAny(value, " \t\r\n\"<>{}") {
+		return &rdf.IRIReference{Value: value}
+	}
+	return &rdf.Literal{Value: value, Datatype: rdf.XSDString}
+}
+
+func csvTerm(n rdf.Node) string {
+	switch n := n.(type) {
+	case *rdf.IRIReference:
+		return n.Value
+	case *rdf.Literal:
+		return n.Value
+	default:
+		return algebra.FormatNode(n)
+	}
+}
@@ -0,0 +1,230 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"io"
+	"strings"
+)
+
+// DecodeJSON reads results in the SPARQL 1.1 Query Results JSON Format. Triple terms are supported as defined by
+// SPARQL-star, i.e. {"type": "triple", "value": {"subject": ..., "predicate": ..., "object": ...}}.
+func DecodeJSON(r io.Reader) (*Results, error) {
+	var doc struct {
+		Head struct {
+			Vars []string `json:"vars"`
+		} `json:"head"`
+		Boolean *bool `json:"boolean"`
+		Results *struct {
+			Bindings []map[string]jsonTerm `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Boolean != nil {
+		return &Results{Boolean: doc.Boolean}, nil
+	}
+	if doc.Results == nil {
+		return nil, fmt.Errorf("json: missing results")
+	}
+	results := &Results{Vars: make([]algebra.Var, len(doc.Head.Vars)), Solutions: []sparql.Solution{}}
+	for i, v := range doc.Head.Vars {
+		results.Vars[i] = algebra.Var(v)
+	}
+	for _, b := range doc.Results.Bindings {
+		s := make(sparql.Solution, len(b))
+		for v, t := range b {
+			n, err := t.node()
+			if err != nil {
+				return nil, err
+			}
+			s[algebra.Var(v)] = n
+		}
+		results.Solutions = append(results.Solutions, s)
+	}
+	return results, nil
+}
+
+// JSONWriter writes results in the SPARQL 1.1 Query Results JSON Format.
+type JSONWriter struct {
+	w    *bufio.Writer
+	vars []algebra.Var
+	rows int
+}
+
+// NewJSONWriter returns a new JSONWriter that writes to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *JSONWriter) Close() error {
+	if _, err := w.w.WriteString("\n]}}\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *JSONWriter) Write(s sparql.Solution) error {
+	b := make(map[string]*jsonTerm, len(s))
+	for _, v := range w.vars {
+		if n, ok := s[v]; ok {
+			t, err := newJSONTerm(n)
+			if err != nil {
+				return err
+			}
+			b[string(v)] = t
+		}
+	}
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if w.rows != 0 {
+		if err := w.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	w.rows++
+	if err := w.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	_, err = w.w.Write(raw)
+	return err
+}
+
+func (w *JSONWriter) WriteBoolean(b bool) error {
+	if _, err := fmt.Fprintf(w.w, "{\"head\":{},\"boolean\":%t}\n", b); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *JSONWriter) WriteHead(vars []algebra.Var) error {
+	w.vars = vars
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = string(v)
+	}
+	raw, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w.w, "{\"head\":{\"vars\":%s},\"results\":{\"bindings\":[", raw)
+	return err
+}
+
+// jsonTerm is an RDF term, the value is either a string or a jsonTriple.
+type jsonTerm struct {
+	Type      string `json:"type"`
+	Value     any    `json:"value"`
+	Language  string `json:"xml:lang,omitempty"`
+	Direction string `json:"its:dir,omitempty"`
+	Datatype  string `json:"datatype,omitempty"`
+}
+
+func newJSONTerm(n rdf.Node) (*jsonTerm, error) {
+	switch n := n.(type) {
+	case *rdf.IRIReference:
+		return &jsonTerm{Type: "uri", Value: n.Value}, nil
+	case *rdf.BlankNode:
+		return &jsonTerm{Type: "bnode", Value: strings.TrimPrefix(n.Attribute, "_:")}, nil
+	case *rdf.Literal:
+		t := &jsonTerm{Type: "literal", Value: n.Value, Language: n.Language, Direction: n.Direction}
+		if n.Language == "" && n.Datatype != rdf.XSDString {
+			t.Datatype = string(n.Datatype)
+		}
+		return t, nil
+	case *rdf.TripleTerm:
+		var terms [3]jsonTerm
+		for i, n := range []rdf.Node{n.Subject, n.Predicate, n.Object} {
+			t, err := newJSONTerm(n)
+			if err != nil {
+				return nil, err
+			}
+			terms[i] = *t
+		}
+		triple := jsonTriple{Subject: terms[0], Predicate: terms[1], Object: terms[2]}
+		return &jsonTerm{Type: "triple", Value: triple}, nil
+	default:
+		return nil, fmt.Errorf("json: unknown node type %T", n)
+	}
+}
+
+func (t *jsonTerm) UnmarshalJSON(raw []byte) error {
+	var v struct {
+		Type      string          `json:"type"`
+		Value     json.RawMessage `json:"value"`
+		Language  string          `json:"xml:lang"`
+		Direction string          `json:"its:dir"`
+		Datatype  string          `json:"datatype"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	*t = jsonTerm{Type: v.Type, Language: v.Language, Direction: v.Direction, Datatype: v.Datatype}
+	if v.Type == "triple" {
+		var triple jsonTriple
+		if err := json.Unmarshal(v.Value, &triple); err != nil {
+			return err
+		}
+		t.Value = triple
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(v.Value, &s); err != nil {
+		return err
+	}
+	t.Value = s
+	return nil
+}
+
+func (t *jsonTerm) node() (rdf.Node, error) {
+	if t.Type == "triple" {
+		triple := t.Value.(jsonTriple)
+		var nodes [3]rdf.Node
+		for i, t := range []jsonTerm{triple.Subject, triple.Predicate, triple.Object} {
+			n, err := t.node()
+			if err != nil {
+				return nil, err
+			}
+			nodes[i] = n
+		}
+		return &rdf.TripleTerm{Triple: rdf.Triple{Subject: nodes[0], Predicate: nodes[1], Object: nodes[2]}}, nil
+	}
+	value, _ := t.Value.(string)
+	switch t.Type {
+	case "uri":
+		return &rdf.IRIReference{Value: value}, nil
+	case "bnode":
+		return &rdf.BlankNode{Attribute: "_:" + value}, nil
+	case "literal", "typed-literal":
+		return newLiteral(value, t.Datatype, t.Language, t.Direction), nil
+	default:
+		return nil, fmt.Errorf("json: unknown term type %q", t.Type)
+	}
+}
+
+type jsonTriple struct {
+	Subject   jsonTerm `json:"subject"`
+	Predicate jsonTerm `json:"predicate"`
+	Object    jsonTerm `json:"object"`
+}
+
+// newLiteral returns a literal, the datatype is derived from the language and direction if not given.
+func newLiteral(value, datatype, language, direction string) *rdf.Literal {
+	l := &rdf.Literal{Value: value, Datatype: rdf.DataType(datatype), Language: language, Direction: direction}
+	switch {
+	case direction != "":
+		l.Datatype = rdf.XSDNSDirString
+	case language != "":
+		l.Datatype = rdf.XSDNSString
+	case datatype == "":
+		l.Datatype = rdf.XSDString
+	}
+	return l
+}
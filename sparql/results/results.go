@@ -0,0 +1,137 @@
+// Package results implements the SPARQL 1.1 Query Results formats: JSON, XML, CSV and TSV. Results are written
+// incrementally by a Writer, so that the solutions of a query can be streamed while they are computed.
+package results
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"io"
+	"mime"
+)
+
+const (
+	// JSONMediaType is the media type of the SPARQL 1.1 Query Results JSON Format.
+	JSONMediaType = "application/sparql-results+json"
+	// XMLMediaType is the media type of the SPARQL Query Results XML Format.
+	XMLMediaType = "application/sparql-results+xml"
+	// CSVMediaType is the media type of the SPARQL 1.1 Query Results CSV Format.
+	CSVMediaType = "text/csv"
+	// TSVMediaType is the media type of the SPARQL 1.1 Query Results TSV Format.
+	TSVMediaType = "text/tab-separated-values"
+)
+
+// askVar is the variable of the boolean result in CSV and TSV, which only define a format for variable bindings.
+const askVar = "_askResult"
+
+// MediaTypes contains the supported media types, in order of preference.
+var MediaTypes = []string{JSONMediaType, XMLMediaType, CSVMediaType, TSVMediaType}
+
+// Decode reads the results in the format of the media type, parameters (e.g. charset) are ignored.
+func Decode(r io.Reader, mediaType string) (*Results, error) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+	switch mt {
+	case JSONMediaType:
+		return DecodeJSON(r)
+	case XMLMediaType:
+		return DecodeXML(r)
+	case CSVMediaType:
+		return DecodeCSV(r)
+	case TSVMediaType:
+		return DecodeTSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported media type %s", mediaType)
+	}
+}
+
+// Encode writes the results in the format of the media type.
+func Encode(w io.Writer, mediaType string, r *Results) error {
+	rw, err := NewWriter(w, mediaType)
+	if err != nil {
+		return err
+	}
+	if r.Boolean != nil {
+		return rw.WriteBoolean(*r.Boolean)
+	}
+	if err := rw.WriteHead(r.Vars); err != nil {
+		return err
+	}
+	for _, s := range r.Solutions {
+		if err := rw.Write(s); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+// NewWriter returns a writer for the format of the media type, parameters (e.g. charset) are ignored.
+func NewWriter(w io.Writer, mediaType string) (Writer, error) {
+	mt, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil, err
+	}
+	switch mt {
+	case JSONMediaType:
+		return NewJSONWriter(w), nil
+	case XMLMediaType:
+		return NewXMLWriter(w), nil
+	case CSVMediaType:
+		return NewCSVWriter(w), nil
+	case TSVMediaType:
+		return NewTSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported media type %s", mediaType)
+	}
+}
+
+// WriteResult writes the result of a SELECT or ASK query, the solutions are written while they are computed.
+func WriteResult(w Writer, r *sparql.Result) error {
+	switch r.Type {
+	case algebra.Select:
+		if err := w.WriteHead(r.Vars); err != nil {
+			return err
+		}
+		for {
+			s, ok := r.Solutions.Next()
+			if !ok {
+				break
+			}
+			if err := w.Write(s); err != nil {
+				return err
+			}
+		}
+		if err := r.Solutions.Err(); err != nil {
+			return err
+		}
+		return w.Close()
+	case algebra.Ask:
+		return w.WriteBoolean(r.Boolean)
+	default:
+		return fmt.Errorf("%s results can not be written as query results", r.Type)
+	}
+}
+
+// Results are the results of a SELECT query (variables and solutions) or an ASK query (boolean).
+type Results struct {
+	Vars      []algebra.Var
+	Solutions []sparql.Solution
+	// Boolean is the result of an ASK query, nil for SELECT results.
+	Boolean *bool
+}
+
+// Writer writes query results. SELECT results are written by calling WriteHead once, followed by Write for every
+// solution and Close. ASK results are written by a single call to WriteBoolean. Writes are buffered, the results are
+// flushed by Close and WriteBoolean.
+type Writer interface {
+	// WriteHead writes the variables of the results.
+	WriteHead(vars []algebra.Var) error
+	// Write writes a single solution, variables that are not in the head are ignored.
+	Write(s sparql.Solution) error
+	// Close completes the results, nothing can be written after closing.
+	Close() error
+	// WriteBoolean writes the complete result of an ASK query.
+	WriteBoolean(b bool) error
+}
@@ -0,0 +1,240 @@
+package results_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/sparql/results"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	alice = &rdf.IRIReference{Value: "http://example.org/alice"}
+	knows = &rdf.IRIReference{Value: "http://xmlns.com/foaf/0.1/knows"}
+	bob   = &rdf.BlankNode{Attribute: "_:bob"}
+)
+
+func ExampleWriteResult() {
+	g := rdf.NewGraph()
+	g.Add(alice, &rdf.IRIReference{Value: "http://xmlns.com/foaf/0.1/name"}, &rdf.Literal{
+		Value:    "Alice",
+		Datatype: rdf.XSDString,
+	})
+	result, _ := sparql.Execute(context.Background(), `SELECT ?s ?name { ?s ?p ?name }`, "", &rdf.Dataset{Default: g})
+	_ = results.WriteResult(results.NewTSVWriter(os.Stdout), result)
+	// Output:
+	// ?s	?name
+	// <http://example.org/alice>	"Alice"
+}
+
+func TestDecode(t *testing.T) {
+	for _, test := range []struct {
+		mediaType string
+		raw       string
+	}{
+		{results.JSONMediaType, `{
+  "head": {"vars": ["s", "o"]},
+  "results": {"bindings": [
+    {"s": {"type": "uri", "value": "http://example.org/alice"}, "o": {"type": "bnode", "value": "bob"}},
+    {"s": {"type": "literal", "value": "1", "datatype": "http://www.w3.org/2001/XMLSchema#integer"}}
+  ]}
+}`},
+		{results.XMLMediaType, `<?xml version="1.0"?>
+<sparql xmlns="http://www.w3.org/2005/sparql-results#">
+  <head><variable name="s"/><variable name="o"/></head>
+  <results>
+    <result>
+      <binding name="s"><uri>http://example.org/alice</uri></binding>
+      <binding name="o"><bnode>bob</bnode></binding>
+    </result>
+    <result>
+      <binding name="s"><literal datatype="http://www.w3.org/2001/XMLSchema#integer">1</literal></binding>
+    </result>
+  </results>
+</sparql>`},
+		{results.TSVMediaType + "; charset=utf-8", "?s\t?o\n<http://example.org/alice>\t_:bob\n1\t\n"},
+	} {
+		r, err := results.Decode(strings.NewReader(test.raw), test.mediaType)
+		if err != nil {
+			t.Fatal(test.mediaType, err)
+		}
+		expected := &results.Results{
+			Vars: []algebra.Var{"s", "o"},
+			Solutions: []sparql.Solution{
+				{"s": alice, "o": bob},
+				{"s": &rdf.Literal{Value: "1", Datatype: rdf.XSDInteger}},
+			},
+		}
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("%s: expected %v, got %v", test.mediaType, expected, r)
+		}
+	}
+	if _, err := results.Decode(strings.NewReader(""), "text/plain"); err == nil {
+		t.Error("expected an error for an unsupported media type")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	r := &results.Results{
+		Vars: []algebra.Var{"s", "o"},
+		Solutions: []sparql.Solution{
+			{"s": alice, "o": bob},
+			{"s": &rdf.Literal{Value: "a \"b\"\tc", Datatype: rdf.XSDString}},
+			{"o": &rdf.Literal{Value: "chat", Datatype: rdf.XSDNSString, Language: "fr"}},
+		},
+	}
+	for _, test := range []struct {
+		mediaType string
+		expected  string
+	}{
+		{results.JSONMediaType, `{"head":{"vars":["s","o"]},"results":{"bindings":[
+{"o":{"type":"bnode","value":"bob"},"s":{"type":"uri","value":"http://example.org/alice"}},
+{"s":{"type":"literal","value":"a \"b\"\tc"}},
+{"o":{"type":"literal","value":"chat","xml:lang":"fr"}}
+]}}
+`},
+		{results.XMLMediaType, `<?xml version="1.0"?>
+<sparql xmlns="http://www.w3.org/2005/sparql-results#" xmlns:its="http://www.w3.org/2005/11/its">
+  <head>
+    <variable name="s"/>
+    <variable name="o"/>
+  </head>
+  <results>
+    <result>
+      <binding name="s"><uri>http://example.org/alice</uri></binding>
+      <binding name="o"><bnode>bob</bnode></binding>
+    </result>
+    <result>
+      <binding name="s"><literal>a &#34;b&#34;&#x9;c</literal></binding>
+    </result>
+    <result>
+      <binding name="o"><literal xml:lang="fr">chat</literal></binding>
+    </result>
+  </results>
+</sparql>
+`},
+		{results.CSVMediaType, "s,o\r\nhttp://example.org/alice,_:bob\r\n\"a \"\"b\"\"\tc\",\r\n,chat\r\n"},
+		{results.TSVMediaType, "?s\t?o\n<http://example.org/alice>\t_:bob\n\"a \\\"b\\\"\\tc\"\t\n\t\"chat\"@fr\n"},
+	} {
+		var b bytes.Buffer
+		if err := results.Encode(&b, test.mediaType, r); err != nil {
+			t.Fatal(test.mediaType, err)
+		}
+		if b.String() != test.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", test.mediaType, test.expected, b.String())
+		}
+	}
+}
+
+func TestEncode_ask(t *testing.T) {
+	for _, mediaType := range results.MediaTypes {
+		for _, b := range []bool{true, false} {
+			var buf bytes.Buffer
+			if err := results.Encode(&buf, mediaType, &results.Results{Boolean: &b}); err != nil {
+				t.Fatal(mediaType, err)
+			}
+			r, err := results.Decode(&buf, mediaType)
+			if err != nil {
+				t.Fatal(mediaType, err)
+			}
+			if r.Boolean == nil || *r.Boolean != b {
+				t.Errorf("%s: expected %t, got %v", mediaType, b, r.Boolean)
+			}
+		}
+	}
+}
+
+func TestEncode_roundTrip(t *testing.T) {
+	r := &results.Results{
+		Vars: []algebra.Var{"s", "o"},
+		Solutions: []sparql.Solution{
+			{"s": alice, "o": &rdf.TripleTerm{Triple: rdf.Triple{Subject: alice, Predicate: knows, Object: bob}}},
+			{"s": bob, "o": &rdf.Literal{Value: "line\nbreak\ttab", Datatype: rdf.XSDString}},
+			{"o": &rdf.Literal{Value: "שלום", Datatype: rdf.XSDNSDirString, Language: "he", Direction: "rtl"}},
+			{"o": &rdf.Literal{Value: "1.5", Datatype: rdf.XSDDecimal}},
+			{},
+		},
+	}
+	for _, mediaType := range []string{results.JSONMediaType, results.XMLMediaType, results.TSVMediaType} {
+		var b bytes.Buffer
+		if err := results.Encode(&b, mediaType, r); err != nil {
+			t.Fatal(mediaType, err)
+		}
+		decoded, err := results.Decode(&b, mediaType)
+		if err != nil {
+			t.Fatal(mediaType, err)
+		}
+		if !reflect.DeepEqual(decoded, r) {
+			t.Errorf("%s: expected %v, got %v", mediaType, r, decoded)
+		}
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	r, err := results.DecodeCSV(strings.NewReader("s,o\r\nhttp://example.org/alice,_:bob\r\n,a b\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []sparql.Solution{
+		{"s": alice, "o": bob},
+		{"o": &rdf.Literal{Value: "a b", Datatype: rdf.XSDString}},
+	}
+	if !reflect.DeepEqual(r.Solutions, expected) {
+		t.Errorf("expected %v, got %v", expected, r.Solutions)
+	}
+}
+
+func TestDecodeTSV_invalid(t *testing.T) {
+	for _, raw := range []string{
+		"s\n",
+		"?s\n<http://example.org/alice\n",
+		"?s\n\"unterminated\n",
+		"?s\n<<( <http://example.org/a> <http://example.org/b> )>>\n",
+		"?s\nabc\n",
+		"?s\n<http://example.org/alice> <http://example.org/bob>\n",
+	} {
+		if _, err := results.DecodeTSV(strings.NewReader(raw)); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	g := rdf.NewGraph()
+	for i := 0; i < 3; i++ {
+		g.Add(&rdf.IRIReference{Value: fmt.Sprintf("http://example.org/%d", i)}, knows, bob)
+	}
+	d := &rdf.Dataset{Default: g}
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{`SELECT ?s { ?s ?p ?o } ORDER BY ?s`, "s\r\nhttp://example.org/0\r\nhttp://example.org/1\r\nhttp://example.org/2\r\n"},
+		{`ASK { ?s ?p ?o }`, "_askResult\r\ntrue\r\n"},
+	} {
+		result, err := sparql.Execute(context.Background(), test.query, "", d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := results.WriteResult(results.NewCSVWriter(&b), result); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, b.String())
+		}
+	}
+	result, err := sparql.Execute(context.Background(), `CONSTRUCT WHERE { ?s ?p ?o }`, "", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := results.WriteResult(results.NewCSVWriter(&bytes.Buffer{}), result); err == nil {
+		t.Error("expected an error for CONSTRUCT results")
+	}
+}
@@ -0,0 +1,194 @@
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/internal/escape"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DecodeTSV reads results in the SPARQL 1.1 Query Results TSV Format. Terms are written in the syntax of Turtle,
+// including the abbreviated forms of numbers and booleans, triple terms are written as "<<( s p o )>>".
+func DecodeTSV(r io.Reader) (*Results, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 16*1024*1024)
+	var records [][]string
+	for s.Scan() {
+		records = append(records, strings.Split(strings.TrimSuffix(s.Text(), "\r"), "\t"))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("tsv: missing header")
+	}
+	if b, ok := askResult(records[0], records[1:]); ok {
+		return &Results{Boolean: &b}, nil
+	}
+	results := &Results{Solutions: []sparql.Solution{}}
+	for _, v := range records[0] {
+		if v == "" {
+			// A result without variables has an empty header.
+			continue
+		}
+		if v[0] != '?' && v[0] != '$' {
+			return nil, fmt.Errorf("tsv: invalid variable %q", v)
+		}
+		results.Vars = append(results.Vars, algebra.Var(v[1:]))
+	}
+	for i, record := range records[1:] {
+		s := make(sparql.Solution)
+		for j, value := range record {
+			if value = strings.TrimSpace(value); value == "" || j >= len(results.Vars) {
+				continue
+			}
+			n, rest, err := parseTSVTerm(value)
+			if err == nil && strings.TrimSpace(rest) != "" {
+				err = fmt.Errorf("unexpected %q", rest)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("tsv: line %d: %w", i+2, err)
+			}
+			s[results.Vars[j]] = n
+		}
+		results.Solutions = append(results.Solutions, s)
+	}
+	return results, nil
+}
+
+// TSVWriter writes results in the SPARQL 1.1 Query Results TSV Format. The result of an ASK query is written as the
+// single binding of the variable "?_askResult".
+type TSVWriter struct {
+	w    *bufio.Writer
+	vars []algebra.Var
+}
+
+// NewTSVWriter returns a new TSVWriter that writes to w.
+func NewTSVWriter(w io.Writer) *TSVWriter {
+	return &TSVWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *TSVWriter) Close() error {
+	return w.w.Flush()
+}
+
+func (w *TSVWriter) Write(s sparql.Solution) error {
+	record := make([]string, len(w.vars))
+	for i, v := range w.vars {
+		if n, ok := s[v]; ok {
+			// Tabs can only occur in literals, they are escaped like newlines.
+			record[i] = strings.ReplaceAll(algebra.FormatNode(n), "\t", `\t`)
+		}
+	}
+	_, err := w.w.WriteString(strings.Join(record, "\t") + "\n")
+	return err
+}
+
+func (w *TSVWriter) WriteBoolean(b bool) error {
+	if _, err := fmt.Fprintf(w.w, "?%s\n%t\n", askVar, b); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *TSVWriter) WriteHead(vars []algebra.Var) error {
+	w.vars = vars
+	record := make([]string, len(vars))
+	for i, v := range vars {
+		record[i] = "?" + string(v)
+	}
+	_, err := w.w.WriteString(strings.Join(record, "\t") + "\n")
+	return err
+}
+
+// parseTSVTerm parses a term at the start of the value, returns the remainder of the value.
+func parseTSVTerm(value string) (rdf.Node, string, error) {
+	value = strings.TrimLeft(value, " ")
+	switch {
+	case strings.HasPrefix(value, "<<("):
+		rest := value[3:]
+		var nodes [3]rdf.Node
+		for i := range nodes {
+			n, r, err := parseTSVTerm(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes[i], rest = n, r
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if !strings.HasPrefix(rest, ")>>") {
+			return nil, "", fmt.Errorf("unterminated triple term %q", value)
+		}
+		triple := rdf.Triple{Subject: nodes[0], Predicate: nodes[1], Object: nodes[2]}
+		return &rdf.TripleTerm{Triple: triple}, rest[3:], nil
+	case strings.HasPrefix(value, "<"):
+		i := strings.IndexByte(value, '>')
+		if i == -1 {
+			return nil, "", fmt.Errorf("unterminated IRI %q", value)
+		}
+		return &rdf.IRIReference{Value: escape.Unescape(value[1:i])}, value[i+1:], nil
+	case strings.HasPrefix(value, "_:"):
+		i := strings.IndexAny(value, " )")
+		if i == -1 {
+			i = len(value)
+		}
+		return &rdf.BlankNode{Attribute: value[:i]}, value[i:], nil
+	case strings.HasPrefix(value, `"`):
+		i := 1
+		for ; i < len(value) && value[i] != '"'; i++ {
+			if value[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(value) {
+			return nil, "", fmt.Errorf("unterminated literal %q", value)
+		}
+		lexical, rest := escape.Unescape(value[1:i]), value[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "@"):
+			i := strings.IndexAny(rest, " )")
+			if i == -1 {
+				i = len(rest)
+			}
+			language, direction, _ := strings.Cut(rest[1:i], "--")
+			return newLiteral(lexical, "", language, direction), rest[i:], nil
+		case strings.HasPrefix(rest, "^^<"):
+			i := strings.IndexByte(rest, '>')
+			if i == -1 {
+				return nil, "", fmt.Errorf("unterminated datatype %q", rest)
+			}
+			return newLiteral(lexical, escape.Unescape(rest[3:i]), "", ""), rest[i+1:], nil
+		default:
+			return newLiteral(lexical, "", "", ""), rest, nil
+		}
+	default:
+		// Abbreviated numbers and booleans.
+		i := strings.IndexAny(value, " )")
+		if i == -1 {
+			i = len(value)
+		}
+		token := value[:i]
+		var datatype rdf.DataType
+		switch {
+		case token == "true" || token == "false":
+			datatype = rdf.XSDBoolean
+		case strings.ContainsAny(token, "eE"):
+			datatype = rdf.XSDDouble
+		case strings.Contains(token, "."):
+			datatype = rdf.XSDDecimal
+		default:
+			datatype = rdf.XSDInteger
+		}
+		if datatype != rdf.XSDBoolean {
+			if _, err := strconv.ParseFloat(token, 64); err != nil {
+				return nil, "", fmt.Errorf("invalid term %q", token)
+			}
+		}
+		return &rdf.Literal{Value: token, Datatype: datatype}, value[i:], nil
+	}
+}
@@ -0,0 +1,215 @@
+package results
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"io"
+	"strings"
+)
+
+const (
+	// xmlNS is the namespace of the SPARQL Query Results XML Format.
+	xmlNS = "http://www.w3.org/2005/sparql-results#"
+	// itsNS is the namespace of the its:dir attribute, the base direction of a literal.
+	itsNS = "http://www.w3.org/2005/11/its"
+)
+
+// DecodeXML reads results in the SPARQL Query Results XML Format. Triple terms are supported as defined by
+// SPARQL-star, i.e. <triple><subject>...</subject><predicate>...</predicate><object>...</object></triple>.
+func DecodeXML(r io.Reader) (*Results, error) {
+	var doc struct {
+		XMLName xml.Name `xml:"sparql"`
+		Head    struct {
+			Variables []struct {
+				Name string `xml:"name,attr"`
+			} `xml:"variable"`
+		} `xml:"head"`
+		Boolean *bool `xml:"boolean"`
+		Results []struct {
+			Bindings []struct {
+				Name string `xml:"name,attr"`
+				xmlTerm
+			} `xml:"binding"`
+		} `xml:"results>result"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Boolean != nil {
+		return &Results{Boolean: doc.Boolean}, nil
+	}
+	results := &Results{Vars: make([]algebra.Var, len(doc.Head.Variables)), Solutions: []sparql.Solution{}}
+	for i, v := range doc.Head.Variables {
+		results.Vars[i] = algebra.Var(v.Name)
+	}
+	for _, r := range doc.Results {
+		s := make(sparql.Solution, len(r.Bindings))
+		for _, b := range r.Bindings {
+			n, err := b.node()
+			if err != nil {
+				return nil, fmt.Errorf("xml: binding %s: %w", b.Name, err)
+			}
+			s[algebra.Var(b.Name)] = n
+		}
+		results.Solutions = append(results.Solutions, s)
+	}
+	return results, nil
+}
+
+// XMLWriter writes results in the SPARQL Query Results XML Format.
+type XMLWriter struct {
+	w    *bufio.Writer
+	vars []algebra.Var
+}
+
+// NewXMLWriter returns a new XMLWriter that writes to w.
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	return &XMLWriter{w: bufio.NewWriter(w)}
+}
+
+func (w *XMLWriter) Close() error {
+	if _, err := w.w.WriteString("  </results>\n</sparql>\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *XMLWriter) Write(s sparql.Solution) error {
+	var b strings.Builder
+	b.WriteString("    <result>\n")
+	for _, v := range w.vars {
+		n, ok := s[v]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "      <binding name=\"%s\">", escapeXML(string(v)))
+		if err := writeXMLTerm(&b, n); err != nil {
+			return err
+		}
+		b.WriteString("</binding>\n")
+	}
+	b.WriteString("    </result>\n")
+	_, err := w.w.WriteString(b.String())
+	return err
+}
+
+func (w *XMLWriter) WriteBoolean(b bool) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.w, "  <head/>\n  <boolean>%t</boolean>\n</sparql>\n", b); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *XMLWriter) WriteHead(vars []algebra.Var) error {
+	w.vars = vars
+	if err := w.start(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("  <head>\n")
+	for _, v := range vars {
+		fmt.Fprintf(&b, "    <variable name=\"%s\"/>\n", escapeXML(string(v)))
+	}
+	b.WriteString("  </head>\n  <results>\n")
+	_, err := w.w.WriteString(b.String())
+	return err
+}
+
+// start writes the XML declaration and the opening tag of the document.
+func (w *XMLWriter) start() error {
+	_, err := fmt.Fprintf(w.w, "<?xml version=\"1.0\"?>\n<sparql xmlns=\"%s\" xmlns:its=\"%s\">\n", xmlNS, itsNS)
+	return err
+}
+
+type xmlLiteral struct {
+	Value     string `xml:",chardata"`
+	Datatype  string `xml:"datatype,attr"`
+	Language  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Direction string `xml:"http://www.w3.org/2005/11/its dir,attr"`
+}
+
+// xmlTerm is an RDF term, exactly one of the fields is set.
+type xmlTerm struct {
+	URI     *string     `xml:"uri"`
+	BNode   *string     `xml:"bnode"`
+	Literal *xmlLiteral `xml:"literal"`
+	Triple  *xmlTriple  `xml:"triple"`
+}
+
+func (t xmlTerm) node() (rdf.Node, error) {
+	switch {
+	case t.URI != nil:
+		return &rdf.IRIReference{Value: strings.TrimSpace(*t.URI)}, nil
+	case t.BNode != nil:
+		return &rdf.BlankNode{Attribute: "_:" + strings.TrimSpace(*t.BNode)}, nil
+	case t.Literal != nil:
+		l := t.Literal
+		return newLiteral(l.Value, l.Datatype, l.Language, l.Direction), nil
+	case t.Triple != nil:
+		var nodes [3]rdf.Node
+		for i, t := range []xmlTerm{t.Triple.Subject, t.Triple.Predicate, t.Triple.Object} {
+			n, err := t.node()
+			if err != nil {
+				return nil, err
+			}
+			nodes[i] = n
+		}
+		return &rdf.TripleTerm{Triple: rdf.Triple{Subject: nodes[0], Predicate: nodes[1], Object: nodes[2]}}, nil
+	default:
+		return nil, fmt.Errorf("missing term")
+	}
+}
+
+type xmlTriple struct {
+	Subject   xmlTerm `xml:"subject"`
+	Predicate xmlTerm `xml:"predicate"`
+	Object    xmlTerm `xml:"object"`
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func writeXMLTerm(b *strings.Builder, n rdf.Node) error {
+	switch n := n.(type) {
+	case *rdf.IRIReference:
+		fmt.Fprintf(b, "<uri>%s</uri>", escapeXML(n.Value))
+	case *rdf.BlankNode:
+		fmt.Fprintf(b, "<bnode>%s</bnode>", escapeXML(strings.TrimPrefix(n.Attribute, "_:")))
+	case *rdf.Literal:
+		b.WriteString("<literal")
+		switch {
+		case n.Language != "":
+			fmt.Fprintf(b, " xml:lang=\"%s\"", escapeXML(n.Language))
+			if n.Direction != "" {
+				fmt.Fprintf(b, " its:dir=\"%s\"", escapeXML(n.Direction))
+			}
+		case n.Datatype != rdf.XSDString:
+			fmt.Fprintf(b, " datatype=\"%s\"", escapeXML(string(n.Datatype)))
+		}
+		fmt.Fprintf(b, ">%s</literal>", escapeXML(n.Value))
+	case *rdf.TripleTerm:
+		b.WriteString("<triple>")
+		for i, n := range []rdf.Node{n.Subject, n.Predicate, n.Object} {
+			name := [3]string{"subject", "predicate", "object"}[i]
+			fmt.Fprintf(b, "<%s>", name)
+			if err := writeXMLTerm(b, n); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "</%s>", name)
+		}
+		b.WriteString("</triple>")
+	default:
+		return fmt.Errorf("xml: unknown node type %T", n)
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package sparql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/rdfxml"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/turtle"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// EvaluateUpdate applies the operations of the update request to the dataset, in order. The request is applied
+// atomically: if an operation fails (or the context is done), the changes of all operations are reverted and the
+// dataset is left unchanged. Operations with the SILENT keyword do not fail, they are skipped instead.
+func EvaluateUpdate(ctx context.Context, u algebra.Update, d *rdf.Dataset) error {
+	t := &transaction{ctx: ctx, dataset: d, bnodes: bnodeAllocator{dataset: d}}
+	for _, o := range u {
+		err := ctx.Err()
+		if err == nil {
+			err = t.apply(o)
+		}
+		if err != nil {
+			t.rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteUpdate parses, translates and applies the update request to the dataset, relative IRIs are resolved against
+// the base.
+func ExecuteUpdate(ctx context.Context, update string, base string, d *rdf.Dataset) error {
+	u, err := ParseUpdate(update)
+	if err != nil {
+		return err
+	}
+	a, err := TranslateUpdate(u, base)
+	if err != nil {
+		return err
+	}
+	return EvaluateUpdate(ctx, a, d)
+}
+
+// transaction applies update operations to a dataset, every change is recorded so that it can be reverted.
+type transaction struct {
+	ctx     context.Context
+	dataset *rdf.Dataset
+	// undo contains the functions that revert the changes, in the order in which the changes were made.
+	undo []func()
+	// bnodes allocates the blank nodes that are created by the operations.
+	bnodes bnodeAllocator
+}
+
+// apply applies a single operation, errors of silent operations are ignored.
+func (t *transaction) apply(o algebra.UpdateOperation) error {
+	switch o := o.(type) {
+	case *algebra.Load:
+		return silent(o.Silent, t.load(o))
+	case *algebra.Clear:
+		names, err := t.targets(o.Target)
+		if err != nil {
+			return silent(o.Silent, err)
+		}
+		for _, name := range names {
+			t.clear(name)
+		}
+		return nil
+	case *algebra.Drop:
+		names, err := t.targets(o.Target)
+		if err != nil {
+			return silent(o.Silent, err)
+		}
+		for _, name := range names {
+			t.drop(name)
+		}
+		return nil
+	case *algebra.Create:
+		if t.dataset.Graph(o.Graph) != nil {
+			return silent(o.Silent, fmt.Errorf("create: graph %s already exists", algebra.FormatNode(o.Graph)))
+		}
+		t.graph(o.Graph)
+		return nil
+	case *algebra.Add:
+		return silent(o.Silent, t.transfer(o.Source, o.Destination, false, false))
+	case *algebra.Copy:
+		return silent(o.Silent, t.transfer(o.Source, o.Destination, true, false))
+	case *algebra.Move:
+		return silent(o.Silent, t.transfer(o.Source, o.Destination, true, true))
+	case algebra.InsertData:
+		bnodes := make(map[string]rdf.Node)
+		for _, q := range o {
+			name, triple, ok := t.instantiate(q, nil, nil, bnodes)
+			if !ok {
+				return fmt.Errorf("insert data: invalid quad %s", q)
+			}
+			t.insert(name, triple)
+		}
+		return nil
+	case algebra.DeleteData:
+		for _, q := range o {
+			name, triple, ok := t.instantiate(q, nil, nil, nil)
+			if !ok {
+				return fmt.Errorf("delete data: invalid quad %s", q)
+			}
+			t.delete(name, triple)
+		}
+		return nil
+	case *algebra.Modify:
+		return t.modify(o)
+	default:
+		return fmt.Errorf("unknown update operation type %T", o)
+	}
+}
+
+// clear removes all the triples of the graph.
+func (t *transaction) clear(name rdf.Node) {
+	g := t.dataset.Graph(name)
+	if g == nil {
+		return
+	}
+	for _, triple := range g.Triples() {
+		t.delete(name, triple)
+	}
+}
+
+// delete removes the triple from the graph, if it contains it.
+func (t *transaction) delete(name rdf.Node, triple *rdf.Triple) {
+	g := t.dataset.Graph(name)
+	if g == nil || !g.Contains(triple.Subject, triple.Predicate, triple.Object) {
+		return
+	}
+	g.Remove(triple)
+	// Graphs are looked up by name, a dropped graph is restored as a new graph.
+	t.undo = append(t.undo, func() { t.dataset.Graph(name).Add(triple.Subject, triple.Predicate, triple.Object) })
+}
+
+// drop removes the named graph, the default graph is cleared instead.
+func (t *transaction) drop(name rdf.Node) {
+	g := t.dataset.Graph(name)
+	if name == nil || g == nil {
+		t.clear(name)
+		return
+	}
+	t.dataset.DropGraph(name)
+	t.undo = append(t.undo, func() {
+		restored := t.dataset.CreateGraph(name)
+		for _, triple := range g.Triples() {
+			restored.Add(triple.Subject, triple.Predicate, triple.Object)
+		}
+	})
+}
+
+// graph returns the graph with the given name, it is created if it does not exist yet.
+func (t *transaction) graph(name rdf.Node) *rdf.Graph {
+	if g := t.dataset.Graph(name); g != nil {
+		return g
+	}
+	g := t.dataset.CreateGraph(name)
+	t.undo = append(t.undo, func() {
+		if name == nil {
+			t.dataset.Default = nil
+		} else {
+			t.dataset.DropGraph(name)
+		}
+	})
+	return g
+}
+
+// insert adds the triple to the graph, which is created if it does not exist yet.
+func (t *transaction) insert(name rdf.Node, triple *rdf.Triple) {
+	g := t.graph(name)
+	if g.Contains(triple.Subject, triple.Predicate, triple.Object) {
+		return
+	}
+	g.Add(triple.Subject, triple.Predicate, triple.Object)
+	t.undo = append(t.undo, func() { t.dataset.Graph(name).Remove(triple) })
+}
+
+// instantiate returns the graph name and triple of the quad, variables are replaced by their bindings in the
+// solution. Blank nodes are replaced by fresh blank nodes, unless bnodes is nil. Quads without a graph belong to the
+// given default graph. Returns false if a variable is unbound or the result is not a valid triple.
+func (t *transaction) instantiate(
+	q algebra.Quad, s Solution, with rdf.Node, bnodes map[string]rdf.Node,
+) (rdf.Node, *rdf.Triple, bool) {
+	node := func(term algebra.Term) rdf.Node {
+		switch term := term.(type) {
+		case algebra.Var:
+			return s[term]
+		case algebra.Constant:
+			if b, ok := term.Node.(*rdf.BlankNode); ok && bnodes != nil {
+				if _, ok := bnodes[b.Attribute]; !ok {
+					bnodes[b.Attribute] = t.bnodes.bnode()
+				}
+				return bnodes[b.Attribute]
+			}
+			return term.Node
+		default:
+			return nil
+		}
+	}
+	name := with
+	if q.Graph != nil {
+		if name = node(q.Graph); name == nil {
+			return nil, nil, false
+		}
+		if _, ok := name.(*rdf.IRIReference); !ok {
+			return nil, nil, false
+		}
+	}
+	subject, predicate, object := node(q.Subject), node(q.Predicate), node(q.Object)
+	if !validTriple(subject, predicate, object) {
+		return nil, nil, false
+	}
+	return name, rdf.NewTriple(subject, predicate, object), true
+}
+
+// load adds the triples of the local document to the graph, blank nodes are replaced by fresh blank nodes.
+func (t *transaction) load(l *algebra.Load) error {
+	g, err := loadDocument(l.IRI.Value)
+	if err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+	var into rdf.Node
+	if l.Into != nil {
+		into = l.Into
+	}
+	bnodes := make(map[string]rdf.Node)
+	fresh := func(n rdf.Node) rdf.Node {
+		if b, ok := n.(*rdf.BlankNode); ok {
+			if _, ok := bnodes[b.Attribute]; !ok {
+				bnodes[b.Attribute] = t.bnodes.bnode()
+			}
+			return bnodes[b.Attribute]
+		}
+		return n
+	}
+	t.graph(into)
+	for _, triple := range g.Triples() {
+		t.insert(into, rdf.NewTriple(fresh(triple.Subject), triple.Predicate, fresh(triple.Object)))
+	}
+	return nil
+}
+
+// modify evaluates the pattern, after which the instantiations of the delete template are removed and those of the
+// insert template are added. Instantiations with unbound variables or invalid terms are skipped.
+func (t *transaction) modify(m *algebra.Modify) error {
+	e := newEvaluator(t.ctx, &algebra.Query{From: m.Using, FromNamed: m.UsingNamed}, t.dataset)
+	var with rdf.Node
+	if m.With != nil {
+		with = m.With
+		if len(m.Using) == 0 && len(m.UsingNamed) == 0 {
+			e.defaultGraph = t.dataset.Union(with)
+		}
+	}
+	solutions, err := (&Solutions{next: e.eval(m.Operator, e.defaultGraph), ctx: t.ctx}).All()
+	if err != nil {
+		return err
+	}
+	type quad struct {
+		name   rdf.Node
+		triple *rdf.Triple
+	}
+	var deletes, inserts []quad
+	for _, s := range solutions {
+		for _, q := range m.Delete {
+			if name, triple, ok := t.instantiate(q, s, with, nil); ok {
+				deletes = append(deletes, quad{name, triple})
+			}
+		}
+		bnodes := make(map[string]rdf.Node)
+		for _, q := range m.Insert {
+			if name, triple, ok := t.instantiate(q, s, with, bnodes); ok {
+				inserts = append(inserts, quad{name, triple})
+			}
+		}
+	}
+	for _, q := range deletes {
+		t.delete(q.name, q.triple)
+	}
+	for _, q := range inserts {
+		t.insert(q.name, q.triple)
+	}
+	return nil
+}
+
+// rollback reverts all changes, in reverse order.
+func (t *transaction) rollback() {
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+}
+
+// targets returns the names of the graphs of the target, nil refers to the default graph. Returns an error if the
+// target is a graph that does not exist.
+func (t *transaction) targets(target algebra.Target) ([]rdf.Node, error) {
+	switch {
+	case target.All:
+		return append([]rdf.Node{nil}, t.dataset.Names()...), nil
+	case target.Named:
+		return t.dataset.Names(), nil
+	case target.Graph == nil:
+		return []rdf.Node{nil}, nil
+	case t.dataset.Graph(target.Graph) == nil:
+		return nil, fmt.Errorf("graph %s does not exist", algebra.FormatNode(target.Graph))
+	default:
+		return []rdf.Node{target.Graph}, nil
+	}
+}
+
+// transfer adds the triples of the source graph to the destination graph. The destination is cleared first if
+// replace is true, the source is dropped afterwards if move is true. Nothing happens if both graphs are the same.
+func (t *transaction) transfer(src, dst rdf.Node, replace, move bool) error {
+	if src != nil && t.dataset.Graph(src) == nil {
+		return fmt.Errorf("graph %s does not exist", algebra.FormatNode(src))
+	}
+	if (src == nil && dst == nil) || (src != nil && dst != nil && sameTerm(src, dst)) {
+		return nil
+	}
+	var triples []*rdf.Triple
+	if g := t.dataset.Graph(src); g != nil {
+		triples = g.Triples()
+	}
+	if replace {
+		t.clear(dst)
+	}
+	t.graph(dst)
+	for _, triple := range triples {
+		t.insert(dst, triple)
+	}
+	if move {
+		t.drop(src)
+	}
+	return nil
+}
+
+// loadDocument reads the local document, the format is determined by the file extension: N-Triples (.nt), Turtle
+// (.ttl) or RDF/XML (.rdf). The IRI is either a file IRI or a file path.
+func loadDocument(iri string) (*rdf.Graph, error) {
+	u, err := url.Parse(iri)
+	if err != nil {
+		return nil, err
+	}
+	path := iri
+	switch u.Scheme {
+	case "file":
+		path = u.Path
+	case "":
+	default:
+		return nil, fmt.Errorf("only local files can be loaded, not %s", iri)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc nt.Document
+	switch filepath.Ext(path) {
+	case ".nt":
+		doc, err = nt.ParseDocument(string(raw))
+	case ".ttl":
+		var d turtle.Document
+		if d, err = turtle.ParseDocument(string(raw)); err == nil {
+			doc, err = turtle.EvaluateDocument(d, iri)
+		}
+	case ".rdf":
+		doc, err = rdfxml.ParseDocument(bytes.NewReader(raw), iri)
+	default:
+		return nil, fmt.Errorf("unknown format of %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rdf.FromNTriples(doc), nil
+}
+
+// silent ignores the error if the operation is silent.
+func silent(silent bool, err error) error {
+	if silent {
+		return nil
+	}
+	return err
+}
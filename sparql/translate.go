@@ -0,0 +1,1021 @@
+package sparql
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	"github.com/0x51-dev/rdf/internal/escape"
+	"github.com/0x51-dev/rdf/iri"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"github.com/0x51-dev/rdf/turtle"
+	"strings"
+)
+
+const (
+	rdfFirst = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfNil   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	rdfRest  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfType  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+)
+
+// Translate translates the query into an algebra expression, relative IRIs are resolved against the base.
+func Translate(q *Query, base string) (*algebra.Query, error) {
+	return NewContext().translateQuery(q, base)
+}
+
+// TranslateUpdate translates the update request into algebra operations, relative IRIs are resolved against the base.
+// The prefixes of an operation remain in scope for the operations that follow.
+func TranslateUpdate(u Update, base string) (algebra.Update, error) {
+	ctx := NewContext()
+	ctx.Base = base
+	r := algebra.Update{}
+	for _, o := range u {
+		if err := ctx.prologue(o.Prologue); err != nil {
+			return nil, err
+		}
+		a, err := ctx.translateUpdateOperation(o.Update)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, a)
+	}
+	return r, nil
+}
+
+// Context contains the state of the translation, the prefixes and base are evaluated by the Turtle context.
+type Context struct {
+	*turtle.Context
+
+	AggIndex int
+
+	// aggregates contains the aggregates of the current query, nil if aggregates are not allowed.
+	aggregates *[]algebra.Aggregate
+	// paths contains the path patterns of the current triples block, nil if paths are not allowed.
+	paths *[]algebra.Operator
+}
+
+func NewContext() *Context {
+	return &Context{Context: turtle.NewContext()}
+}
+
+// TranslateExpression translates the expression, aggregates are not allowed.
+func (ctx *Context) TranslateExpression(e Expression) (algebra.Expression, error) {
+	switch e := e.(type) {
+	case *Var:
+		return algebra.Var(*e), nil
+	case *turtle.IRI:
+		return ctx.constant(e)
+	case turtle.Literal:
+		return ctx.constant(e)
+	case *BinaryExpression:
+		left, err := ctx.TranslateExpression(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ctx.TranslateExpression(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Call{Function: e.Operator, Args: []algebra.Expression{left, right}}, nil
+	case *UnaryExpression:
+		arg, err := ctx.TranslateExpression(e.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Call{Function: e.Operator, Args: []algebra.Expression{arg}}, nil
+	case *InExpression:
+		args, err := ctx.translateExpressions(append([]Expression{e.Expression}, e.List...))
+		if err != nil {
+			return nil, err
+		}
+		if e.Not {
+			return &algebra.Call{Function: "NOT IN", Args: args}, nil
+		}
+		return &algebra.Call{Function: "IN", Args: args}, nil
+	case *BuiltInCall:
+		args, err := ctx.translateExpressions(e.Args)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Call{Function: e.Name, Args: args}, nil
+	case *FunctionCall:
+		f, err := ctx.iri(e.IRI)
+		if err != nil {
+			return nil, err
+		}
+		args, err := ctx.translateExpressions(e.Args)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Call{Function: f.Value, Args: args, Distinct: e.Distinct}, nil
+	case *ExistsExpression:
+		// Aggregates are not allowed within the pattern, it is evaluated per solution.
+		aggregates := ctx.aggregates
+		ctx.aggregates = nil
+		defer func() { ctx.aggregates = aggregates }()
+		p, err := ctx.TranslateGroupGraphPattern(e.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Exists{Not: e.Not, Pattern: p}, nil
+	case *Aggregate:
+		return ctx.translateAggregate(e)
+	default:
+		return nil, fmt.Errorf("unknown expression type %T", e)
+	}
+}
+
+// TranslateGroupGraphPattern translates the group, as defined in section 18.2.2.6 of the specification.
+func (ctx *Context) TranslateGroupGraphPattern(g *GroupGraphPattern) (algebra.Operator, error) {
+	if g.SubSelect != nil {
+		q, err := ctx.translateSelect(g.SubSelect)
+		if err != nil {
+			return nil, err
+		}
+		return q.Operator, nil
+	}
+	var filters []algebra.Expression
+	var op algebra.Operator = algebra.BGP{}
+	for _, p := range g.Patterns {
+		switch p := p.(type) {
+		case TriplesBlock:
+			var paths []algebra.Operator
+			ctx.paths = &paths
+			ts, err := ctx.TranslateTriples(p, true)
+			ctx.paths = nil
+			if err != nil {
+				return nil, err
+			}
+			op = join(op, algebra.BGP(ts))
+			for _, p := range paths {
+				op = join(op, p)
+			}
+		case *GroupGraphPattern:
+			a, err := ctx.TranslateGroupGraphPattern(p)
+			if err != nil {
+				return nil, err
+			}
+			op = join(op, a)
+		case UnionGraphPattern:
+			var union algebra.Operator
+			for _, p := range p {
+				a, err := ctx.TranslateGroupGraphPattern(p)
+				if err != nil {
+					return nil, err
+				}
+				if union == nil {
+					union = a
+				} else {
+					union = &algebra.Union{Left: union, Right: a}
+				}
+			}
+			op = join(op, union)
+		case *OptionalGraphPattern:
+			a, err := ctx.TranslateGroupGraphPattern(p.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			if f, ok := a.(*algebra.Filter); ok {
+				op = &algebra.LeftJoin{Left: op, Right: f.Operator, Expression: conjunction(f.Expressions)}
+			} else {
+				op = &algebra.LeftJoin{Left: op, Right: a}
+			}
+		case *MinusGraphPattern:
+			a, err := ctx.TranslateGroupGraphPattern(p.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			op = &algebra.Minus{Left: op, Right: a}
+		case *GraphGraphPattern:
+			name, err := ctx.translateTerm(p.Name)
+			if err != nil {
+				return nil, err
+			}
+			a, err := ctx.TranslateGroupGraphPattern(p.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			op = join(op, &algebra.Graph{Name: name, Operator: a})
+		case *Filter:
+			e, err := ctx.TranslateExpression(p.Expression)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, e)
+		case *Bind:
+			if inScope(op, algebra.Var(p.Var)) {
+				return nil, fmt.Errorf("bind: variable %s is already in scope", p.Var)
+			}
+			e, err := ctx.TranslateExpression(p.Expression)
+			if err != nil {
+				return nil, err
+			}
+			op = &algebra.Extend{Operator: op, Var: algebra.Var(p.Var), Expression: e}
+		case *InlineData:
+			t, err := ctx.translateInlineData(p)
+			if err != nil {
+				return nil, err
+			}
+			op = join(op, t)
+		default:
+			return nil, fmt.Errorf("unknown pattern type %T", p)
+		}
+	}
+	if len(filters) != 0 {
+		op = &algebra.Filter{Expressions: filters, Operator: op}
+	}
+	return op, nil
+}
+
+// TranslateTriples translates the triples to triple patterns. Blank nodes are translated to variables if blankVars
+// is true (query patterns), otherwise they remain blank nodes (templates).
+func (ctx *Context) TranslateTriples(ts []*TriplesSameSubject, blankVars bool) ([]algebra.TriplePattern, error) {
+	var patterns []algebra.TriplePattern
+	for _, t := range ts {
+		s, ps, err := ctx.translateNode(t.Subject, blankVars)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, ps...)
+		ps, err = ctx.translatePropertyList(s, t.PropertyList, blankVars)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, ps...)
+	}
+	return patterns, nil
+}
+
+// bn returns a fresh blank node, or a variable that represents it.
+func (ctx *Context) bn(blankVars bool) algebra.Term {
+	ctx.BnIndex++
+	label := fmt.Sprintf("b%d", ctx.BnIndex)
+	if blankVars {
+		return algebra.Var("_:" + label)
+	}
+	return algebra.Constant{Node: &rdf.BlankNode{Attribute: "_:" + label}}
+}
+
+func (ctx *Context) constant(t Term) (algebra.Constant, error) {
+	var l *nt.Literal
+	var err error
+	switch t := t.(type) {
+	case *turtle.IRI:
+		i, err := ctx.iri(t)
+		if err != nil {
+			return algebra.Constant{}, err
+		}
+		return algebra.Constant{Node: i}, nil
+	case turtle.A:
+		return algebra.Constant{Node: &rdf.IRIReference{Value: rdfType}}, nil
+	case Nil:
+		return algebra.Constant{Node: &rdf.IRIReference{Value: rdfNil}}, nil
+	case *turtle.StringLiteral:
+		l, err = ctx.EvaluateStringLiteral(t)
+	case *turtle.NumericLiteral:
+		l, err = ctx.EvaluateNumericLiteral(t)
+	case *turtle.BooleanLiteral:
+		l, err = ctx.EvaluateBooleanLiteral(t)
+	default:
+		return algebra.Constant{}, fmt.Errorf("unknown term type %T", t)
+	}
+	if err != nil {
+		return algebra.Constant{}, err
+	}
+	return algebra.Constant{Node: fromNTriplesLiteral(*l)}, nil
+}
+
+func (ctx *Context) iri(i *turtle.IRI) (*rdf.IRIReference, error) {
+	r, err := ctx.EvaluateIRI(i)
+	if err != nil {
+		return nil, err
+	}
+	return &rdf.IRIReference{Value: escape.Unescape(string(*r))}, nil
+}
+
+// translateAggregate replaces the aggregate by a variable, the aggregate itself is evaluated by the group.
+func (ctx *Context) translateAggregate(a *Aggregate) (algebra.Expression, error) {
+	if ctx.aggregates == nil {
+		return nil, fmt.Errorf("aggregate %s is not allowed here", a.Name)
+	}
+	aggregate := algebra.Aggregate{Name: a.Name, Distinct: a.Distinct, Separator: " "}
+	if a.Expression != nil {
+		// Nested aggregates are not allowed.
+		aggregates := ctx.aggregates
+		ctx.aggregates = nil
+		e, err := ctx.TranslateExpression(a.Expression)
+		ctx.aggregates = aggregates
+		if err != nil {
+			return nil, err
+		}
+		aggregate.Expression = e
+	}
+	if a.Separator != nil {
+		s, err := ctx.EvaluateStringLiteral(a.Separator)
+		if err != nil {
+			return nil, err
+		}
+		aggregate.Separator = escape.Unescape(s.Value)
+	}
+	ctx.AggIndex++
+	aggregate.Var = algebra.Var(fmt.Sprintf(".%d", ctx.AggIndex))
+	*ctx.aggregates = append(*ctx.aggregates, aggregate)
+	return aggregate.Var, nil
+}
+
+func (ctx *Context) translateExpressions(es []Expression) ([]algebra.Expression, error) {
+	args := make([]algebra.Expression, len(es))
+	for i, e := range es {
+		a, err := ctx.TranslateExpression(e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = a
+	}
+	return args, nil
+}
+
+func (ctx *Context) translateInlineData(d *InlineData) (*algebra.Table, error) {
+	t := &algebra.Table{}
+	for _, v := range d.Vars {
+		t.Vars = append(t.Vars, algebra.Var(v))
+	}
+	for _, row := range d.Rows {
+		values := make([]rdf.Node, len(row))
+		for i, v := range row {
+			if v == nil {
+				continue
+			}
+			c, err := ctx.constant(v)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = c.Node
+		}
+		t.Rows = append(t.Rows, values)
+	}
+	return t, nil
+}
+
+// translateModifiers translates the grouping, aggregates, projection and solution modifiers of the query, as defined
+// in section 18.2.4 of the specification. Returns the projected variables.
+func (ctx *Context) translateModifiers(q *Query, op algebra.Operator) (algebra.Operator, []algebra.Var, error) {
+	var aggregates []algebra.Aggregate
+	ctx.aggregates = &aggregates
+	defer func() { ctx.aggregates = nil }()
+
+	// The expressions are translated first, to collect the aggregates.
+	projection := make([]algebra.Expression, len(q.Projection))
+	for i, p := range q.Projection {
+		if p.Expression == nil {
+			continue
+		}
+		e, err := ctx.TranslateExpression(p.Expression)
+		if err != nil {
+			return nil, nil, err
+		}
+		projection[i] = e
+	}
+	having, err := ctx.translateExpressions(q.Having)
+	if err != nil {
+		return nil, nil, err
+	}
+	var order []algebra.OrderCondition
+	for _, c := range q.OrderBy {
+		e, err := ctx.TranslateExpression(c.Expression)
+		if err != nil {
+			return nil, nil, err
+		}
+		order = append(order, algebra.OrderCondition{Expression: e, Descending: c.Descending})
+	}
+
+	grouped := len(q.GroupBy) != 0 || len(aggregates) != 0
+	if grouped {
+		ctx.aggregates = nil
+		var keys []algebra.Expression
+		// Variables that can be used after grouping.
+		groupVars := make(map[algebra.Var]bool)
+		for _, c := range q.GroupBy {
+			e, err := ctx.TranslateExpression(c.Expression)
+			if err != nil {
+				return nil, nil, err
+			}
+			if c.Var != "" {
+				v := algebra.Var(c.Var)
+				op = &algebra.Extend{Operator: op, Var: v, Expression: e}
+				e = v
+			}
+			if v, ok := e.(algebra.Var); ok {
+				groupVars[v] = true
+			}
+			keys = append(keys, e)
+		}
+		for _, a := range aggregates {
+			groupVars[a.Var] = true
+		}
+		op = &algebra.Group{Keys: keys, Aggregates: aggregates, Operator: op}
+		if q.Type == algebra.Select && len(q.Projection) == 0 {
+			return nil, nil, fmt.Errorf("SELECT * is not allowed with GROUP BY")
+		}
+		for i, p := range q.Projection {
+			var vars []algebra.Var
+			if projection[i] == nil {
+				vars = []algebra.Var{algebra.Var(p.Var)}
+			} else {
+				vars = algebra.Vars(projection[i])
+			}
+			for _, v := range vars {
+				if !groupVars[v] {
+					return nil, nil, fmt.Errorf("variable %s is not a group key", v)
+				}
+			}
+			groupVars[algebra.Var(p.Var)] = true
+		}
+	}
+	if len(having) != 0 {
+		op = &algebra.Filter{Expressions: having, Operator: op}
+	}
+	if q.Values != nil {
+		t, err := ctx.translateInlineData(q.Values)
+		if err != nil {
+			return nil, nil, err
+		}
+		op = join(op, t)
+	}
+
+	var vars []algebra.Var
+	if q.Type == algebra.Select {
+		if len(q.Projection) == 0 {
+			vars = algebra.InScope(op)
+		}
+		for i, p := range q.Projection {
+			v := algebra.Var(p.Var)
+			if projection[i] != nil {
+				if inScope(op, v) {
+					return nil, nil, fmt.Errorf("variable %s is already in scope", v)
+				}
+				op = &algebra.Extend{Operator: op, Var: v, Expression: projection[i]}
+			}
+			vars = append(vars, v)
+		}
+	}
+	if len(order) != 0 {
+		op = &algebra.OrderBy{Conditions: order, Operator: op}
+	}
+	if q.Type == algebra.Select {
+		op = &algebra.Project{Vars: vars, Operator: op}
+	}
+	switch q.Modifier {
+	case "DISTINCT":
+		op = &algebra.Distinct{Operator: op}
+	case "REDUCED":
+		op = &algebra.Reduced{Operator: op}
+	}
+	if q.Limit != -1 || q.Offset != 0 {
+		op = &algebra.Slice{Offset: q.Offset, Limit: q.Limit, Operator: op}
+	}
+	return op, vars, nil
+}
+
+// translateNode translates a term of a triple, collections and blank node property lists result in additional
+// triple patterns.
+func (ctx *Context) translateNode(t Term, blankVars bool) (algebra.Term, []algebra.TriplePattern, error) {
+	switch t := t.(type) {
+	case *turtle.BlankNode:
+		if *t == "[]" {
+			return ctx.bn(blankVars), nil, nil
+		}
+		if blankVars {
+			return algebra.Var("_:" + string(*t)), nil, nil
+		}
+		return algebra.Constant{Node: &rdf.BlankNode{Attribute: "_:" + string(*t)}}, nil, nil
+	case Collection:
+		var patterns []algebra.TriplePattern
+		first := algebra.Constant{Node: &rdf.IRIReference{Value: rdfFirst}}
+		rest := algebra.Constant{Node: &rdf.IRIReference{Value: rdfRest}}
+		head := ctx.bn(blankVars)
+		for i, n := head, 0; n < len(t); n++ {
+			o, ps, err := ctx.translateNode(t[n], blankVars)
+			if err != nil {
+				return nil, nil, err
+			}
+			patterns = append(patterns, ps...)
+			patterns = append(patterns, algebra.TriplePattern{Subject: i, Predicate: first, Object: o})
+			var next algebra.Term = algebra.Constant{Node: &rdf.IRIReference{Value: rdfNil}}
+			if n+1 != len(t) {
+				next = ctx.bn(blankVars)
+			}
+			patterns = append(patterns, algebra.TriplePattern{Subject: i, Predicate: rest, Object: next})
+			i = next
+		}
+		return head, patterns, nil
+	case BlankNodePropertyList:
+		s := ctx.bn(blankVars)
+		patterns, err := ctx.translatePropertyList(s, t, blankVars)
+		if err != nil {
+			return nil, nil, err
+		}
+		return s, patterns, nil
+	default:
+		term, err := ctx.translateTerm(t)
+		return term, nil, err
+	}
+}
+
+func (ctx *Context) translatePropertyList(
+	s algebra.Term, pl []PredicateObject, blankVars bool,
+) ([]algebra.TriplePattern, error) {
+	var patterns []algebra.TriplePattern
+	for _, po := range pl {
+		var p algebra.Term
+		var path algebra.PathExpression
+		var err error
+		switch po.Verb.(type) {
+		case PathAlternative, PathSequence, PathElt, PathInverse, PathNegatedPropertySet:
+			if ctx.paths == nil {
+				return nil, fmt.Errorf("property paths are not allowed in templates")
+			}
+			path, err = ctx.translatePath(po.Verb)
+		default:
+			p, err = ctx.translateTerm(po.Verb)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range po.Objects {
+			o, ps, err := ctx.translateNode(o, blankVars)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, ps...)
+			if path != nil {
+				patterns = append(patterns, ctx.translatePathPattern(s, path, o)...)
+			} else {
+				patterns = append(patterns, algebra.TriplePattern{Subject: s, Predicate: p, Object: o})
+			}
+		}
+	}
+	return patterns, nil
+}
+
+// translatePath translates the property path, as defined in section 18.2.2.3 of the specification.
+func (ctx *Context) translatePath(t Term) (algebra.PathExpression, error) {
+	switch t := t.(type) {
+	case PathAlternative:
+		return ctx.translatePaths(t, func(l, r algebra.PathExpression) algebra.PathExpression {
+			return &algebra.Alternative{Left: l, Right: r}
+		})
+	case PathSequence:
+		return ctx.translatePaths(t, func(l, r algebra.PathExpression) algebra.PathExpression {
+			return &algebra.Sequence{Left: l, Right: r}
+		})
+	case PathElt:
+		p, err := ctx.translatePath(t.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch t.Modifier {
+		case "?":
+			return &algebra.ZeroOrOne{Path: p}, nil
+		case "*":
+			return &algebra.ZeroOrMore{Path: p}, nil
+		case "+":
+			return &algebra.OneOrMore{Path: p}, nil
+		default:
+			return nil, fmt.Errorf("unknown path modifier %q", t.Modifier)
+		}
+	case PathInverse:
+		p, err := ctx.translatePath(t.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Inverse{Path: p}, nil
+	case PathNegatedPropertySet:
+		// !(:a | ^:b) is translated to (alt (notoneof :a) (reverse (notoneof :b))).
+		forward, inverse := algebra.NegatedPropertySet{}, algebra.NegatedPropertySet{}
+		for _, e := range t {
+			if i, ok := e.(PathInverse); ok {
+				c, err := ctx.constant(i.Path)
+				if err != nil {
+					return nil, err
+				}
+				inverse = append(inverse, c.Node.(*rdf.IRIReference))
+				continue
+			}
+			c, err := ctx.constant(e)
+			if err != nil {
+				return nil, err
+			}
+			forward = append(forward, c.Node.(*rdf.IRIReference))
+		}
+		switch {
+		case len(inverse) == 0:
+			return forward, nil
+		case len(forward) == 0:
+			return &algebra.Inverse{Path: inverse}, nil
+		default:
+			return &algebra.Alternative{Left: forward, Right: &algebra.Inverse{Path: inverse}}, nil
+		}
+	case *turtle.IRI, turtle.A:
+		c, err := ctx.constant(t)
+		if err != nil {
+			return nil, err
+		}
+		return algebra.Link{Predicate: c.Node.(*rdf.IRIReference)}, nil
+	default:
+		return nil, fmt.Errorf("unknown path type %T", t)
+	}
+}
+
+// translatePaths translates the paths and combines them from left to right.
+func (ctx *Context) translatePaths(
+	ts []Term, combine func(l, r algebra.PathExpression) algebra.PathExpression,
+) (algebra.PathExpression, error) {
+	var path algebra.PathExpression
+	for _, t := range ts {
+		p, err := ctx.translatePath(t)
+		if err != nil {
+			return nil, err
+		}
+		if path == nil {
+			path = p
+		} else {
+			path = combine(path, p)
+		}
+	}
+	return path, nil
+}
+
+// translatePathPattern translates the path pattern into triple patterns, as defined in section 18.2.2.4 of the
+// specification. Links and inverse links result in a triple pattern and sequences are split by a fresh variable. All
+// other paths are added as path operator to the paths of the context.
+func (ctx *Context) translatePathPattern(
+	s algebra.Term, p algebra.PathExpression, o algebra.Term,
+) []algebra.TriplePattern {
+	switch p := p.(type) {
+	case algebra.Link:
+		return []algebra.TriplePattern{{Subject: s, Predicate: algebra.Constant{Node: p.Predicate}, Object: o}}
+	case *algebra.Inverse:
+		if l, ok := p.Path.(algebra.Link); ok {
+			return []algebra.TriplePattern{{Subject: o, Predicate: algebra.Constant{Node: l.Predicate}, Object: s}}
+		}
+	case *algebra.Sequence:
+		v := ctx.bn(true)
+		return append(ctx.translatePathPattern(s, p.Left, v), ctx.translatePathPattern(v, p.Right, o)...)
+	}
+	*ctx.paths = append(*ctx.paths, &algebra.Path{Subject: s, Path: p, Object: o})
+	return nil
+}
+
+// prologue evaluates the BASE and PREFIX directives.
+func (ctx *Context) prologue(prologue []turtle.Directive) error {
+	for _, d := range prologue {
+		switch d := d.(type) {
+		case *turtle.Base:
+			ctx.Base = iri.Resolve(ctx.Base, string(*d))
+		case *turtle.Prefix:
+			ctx.Prefixes[d.Name] = iri.Resolve(ctx.Base, d.IRI)
+		default:
+			return fmt.Errorf("unknown directive type %T", d)
+		}
+	}
+	return nil
+}
+
+func (ctx *Context) translateQuery(q *Query, base string) (*algebra.Query, error) {
+	ctx.Base = base
+	if err := ctx.prologue(q.Prologue); err != nil {
+		return nil, err
+	}
+
+	if q.Type == algebra.Select {
+		return ctx.translateSelect(q)
+	}
+	r := &algebra.Query{Type: q.Type}
+	if err := ctx.translateDataset(q, r); err != nil {
+		return nil, err
+	}
+	var op algebra.Operator = &algebra.Table{Rows: [][]rdf.Node{{}}}
+	if q.Where != nil {
+		var err error
+		if op, err = ctx.TranslateGroupGraphPattern(q.Where); err != nil {
+			return nil, err
+		}
+	}
+	op, _, err := ctx.translateModifiers(q, op)
+	if err != nil {
+		return nil, err
+	}
+	r.Operator = op
+	switch q.Type {
+	case algebra.Construct:
+		if r.Template, err = ctx.TranslateTriples(q.Template, false); err != nil {
+			return nil, err
+		}
+	case algebra.Describe:
+		for _, t := range q.Describe {
+			term, err := ctx.translateTerm(t)
+			if err != nil {
+				return nil, err
+			}
+			r.Describe = append(r.Describe, term)
+		}
+		if len(q.Describe) == 0 {
+			for _, v := range algebra.InScope(op) {
+				r.Describe = append(r.Describe, v)
+			}
+		}
+	}
+	return r, nil
+}
+
+func (ctx *Context) translateDataset(q *Query, r *algebra.Query) error {
+	for _, d := range q.Dataset {
+		i, err := ctx.iri(d.IRI)
+		if err != nil {
+			return err
+		}
+		if d.Named {
+			r.FromNamed = append(r.FromNamed, i.Value)
+		} else {
+			r.From = append(r.From, i.Value)
+		}
+	}
+	return nil
+}
+
+// translateQuads translates the quads to quad patterns, blank nodes remain blank nodes.
+func (ctx *Context) translateQuads(qs Quads) ([]algebra.Quad, error) {
+	var quads []algebra.Quad
+	for _, g := range qs {
+		var name algebra.Term
+		if g.Graph != nil {
+			var err error
+			if name, err = ctx.translateTerm(g.Graph); err != nil {
+				return nil, err
+			}
+		}
+		ts, err := ctx.TranslateTriples(g.Triples, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ts {
+			quads = append(quads, algebra.Quad{Graph: name, TriplePattern: t})
+		}
+	}
+	return quads, nil
+}
+
+func (ctx *Context) translateSelect(q *Query) (*algebra.Query, error) {
+	r := &algebra.Query{Type: algebra.Select}
+	if err := ctx.translateDataset(q, r); err != nil {
+		return nil, err
+	}
+	op, err := ctx.TranslateGroupGraphPattern(q.Where)
+	if err != nil {
+		return nil, err
+	}
+	aggregates := ctx.aggregates
+	defer func() { ctx.aggregates = aggregates }()
+	if r.Operator, r.Vars, err = ctx.translateModifiers(q, op); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// translateTerm translates a variable or RDF term (no blank nodes).
+func (ctx *Context) translateTerm(t Term) (algebra.Term, error) {
+	if v, ok := t.(*Var); ok {
+		return algebra.Var(*v), nil
+	}
+	return ctx.constant(t)
+}
+
+func (ctx *Context) translateUpdateOperation(o UpdateOperation) (algebra.UpdateOperation, error) {
+	switch o := o.(type) {
+	case *Load:
+		i, err := ctx.iri(o.IRI)
+		if err != nil {
+			return nil, err
+		}
+		l := &algebra.Load{Silent: o.Silent, IRI: i}
+		if o.Into != nil {
+			if l.Into, err = ctx.iri(o.Into); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case *Clear:
+		t, err := ctx.translateGraphRef(o.Target)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Clear{Silent: o.Silent, Target: *t}, nil
+	case *Drop:
+		t, err := ctx.translateGraphRef(o.Target)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Drop{Silent: o.Silent, Target: *t}, nil
+	case *Create:
+		i, err := ctx.iri(o.Graph)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Create{Silent: o.Silent, Graph: i}, nil
+	case *Transfer:
+		var graphs [2]rdf.Node
+		for i, g := range []*turtle.IRI{o.Source, o.Destination} {
+			if g == nil {
+				continue
+			}
+			name, err := ctx.iri(g)
+			if err != nil {
+				return nil, err
+			}
+			graphs[i] = name
+		}
+		switch o.Kind {
+		case "ADD":
+			return &algebra.Add{Silent: o.Silent, Source: graphs[0], Destination: graphs[1]}, nil
+		case "MOVE":
+			return &algebra.Move{Silent: o.Silent, Source: graphs[0], Destination: graphs[1]}, nil
+		case "COPY":
+			return &algebra.Copy{Silent: o.Silent, Source: graphs[0], Destination: graphs[1]}, nil
+		default:
+			return nil, fmt.Errorf("unknown transfer %s", o.Kind)
+		}
+	case InsertData:
+		quads, err := ctx.translateQuads(Quads(o))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkQuads(quads, "INSERT DATA", false); err != nil {
+			return nil, err
+		}
+		return algebra.InsertData(quads), nil
+	case DeleteData:
+		quads, err := ctx.translateQuads(Quads(o))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkQuads(quads, "DELETE DATA", true); err != nil {
+			return nil, err
+		}
+		return algebra.DeleteData(quads), nil
+	case DeleteWhere:
+		quads, err := ctx.translateQuads(Quads(o))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkQuads(quads, "DELETE WHERE", true); err != nil {
+			return nil, err
+		}
+		// The quads are also the pattern, the same quads are matched and removed.
+		where := new(GroupGraphPattern)
+		for _, g := range o {
+			if g.Graph == nil {
+				where.Patterns = append(where.Patterns, TriplesBlock(g.Triples))
+				continue
+			}
+			where.Patterns = append(where.Patterns, &GraphGraphPattern{
+				Name:    g.Graph,
+				Pattern: &GroupGraphPattern{Patterns: []Pattern{TriplesBlock(g.Triples)}},
+			})
+		}
+		op, err := ctx.TranslateGroupGraphPattern(where)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Modify{Delete: quads, Operator: op}, nil
+	case *Modify:
+		m := new(algebra.Modify)
+		if o.With != nil {
+			i, err := ctx.iri(o.With)
+			if err != nil {
+				return nil, err
+			}
+			m.With = i
+		}
+		var err error
+		if m.Delete, err = ctx.translateQuads(o.Delete); err != nil {
+			return nil, err
+		}
+		if err := checkQuads(m.Delete, "DELETE", true); err != nil {
+			return nil, err
+		}
+		if m.Insert, err = ctx.translateQuads(o.Insert); err != nil {
+			return nil, err
+		}
+		for _, u := range o.Using {
+			i, err := ctx.iri(u.IRI)
+			if err != nil {
+				return nil, err
+			}
+			if u.Named {
+				m.UsingNamed = append(m.UsingNamed, i.Value)
+			} else {
+				m.Using = append(m.Using, i.Value)
+			}
+		}
+		if m.Operator, err = ctx.TranslateGroupGraphPattern(o.Where); err != nil {
+			return nil, err
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unknown update operation type %T", o)
+	}
+}
+
+func (ctx *Context) translateGraphRef(g GraphRef) (*algebra.Target, error) {
+	switch g.Kind {
+	case "GRAPH":
+		i, err := ctx.iri(g.IRI)
+		if err != nil {
+			return nil, err
+		}
+		return &algebra.Target{Graph: i}, nil
+	case "DEFAULT":
+		return &algebra.Target{}, nil
+	case "NAMED":
+		return &algebra.Target{Named: true}, nil
+	case "ALL":
+		return &algebra.Target{All: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown graph ref %s", g.Kind)
+	}
+}
+
+// checkQuads returns an error if the quads contain variables (data only) or blank nodes (if not allowed).
+func checkQuads(quads []algebra.Quad, form string, noBlankNodes bool) error {
+	data := strings.HasSuffix(form, "DATA")
+	for _, q := range quads {
+		for _, t := range []algebra.Term{q.Graph, q.Subject, q.Predicate, q.Object} {
+			switch t := t.(type) {
+			case algebra.Var:
+				if data {
+					return fmt.Errorf("%s: variables are not allowed", form)
+				}
+			case algebra.Constant:
+				if _, ok := t.Node.(*rdf.BlankNode); ok && noBlankNodes {
+					return fmt.Errorf("%s: blank nodes are not allowed", form)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// conjunction combines the expressions with &&.
+func conjunction(es []algebra.Expression) algebra.Expression {
+	e := es[0]
+	for _, r := range es[1:] {
+		e = &algebra.Call{Function: "&&", Args: []algebra.Expression{e, r}}
+	}
+	return e
+}
+
+func fromNTriplesLiteral(l nt.Literal) *rdf.Literal {
+	literal := &rdf.Literal{
+		Value:    escape.Unescape(l.Value),
+		Datatype: rdf.XSDString,
+	}
+	switch {
+	case l.Direction != "":
+		literal.Datatype = rdf.XSDNSDirString
+		literal.Language = l.Language
+		literal.Direction = l.Direction
+	case l.Language != "":
+		literal.Datatype = rdf.XSDNSString
+		literal.Language = l.Language
+	case l.Reference != nil:
+		literal.Datatype = rdf.DataType(escape.Unescape(string(*l.Reference)))
+	}
+	return literal
+}
+
+// inScope returns true if the variable is in scope of the operator.
+func inScope(op algebra.Operator, v algebra.Var) bool {
+	for _, s := range algebra.InScope(op) {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// join joins both operators, the empty BGP is the identity and adjacent BGPs are merged.
+func join(left, right algebra.Operator) algebra.Operator {
+	l, lok := left.(algebra.BGP)
+	r, rok := right.(algebra.BGP)
+	switch {
+	case lok && rok:
+		return append(append(algebra.BGP{}, l...), r...)
+	case lok && len(l) == 0:
+		return right
+	case rok && len(r) == 0:
+		return left
+	default:
+		return &algebra.Join{Left: left, Right: right}
+	}
+}
@@ -0,0 +1,72 @@
+package sparql_test
+
+import (
+	"github.com/0x51-dev/rdf/sparql"
+	"testing"
+)
+
+func TestTranslate(t *testing.T) {
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    `PREFIX ex: <http://example.org/> SELECT ?s WHERE { ?s ex:p ?o . FILTER(?o > 1) }`,
+			expected: `(project (?s) (filter (> ?o "1"^^<http://www.w3.org/2001/XMLSchema#integer>) (bgp (triple ?s <http://example.org/p> ?o))))`,
+		},
+		{
+			query:    `SELECT DISTINCT ?s (COUNT(?o) AS ?c) WHERE { ?s ?p ?o } GROUP BY ?s ORDER BY DESC(?c) LIMIT 10 OFFSET 2`,
+			expected: `(slice 2 10 (distinct (project (?s ?c) (order ((desc ?c)) (extend ((?c ?.1)) (group (?s) ((?.1 (count ?o))) (bgp (triple ?s ?p ?o))))))))`,
+		},
+		{
+			query:    `SELECT * { ?s ?p ?o OPTIONAL { ?o ?q ?r FILTER(?r != "x") } { ?a ?b ?c } UNION { ?d ?e ?f } MINUS { ?s a ?t } }`,
+			expected: `(project (?s ?p ?o ?q ?r ?a ?b ?c ?d ?e ?f) (minus (join (leftjoin (bgp (triple ?s ?p ?o)) (bgp (triple ?o ?q ?r)) (!= ?r "x")) (union (bgp (triple ?a ?b ?c)) (bgp (triple ?d ?e ?f)))) (bgp (triple ?s <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> ?t))))`,
+		},
+		{
+			query:    `SELECT ?x { BIND(1 AS ?x) VALUES ?y { 1 UNDEF } }`,
+			expected: `(project (?x) (join (extend ((?x "1"^^<http://www.w3.org/2001/XMLSchema#integer>)) (bgp)) (table (vars ?y) (row [?y "1"^^<http://www.w3.org/2001/XMLSchema#integer>]) (row))))`,
+		},
+		{
+			query:    `ASK { GRAPH ?g { ?s ?p [] } FILTER NOT EXISTS { ?s ?p <o> } }`,
+			expected: `(filter (notexists (bgp (triple ?s ?p <http://example.org/o>))) (graph ?g (bgp (triple ?s ?p _:b1))))`,
+		},
+		{
+			query:    `SELECT (GROUP_CONCAT(DISTINCT ?o; SEPARATOR=",") AS ?c) { ?s ?p ?o FILTER(?o IN (1) && BOUND(?s)) }`,
+			expected: `(project (?c) (extend ((?c ?.1)) (group () ((?.1 (group_concat distinct ?o ","))) (filter (&& (in ?o "1"^^<http://www.w3.org/2001/XMLSchema#integer>) (bound ?s)) (bgp (triple ?s ?p ?o))))))`,
+		},
+		{
+			query:    `SELECT * { ?s <p>/^<q>/<r>* ?o . ?o !(<p>|^<q>) ?x }`,
+			expected: `(project (?s ?o ?x) (join (join (bgp (triple ?s <http://example.org/p> _:b2) (triple _:b1 <http://example.org/q> _:b2)) (path _:b1 (path* <http://example.org/r>) ?o)) (path ?o (alt (notoneof <http://example.org/p>) (reverse (notoneof <http://example.org/q>))) ?x)))`,
+		},
+	} {
+		q, err := sparql.ParseQuery(test.query)
+		if err != nil {
+			t.Fatal(test.query, err)
+		}
+		a, err := sparql.Translate(q, "http://example.org/")
+		if err != nil {
+			t.Fatal(test.query, err)
+		}
+		if a.String() != test.expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", test.expected, a)
+		}
+	}
+}
+
+func TestTranslate_invalid(t *testing.T) {
+	for _, test := range []string{
+		`SELECT * { } GROUP BY ?s`,
+		`SELECT ?o { ?s ?p ?o } GROUP BY ?s`,
+		`SELECT ?x { BIND(1 AS ?x) BIND(2 AS ?x) }`,
+		`SELECT (1 AS ?s) { ?s ?p ?o }`,
+		`SELECT ?s { ?s ex:p ?o }`,
+	} {
+		q, err := sparql.ParseQuery(test)
+		if err != nil {
+			t.Fatal(test, err)
+		}
+		if _, err := sparql.Translate(q, ""); err == nil {
+			t.Errorf("expected error for %q", test)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package sparql
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/sparql/grammar"
+	"github.com/0x51-dev/rdf/turtle"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"strings"
+)
+
+// Clear removes all the triples of the target graph(s).
+type Clear struct {
+	Silent bool
+	Target GraphRef
+}
+
+func (c Clear) String() string {
+	return fmt.Sprintf("CLEAR%s %s", silentString(c.Silent), c.Target)
+}
+
+func (c Clear) update() {}
+
+// Create creates an empty graph.
+type Create struct {
+	Silent bool
+	Graph  *turtle.IRI
+}
+
+func (c Create) String() string {
+	return fmt.Sprintf("CREATE%s GRAPH %s", silentString(c.Silent), c.Graph)
+}
+
+func (c Create) update() {}
+
+// DeleteData removes the ground quads, e.g. "DELETE DATA { :s :p :o }".
+type DeleteData Quads
+
+func (d DeleteData) String() string {
+	return fmt.Sprintf("DELETE DATA %s", Quads(d))
+}
+
+func (d DeleteData) update() {}
+
+// DeleteWhere removes the matches of the quad pattern, e.g. "DELETE WHERE { ?s :p ?o }".
+type DeleteWhere Quads
+
+func (d DeleteWhere) String() string {
+	return fmt.Sprintf("DELETE WHERE %s", Quads(d))
+}
+
+func (d DeleteWhere) update() {}
+
+// Drop removes the target graph(s).
+type Drop struct {
+	Silent bool
+	Target GraphRef
+}
+
+func (d Drop) String() string {
+	return fmt.Sprintf("DROP%s %s", silentString(d.Silent), d.Target)
+}
+
+func (d Drop) update() {}
+
+// GraphRef identifies the graph(s) of CLEAR and DROP.
+type GraphRef struct {
+	// Kind is either "GRAPH", "DEFAULT", "NAMED" or "ALL".
+	Kind string
+	// IRI is only set if the kind is "GRAPH".
+	IRI *turtle.IRI
+}
+
+func ParseGraphRef(n *parser.Node) (*GraphRef, error) {
+	switch n.Name {
+	case "IRI":
+		i, err := turtle.ParseIRI(n)
+		if err != nil {
+			return nil, err
+		}
+		return &GraphRef{Kind: "GRAPH", IRI: i}, nil
+	case "Default", "Named", "All":
+		return &GraphRef{Kind: strings.ToUpper(n.Name)}, nil
+	default:
+		return nil, fmt.Errorf("graph ref: unknown %s", n.Name)
+	}
+}
+
+func (g GraphRef) String() string {
+	if g.Kind == "GRAPH" {
+		return fmt.Sprintf("GRAPH %s", g.IRI)
+	}
+	return g.Kind
+}
+
+// GraphTriples are the triples of a quad pattern within the same graph.
+type GraphTriples struct {
+	// Graph is either a Var or an IRI, nil for the default graph.
+	Graph   Term
+	Triples []*TriplesSameSubject
+}
+
+func (g GraphTriples) String() string {
+	if g.Graph == nil {
+		return templateString(g.Triples)
+	}
+	if len(g.Triples) == 0 {
+		return fmt.Sprintf("GRAPH %s {}", g.Graph)
+	}
+	return fmt.Sprintf("GRAPH %s { %s }", g.Graph, templateString(g.Triples))
+}
+
+// InsertData adds the quads, e.g. "INSERT DATA { :s :p :o }".
+type InsertData Quads
+
+func (i InsertData) String() string {
+	return fmt.Sprintf("INSERT DATA %s", Quads(i))
+}
+
+func (i InsertData) update() {}
+
+// Load adds the triples of the document to a graph.
+type Load struct {
+	Silent bool
+	IRI    *turtle.IRI
+	// Into is nil for the default graph.
+	Into *turtle.IRI
+}
+
+func (l Load) String() string {
+	if l.Into == nil {
+		return fmt.Sprintf("LOAD%s %s", silentString(l.Silent), l.IRI)
+	}
+	return fmt.Sprintf("LOAD%s %s INTO GRAPH %s", silentString(l.Silent), l.IRI, l.Into)
+}
+
+func (l Load) update() {}
+
+// Modify removes and adds triples, based on the solutions of the pattern.
+type Modify struct {
+	With *turtle.IRI
+	// Delete and Insert are the templates, at least one of them is not nil.
+	Delete, Insert Quads
+	// Using contains the USING (NAMED) clauses.
+	Using []DatasetClause
+	Where *GroupGraphPattern
+}
+
+func (m Modify) String() string {
+	var s []string
+	if m.With != nil {
+		s = append(s, fmt.Sprintf("WITH %s", m.With))
+	}
+	if m.Delete != nil {
+		s = append(s, fmt.Sprintf("DELETE %s", m.Delete))
+	}
+	if m.Insert != nil {
+		s = append(s, fmt.Sprintf("INSERT %s", m.Insert))
+	}
+	for _, u := range m.Using {
+		if u.Named {
+			s = append(s, fmt.Sprintf("USING NAMED %s", u.IRI))
+		} else {
+			s = append(s, fmt.Sprintf("USING %s", u.IRI))
+		}
+	}
+	s = append(s, fmt.Sprintf("WHERE %s", m.Where))
+	return strings.Join(s, "\n")
+}
+
+func (m Modify) update() {}
+
+// Operation is an update operation with the prologue that precedes it. The prologue also applies to the operations
+// that follow.
+type Operation struct {
+	Prologue []turtle.Directive
+	Update   UpdateOperation
+}
+
+func (o Operation) String() string {
+	return strings.Join(append(prologueStrings(o.Prologue), o.Update.String()), "\n")
+}
+
+// Quads are the triples of a quad pattern, grouped by graph, e.g. "{ :s :p :o . GRAPH :g { :s :p :o } }".
+type Quads []GraphTriples
+
+func ParseQuads(n *parser.Node) (Quads, error) {
+	if n.Name != "Quads" {
+		return nil, fmt.Errorf("quads: unknown %s", n.Name)
+	}
+	q := Quads{}
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "TriplesSameSubject":
+			t, err := ParseTriplesSameSubject(n)
+			if err != nil {
+				return nil, err
+			}
+			if len(q) == 0 || q[len(q)-1].Graph != nil {
+				q = append(q, GraphTriples{})
+			}
+			q[len(q)-1].Triples = append(q[len(q)-1].Triples, t)
+		case "QuadsNotTriples":
+			g, err := ParseTerm(n.Children()[0])
+			if err != nil {
+				return nil, err
+			}
+			b := GraphTriples{Graph: g}
+			for _, n := range n.Children()[1:] {
+				t, err := ParseTriplesSameSubject(n)
+				if err != nil {
+					return nil, err
+				}
+				b.Triples = append(b.Triples, t)
+			}
+			q = append(q, b)
+		default:
+			return nil, fmt.Errorf("quads: unknown %s", n.Name)
+		}
+	}
+	return q, nil
+}
+
+func (q Quads) String() string {
+	if len(q) == 0 {
+		return "{}"
+	}
+	s := make([]string, len(q))
+	for i, g := range q {
+		s[i] = g.String()
+	}
+	return fmt.Sprintf("{ %s }", strings.Join(s, " . "))
+}
+
+// Transfer adds, moves or copies the triples of a graph to another graph.
+type Transfer struct {
+	// Kind is either "ADD", "MOVE" or "COPY".
+	Kind   string
+	Silent bool
+	// Source and Destination are nil for the default graph.
+	Source, Destination *turtle.IRI
+}
+
+func (t Transfer) String() string {
+	graph := func(i *turtle.IRI) string {
+		if i == nil {
+			return "DEFAULT"
+		}
+		return fmt.Sprintf("GRAPH %s", i)
+	}
+	return fmt.Sprintf("%s%s %s TO %s", t.Kind, silentString(t.Silent), graph(t.Source), graph(t.Destination))
+}
+
+func (t Transfer) update() {}
+
+// Update is a SPARQL update request, a sequence of operations that are applied in order.
+type Update []*Operation
+
+// ParseUpdate parses a SPARQL update request.
+func ParseUpdate(update string) (Update, error) {
+	if update == "" {
+		// An empty request is valid, it contains no operations.
+		return Update{}, nil
+	}
+	p, err := grammar.NewParser([]rune(update))
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.Parse(op.And{grammar.UpdateUnit, op.EOF{}})
+	if err != nil {
+		return nil, err
+	}
+	return parseUpdate(n)
+}
+
+func parseUpdate(n *parser.Node) (Update, error) {
+	if n.Name != "Update" {
+		return nil, fmt.Errorf("update: unknown %s", n.Name)
+	}
+	u := Update{}
+	var prologue []turtle.Directive
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Base", "Prefix":
+			d, err := parseDirective(n)
+			if err != nil {
+				return nil, err
+			}
+			prologue = append(prologue, d)
+		default:
+			o, err := ParseUpdateOperation(n)
+			if err != nil {
+				return nil, err
+			}
+			u = append(u, &Operation{Prologue: prologue, Update: o})
+			prologue = nil
+		}
+	}
+	return u, nil
+}
+
+func (u Update) String() string {
+	s := make([]string, len(u))
+	for i, o := range u {
+		s[i] = o.String()
+	}
+	return strings.Join(s, " ;\n")
+}
+
+// UpdateOperation is either Load, Clear, Drop, Create, Transfer, InsertData, DeleteData, DeleteWhere or Modify.
+type UpdateOperation interface {
+	update()
+
+	fmt.Stringer
+}
+
+func ParseUpdateOperation(n *parser.Node) (UpdateOperation, error) {
+	children := n.Children()
+	var silent bool
+	if len(children) != 0 && children[0].Name == "Silent" {
+		silent = true
+		children = children[1:]
+	}
+	switch n.Name {
+	case "Load":
+		i, err := turtle.ParseIRI(children[0])
+		if err != nil {
+			return nil, err
+		}
+		l := &Load{Silent: silent, IRI: i}
+		if len(children) == 2 {
+			if l.Into, err = turtle.ParseIRI(children[1]); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case "Clear", "Drop":
+		g, err := ParseGraphRef(children[0])
+		if err != nil {
+			return nil, err
+		}
+		if n.Name == "Clear" {
+			return &Clear{Silent: silent, Target: *g}, nil
+		}
+		return &Drop{Silent: silent, Target: *g}, nil
+	case "Create":
+		i, err := turtle.ParseIRI(children[0])
+		if err != nil {
+			return nil, err
+		}
+		return &Create{Silent: silent, Graph: i}, nil
+	case "Add", "Move", "Copy":
+		t := &Transfer{Kind: strings.ToUpper(n.Name), Silent: silent}
+		for i, g := range []**turtle.IRI{&t.Source, &t.Destination} {
+			if children[i].Name == "Default" {
+				continue
+			}
+			iri, err := turtle.ParseIRI(children[i])
+			if err != nil {
+				return nil, err
+			}
+			*g = iri
+		}
+		return t, nil
+	case "InsertData", "DeleteData", "DeleteWhere":
+		q, err := ParseQuads(children[0])
+		if err != nil {
+			return nil, err
+		}
+		switch n.Name {
+		case "InsertData":
+			return InsertData(q), nil
+		case "DeleteData":
+			return DeleteData(q), nil
+		default:
+			return DeleteWhere(q), nil
+		}
+	case "Modify":
+		m := new(Modify)
+		for _, n := range children {
+			switch n.Name {
+			case "With":
+				i, err := turtle.ParseIRI(n.Children()[0])
+				if err != nil {
+					return nil, err
+				}
+				m.With = i
+			case "DeleteClause", "InsertClause":
+				q, err := ParseQuads(n.Children()[0])
+				if err != nil {
+					return nil, err
+				}
+				if n.Name == "DeleteClause" {
+					m.Delete = q
+				} else {
+					m.Insert = q
+				}
+			case "DefaultGraphClause", "NamedGraphClause":
+				d, err := ParseDatasetClause(n)
+				if err != nil {
+					return nil, err
+				}
+				m.Using = append(m.Using, *d)
+			case "GroupGraphPattern":
+				g, err := ParseGroupGraphPattern(n)
+				if err != nil {
+					return nil, err
+				}
+				m.Where = g
+			default:
+				return nil, fmt.Errorf("modify: unknown %s", n.Name)
+			}
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("update operation: unknown %s", n.Name)
+	}
+}
+
+func silentString(silent bool) string {
+	if silent {
+		return " SILENT"
+	}
+	return ""
+}
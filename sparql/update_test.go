@@ -0,0 +1,358 @@
+package sparql_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/0x51-dev/rdf"
+	nq "github.com/0x51-dev/rdf/nquads"
+	"github.com/0x51-dev/rdf/sparql"
+	"github.com/0x51-dev/rdf/sparql/algebra"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// data is the dataset of the update tests, in N-Quads.
+const data = `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+`
+
+func ExampleExecuteUpdate() {
+	d := rdf.NewDataset()
+	_ = sparql.ExecuteUpdate(context.Background(), `PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+INSERT DATA { <http://example.org/alice> foaf:name "Alice" } ;
+DELETE { ?s foaf:name ?name } INSERT { ?s foaf:givenName ?name } WHERE { ?s foaf:name ?name }`, "", d)
+	doc, _ := d.ToNQuads()
+	fmt.Print(doc)
+	// Output:
+	// <http://example.org/alice> <http://xmlns.com/foaf/0.1/givenName> "Alice" .
+}
+
+func TestParseUpdate(t *testing.T) {
+	for _, test := range []string{
+		``,
+		`PREFIX ex: <http://example.org/> INSERT DATA { ex:s ex:p ex:o . GRAPH ex:g { ex:s ex:p "o" } }`,
+		`DELETE DATA { GRAPH <http://e/g> {} <http://e/s> <http://e/p> 1 } ; INSERT DATA {}`,
+		`LOAD SILENT <file:///data.ttl> INTO GRAPH <http://e/g> ; CLEAR ALL ; DROP SILENT GRAPH <http://e/g>`,
+		`CREATE GRAPH <http://e/g> ; CLEAR DEFAULT ; DROP NAMED ;`,
+		`ADD DEFAULT TO <http://e/g> ; MOVE SILENT GRAPH <http://e/g> TO DEFAULT ; COPY <http://e/g> TO GRAPH <http://e/h>`,
+		`WITH <http://e/g> DELETE { ?s ?p ?o } INSERT { GRAPH <http://e/h> { ?s ?p [] } } USING <http://e/g> USING NAMED <http://e/h> WHERE { ?s ?p ?o }`,
+		`PREFIX ex: <http://example.org/> DELETE WHERE { ?s ex:p ?o . GRAPH ?g { ?s ?p ?o } } ; BASE <http://e/> INSERT { ?s ?p (1 2) } WHERE { ?s ?p ?o }`,
+	} {
+		u, err := sparql.ParseUpdate(test)
+		if err != nil {
+			t.Fatal(test, err)
+		}
+		// The string representation of the update must result in the same update.
+		u2, err := sparql.ParseUpdate(u.String())
+		if err != nil {
+			t.Fatal(u, err)
+		}
+		if u.String() != u2.String() {
+			t.Errorf("expected:\n%s\ngot:\n%s", u, u2)
+		}
+	}
+	for _, test := range []string{
+		`INSERT DATA { <http://e/s> <http://e/p> ?o }`,
+		`DELETE DATA { GRAPH ?g { <http://e/s> <http://e/p> <http://e/o> } }`,
+		`DELETE DATA { <http://e/s> <http://e/p> [] }`,
+		`DELETE WHERE { _:b <http://e/p> ?o }`,
+		`DELETE { ?s <http://e/p> [] } WHERE { ?s ?p ?o }`,
+		`INSERT { ?s <http://e/p>* ?o } WHERE { ?s ?p ?o }`,
+	} {
+		u, err := sparql.ParseUpdate(test)
+		if err == nil {
+			_, err = sparql.TranslateUpdate(u, "")
+		}
+		if err == nil {
+			t.Errorf("expected an error: %s", test)
+		}
+	}
+}
+
+func TestTranslateUpdate(t *testing.T) {
+	for _, test := range []struct {
+		update   string
+		expected string
+	}{
+		{
+			update:   `PREFIX ex: <http://example.org/> INSERT DATA { ex:s ex:p [ ex:q 1 ] . GRAPH ex:g { ex:s ex:p ex:o } }`,
+			expected: `(update (insertData (triple _:b1 <http://example.org/q> "1"^^<http://www.w3.org/2001/XMLSchema#integer>) (triple <http://example.org/s> <http://example.org/p> _:b1) (quad <http://example.org/g> <http://example.org/s> <http://example.org/p> <http://example.org/o>)))`,
+		},
+		{
+			update:   `LOAD <file:///data.ttl> INTO GRAPH <http://e/g> ; CLEAR SILENT DEFAULT ; DROP ALL ; CREATE GRAPH <http://e/g> ; MOVE <http://e/g> TO DEFAULT`,
+			expected: `(update (load <file:///data.ttl> <http://e/g>) (clear silent default) (drop all) (create <http://e/g>) (move <http://e/g> default))`,
+		},
+		{
+			update:   `PREFIX ex: <http://example.org/> DELETE WHERE { ?s ex:p ?o . GRAPH ?g { ?s ?p ?o } }`,
+			expected: `(update (modify (delete (triple ?s <http://example.org/p> ?o) (quad ?g ?s ?p ?o)) (join (bgp (triple ?s <http://example.org/p> ?o)) (graph ?g (bgp (triple ?s ?p ?o))))))`,
+		},
+		{
+			update:   `WITH <http://e/g> DELETE { ?s ?p ?o } INSERT { GRAPH <http://e/h> { ?s ?p ?o } } USING NAMED <http://e/h> WHERE { ?s ?p ?o }`,
+			expected: `(update (modify (with <http://e/g>) (using named <http://e/h>) (delete (triple ?s ?p ?o)) (insert (quad <http://e/h> ?s ?p ?o)) (bgp (triple ?s ?p ?o))))`,
+		},
+	} {
+		u, err := sparql.ParseUpdate(test.update)
+		if err != nil {
+			t.Fatal(test.update, err)
+		}
+		a, err := sparql.TranslateUpdate(u, "")
+		if err != nil {
+			t.Fatal(test.update, err)
+		}
+		if a.String() != test.expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", test.expected, a)
+		}
+	}
+}
+
+func TestExecuteUpdate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		update   string
+		expected string
+	}{
+		{
+			name:   "insert data",
+			update: `INSERT DATA { <http://example.org/c> <http://example.org/p> "5" . GRAPH <http://example.org/i> { _:x <http://example.org/p> _:x } }`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/p> "5" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+_:b <http://example.org/p> _:b <http://example.org/i> .
+# <http://example.org/g>
+# <http://example.org/h>
+# <http://example.org/i>`,
+		},
+		{
+			name:   "delete data",
+			update: `DELETE DATA { <http://example.org/a> <http://example.org/p> "1" . GRAPH <http://example.org/g> { <http://example.org/a> <http://example.org/p> "3" } . GRAPH <http://example.org/x> { <http://example.org/a> <http://example.org/p> "3" } }`,
+			expected: `<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "delete where",
+			update: `DELETE WHERE { GRAPH ?g { ?s ?p ?o } }`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/b> <http://example.org/p> "2" .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "delete insert",
+			update: `PREFIX ex: <http://example.org/> DELETE { ?s ex:p ?o } INSERT { GRAPH ex:g { ?s ex:r ?o } } WHERE { ?s ex:p ?o FILTER(?o != "2") }`,
+			expected: `<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/a> <http://example.org/r> "1" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "with",
+			update: `PREFIX ex: <http://example.org/> WITH ex:g DELETE { ?s ex:p ?o } INSERT { ?s ex:q ?o } WHERE { ?s ex:p ?o }`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/q> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "using",
+			update: `PREFIX ex: <http://example.org/> INSERT { ?s ex:r ?o } USING ex:g USING ex:h WHERE { ?s ?p ?o }`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/a> <http://example.org/r> "3" .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+<http://example.org/c> <http://example.org/r> "4" .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "insert blank nodes",
+			update: `PREFIX ex: <http://example.org/> INSERT { GRAPH ex:i { [] ex:r ?o } } WHERE { ?s ex:p ?o }`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+_:b <http://example.org/r> "1" <http://example.org/i> .
+_:b <http://example.org/r> "2" <http://example.org/i> .
+# <http://example.org/g>
+# <http://example.org/h>
+# <http://example.org/i>`,
+		},
+		{
+			name:     "clear and drop",
+			update:   `CLEAR GRAPH <http://example.org/g> ; DROP GRAPH <http://example.org/h> ; CLEAR DEFAULT`,
+			expected: `# <http://example.org/g>`,
+		},
+		{
+			name:     "drop all",
+			update:   `DROP ALL`,
+			expected: ``,
+		},
+		{
+			name:   "create",
+			update: `CREATE GRAPH <http://example.org/i> ; CREATE SILENT GRAPH <http://example.org/g>`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>
+# <http://example.org/i>`,
+		},
+		{
+			name:   "add",
+			update: `ADD <http://example.org/g> TO DEFAULT ; ADD DEFAULT TO DEFAULT`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "copy",
+			update: `COPY DEFAULT TO <http://example.org/h>`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "1" <http://example.org/h> .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/g> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/b> <http://example.org/p> "2" <http://example.org/h> .
+# <http://example.org/g>
+# <http://example.org/h>`,
+		},
+		{
+			name:   "move",
+			update: `MOVE <http://example.org/g> TO <http://example.org/i> ; MOVE SILENT <http://example.org/x> TO DEFAULT`,
+			expected: `<http://example.org/a> <http://example.org/p> "1" .
+<http://example.org/a> <http://example.org/p> "3" <http://example.org/i> .
+<http://example.org/b> <http://example.org/p> "2" .
+<http://example.org/c> <http://example.org/q> "4" <http://example.org/h> .
+# <http://example.org/h>
+# <http://example.org/i>`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			d := loadNQuads(t, data)
+			if err := sparql.ExecuteUpdate(context.Background(), test.update, "", d); err != nil {
+				t.Fatal(err)
+			}
+			if s := datasetString(d); s != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, s)
+			}
+		})
+	}
+}
+
+func TestExecuteUpdate_atomic(t *testing.T) {
+	for _, update := range []string{
+		`CREATE GRAPH <http://example.org/g>`,
+		`DROP GRAPH <http://example.org/x>`,
+		`COPY <http://example.org/x> TO DEFAULT`,
+		`LOAD <file:///does/not/exist.ttl>`,
+	} {
+		d := loadNQuads(t, data)
+		expected := datasetString(d)
+		err := sparql.ExecuteUpdate(context.Background(), `PREFIX ex: <http://example.org/>
+INSERT DATA { ex:a ex:p "5" . GRAPH ex:i { ex:a ex:p [] } } ;
+DELETE { GRAPH ?g { ?s ?p ?o } } INSERT { ?s ?p ?o } WHERE { GRAPH ?g { ?s ?p ?o } } ;
+DROP GRAPH ex:g ; CLEAR DEFAULT ; MOVE ex:h TO ex:g ; INSERT DATA { GRAPH ex:g { ex:a ex:p "6" } } ;
+`+update, "", d)
+		if err == nil {
+			t.Fatalf("expected an error: %s", update)
+		}
+		if s := datasetString(d); s != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+		}
+	}
+}
+
+func TestExecuteUpdate_canceled(t *testing.T) {
+	d := loadNQuads(t, data)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sparql.ExecuteUpdate(ctx, `CLEAR ALL`, "", d); err != context.Canceled {
+		t.Fatal(err)
+	}
+	if d.Len() != 4 {
+		t.Fatal("expected the dataset to be unchanged")
+	}
+}
+
+func TestExecuteUpdate_load(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"data.ttl": `@prefix ex: <http://example.org/> . ex:a ex:p [ ex:q "x" ] .`,
+		"data.nt":  `<http://example.org/a> <http://example.org/p> _:b1 .`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := loadNQuads(t, `_:b1 <http://example.org/p> "1" .`)
+	base := "file://" + filepath.ToSlash(dir) + "/"
+	if err := sparql.ExecuteUpdate(context.Background(), `LOAD <data.ttl> INTO GRAPH <http://example.org/g> ;
+LOAD <data.nt> ; LOAD SILENT <data.xyz> ; LOAD SILENT <http://example.org/remote.ttl>`, base, d); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<http://example.org/a> <http://example.org/p> _:b .
+<http://example.org/a> <http://example.org/p> _:b <http://example.org/g> .
+_:b <http://example.org/p> "1" .
+_:b <http://example.org/q> "x" <http://example.org/g> .
+# <http://example.org/g>`
+	if s := datasetString(d); s != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, s)
+	}
+	// Loaded blank nodes are fresh, they do not refer to blank nodes of the dataset.
+	a := &rdf.IRIReference{Value: "http://example.org/a"}
+	if o := d.Default.Find(a, nil, nil).Object; algebra.FormatNode(o) == "_:b1" {
+		t.Error("expected a fresh blank node")
+	}
+}
+
+// datasetString returns the quads of the dataset as sorted N-Quads, blank nodes are replaced by a placeholder. The
+// names of the named graphs follow the quads, as comments.
+func datasetString(d *rdf.Dataset) string {
+	var lines, names []string
+	for _, q := range d.FindAll(nil, nil, nil, nil) {
+		nodes := []rdf.Node{q.Subject, q.Predicate, q.Object}
+		if q.Graph != nil {
+			nodes = append(nodes, q.Graph)
+		}
+		s := make([]string, len(nodes))
+		for i, n := range nodes {
+			if _, ok := n.(*rdf.BlankNode); ok {
+				s[i] = "_:b"
+			} else {
+				s[i] = algebra.FormatNode(n)
+			}
+		}
+		lines = append(lines, strings.Join(s, " ")+" .")
+	}
+	for _, n := range d.Names() {
+		names = append(names, "# "+algebra.FormatNode(n))
+	}
+	sort.Strings(lines)
+	sort.Strings(names)
+	return strings.Join(append(lines, names...), "\n")
+}
+
+func loadNQuads(t *testing.T, raw string) *rdf.Dataset {
+	doc, err := nq.ParseDocument(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rdf.FromNQuads(doc)
+}
@@ -2,6 +2,7 @@ package nquads
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/internal/isomorphism"
 	nq "github.com/0x51-dev/rdf/nquads"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	"github.com/0x51-dev/rdf/star/nquads/grammar"
@@ -13,7 +14,13 @@ import (
 
 type Document []Quad
 
+// ParseDocument parses the N-Quads-star document within the RDF-star mode, see ParseDocumentMode.
 func ParseDocument(doc string) (Document, error) {
+	return ParseDocumentMode(doc, nt.RDFStar)
+}
+
+// ParseDocumentMode parses the N-Quads-star document, the quads must be supported by the given version of RDF.
+func ParseDocumentMode(doc string, m nt.Mode) (Document, error) {
 	if len(doc) == 0 {
 		return nil, nil
 	}
@@ -28,10 +35,10 @@ func ParseDocument(doc string) (Document, error) {
 	if err != nil {
 		return nil, err
 	}
-	return parseDocument(n)
+	return parseDocument(n, m)
 }
 
-func parseDocument(n *parser.Node) (Document, error) {
+func parseDocument(n *parser.Node, m nt.Mode) (Document, error) {
 	if n.Name != "Document" {
 		return nil, fmt.Errorf("document: unknown %s", n.Name)
 	}
@@ -41,11 +48,68 @@ func parseDocument(n *parser.Node) (Document, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := quad.Triple.Validate(m); err != nil {
+			return nil, err
+		}
 		quads = append(quads, *quad)
 	}
 	return quads, nil
 }
 
+// Equal returns true if the document is equal to the given value.
+// NOTE: blank nodes will be compared, not by value, but by relation in the document (see Isomorphic).
+func (d Document) Equal(other Document) bool {
+	_, ok := d.Isomorphic(other)
+	return ok
+}
+
+// Isomorphic returns true if both documents describe the same dataset, i.e. there is a bijection between the blank
+// nodes of both documents so that their quads are equal. Blank nodes within quoted triples and graph labels are
+// included in the mapping. Duplicate quads are ignored.
+func (d Document) Isomorphic(other Document) (map[nt.BlankNode]nt.BlankNode, bool) {
+	m, ok := isomorphism.Find(d.statements(), other.statements())
+	if !ok {
+		return nil, false
+	}
+	mapping := make(map[nt.BlankNode]nt.BlankNode, len(m))
+	for k, v := range m {
+		mapping[nt.BlankNode(k)] = nt.BlankNode(v)
+	}
+	return mapping, true
+}
+
+func (d Document) String() string {
+	var s string
+	for _, q := range d {
+		s += fmt.Sprintf("%s\n", q)
+	}
+	return s
+}
+
+func (d Document) statements() []isomorphism.Statement {
+	statements := make([]isomorphism.Statement, len(d))
+	for i, q := range d {
+		s := q.Triple.Terms(nil)
+		// The default graph is represented by an empty term.
+		switch g := q.GraphLabel.(type) {
+		case nil:
+			s = append(s, isomorphism.Term{})
+		case nt.BlankNode:
+			s = append(s, isomorphism.BlankNode(string(g)))
+		case *nt.BlankNode:
+			s = append(s, isomorphism.BlankNode(string(*g)))
+		case nt.IRIReference:
+			s = append(s, isomorphism.IRI(string(g)))
+		case *nt.IRIReference:
+			s = append(s, isomorphism.IRI(string(*g)))
+		default:
+			panic(fmt.Sprintf("unknown graph label type %T", g))
+		}
+		statements[i] = s
+	}
+	return statements
+}
+
 type Quad struct {
 	nts.Triple
 	GraphLabel nt.Subject
@@ -87,3 +151,10 @@ func ParseQuad(n *parser.Node) (*Quad, error) {
 		GraphLabel: g,
 	}, nil
 }
+
+func (q Quad) String() string {
+	if q.GraphLabel == nil {
+		return q.Triple.String()
+	}
+	return fmt.Sprintf("%s %s %s %s .", q.Subject, q.Predicate, q.Object, q.GraphLabel)
+}
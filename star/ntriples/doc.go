@@ -2,6 +2,7 @@ package ntriples
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/internal/isomorphism"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	"github.com/0x51-dev/rdf/star/ntriples/grammar"
 	"github.com/0x51-dev/upeg/parser"
@@ -25,7 +26,13 @@ func (b BlankNode) subject() {}
 
 type Document []Triple
 
+// ParseDocument parses the N-Triples-star document within the RDF-star mode, see ParseDocumentMode.
 func ParseDocument(doc string) (Document, error) {
+	return ParseDocumentMode(doc, nt.RDFStar)
+}
+
+// ParseDocumentMode parses the N-Triples-star document, the triples must be supported by the given version of RDF.
+func ParseDocumentMode(doc string, m nt.Mode) (Document, error) {
 	if len(doc) == 0 {
 		return nil, nil
 	}
@@ -40,10 +47,10 @@ func ParseDocument(doc string) (Document, error) {
 	if err != nil {
 		return nil, err
 	}
-	return parseDocument(n)
+	return parseDocument(n, m)
 }
 
-func parseDocument(n *parser.Node) (Document, error) {
+func parseDocument(n *parser.Node, m nt.Mode) (Document, error) {
 	if n.Name != "Document" {
 		return nil, fmt.Errorf("document: unknown %s", n.Name)
 	}
@@ -53,25 +60,34 @@ func parseDocument(n *parser.Node) (Document, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := t.Validate(m); err != nil {
+			return nil, err
+		}
 		triples = append(triples, *t)
 	}
 	return triples, nil
 }
 
-// Equal returns true if the document is equal to the given value. Both document will be assumed sorted.
-// NOTE: blank nodes will be compared, not by value, but by relation in the document.
+// Equal returns true if the document is equal to the given value.
+// NOTE: blank nodes will be compared, not by value, but by relation in the document (see Isomorphic).
 func (d Document) Equal(other Document) bool {
-	if len(d) != len(other) {
-		return false
+	_, ok := d.Isomorphic(other)
+	return ok
+}
+
+// Isomorphic returns true if both documents describe the same graph, i.e. there is a bijection between the blank
+// nodes of both documents so that their triples are equal. Blank nodes within quoted triples are included in the
+// mapping. Duplicate triples are ignored.
+func (d Document) Isomorphic(other Document) (map[BlankNode]BlankNode, bool) {
+	m, ok := isomorphism.Find(d.statements(), other.statements())
+	if !ok {
+		return nil, false
 	}
-	d, o := d.normalizeBlankNodes(), other.normalizeBlankNodes()
-	for i := range d {
-		t0, t1 := d[i], o[i]
-		if !t0.equal(t1, true) {
-			return false
-		}
+	mapping := make(map[BlankNode]BlankNode, len(m))
+	for k, v := range m {
+		mapping[BlankNode(k)] = BlankNode(v)
 	}
-	return true
+	return mapping, true
 }
 
 func (d Document) String() string {
@@ -82,40 +98,12 @@ func (d Document) String() string {
 	return s
 }
 
-func (d Document) normalizeBlankNodes() (n Document) {
-	var i int
-	mapping := make(map[string]string)
-	for _, t := range d {
-		subject := t.Subject
-		if b, ok := t.Subject.(BlankNode); ok {
-			if bn, ok := mapping[string(b)]; !ok {
-				bn = fmt.Sprintf("%d", i)
-				mapping[string(b)] = bn
-				subject = (*BlankNode)(&bn)
-				i++
-			} else {
-				subject = (*BlankNode)(&bn)
-			}
-		}
-		predicate := t.Predicate
-		object := t.Object
-		if b, ok := t.Object.(BlankNode); ok {
-			if bn, ok := mapping[string(b)]; !ok {
-				bn = fmt.Sprintf("%d", i)
-				mapping[string(b)] = bn
-				object = (*BlankNode)(&bn)
-				i++
-			} else {
-				object = (*BlankNode)(&bn)
-			}
-		}
-		n = append(n, Triple{
-			Subject:   subject,
-			Predicate: predicate,
-			Object:    object,
-		})
+func (d Document) statements() []isomorphism.Statement {
+	statements := make([]isomorphism.Statement, len(d))
+	for i, t := range d {
+		statements[i] = t.Terms(nil)
 	}
-	return
+	return statements
 }
 
 type IRIReference nt.IRIReference
@@ -144,7 +132,15 @@ func (l Literal) String() string {
 
 func (l Literal) object() {}
 
-// Object is either an IRI, a blank node, literal or quoted triple.
+func literal(l Literal) isomorphism.Term {
+	var datatype string
+	if l.Reference != nil {
+		datatype = string(*l.Reference)
+	}
+	return isomorphism.Literal(l.Value, datatype, l.Language, l.Direction)
+}
+
+// Object is either an IRI, a blank node, literal, quoted triple or triple term.
 type Object interface {
 	object()
 
@@ -177,6 +173,8 @@ func ParseObject(n *parser.Node) (Object, error) {
 		return (*Literal)(l), nil
 	case "QuotedTriple":
 		return ParseQuotedTriple(n)
+	case "TripleTerm":
+		return ParseTripleTerm(n)
 	default:
 		return nil, fmt.Errorf("object: unknown: %s", n.Name)
 	}
@@ -248,6 +246,39 @@ func ParseSubject(n *parser.Node) (Subject, error) {
 	}
 }
 
+// TripleTerm is a triple that is used as object of another triple (RDF 1.2), it is not asserted.
+type TripleTerm struct {
+	Triple
+}
+
+func ParseTripleTerm(n *parser.Node) (*TripleTerm, error) {
+	if n.Name != "TripleTerm" {
+		return nil, fmt.Errorf("triple term: unknown: %s", n.Name)
+	}
+	n.Name = "Triple"
+	t, err := ParseTriple(n)
+	if err != nil {
+		return nil, err
+	}
+	return &TripleTerm{Triple: *t}, nil
+}
+
+func (t TripleTerm) Equal(v any) bool {
+	if o, ok := v.(TripleTerm); ok {
+		return t.equal(o.Triple, true)
+	}
+	if o, ok := v.(*TripleTerm); ok && o != nil {
+		return t.equal(o.Triple, true)
+	}
+	return false
+}
+
+func (t TripleTerm) String() string {
+	return fmt.Sprintf("<<( %s %s %s )>>", t.Subject, t.Predicate, t.Object)
+}
+
+func (t TripleTerm) object() {}
+
 type Triple struct {
 	Subject   Subject
 	Predicate nt.IRIReference
@@ -297,6 +328,83 @@ func (t Triple) String() string {
 	return fmt.Sprintf("%s %s %s .", t.Subject, t.Predicate, t.Object)
 }
 
+// Validate returns an error if the triple is not supported by the given version of RDF, i.e. quoted triples are only
+// supported by RDF-star, triple terms and base directions only by RDF 1.2.
+func (t Triple) Validate(m nt.Mode) error {
+	for _, v := range []any{t.Subject, t.Object} {
+		switch v := v.(type) {
+		case QuotedTriple:
+			if err := v.validate(m, nt.RDFStar, "quoted triple"); err != nil {
+				return err
+			}
+		case *QuotedTriple:
+			if err := v.validate(m, nt.RDFStar, "quoted triple"); err != nil {
+				return err
+			}
+		case TripleTerm:
+			if err := v.validate(m, nt.RDF12, "triple term"); err != nil {
+				return err
+			}
+		case *TripleTerm:
+			if err := v.validate(m, nt.RDF12, "triple term"); err != nil {
+				return err
+			}
+		case Literal:
+			if v.Direction != "" {
+				return m.ValidateDirection(v.Direction)
+			}
+		case *Literal:
+			if v.Direction != "" {
+				return m.ValidateDirection(v.Direction)
+			}
+		}
+	}
+	return nil
+}
+
+// validate returns an error if the nested triple is not supported by the given version of RDF, the name is used in
+// the error message.
+func (t Triple) validate(m, supported nt.Mode, name string) error {
+	if m != supported {
+		return fmt.Errorf("%s: not supported by %s", name, m)
+	}
+	return t.Validate(m)
+}
+
+// Terms appends the isomorphism terms of the triple to the given statement, e.g. to compare N-Quads-star documents.
+// Quoted triples and triple terms are flattened, their terms are enclosed by "<<" and ">>", or "<<(" and ")>>".
+func (t Triple) Terms(s isomorphism.Statement) isomorphism.Statement {
+	for _, v := range []any{t.Subject, t.Predicate, t.Object} {
+		switch v := v.(type) {
+		case BlankNode:
+			s = append(s, isomorphism.BlankNode(string(v)))
+		case *BlankNode:
+			s = append(s, isomorphism.BlankNode(string(*v)))
+		case IRIReference:
+			s = append(s, isomorphism.IRI(string(v)))
+		case *IRIReference:
+			s = append(s, isomorphism.IRI(string(*v)))
+		case nt.IRIReference:
+			s = append(s, isomorphism.IRI(string(v)))
+		case Literal:
+			s = append(s, literal(v))
+		case *Literal:
+			s = append(s, literal(*v))
+		case QuotedTriple:
+			s = append(v.Terms(append(s, isomorphism.Term{Value: "<<"})), isomorphism.Term{Value: ">>"})
+		case *QuotedTriple:
+			s = append(v.Terms(append(s, isomorphism.Term{Value: "<<"})), isomorphism.Term{Value: ">>"})
+		case TripleTerm:
+			s = append(v.Terms(append(s, isomorphism.Term{Value: "<<("})), isomorphism.Term{Value: ")>>"})
+		case *TripleTerm:
+			s = append(v.Terms(append(s, isomorphism.Term{Value: "<<("})), isomorphism.Term{Value: ")>>"})
+		default:
+			panic(fmt.Sprintf("unknown term type %T", v))
+		}
+	}
+	return s
+}
+
 func (t Triple) equal(other Triple, checkBlankNode bool) bool {
 	switch t.Subject.(type) {
 	case BlankNode:
@@ -1,13 +1,68 @@
 package ntriples_test
 
 import (
+	nt "github.com/0x51-dev/rdf/ntriples"
 	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"maps"
 	"testing"
 )
 
+func TestDocument_Isomorphic(t *testing.T) {
+	a, err := nts.ParseDocument(`<< _:a <http://example/p> _:b >> <http://example/q> _:a .
+_:b <http://example/q> "b" .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := nts.ParseDocument(`_:y <http://example/q> "b" .
+<< _:x <http://example/p> _:y >> <http://example/q> _:x .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping, ok := a.Isomorphic(b)
+	if !ok {
+		t.Fatal("expected isomorphic documents")
+	}
+	if !maps.Equal(mapping, map[nts.BlankNode]nts.BlankNode{"a": "x", "b": "y"}) {
+		t.Error(mapping)
+	}
+
+	c, err := nts.ParseDocument(`<< _:x <http://example/p> _:y >> <http://example/q> _:y .
+_:y <http://example/q> "b" .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Equal(c) {
+		t.Error("expected different documents")
+	}
+}
+
 // MORE TESTS: https://w3c.github.io/rdf-star/tests/nt/syntax/manifest.html
 func TestParseDocument(t *testing.T) {
 	if _, err := nts.ParseDocument("<< <http://example/s> <http://example/p> <http://example/o> >> <http://example/q> <http://example/z> ."); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestParseDocument_tripleTerm(t *testing.T) {
+	const doc = "_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/s> <http://example/p> \"o\"@en--ltr )>> .\n"
+	if _, err := nts.ParseDocument(doc); err == nil {
+		t.Errorf("expected triple term to be rejected by %s", nt.RDFStar)
+	}
+
+	triples, err := nts.ParseDocumentMode(doc, nt.RDF12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := triples[0].Object.(*nts.TripleTerm); !ok {
+		t.Fatalf("expected triple term, got %T", triples[0].Object)
+	}
+	if s := triples.String(); s != doc {
+		t.Error(s)
+	}
+	if _, err := nts.ParseDocumentMode(`<< <http://example/s> <http://example/p> <http://example/o> >> <http://example/q> <http://example/z> .`, nt.RDF12); err == nil {
+		t.Error("expected quoted triple to be rejected by RDF 1.2")
+	}
+}
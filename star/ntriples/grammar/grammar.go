@@ -30,7 +30,7 @@ var (
 	}
 	Object = op.Capture{
 		Name:  "Object",
-		Value: op.Or{nt.IRIReference, nt.BlankNodeLabel, nt.Literal, QuotedTriple},
+		Value: op.Or{nt.IRIReference, nt.BlankNodeLabel, nt.Literal, TripleTerm, QuotedTriple},
 	}
 	QuotedTriple = op.Capture{
 		Name: "QuotedTriple",
@@ -43,6 +43,18 @@ var (
 			">>",
 		},
 	}
+	// TripleTerm is the triple term of RDF 1.2, which can only be used as object.
+	TripleTerm = op.Capture{
+		Name: "TripleTerm",
+		Value: op.And{
+			"<<(",
+			nt.OWhitespace,
+			op.Reference{Name: "Subject"}, nt.OWhitespace,
+			nt.Predicate, nt.OWhitespace,
+			op.Reference{Name: "Object"}, nt.OWhitespace,
+			")>>",
+		},
+	}
 )
 
 func NewParser(input []rune) (*parser.Parser, error) {
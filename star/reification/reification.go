@@ -0,0 +1,351 @@
+// Package reification converts RDF-star documents to standard RDF reification and back. Every quoted triple (or
+// triple term) is replaced by a statement node, which is described by rdf:type rdf:Statement, rdf:subject,
+// rdf:predicate and rdf:object triples.
+package reification
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"sort"
+	"strings"
+)
+
+const (
+	rdfObject    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#object"
+	rdfPredicate = "http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate"
+	rdfStatement = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement"
+	rdfSubject   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#subject"
+	rdfType      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+	// genid is the path of skolem IRIs, as defined by RFC 7511.
+	genid = "/.well-known/genid/"
+)
+
+// Reify replaces the quoted triples of the document by statement nodes. The statement nodes are blank nodes that are
+// derived from the quoted triple, so the same quoted triple always results in the same blank node. If skolem is not
+// empty (e.g. "https://example.org"), skolem IRIs (<skolem>/.well-known/genid/<hash>) are used instead.
+func Reify(doc nts.Document, skolem string) nt.Document {
+	quads := make(nqs.Document, len(doc))
+	for i, t := range doc {
+		quads[i] = nqs.Quad{Triple: t}
+	}
+	var triples nt.Document
+	for _, q := range ReifyQuads(quads, skolem) {
+		triples = append(triples, q.Triple)
+	}
+	return triples
+}
+
+// ReifyQuads replaces the quoted triples of the dataset by statement nodes (see Reify). The reification triples are
+// added to the graph in which the quoted triple occurs, the statement nodes are derived from the graph name as well.
+func ReifyQuads(doc nqs.Document, skolem string) nq.Document {
+	r := reifier{skolem: skolem, seen: make(map[string]bool)}
+	for _, q := range doc {
+		s := r.node(q.Subject, q.GraphLabel).(nt.Subject)
+		o := r.node(q.Object, q.GraphLabel)
+		r.add(s, q.Predicate, o, q.GraphLabel)
+	}
+	return r.quads
+}
+
+// Unreify lifts well-formed reifications back to quoted triples, it is the inverse of Reify. A statement node is
+// well-formed if it is a blank node (or skolem IRI) with exactly one rdf:type rdf:Statement, rdf:subject, rdf:predicate
+// and rdf:object triple, which is not used as predicate. Statement nodes that are not used by other triples are kept
+// as is.
+func Unreify(doc nt.Document) nts.Document {
+	quads := make(nq.Document, len(doc))
+	for i, t := range doc {
+		quads[i] = nq.NewQuadFromTriple(t, nil)
+	}
+	var triples nts.Document
+	for _, q := range UnreifyQuads(quads) {
+		triples = append(triples, q.Triple)
+	}
+	return triples
+}
+
+// UnreifyQuads lifts well-formed reifications of the dataset back to quoted triples (see Unreify). The triples of a
+// statement node all need to be in the same graph, in which the statement node also needs to be used.
+func UnreifyQuads(doc nq.Document) nqs.Document {
+	u := unreifier{statements: make(map[string]*statement), lifted: make(map[string]*nts.QuotedTriple)}
+	u.collect(doc)
+	u.resolve()
+
+	var quads nqs.Document
+	for _, q := range doc {
+		if st, ok := u.statements[q.Subject.String()]; ok && st.triples[q.Triple.String()] {
+			continue
+		}
+		quads = append(quads, nqs.Quad{
+			Triple: nts.Triple{
+				Subject:   u.node(q.Subject.(nt.Object)).(nts.Subject),
+				Predicate: q.Predicate,
+				Object:    u.node(q.Object),
+			},
+			GraphLabel: q.GraphLabel,
+		})
+	}
+	return quads
+}
+
+// graphKey returns the key of the graph label, the default graph is represented by an empty string.
+func graphKey(g nt.Subject) string {
+	if g == nil {
+		return ""
+	}
+	return g.String()
+}
+
+type reifier struct {
+	skolem string
+	// seen contains the statement nodes that are already described.
+	seen  map[string]bool
+	quads nq.Document
+}
+
+func (r *reifier) add(s nt.Subject, p nt.IRIReference, o nt.Object, g nt.Subject) {
+	r.quads = append(r.quads, nq.NewQuadFromTriple(nt.Triple{Subject: s, Predicate: p, Object: o}, g))
+}
+
+// node converts the node, quoted triples and triple terms are replaced by their statement node.
+func (r *reifier) node(n any, g nt.Subject) nt.Object {
+	switch n := n.(type) {
+	case nts.BlankNode:
+		return nt.BlankNode(n)
+	case *nts.BlankNode:
+		return nt.BlankNode(*n)
+	case nts.IRIReference:
+		return nt.IRIReference(n)
+	case *nts.IRIReference:
+		return nt.IRIReference(*n)
+	case nts.Literal:
+		return nt.Literal(n)
+	case *nts.Literal:
+		return nt.Literal(*n)
+	case nts.QuotedTriple:
+		return r.statement(n.Triple, g)
+	case *nts.QuotedTriple:
+		return r.statement(n.Triple, g)
+	case nts.TripleTerm:
+		return r.statement(n.Triple, g)
+	case *nts.TripleTerm:
+		return r.statement(n.Triple, g)
+	default:
+		panic(fmt.Sprintf("unknown node type %T", n))
+	}
+}
+
+// statement returns the statement node of the triple, the reification triples are added once per graph. Every graph
+// gets its own statement node, so they can be lifted independently.
+func (r *reifier) statement(t nts.Triple, g nt.Subject) nt.Object {
+	hash := sha256.Sum256([]byte(t.String() + graphKey(g)))
+	id := hex.EncodeToString(hash[:8])
+	var o nt.Object = nt.BlankNode("qt" + id)
+	if r.skolem != "" {
+		o = nt.IRIReference(strings.TrimSuffix(r.skolem, "/") + genid + id)
+	}
+	if !r.seen[o.String()] {
+		r.seen[o.String()] = true
+		s := o.(nt.Subject)
+		subject := r.node(t.Subject, g)
+		object := r.node(t.Object, g)
+		r.add(s, rdfType, nt.IRIReference(rdfStatement), g)
+		r.add(s, rdfSubject, subject, g)
+		r.add(s, rdfPredicate, t.Predicate, g)
+		r.add(s, rdfObject, object, g)
+	}
+	return o
+}
+
+// statement is a (candidate) statement node and its reification.
+type statement struct {
+	graph                      string
+	subject, predicate, object []nt.Object
+	isStatement                bool
+	// triples contains the reification triples of the statement node.
+	triples map[string]bool
+	// used is true if the node is used by a triple that is not part of its reification.
+	used bool
+	// invalid is true if the node can not be lifted, e.g. because it is used as predicate.
+	invalid bool
+}
+
+func (s *statement) wellFormed() bool {
+	return !s.invalid && s.used && s.isStatement && len(s.subject) == 1 && len(s.predicate) == 1 && len(s.object) == 1
+}
+
+type unreifier struct {
+	statements map[string]*statement
+	lifted     map[string]*nts.QuotedTriple
+}
+
+// collect collects the candidate statement nodes and checks how they are used.
+func (u *unreifier) collect(doc nq.Document) {
+	for _, q := range doc {
+		if !isStatementNode(q.Subject) {
+			continue
+		}
+		st, ok := u.statements[q.Subject.String()]
+		if !ok {
+			st = &statement{graph: graphKey(q.GraphLabel), triples: make(map[string]bool)}
+			u.statements[q.Subject.String()] = st
+		}
+		if st.graph != graphKey(q.GraphLabel) {
+			st.invalid = true
+		}
+		switch q.Predicate {
+		case rdfType:
+			if q.Object.String() != nt.IRIReference(rdfStatement).String() {
+				continue
+			}
+			st.isStatement = true
+		case rdfSubject:
+			st.subject = append(st.subject, q.Object)
+		case rdfPredicate:
+			st.predicate = append(st.predicate, q.Object)
+		case rdfObject:
+			st.object = append(st.object, q.Object)
+		default:
+			continue
+		}
+		st.triples[q.Triple.String()] = true
+	}
+	for _, q := range doc {
+		g := graphKey(q.GraphLabel)
+		if st, ok := u.statements[q.Predicate.String()]; ok {
+			st.invalid = true
+		}
+		if q.GraphLabel != nil {
+			if st, ok := u.statements[q.GraphLabel.String()]; ok {
+				st.invalid = true
+			}
+		}
+		for i, n := range []nt.Object{q.Subject.(nt.Object), q.Object} {
+			st, ok := u.statements[n.String()]
+			if !ok {
+				continue
+			}
+			if st.graph != g {
+				st.invalid = true
+			}
+			if i == 1 || !st.triples[q.Triple.String()] {
+				st.used = true
+			}
+		}
+	}
+}
+
+// resolve lifts all well-formed statement nodes, statement nodes that are part of a cycle are not lifted.
+func (u *unreifier) resolve() {
+	keys := make([]string, 0, len(u.statements))
+	for k := range u.statements {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for {
+		u.lifted = make(map[string]*nts.QuotedTriple)
+		var failed string
+		for _, k := range keys {
+			if _, ok := u.lift(k, make(map[string]bool)); !ok && u.statements[k].wellFormed() {
+				failed = k
+				break
+			}
+		}
+		if failed == "" {
+			break
+		}
+		u.statements[failed].invalid = true
+	}
+	for k := range u.statements {
+		if _, ok := u.lifted[k]; !ok {
+			delete(u.statements, k)
+		}
+	}
+}
+
+// lift returns the quoted triple of the statement node, returns false if it is not well-formed.
+func (u *unreifier) lift(k string, visiting map[string]bool) (*nts.QuotedTriple, bool) {
+	if qt, ok := u.lifted[k]; ok {
+		return qt, true
+	}
+	st, ok := u.statements[k]
+	if !ok || !st.wellFormed() || visiting[k] {
+		return nil, false
+	}
+	visiting[k] = true
+	defer delete(visiting, k)
+
+	p, ok := convert(st.predicate[0]).(nts.IRIReference)
+	if !ok {
+		return nil, false
+	}
+	s, ok := u.liftNode(st.subject[0], visiting)
+	if !ok {
+		return nil, false
+	}
+	subject, ok := s.(nts.Subject)
+	if !ok {
+		return nil, false
+	}
+	o, ok := u.liftNode(st.object[0], visiting)
+	if !ok {
+		return nil, false
+	}
+	qt := &nts.QuotedTriple{Triple: nts.Triple{Subject: subject, Predicate: nt.IRIReference(p), Object: o}}
+	u.lifted[k] = qt
+	return qt, true
+}
+
+// liftNode converts the node, well-formed statement nodes are lifted.
+func (u *unreifier) liftNode(n nt.Object, visiting map[string]bool) (nts.Object, bool) {
+	if st, ok := u.statements[n.String()]; ok && st.wellFormed() {
+		return u.lift(n.String(), visiting)
+	}
+	return convert(n), true
+}
+
+// node converts the node, lifted statement nodes are replaced by their quoted triple.
+func (u *unreifier) node(n nt.Object) nts.Object {
+	if qt, ok := u.lifted[n.String()]; ok {
+		return qt
+	}
+	return convert(n)
+}
+
+func convert(n nt.Object) nts.Object {
+	switch n := n.(type) {
+	case nt.BlankNode:
+		return nts.BlankNode(n)
+	case *nt.BlankNode:
+		return nts.BlankNode(*n)
+	case nt.IRIReference:
+		return nts.IRIReference(n)
+	case *nt.IRIReference:
+		return nts.IRIReference(*n)
+	case nt.Literal:
+		return nts.Literal(n)
+	case *nt.Literal:
+		return nts.Literal(*n)
+	default:
+		panic(fmt.Sprintf("unknown node type %T", n))
+	}
+}
+
+// isStatementNode returns true if the node can be a statement node, i.e. a blank node or a skolem IRI.
+func isStatementNode(n nt.Subject) bool {
+	switch n := n.(type) {
+	case nt.BlankNode, *nt.BlankNode:
+		return true
+	case nt.IRIReference:
+		return strings.Contains(string(n), genid)
+	case *nt.IRIReference:
+		return strings.Contains(string(*n), genid)
+	default:
+		return false
+	}
+}
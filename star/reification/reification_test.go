@@ -0,0 +1,166 @@
+package reification_test
+
+import (
+	"fmt"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"github.com/0x51-dev/rdf/star/reification"
+	"testing"
+)
+
+func ExampleReify() {
+	doc, _ := nts.ParseDocument(`<< <http://example/alice> <http://example/knows> <http://example/bob> >> <http://example/since> "2020" .`)
+	fmt.Print(reification.Reify(doc, "https://example.org"))
+	// Output:
+	// <https://example.org/.well-known/genid/6a35d826ba271c03> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+	// <https://example.org/.well-known/genid/6a35d826ba271c03> <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example/alice> .
+	// <https://example.org/.well-known/genid/6a35d826ba271c03> <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/knows> .
+	// <https://example.org/.well-known/genid/6a35d826ba271c03> <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> <http://example/bob> .
+	// <https://example.org/.well-known/genid/6a35d826ba271c03> <http://example/since> "2020" .
+}
+
+func TestReify(t *testing.T) {
+	doc, err := nts.ParseDocument(`<< _:a <http://example/p> << <http://example/s> <http://example/p> "o" >> >> <http://example/q> _:a .
+<http://example/s> <http://example/p> << _:a <http://example/p> << <http://example/s> <http://example/p> "o" >> >> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, skolem := range []string{"", "https://example.org/"} {
+		triples := reification.Reify(doc, skolem)
+		// 2 asserted triples and 2 reified (distinct) quoted triples.
+		if len(triples) != 2+2*4 {
+			t.Error(triples)
+		}
+		if _, err := nt.ParseDocument(triples.String()); err != nil {
+			t.Fatal(err)
+		}
+		if doc2 := reification.Unreify(triples); !doc.Equal(doc2) {
+			t.Error(doc2)
+		}
+	}
+
+	// Triple terms are reified as well, but are lifted to quoted triples.
+	doc, err = nts.ParseDocumentMode(`_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/s> <http://example/p> "o" )>> .`, nt.RDF12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if triples := reification.Reify(doc, ""); len(triples) != 5 {
+		t.Error(triples)
+	}
+}
+
+func TestReifyQuads(t *testing.T) {
+	doc, err := nqs.ParseDocument(`<< <http://example/s> <http://example/p> "o" >> <http://example/q> "a" .
+<< <http://example/s> <http://example/p> "o" >> <http://example/q> "b" <http://example/g> .
+<< <http://example/s> <http://example/p> "o" >> <http://example/q> "c" <http://example/g> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quads := reification.ReifyQuads(doc, "")
+	if len(quads) != 3+2*4 {
+		t.Error(quads)
+	}
+	if len(quads.Graphs()) != 2 {
+		t.Error(quads.Graphs())
+	}
+	if doc2 := reification.UnreifyQuads(quads); !doc.Equal(doc2) {
+		t.Error(doc2)
+	}
+}
+
+func TestUnreify(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		triples  string
+		expected string
+	}{
+		{
+			name: "well-formed",
+			triples: `_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example/s> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "o" .
+_:r <http://example/q> <http://example/z> .
+<http://example/z> <http://example/q> _:r .`,
+			expected: `<< <http://example/s> <http://example/p> "o" >> <http://example/q> <http://example/z> .
+<http://example/z> <http://example/q> << <http://example/s> <http://example/p> "o" >> .`,
+		},
+		{
+			name: "multiple objects",
+			triples: `_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example/s> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "o" .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "p" .
+_:r <http://example/q> <http://example/z> .`,
+		},
+		{
+			name: "literal subject",
+			triples: `_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> "s" .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "o" .
+_:r <http://example/q> <http://example/z> .`,
+		},
+		{
+			name: "unused",
+			triples: `_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example/s> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "o" .`,
+		},
+		{
+			name: "cycle",
+			// Only one of the statement nodes can be lifted.
+			expected: `_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> << _:a <http://example/p> "b" >> .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "a" .`,
+			triples: `_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> _:b .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:a <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "a" .
+_:b <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:b <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> _:a .
+_:b <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:b <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "b" .`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := nt.ParseDocument(test.triples)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := test.expected
+			if expected == "" {
+				// Reifications that are not well-formed are kept as is.
+				expected = test.triples
+			}
+			triples, err := nts.ParseDocument(expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc2 := reification.Unreify(doc); !triples.Equal(doc2) {
+				t.Error(doc2)
+			}
+		})
+	}
+
+	// Statement nodes that are used in another graph can not be lifted.
+	quads, err := nq.ParseDocument(`_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example/s> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://example/p> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "o" .
+_:r <http://example/q> <http://example/z> <http://example/g> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := reification.UnreifyQuads(quads); len(doc) != len(quads) {
+		t.Error(doc)
+	}
+}
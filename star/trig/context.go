@@ -0,0 +1,22 @@
+package trig
+
+import (
+	"fmt"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	stl "github.com/0x51-dev/rdf/star/turtle"
+)
+
+type Context struct {
+	*stl.Context
+}
+
+func NewContext() *Context {
+	return &Context{
+		Context: stl.NewContext(),
+	}
+}
+
+func (ctx *Context) bn() nt.BlankNode {
+	ctx.BnIndex++
+	return nt.BlankNode(fmt.Sprintf("b%d", ctx.BnIndex))
+}
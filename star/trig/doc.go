@@ -0,0 +1,238 @@
+package trig
+
+import (
+	"fmt"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	"github.com/0x51-dev/rdf/star/trig/grammar"
+	stl "github.com/0x51-dev/rdf/star/turtle"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"strings"
+)
+
+// EvaluateDocument evaluates the TriG-star document into an N-Quads-star document.
+func EvaluateDocument(doc Document) (nqs.Document, error) {
+	return NewContext().evaluateDocument(doc)
+}
+
+// EvaluateDocumentMode evaluates the document within the given version of RDF, see EvaluateDocument.
+func EvaluateDocumentMode(doc Document, m nt.Mode) (nqs.Document, error) {
+	ctx := NewContext()
+	ctx.Mode = m
+	return ctx.evaluateDocument(doc)
+}
+
+type Base ttl.Base
+
+func (b Base) String() string {
+	return ttl.Base(b).String()
+}
+
+func (b Base) statement() {}
+
+type Document []Statement
+
+func ParseDocument(doc string) (Document, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	if !strings.HasSuffix(doc, "\n") {
+		doc += "\n"
+	}
+	p, err := grammar.NewParser([]rune(doc))
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.Parse(op.And{grammar.Document, op.EOF{}})
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(n)
+}
+
+func parseDocument(n *parser.Node) (Document, error) {
+	if n.Name != "Document" {
+		return nil, fmt.Errorf("document: unknown: %s", n.Name)
+	}
+	var doc Document
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Directive":
+			d, err := ttl.ParseDirective(n)
+			if err != nil {
+				return nil, err
+			}
+			switch d := d.(type) {
+			case *ttl.Base:
+				doc = append(doc, (*Base)(d))
+			case *ttl.Prefix:
+				doc = append(doc, (*Prefix)(d))
+			default:
+				return nil, fmt.Errorf("unknown directive type: %T", d)
+			}
+		case "Block":
+			b, err := ParseBlock(n)
+			if err != nil {
+				return nil, err
+			}
+			doc = append(doc, b)
+		default:
+			return nil, fmt.Errorf("document: unknown: %s", n.Name)
+		}
+	}
+	return doc, nil
+}
+
+func (d Document) String() string {
+	var b strings.Builder
+	for _, s := range d {
+		b.WriteString(s.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Graph is a wrapped graph with a label, the label is either an IRI or a blank node.
+type Graph struct {
+	Label        stl.Subject
+	WrappedGraph WrappedGraph
+}
+
+func ParseGraph(n *parser.Node) (*Graph, error) {
+	if n.Name != "Graph" {
+		return nil, fmt.Errorf("graph: unknown: %s", n.Name)
+	}
+	l, err := ParseLabelOrSubject(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	wg, err := ParseWrappedGraph(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Graph{Label: l, WrappedGraph: wg}, nil
+}
+
+func (g Graph) String() string {
+	return fmt.Sprintf("GRAPH %s %s", g.Label, g.WrappedGraph)
+}
+
+func (g Graph) statement() {}
+
+// ParseBlock parses a graph, a wrapped graph or the triples of the default graph.
+func ParseBlock(n *parser.Node) (Statement, error) {
+	if n.Name != "Block" {
+		return nil, fmt.Errorf("block: unknown: %s", n.Name)
+	}
+	switch n := n.Children()[0]; n.Name {
+	case "Graph":
+		return ParseGraph(n)
+	case "WrappedGraph":
+		return ParseWrappedGraph(n)
+	case "Triples":
+		t, err := stl.ParseTriples(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*Triple)(t), nil
+	default:
+		return nil, fmt.Errorf("block: unknown: %s", n.Name)
+	}
+}
+
+func ParseLabelOrSubject(n *parser.Node) (stl.Subject, error) {
+	if n.Name != "LabelOrSubject" {
+		return nil, fmt.Errorf("label or subject: unknown: %s", n.Name)
+	}
+	switch n := n.Children()[0]; n.Name {
+	case "IRI":
+		iri, err := ttl.ParseIRI(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*stl.IRI)(iri), nil
+	case "BlankNode":
+		bn, err := ttl.ParseBlankNode(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*stl.BlankNode)(bn), nil
+	default:
+		return nil, fmt.Errorf("label or subject: unknown: %s", n.Name)
+	}
+}
+
+type Prefix ttl.Prefix
+
+func (p Prefix) String() string {
+	return ttl.Prefix(p).String()
+}
+
+func (p Prefix) statement() {}
+
+type Statement interface {
+	statement()
+
+	fmt.Stringer
+}
+
+// Triple is a triple of the default graph.
+type Triple stl.Triple
+
+func (t Triple) String() string {
+	return stl.Triple(t).String()
+}
+
+func (t Triple) statement() {}
+
+type WrappedGraph []stl.Triple
+
+func ParseWrappedGraph(n *parser.Node) (WrappedGraph, error) {
+	if n.Name != "WrappedGraph" {
+		return nil, fmt.Errorf("wrapped graph: unknown: %s", n.Name)
+	}
+	var wg WrappedGraph
+	if len(n.Children()) == 1 {
+		if err := wg.parseTriplesBlock(n.Children()[0]); err != nil {
+			return nil, err
+		}
+	}
+	return wg, nil
+}
+
+func (w WrappedGraph) String() string {
+	var b strings.Builder
+	b.WriteString("{")
+	for _, t := range w {
+		b.WriteString(" " + t.String())
+	}
+	b.WriteString(" }")
+	return b.String()
+}
+
+func (w *WrappedGraph) parseTriplesBlock(n *parser.Node) error {
+	if n.Name != "TriplesBlock" {
+		return fmt.Errorf("triples block: unknown: %s", n.Name)
+	}
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Triples":
+			t, err := stl.ParseTriples(n)
+			if err != nil {
+				return err
+			}
+			*w = append(*w, *t)
+		case "TriplesBlock":
+			if err := w.parseTriplesBlock(n); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("triples block: unknown: %s", n.Name)
+		}
+	}
+	return nil
+}
+
+func (w WrappedGraph) statement() {}
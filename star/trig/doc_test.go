@@ -0,0 +1,123 @@
+package trig_test
+
+import (
+	"embed"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	sts "github.com/0x51-dev/rdf/star/trig"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"os"
+	"testing"
+)
+
+// base is the base IRI of the test suites, documents are resolved relative to it.
+const base = "https://w3c.github.io/rdf-star/tests/trig/"
+
+//go:embed testdata/suite
+var suite embed.FS
+
+func ExampleEvaluateDocument() {
+	doc, _ := sts.ParseDocument(`PREFIX : <http://example/>
+:g { :alice :knows :bob {| :since 2020 |} . }`)
+	quads, _ := sts.EvaluateDocument(doc)
+	fmt.Print(quads)
+	// Output:
+	// <http://example/alice> <http://example/knows> <http://example/bob> <http://example/g> .
+	// <<<http://example/alice> <http://example/knows> <http://example/bob>>> <http://example/since> "2020"^^<http://www.w3.org/2001/XMLSchema#integer> <http://example/g> .
+}
+
+func TestSuite(t *testing.T) {
+	for _, kind := range []string{"syntax", "eval"} {
+		raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/manifest.ttl", kind))
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest, err := testsuite.LoadManifest(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report := project.NewReport(ttl.IRI{Value: fmt.Sprintf("%s%s/manifest#", base, kind)})
+		for _, k := range manifest.Keys {
+			e := manifest.Entries[k]
+			raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/%s", kind, e.Action))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := sts.ParseDocument(string(raw))
+			switch e.Type {
+			case "rdft:TestTrigPositiveSyntax":
+				t.Run(e.Name, func(t *testing.T) {
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					quads, err := sts.EvaluateDocument(doc)
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					if _, err := nqs.ParseDocument(quads.String()); err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(quads.String())
+					}
+
+					// fmt.Stringer
+					if _, err := sts.ParseDocument(doc.String()); err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			case "rdft:TestTrigNegativeSyntax":
+				t.Run(e.Name, func(t *testing.T) {
+					if err == nil {
+						if _, err := sts.EvaluateDocument(doc); err == nil {
+							report.AddTest(e.Name, testsuite.Failed)
+							t.Fatal("expected error")
+						}
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			case "rdft:TestTrigEval":
+				t.Run(e.Name, func(t *testing.T) {
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+
+					raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/%s", kind, e.Result))
+					if err != nil {
+						t.Fatal(err)
+					}
+					expected, err := nqs.ParseDocument(string(raw))
+					if err != nil {
+						t.Fatal(err)
+					}
+					quads, err := sts.EvaluateDocument(doc)
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					if !expected.Equal(quads) {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatalf("expected:\n%s\nactual:\n%s", expected, quads)
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			default:
+				t.Fatal("unknown test type", e.Type)
+			}
+		}
+
+		t.Log("Total tests:", report.Len())
+		if os.Getenv("TEST_SUITE_REPORT") == "true" {
+			_ = os.WriteFile(fmt.Sprintf("testdata/suite/%s/report.ttl", kind), []byte(report.String()), 0644)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package trig
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/iri"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nqs "github.com/0x51-dev/rdf/star/nquads"
+	stl "github.com/0x51-dev/rdf/star/turtle"
+	ttl "github.com/0x51-dev/rdf/turtle"
+)
+
+// EvaluateGraph evaluates the triples of the wrapped graph into quads with the given graph label.
+func (ctx *Context) EvaluateGraph(wg WrappedGraph, graphLabel nt.Subject) ([]nqs.Quad, error) {
+	var quads []nqs.Quad
+	for _, t := range wg {
+		ts, err := ctx.EvaluateTriple(&t)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range ts {
+			quads = append(quads, nqs.Quad{Triple: t, GraphLabel: graphLabel})
+		}
+	}
+	return quads, nil
+}
+
+func (ctx *Context) EvaluateGraphLabel(l stl.Subject) (nt.Subject, error) {
+	switch l := l.(type) {
+	case *stl.IRI:
+		return ctx.EvaluateIRI((*ttl.IRI)(l))
+	case *stl.BlankNode:
+		if *l == "[]" {
+			bn := ctx.bn()
+			return &bn, nil
+		}
+		return (*nt.BlankNode)(l), nil
+	default:
+		return nil, fmt.Errorf("unknown graph label type %T", l)
+	}
+}
+
+func (ctx *Context) evaluateDocument(d Document) (nqs.Document, error) {
+	var quads []nqs.Quad
+	for _, t := range d {
+		switch t := t.(type) {
+		case *Base:
+			ctx.Context.Base = iri.Resolve(ctx.Context.Base, string(*t))
+		case *Prefix:
+			ctx.Context.Prefixes[t.Name] = iri.Resolve(ctx.Context.Base, t.IRI)
+		case *Graph:
+			graphLabel, err := ctx.EvaluateGraphLabel(t.Label)
+			if err != nil {
+				return nil, err
+			}
+			qs, err := ctx.EvaluateGraph(t.WrappedGraph, graphLabel)
+			if err != nil {
+				return nil, err
+			}
+			quads = append(quads, qs...)
+		case WrappedGraph:
+			qs, err := ctx.EvaluateGraph(t, nil)
+			if err != nil {
+				return nil, err
+			}
+			quads = append(quads, qs...)
+		case *Triple:
+			qs, err := ctx.EvaluateGraph(WrappedGraph{stl.Triple(*t)}, nil)
+			if err != nil {
+				return nil, err
+			}
+			quads = append(quads, qs...)
+		default:
+			return nil, fmt.Errorf("unknown document type %T", t)
+		}
+	}
+	return quads, nil
+}
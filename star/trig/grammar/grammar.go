@@ -0,0 +1,70 @@
+package grammar
+
+import (
+	nt "github.com/0x51-dev/rdf/ntriples/grammar"
+	stl "github.com/0x51-dev/rdf/star/turtle/grammar"
+	ttl "github.com/0x51-dev/rdf/turtle/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+)
+
+var (
+	Document = op.Capture{
+		Name: "Document",
+		Value: op.ZeroOrMore{Value: op.And{
+			nt.OWhitespace,
+			op.Or{
+				ttl.Directive,
+				Block,
+				op.And{op.Optional{Value: nt.Comment}, op.EndOfLine{}},
+			},
+		}},
+	}
+	// Block is either a (labeled) graph or triples of the default graph. Next to the triples of Turtle-star, this
+	// includes the triples with a quoted triple as subject.
+	Block = op.Capture{
+		Name: "Block",
+		Value: op.Or{
+			op.Capture{
+				Name: "Graph",
+				Value: op.And{
+					op.Optional{Value: op.And{"GRAPH", nt.Whitespace}},
+					LabelOrSubject, ttl.WSPLNC, WrappedGraph,
+				},
+			},
+			WrappedGraph,
+			op.And{stl.Triples, ttl.WSPLNC, '.'},
+		},
+	}
+	WrappedGraph = op.Capture{
+		Name: "WrappedGraph",
+		Value: op.And{
+			'{', ttl.WSPLNC, op.Optional{Value: op.And{TriplesBlock, ttl.WSPLNC}}, '}',
+		},
+	}
+	TriplesBlock = op.Capture{
+		Name: "TriplesBlock",
+		Value: op.And{
+			stl.Triples,
+			op.Optional{Value: op.And{
+				ttl.WSPLNC, '.',
+				op.Optional{Value: op.And{ttl.WSPLNC, op.Reference{Name: "TriplesBlock"}}},
+			}},
+		},
+	}
+	LabelOrSubject = op.Capture{
+		Name: "LabelOrSubject",
+		Value: op.Or{
+			ttl.IRI, ttl.BlankNode,
+		},
+	}
+)
+
+func NewParser(input []rune) (*parser.Parser, error) {
+	p, err := stl.NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	p.Rules["TriplesBlock"] = TriplesBlock
+	return p, nil
+}
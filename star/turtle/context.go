@@ -0,0 +1,27 @@
+package turtle
+
+import (
+	"fmt"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	ttl "github.com/0x51-dev/rdf/turtle"
+)
+
+type Context struct {
+	*ttl.Context
+}
+
+func NewContext() *Context {
+	return &Context{
+		Context: ttl.NewContext(),
+	}
+}
+
+func (ctx *Context) bn() nts.BlankNode {
+	ctx.BnIndex++
+	return nts.BlankNode(fmt.Sprintf("b%d", ctx.BnIndex))
+}
+
+func (ctx *Context) el() nts.BlankNode {
+	ctx.ElIndex++
+	return nts.BlankNode(fmt.Sprintf("el%d", ctx.ElIndex))
+}
@@ -0,0 +1,611 @@
+package turtle
+
+import (
+	"fmt"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	"github.com/0x51-dev/rdf/star/turtle/grammar"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"strings"
+)
+
+// EvaluateDocument evaluates the Turtle-star document into an N-Triples-star document. Quoted triples are not
+// asserted, annotated triples are asserted and used as quoted subject of the triples of their annotation. Within RDF
+// 1.2 (see EvaluateDocumentMode) quoted triples and annotations are reified instead.
+func EvaluateDocument(doc Document, cwd string) (nts.Document, error) {
+	return NewContext().evaluateDocument(doc, cwd)
+}
+
+// EvaluateDocumentMode evaluates the document within the given version of RDF, see EvaluateDocument.
+func EvaluateDocumentMode(doc Document, cwd string, m nt.Mode) (nts.Document, error) {
+	ctx := NewContext()
+	ctx.Mode = m
+	return ctx.evaluateDocument(doc, cwd)
+}
+
+// AnnotatedObject is an object followed by one or more annotations.
+type AnnotatedObject struct {
+	Object      Object
+	Annotations []Annotation
+}
+
+func (a AnnotatedObject) String() string {
+	s := a.Object.String()
+	for _, a := range a.Annotations {
+		s += " " + a.String()
+	}
+	return s
+}
+
+func (a AnnotatedObject) object() {}
+
+// Annotation contains the predicates and objects of the triples that have the triple of the annotated object as
+// (quoted) subject. Within RDF 1.2 the subject is the reifier of the triple, if present.
+type Annotation struct {
+	Reifier             *Reifier
+	PredicateObjectList PredicateObjectList
+}
+
+func (a Annotation) String() string {
+	var s []string
+	if a.Reifier != nil {
+		s = append(s, a.Reifier.String())
+	}
+	if a.PredicateObjectList != nil {
+		s = append(s, fmt.Sprintf("{| %s |}", a.PredicateObjectList))
+	}
+	return strings.Join(s, " ")
+}
+
+type Base ttl.Base
+
+func (b Base) String() string {
+	return ttl.Base(b).String()
+}
+
+func (b Base) statement() {}
+
+type BlankNode ttl.BlankNode
+
+func (b BlankNode) String() string {
+	return ttl.BlankNode(b).String()
+}
+
+func (b BlankNode) object() {}
+
+func (b BlankNode) subject() {}
+
+// BlankNodePropertyList may appear in the subject or object position of a triple, but not within a quoted triple.
+type BlankNodePropertyList PredicateObjectList
+
+func ParseBlankNodePropertyList(n *parser.Node) (BlankNodePropertyList, error) {
+	if n.Name != "BlankNodePropertyList" {
+		return nil, fmt.Errorf("blank node property list: unknown %s", n.Name)
+	}
+	pol, err := ParsePredicateObjectList(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	return BlankNodePropertyList(pol), nil
+}
+
+func (b BlankNodePropertyList) String() string {
+	return fmt.Sprintf("[ %s ]", PredicateObjectList(b))
+}
+
+func (b BlankNodePropertyList) object() {}
+
+// Collection represents a rdf:first/rdf:rest list structure, its objects can be quoted triples.
+type Collection []Object
+
+func ParseCollection(n *parser.Node) (Collection, error) {
+	if n.Name != "Collection" {
+		return nil, fmt.Errorf("collection: unknown %s", n.Name)
+	}
+	var collection Collection
+	for _, n := range n.Children() {
+		object, err := ParseObject(n)
+		if err != nil {
+			return nil, err
+		}
+		collection = append(collection, object)
+	}
+	return collection, nil
+}
+
+func (c Collection) String() string {
+	var s string
+	s += "("
+	for _, o := range c {
+		s += " " + o.String()
+	}
+	s += " )"
+	return s
+}
+
+func (c Collection) object() {}
+
+func (c Collection) subject() {}
+
+type Document []Statement
+
+func ParseDocument(doc string) (Document, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	if !strings.HasSuffix(doc, "\n") {
+		doc += "\n"
+	}
+	p, err := grammar.NewParser([]rune(doc))
+	if err != nil {
+		return nil, err
+	}
+	n, err := p.Parse(op.And{grammar.Document, op.EOF{}})
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(n)
+}
+
+func parseDocument(n *parser.Node) (Document, error) {
+	if n.Name != "Document" {
+		return nil, fmt.Errorf("document: unknown: %s", n.Name)
+	}
+	var doc Document
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Directive":
+			d, err := ParseDirective(n)
+			if err != nil {
+				return nil, err
+			}
+			doc = append(doc, d)
+		case "Triples":
+			t, err := ParseTriples(n)
+			if err != nil {
+				return nil, err
+			}
+			doc = append(doc, t)
+		default:
+			return nil, fmt.Errorf("document: unknown: %s", n.Name)
+		}
+	}
+	return doc, nil
+}
+
+// ParseDirective parses a base or prefix directive.
+func ParseDirective(n *parser.Node) (Statement, error) {
+	d, err := ttl.ParseDirective(n)
+	if err != nil {
+		return nil, err
+	}
+	switch d := d.(type) {
+	case *ttl.Base:
+		return (*Base)(d), nil
+	case *ttl.Prefix:
+		return (*Prefix)(d), nil
+	default:
+		return nil, fmt.Errorf("unknown directive type: %T", d)
+	}
+}
+
+func (d Document) String() string {
+	var b strings.Builder
+	for _, s := range d {
+		b.WriteString(s.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+type IRI ttl.IRI
+
+func (i IRI) String() string {
+	return ttl.IRI(i).String()
+}
+
+func (i IRI) object() {}
+
+func (i IRI) subject() {}
+
+// Literal is either a string, numeric or boolean literal.
+type Literal struct {
+	ttl.Literal
+}
+
+func ParseLiteral(n *parser.Node) (*Literal, error) {
+	l, err := ttl.ParseLiteral(n)
+	if err != nil {
+		return nil, err
+	}
+	return &Literal{Literal: l}, nil
+}
+
+func (l Literal) object() {}
+
+// Object is either an IRI, blank node, literal, collection, blank node property list, quoted triple, triple term or
+// an annotated object.
+type Object interface {
+	object()
+
+	fmt.Stringer
+}
+
+func ParseObject(n *parser.Node) (Object, error) {
+	if n.Name != "Object" && n.Name != "QtObject" && n.Name != "TtObject" {
+		return nil, fmt.Errorf("object: unknown %s", n.Name)
+	}
+	switch n = n.Children()[0]; n.Name {
+	case "IRI":
+		iri, err := ttl.ParseIRI(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*IRI)(iri), nil
+	case "BlankNode":
+		bn, err := ttl.ParseBlankNode(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*BlankNode)(bn), nil
+	case "Collection":
+		return ParseCollection(n)
+	case "BlankNodePropertyList":
+		return ParseBlankNodePropertyList(n)
+	case "Literal":
+		return ParseLiteral(n)
+	case "QuotedTriple":
+		return ParseQuotedTriple(n)
+	case "TripleTerm":
+		return ParseTripleTerm(n)
+	default:
+		return nil, fmt.Errorf("object: unknown: %s", n.Name)
+	}
+}
+
+// ObjectList matches a series of objects separated by ',' following a predicate, each object can be annotated.
+type ObjectList []Object
+
+func ParseObjectList(n *parser.Node) (ObjectList, error) {
+	if n.Name != "ObjectList" {
+		return nil, fmt.Errorf("object list: unknown %s", n.Name)
+	}
+	var ol ObjectList
+	// annotated returns the last object as annotated object.
+	annotated := func() (*AnnotatedObject, error) {
+		if len(ol) == 0 {
+			return nil, fmt.Errorf("object list: annotation without object")
+		}
+		a, ok := ol[len(ol)-1].(*AnnotatedObject)
+		if !ok {
+			a = &AnnotatedObject{Object: ol[len(ol)-1]}
+			ol[len(ol)-1] = a
+		}
+		return a, nil
+	}
+	for _, n := range n.Children() {
+		switch n.Name {
+		case "Object":
+			o, err := ParseObject(n)
+			if err != nil {
+				return nil, err
+			}
+			ol = append(ol, o)
+		case "Reifier":
+			a, err := annotated()
+			if err != nil {
+				return nil, err
+			}
+			r, err := ParseReifier(n)
+			if err != nil {
+				return nil, err
+			}
+			a.Annotations = append(a.Annotations, Annotation{Reifier: r})
+		case "Annotation":
+			a, err := annotated()
+			if err != nil {
+				return nil, err
+			}
+			pol, err := ParsePredicateObjectList(n.Children()[0])
+			if err != nil {
+				return nil, err
+			}
+			// An annotation block belongs to the directly preceding reifier.
+			if i := len(a.Annotations) - 1; i >= 0 && a.Annotations[i].PredicateObjectList == nil {
+				a.Annotations[i].PredicateObjectList = pol
+			} else {
+				a.Annotations = append(a.Annotations, Annotation{PredicateObjectList: pol})
+			}
+		default:
+			return nil, fmt.Errorf("object list: unknown: %s", n.Name)
+		}
+	}
+	return ol, nil
+}
+
+func (ol ObjectList) String() string {
+	var s string
+	for i, o := range ol {
+		if i > 0 {
+			s += ", "
+		}
+		s += o.String()
+	}
+	return s
+}
+
+type PredicateObject struct {
+	Verb       ttl.Verb
+	ObjectList ObjectList
+}
+
+func ParsePredicateObject(n *parser.Node) (*PredicateObject, error) {
+	if n.Name != "PredicateObject" {
+		return nil, fmt.Errorf("predicate object: unknown %s", n.Name)
+	}
+	v, err := ttl.ParseVerb(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	ol, err := ParseObjectList(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	return &PredicateObject{Verb: v, ObjectList: ol}, nil
+}
+
+func (po PredicateObject) String() string {
+	return fmt.Sprintf("%s %s", po.Verb, po.ObjectList)
+}
+
+type PredicateObjectList []PredicateObject
+
+func ParsePredicateObjectList(n *parser.Node) (PredicateObjectList, error) {
+	if n.Name != "PredicateObjectList" {
+		return nil, fmt.Errorf("predicate object list: unknown %s", n.Name)
+	}
+	var pol PredicateObjectList
+	for _, n := range n.Children() {
+		po, err := ParsePredicateObject(n)
+		if err != nil {
+			return nil, err
+		}
+		pol = append(pol, *po)
+	}
+	return pol, nil
+}
+
+func (pol PredicateObjectList) String() string {
+	var s string
+	for i, po := range pol {
+		if i > 0 {
+			s += " ; "
+		}
+		s += po.String()
+	}
+	return s
+}
+
+type Prefix ttl.Prefix
+
+func (p Prefix) String() string {
+	return ttl.Prefix(p).String()
+}
+
+func (p Prefix) statement() {}
+
+// QuotedTriple is a triple that is used as subject or object of another triple, it is not asserted. Within RDF 1.2 it
+// is a reified triple, which is identified by its reifier.
+type QuotedTriple struct {
+	Subject Subject
+	Verb    ttl.Verb
+	Object  Object
+	Reifier *Reifier
+}
+
+func ParseQuotedTriple(n *parser.Node) (*QuotedTriple, error) {
+	if n.Name != "QuotedTriple" {
+		return nil, fmt.Errorf("quoted triple: unknown %s", n.Name)
+	}
+	if len(n.Children()) != 3 && len(n.Children()) != 4 {
+		return nil, fmt.Errorf("quoted triple: expected 3 or 4 children")
+	}
+	s, err := ParseSubject(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	v, err := ttl.ParseVerb(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	o, err := ParseObject(n.Children()[2])
+	if err != nil {
+		return nil, err
+	}
+	t := QuotedTriple{Subject: s, Verb: v, Object: o}
+	if len(n.Children()) == 4 {
+		r, err := ParseReifier(n.Children()[3])
+		if err != nil {
+			return nil, err
+		}
+		t.Reifier = r
+	}
+	return &t, nil
+}
+
+func (t QuotedTriple) String() string {
+	if t.Reifier != nil {
+		return fmt.Sprintf("<< %s %s %s %s >>", t.Subject, t.Verb, t.Object, t.Reifier)
+	}
+	return fmt.Sprintf("<< %s %s %s >>", t.Subject, t.Verb, t.Object)
+}
+
+func (t QuotedTriple) object() {}
+
+func (t QuotedTriple) subject() {}
+
+// Reifier identifies the reification of a triple (RDF 1.2). The label is either an IRI or a blank node, if it is
+// omitted a fresh blank node is used.
+type Reifier struct {
+	Label Subject
+}
+
+func ParseReifier(n *parser.Node) (*Reifier, error) {
+	if n.Name != "Reifier" {
+		return nil, fmt.Errorf("reifier: unknown %s", n.Name)
+	}
+	if len(n.Children()) == 0 {
+		return &Reifier{}, nil
+	}
+	switch n = n.Children()[0]; n.Name {
+	case "IRI":
+		iri, err := ttl.ParseIRI(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Reifier{Label: (*IRI)(iri)}, nil
+	case "BlankNode":
+		bn, err := ttl.ParseBlankNode(n)
+		if err != nil {
+			return nil, err
+		}
+		return &Reifier{Label: (*BlankNode)(bn)}, nil
+	default:
+		return nil, fmt.Errorf("reifier: unknown: %s", n.Name)
+	}
+}
+
+func (r Reifier) String() string {
+	if r.Label == nil {
+		return "~"
+	}
+	return fmt.Sprintf("~ %s", r.Label)
+}
+
+type Statement interface {
+	statement()
+
+	fmt.Stringer
+}
+
+// Subject is either an IRI, blank node, collection or quoted triple.
+type Subject interface {
+	subject()
+
+	fmt.Stringer
+}
+
+func ParseSubject(n *parser.Node) (Subject, error) {
+	if n.Name != "Subject" && n.Name != "QtSubject" && n.Name != "TtSubject" {
+		return nil, fmt.Errorf("subject: unknown %s", n.Name)
+	}
+	switch n = n.Children()[0]; n.Name {
+	case "IRI":
+		iri, err := ttl.ParseIRI(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*IRI)(iri), nil
+	case "BlankNode":
+		bn, err := ttl.ParseBlankNode(n)
+		if err != nil {
+			return nil, err
+		}
+		return (*BlankNode)(bn), nil
+	case "Collection":
+		return ParseCollection(n)
+	case "QuotedTriple":
+		return ParseQuotedTriple(n)
+	default:
+		return nil, fmt.Errorf("subject: unknown: %s", n.Name)
+	}
+}
+
+// TripleTerm is a triple that is used as object of another triple (RDF 1.2), it is not asserted.
+type TripleTerm struct {
+	Subject Subject
+	Verb    ttl.Verb
+	Object  Object
+}
+
+func ParseTripleTerm(n *parser.Node) (*TripleTerm, error) {
+	if n.Name != "TripleTerm" {
+		return nil, fmt.Errorf("triple term: unknown %s", n.Name)
+	}
+	if len(n.Children()) != 3 {
+		return nil, fmt.Errorf("triple term: expected 3 children")
+	}
+	s, err := ParseSubject(n.Children()[0])
+	if err != nil {
+		return nil, err
+	}
+	v, err := ttl.ParseVerb(n.Children()[1])
+	if err != nil {
+		return nil, err
+	}
+	o, err := ParseObject(n.Children()[2])
+	if err != nil {
+		return nil, err
+	}
+	return &TripleTerm{Subject: s, Verb: v, Object: o}, nil
+}
+
+func (t TripleTerm) String() string {
+	return fmt.Sprintf("<<( %s %s %s )>>", t.Subject, t.Verb, t.Object)
+}
+
+func (t TripleTerm) object() {}
+
+type Triple struct {
+	Subject               Subject
+	BlankNodePropertyList BlankNodePropertyList
+	PredicateObjectList   PredicateObjectList
+}
+
+func ParseTriples(n *parser.Node) (*Triple, error) {
+	if n.Name != "Triples" {
+		return nil, fmt.Errorf("triples: unknown %s", n.Name)
+	}
+	var t Triple
+	switch n = n.Children()[0]; n.Name {
+	case "TripleSubject":
+		s, err := ParseSubject(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		t.Subject = s
+	case "TripleBlankNodePropertyList":
+		bnpl, err := ParseBlankNodePropertyList(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		t.BlankNodePropertyList = bnpl
+	default:
+		return nil, fmt.Errorf("triples: unknown: %s", n.Name)
+	}
+	if len(n.Children()) == 2 {
+		pol, err := ParsePredicateObjectList(n.Children()[1])
+		if err != nil {
+			return nil, err
+		}
+		t.PredicateObjectList = pol
+	}
+	return &t, nil
+}
+
+func (t Triple) String() string {
+	var s string
+	if t.Subject != nil {
+		s += t.Subject.String()
+	} else {
+		s += t.BlankNodePropertyList.String()
+	}
+	if t.PredicateObjectList != nil {
+		s += fmt.Sprintf(" %s", t.PredicateObjectList)
+	}
+	return s + " ."
+}
+
+func (t Triple) statement() {}
@@ -0,0 +1,192 @@
+package turtle_test
+
+import (
+	"embed"
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/project"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	stl "github.com/0x51-dev/rdf/star/turtle"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"os"
+	"testing"
+)
+
+// base is the base IRI of the test suites, documents are resolved relative to it.
+const base = "https://w3c.github.io/rdf-star/tests/turtle/"
+
+//go:embed testdata/suite
+var suite embed.FS
+
+func ExampleEvaluateDocument() {
+	doc, _ := stl.ParseDocument(`PREFIX : <http://example/>
+:alice :knows :bob {| :since 2020 |} .`)
+	triples, _ := stl.EvaluateDocument(doc, "http://example/")
+	fmt.Print(triples)
+	// Output:
+	// <http://example/alice> <http://example/knows> <http://example/bob> .
+	// <<<http://example/alice> <http://example/knows> <http://example/bob>>> <http://example/since> "2020"^^<http://www.w3.org/2001/XMLSchema#integer> .
+}
+
+func TestEvaluateDocument_rdf12(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		turtle   string
+		ntriples string
+	}{
+		{
+			name:   "reified triple",
+			turtle: `<< :a :b :c ~ :r >> :q :z .`,
+			ntriples: `<http://example/r> <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/a> <http://example/b> <http://example/c> )>> .
+<http://example/r> <http://example/q> <http://example/z> .`,
+		},
+		{
+			name:   "reified triple without reifier",
+			turtle: `:s :p << :a :b :c >> .`,
+			ntriples: `_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/a> <http://example/b> <http://example/c> )>> .
+<http://example/s> <http://example/p> _:r .`,
+		},
+		{
+			name:   "annotations",
+			turtle: `:s :p :o ~ :r {| :q 1 |} {| :q 2 |} .`,
+			ntriples: `<http://example/s> <http://example/p> <http://example/o> .
+<http://example/r> <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/s> <http://example/p> <http://example/o> )>> .
+<http://example/r> <http://example/q> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:r <http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies> <<( <http://example/s> <http://example/p> <http://example/o> )>> .
+_:r <http://example/q> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		},
+		{
+			name:     "triple term",
+			turtle:   `:s :p <<( :a :b <<( :c :d "e"@en--ltr )>> )>> .`,
+			ntriples: `<http://example/s> <http://example/p> <<( <http://example/a> <http://example/b> <<( <http://example/c> <http://example/d> "e"@en--ltr )>> )>> .`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := stl.ParseDocument("PREFIX : <http://example/>\n" + test.turtle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			triples, err := stl.EvaluateDocumentMode(doc, "", nt.RDF12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := nts.ParseDocumentMode(test.ntriples, nt.RDF12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !triples.Equal(expected) {
+				t.Error(triples, expected)
+			}
+		})
+	}
+
+	// Quoted triples are not supported by RDF 1.1, reifiers and triple terms are not supported by RDF-star.
+	for mode, turtle := range map[nt.Mode]string{
+		nt.RDF11:   `:s :p :o {| :q :z |} .`,
+		nt.RDFStar: `:s :p <<( :a :b :c )>> .`,
+	} {
+		doc, err := stl.ParseDocument("PREFIX : <http://example/>\n" + turtle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stl.EvaluateDocumentMode(doc, "", mode); err == nil {
+			t.Errorf("expected error for %s", mode)
+		}
+	}
+}
+
+func TestSuite(t *testing.T) {
+	for _, kind := range []string{"syntax", "eval"} {
+		raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/manifest.ttl", kind))
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest, err := testsuite.LoadManifest(string(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report := project.NewReport(ttl.IRI{Value: fmt.Sprintf("%s%s/manifest#", base, kind)})
+		for _, k := range manifest.Keys {
+			e := manifest.Entries[k]
+			raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/%s", kind, e.Action))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cwd := fmt.Sprintf("%s%s/%s", base, kind, e.Action)
+			doc, err := stl.ParseDocument(string(raw))
+			switch e.Type {
+			case "rdft:TestTurtlePositiveSyntax":
+				t.Run(e.Name, func(t *testing.T) {
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					triples, err := stl.EvaluateDocument(doc, cwd)
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					if _, err := nts.ParseDocument(triples.String()); err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(triples.String())
+					}
+
+					// fmt.Stringer
+					if _, err := stl.ParseDocument(doc.String()); err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			case "rdft:TestTurtleNegativeSyntax":
+				t.Run(e.Name, func(t *testing.T) {
+					if err == nil {
+						if _, err := stl.EvaluateDocument(doc, cwd); err == nil {
+							report.AddTest(e.Name, testsuite.Failed)
+							t.Fatal("expected error")
+						}
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			case "rdft:TestTurtleEval":
+				t.Run(e.Name, func(t *testing.T) {
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+
+					raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s/%s", kind, e.Result))
+					if err != nil {
+						t.Fatal(err)
+					}
+					expected, err := nts.ParseDocument(string(raw))
+					if err != nil {
+						t.Fatal(err)
+					}
+					triples, err := stl.EvaluateDocument(doc, cwd)
+					if err != nil {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatal(err)
+					}
+					if !expected.Equal(triples) {
+						report.AddTest(e.Name, testsuite.Failed)
+						t.Fatalf("expected:\n%s\nactual:\n%s", expected, triples)
+					}
+
+					report.AddTest(e.Name, testsuite.Passed)
+				})
+			default:
+				t.Fatal("unknown test type", e.Type)
+			}
+		}
+
+		t.Log("Total tests:", report.Len())
+		if os.Getenv("TEST_SUITE_REPORT") == "true" {
+			_ = os.WriteFile(fmt.Sprintf("testdata/suite/%s/report.ttl", kind), []byte(report.String()), 0644)
+		}
+	}
+}
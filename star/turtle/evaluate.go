@@ -0,0 +1,359 @@
+package turtle
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/iri"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	nts "github.com/0x51-dev/rdf/star/ntriples"
+	ttl "github.com/0x51-dev/rdf/turtle"
+)
+
+const (
+	rdfFirst   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfNil     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	rdfReifies = "http://www.w3.org/1999/02/22-rdf-syntax-ns#reifies"
+	rdfRest    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfType    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+)
+
+// EvaluateAnnotation evaluates the annotation of the (asserted) triple. Within RDF-star the triple is used as quoted
+// subject, within RDF 1.2 the triple is reified by the reifier of the annotation (or a fresh blank node).
+func (ctx *Context) EvaluateAnnotation(t nts.Triple, a Annotation) ([]nts.Triple, error) {
+	switch m := ctx.Mode; m {
+	case nt.RDFStar:
+		if a.Reifier != nil {
+			return nil, fmt.Errorf("annotation: reifiers are not supported by %s", m)
+		}
+		return ctx.EvaluatePredicateObjectList(nts.QuotedTriple{Triple: t}, a.PredicateObjectList)
+	case nt.RDF12:
+		r, err := ctx.EvaluateReifier(a.Reifier)
+		if err != nil {
+			return nil, err
+		}
+		triples := []nts.Triple{{Subject: r, Predicate: rdfReifies, Object: nts.TripleTerm{Triple: t}}}
+		ts, err := ctx.EvaluatePredicateObjectList(r, a.PredicateObjectList)
+		if err != nil {
+			return nil, err
+		}
+		return append(triples, ts...), nil
+	default:
+		return nil, fmt.Errorf("annotation: not supported by %s", m)
+	}
+}
+
+func (ctx *Context) EvaluateCollection(c Collection) (nts.Object, []nts.Triple, error) {
+	var objects []nts.Object
+	var triples []nts.Triple
+	for _, o := range c {
+		o, ts, err := ctx.EvaluateObject(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		objects = append(objects, o)
+		triples = append(triples, ts...)
+	}
+	if len(objects) == 0 {
+		return nts.IRIReference(rdfNil), triples, nil
+	}
+	elements := make([]nts.BlankNode, len(objects))
+	for i := range elements {
+		elements[i] = ctx.el()
+	}
+	for i, o := range objects {
+		triples = append(triples, nts.Triple{Subject: elements[i], Predicate: rdfFirst, Object: o})
+		var rest nts.Object = nts.IRIReference(rdfNil)
+		if i+1 != len(objects) {
+			rest = elements[i+1]
+		}
+		triples = append(triples, nts.Triple{Subject: elements[i], Predicate: rdfRest, Object: rest})
+	}
+	return elements[0], triples, nil
+}
+
+func (ctx *Context) EvaluateLiteral(l *Literal) (nts.Literal, error) {
+	var (
+		v   *nt.Literal
+		err error
+	)
+	switch l := l.Literal.(type) {
+	case *ttl.StringLiteral:
+		v, err = ctx.EvaluateStringLiteral(l)
+	case *ttl.NumericLiteral:
+		v, err = ctx.EvaluateNumericLiteral(l)
+	case *ttl.BooleanLiteral:
+		v, err = ctx.EvaluateBooleanLiteral(l)
+	default:
+		return nts.Literal{}, fmt.Errorf("unknown literal type %T", l)
+	}
+	if err != nil {
+		return nts.Literal{}, err
+	}
+	return nts.Literal(*v), nil
+}
+
+// EvaluateObject evaluates the object, the returned triples are the triples that are asserted by the object (e.g. the
+// elements of a collection).
+func (ctx *Context) EvaluateObject(o Object) (nts.Object, []nts.Triple, error) {
+	switch o := o.(type) {
+	case *IRI:
+		i, err := ctx.EvaluateIRI((*ttl.IRI)(o))
+		if err != nil {
+			return nil, nil, err
+		}
+		return nts.IRIReference(*i), nil, nil
+	case *BlankNode:
+		return ctx.evaluateBlankNode(o), nil, nil
+	case BlankNodePropertyList:
+		bn := ctx.bn()
+		ts, err := ctx.EvaluatePredicateObjectList(bn, PredicateObjectList(o))
+		if err != nil {
+			return nil, nil, err
+		}
+		return bn, ts, nil
+	case Collection:
+		return ctx.EvaluateCollection(o)
+	case *Literal:
+		l, err := ctx.EvaluateLiteral(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		return l, nil, nil
+	case *QuotedTriple:
+		s, ts, err := ctx.evaluateQuotedTriple(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		return s.(nts.Object), ts, nil
+	case *TripleTerm:
+		t, err := ctx.EvaluateTripleTerm(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		return t, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown object type %T", o)
+	}
+}
+
+// EvaluatePredicateObjectList evaluates the predicates and objects with the given subject. The triples of annotated
+// objects are asserted before their annotations are evaluated (see EvaluateAnnotation).
+func (ctx *Context) EvaluatePredicateObjectList(s nts.Subject, pol PredicateObjectList) ([]nts.Triple, error) {
+	var triples []nts.Triple
+	for _, po := range pol {
+		p, err := ctx.EvaluateVerb(po.Verb)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range po.ObjectList {
+			var annotations []Annotation
+			if a, ok := o.(*AnnotatedObject); ok {
+				o, annotations = a.Object, a.Annotations
+			}
+			o, ts, err := ctx.EvaluateObject(o)
+			if err != nil {
+				return nil, err
+			}
+			triples = append(triples, ts...)
+			t := nts.Triple{Subject: s, Predicate: p, Object: o}
+			triples = append(triples, t)
+			if len(annotations) > 1 && ctx.Mode != nt.RDF12 {
+				return nil, fmt.Errorf("annotation: multiple annotations are not supported by %s", ctx.Mode)
+			}
+			for _, a := range annotations {
+				ts, err := ctx.EvaluateAnnotation(t, a)
+				if err != nil {
+					return nil, err
+				}
+				triples = append(triples, ts...)
+			}
+		}
+	}
+	return triples, nil
+}
+
+// EvaluateQuotedTriple evaluates the quoted triple of RDF-star, it does not assert the triple.
+func (ctx *Context) EvaluateQuotedTriple(t *QuotedTriple) (nts.QuotedTriple, error) {
+	if t.Reifier != nil {
+		return nts.QuotedTriple{}, fmt.Errorf("quoted triple: reifiers are not supported by %s", ctx.Mode)
+	}
+	s, ts, err := ctx.EvaluateSubject(t.Subject)
+	if err != nil {
+		return nts.QuotedTriple{}, err
+	}
+	p, err := ctx.EvaluateVerb(t.Verb)
+	if err != nil {
+		return nts.QuotedTriple{}, err
+	}
+	o, os, err := ctx.EvaluateObject(t.Object)
+	if err != nil {
+		return nts.QuotedTriple{}, err
+	}
+	if len(ts) != 0 || len(os) != 0 {
+		return nts.QuotedTriple{}, fmt.Errorf("quoted triple: unexpected nested triples in %s", t)
+	}
+	return nts.QuotedTriple{Triple: nts.Triple{Subject: s, Predicate: p, Object: o}}, nil
+}
+
+// EvaluateReifiedTriple evaluates the reified triple of RDF 1.2, it returns the reifier together with the triples that
+// reify the triple (and the nested reified triples). The triple itself is not asserted.
+func (ctx *Context) EvaluateReifiedTriple(t *QuotedTriple) (nts.Subject, []nts.Triple, error) {
+	s, triples, err := ctx.EvaluateSubject(t.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := ctx.EvaluateVerb(t.Verb)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, ts, err := ctx.EvaluateObject(t.Object)
+	if err != nil {
+		return nil, nil, err
+	}
+	triples = append(triples, ts...)
+	r, err := ctx.EvaluateReifier(t.Reifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	tt := nts.TripleTerm{Triple: nts.Triple{Subject: s, Predicate: p, Object: o}}
+	return r, append(triples, nts.Triple{Subject: r, Predicate: rdfReifies, Object: tt}), nil
+}
+
+// EvaluateReifier evaluates the label of the reifier, a fresh blank node is returned if the reifier or its label is
+// omitted.
+func (ctx *Context) EvaluateReifier(r *Reifier) (nts.Subject, error) {
+	if r == nil || r.Label == nil {
+		return ctx.bn(), nil
+	}
+	s, _, err := ctx.EvaluateSubject(r.Label)
+	return s, err
+}
+
+func (ctx *Context) EvaluateSubject(s Subject) (nts.Subject, []nts.Triple, error) {
+	switch s := s.(type) {
+	case *IRI:
+		i, err := ctx.EvaluateIRI((*ttl.IRI)(s))
+		if err != nil {
+			return nil, nil, err
+		}
+		return nts.IRIReference(*i), nil, nil
+	case *BlankNode:
+		return ctx.evaluateBlankNode(s), nil, nil
+	case Collection:
+		o, ts, err := ctx.EvaluateCollection(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		return o.(nts.Subject), ts, nil
+	case *QuotedTriple:
+		return ctx.evaluateQuotedTriple(s)
+	default:
+		return nil, nil, fmt.Errorf("unknown subject type %T", s)
+	}
+}
+
+func (ctx *Context) EvaluateTriple(t *Triple) ([]nts.Triple, error) {
+	var (
+		subject nts.Subject
+		triples []nts.Triple
+	)
+	if t.Subject != nil {
+		s, ts, err := ctx.EvaluateSubject(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+		subject, triples = s, ts
+	} else {
+		bn := ctx.bn()
+		ts, err := ctx.EvaluatePredicateObjectList(bn, PredicateObjectList(t.BlankNodePropertyList))
+		if err != nil {
+			return nil, err
+		}
+		subject, triples = bn, ts
+	}
+	ts, err := ctx.EvaluatePredicateObjectList(subject, t.PredicateObjectList)
+	if err != nil {
+		return nil, err
+	}
+	return append(triples, ts...), nil
+}
+
+// EvaluateTripleTerm evaluates the triple term of RDF 1.2, it does not assert the triple.
+func (ctx *Context) EvaluateTripleTerm(t *TripleTerm) (nts.TripleTerm, error) {
+	if ctx.Mode != nt.RDF12 {
+		return nts.TripleTerm{}, fmt.Errorf("triple term: not supported by %s", ctx.Mode)
+	}
+	s, _, err := ctx.EvaluateSubject(t.Subject)
+	if err != nil {
+		return nts.TripleTerm{}, err
+	}
+	p, err := ctx.EvaluateVerb(t.Verb)
+	if err != nil {
+		return nts.TripleTerm{}, err
+	}
+	o, _, err := ctx.EvaluateObject(t.Object)
+	if err != nil {
+		return nts.TripleTerm{}, err
+	}
+	return nts.TripleTerm{Triple: nts.Triple{Subject: s, Predicate: p, Object: o}}, nil
+}
+
+func (ctx *Context) EvaluateVerb(v ttl.Verb) (nt.IRIReference, error) {
+	switch v := v.(type) {
+	case *ttl.IRI:
+		p, err := ctx.EvaluateIRI(v)
+		if err != nil {
+			return "", err
+		}
+		return *p, nil
+	case *ttl.A:
+		return rdfType, nil
+	default:
+		return "", fmt.Errorf("unknown verb type %T", v)
+	}
+}
+
+func (ctx *Context) evaluateBlankNode(b *BlankNode) nts.BlankNode {
+	if *b == "[]" {
+		return ctx.bn()
+	}
+	return nts.BlankNode(*b)
+}
+
+// evaluateQuotedTriple evaluates the quoted triple based on the mode of the context, see EvaluateQuotedTriple and
+// EvaluateReifiedTriple.
+func (ctx *Context) evaluateQuotedTriple(t *QuotedTriple) (nts.Subject, []nts.Triple, error) {
+	switch m := ctx.Mode; m {
+	case nt.RDFStar:
+		qt, err := ctx.EvaluateQuotedTriple(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		return qt, nil, nil
+	case nt.RDF12:
+		return ctx.EvaluateReifiedTriple(t)
+	default:
+		return nil, nil, fmt.Errorf("quoted triple: not supported by %s", m)
+	}
+}
+
+func (ctx *Context) evaluateDocument(d Document, cwd string) (nts.Document, error) {
+	ctx.Base = cwd
+	var document nts.Document
+	for _, t := range d {
+		switch t := t.(type) {
+		case *Base:
+			ctx.Base = iri.Resolve(ctx.Base, string(*t))
+		case *Prefix:
+			ctx.Prefixes[t.Name] = iri.Resolve(ctx.Base, t.IRI)
+		case *Triple:
+			ts, err := ctx.EvaluateTriple(t)
+			if err != nil {
+				return nil, err
+			}
+			document = append(document, ts...)
+		default:
+			return nil, fmt.Errorf("unknown document type %T", t)
+		}
+	}
+	return document, nil
+}
@@ -0,0 +1,173 @@
+package grammar
+
+import (
+	nt "github.com/0x51-dev/rdf/ntriples/grammar"
+	ttl "github.com/0x51-dev/rdf/turtle/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+)
+
+var (
+	Document = op.Capture{
+		Name: "Document",
+		Value: op.ZeroOrMore{Value: op.And{
+			nt.OWhitespace,
+			op.Or{
+				ttl.Directive,
+				op.And{Triples, ttl.WSPLNC, '.'},
+				op.And{op.Optional{Value: nt.Comment}, op.EndOfLine{}},
+			},
+		}},
+	}
+	Triples = op.Capture{
+		Name: "Triples",
+		Value: op.Or{
+			op.Capture{
+				Name: "TripleSubject",
+				Value: op.And{
+					Subject,
+					ttl.WSPLNC,
+					PredicateObjectList,
+				},
+			},
+			op.Capture{
+				Name: "TripleBlankNodePropertyList",
+				Value: op.And{
+					BlankNodePropertyList,
+					op.Optional{Value: op.And{ttl.WSPLNC, PredicateObjectList}},
+				},
+			},
+		},
+	}
+	PredicateObject = op.Capture{
+		Name: "PredicateObject",
+		Value: op.And{
+			ttl.Verb,
+			ttl.WSPLNC,
+			ObjectList,
+		},
+	}
+	PredicateObjectList = op.Capture{
+		Name: "PredicateObjectList",
+		Value: op.And{
+			PredicateObject,
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, ';',
+				op.Optional{Value: op.And{ttl.WSPLNC, PredicateObject}},
+			}},
+		},
+	}
+	ObjectList = op.Capture{
+		Name: "ObjectList",
+		Value: op.And{
+			Object,
+			op.ZeroOrMore{Value: op.And{ttl.WSPLNC, op.Or{Reifier, Annotation}}},
+			op.ZeroOrMore{Value: op.And{
+				ttl.WSPLNC, ',',
+				ttl.WSPLNC, Object,
+				op.ZeroOrMore{Value: op.And{ttl.WSPLNC, op.Or{Reifier, Annotation}}},
+			}},
+		},
+	}
+	Subject = op.Capture{
+		Name:  "Subject",
+		Value: op.Or{ttl.IRI, ttl.BlankNode, Collection, QuotedTriple},
+	}
+	Object = op.Capture{
+		Name: "Object",
+		Value: op.Or{
+			ttl.Literal,
+			ttl.IRI,
+			ttl.BlankNode,
+			op.Reference{Name: "Collection"},
+			op.Reference{Name: "BlankNodePropertyList"},
+			TripleTerm,
+			QuotedTriple,
+		},
+	}
+	BlankNodePropertyList = op.Capture{
+		Name:  "BlankNodePropertyList",
+		Value: op.And{'[', ttl.WSPLNC, op.Reference{Name: "PredicateObjectList"}, ttl.WSPLNC, ']'},
+	}
+	Collection = op.Capture{
+		Name: "Collection",
+		Value: op.And{
+			'(',
+			ttl.WSPLNC,
+			op.ZeroOrMore{Value: op.And{
+				op.Reference{Name: "Object"},
+				ttl.WSPLNC,
+			}},
+			')',
+		},
+	}
+	// QuotedTriple is a triple that is used as subject or object, without being asserted.
+	QuotedTriple = op.Capture{
+		Name: "QuotedTriple",
+		Value: op.And{
+			"<<", ttl.WSPLNC,
+			QtSubject, ttl.WSPLNC,
+			ttl.Verb, ttl.WSPLNC,
+			QtObject, ttl.WSPLNC,
+			op.Optional{Value: op.And{Reifier, ttl.WSPLNC}},
+			">>",
+		},
+	}
+	QtSubject = op.Capture{
+		Name:  "QtSubject",
+		Value: op.Or{ttl.IRI, ttl.BlankNode, op.Reference{Name: "QuotedTriple"}},
+	}
+	QtObject = op.Capture{
+		Name: "QtObject",
+		Value: op.Or{
+			ttl.Literal,
+			ttl.IRI,
+			ttl.BlankNode,
+			op.Reference{Name: "TripleTerm"},
+			op.Reference{Name: "QuotedTriple"},
+		},
+	}
+	// TripleTerm is the triple term of RDF 1.2, which can only be used as object.
+	TripleTerm = op.Capture{
+		Name: "TripleTerm",
+		Value: op.And{
+			"<<(", ttl.WSPLNC,
+			TtSubject, ttl.WSPLNC,
+			ttl.Verb, ttl.WSPLNC,
+			TtObject, ttl.WSPLNC,
+			")>>",
+		},
+	}
+	TtSubject = op.Capture{
+		Name:  "TtSubject",
+		Value: op.Or{ttl.IRI, ttl.BlankNode},
+	}
+	TtObject = op.Capture{
+		Name:  "TtObject",
+		Value: op.Or{ttl.Literal, ttl.IRI, ttl.BlankNode, op.Reference{Name: "TripleTerm"}},
+	}
+	// Reifier identifies the reification of the preceding triple (RDF 1.2), e.g. "~ :r".
+	Reifier = op.Capture{
+		Name:  "Reifier",
+		Value: op.And{'~', ttl.WSPLNC, op.Optional{Value: op.Or{ttl.IRI, ttl.BlankNode}}},
+	}
+	// Annotation asserts the triples of the predicate object list with the preceding triple as (quoted) subject.
+	Annotation = op.Capture{
+		Name:  "Annotation",
+		Value: op.And{"{|", ttl.WSPLNC, op.Reference{Name: "PredicateObjectList"}, ttl.WSPLNC, "|}"},
+	}
+)
+
+func NewParser(input []rune) (*parser.Parser, error) {
+	p, err := parser.New(input)
+	if err != nil {
+		return nil, err
+	}
+	p.Rules["BlankNodePropertyList"] = BlankNodePropertyList
+	p.Rules["Collection"] = Collection
+	p.Rules["Object"] = Object
+	p.Rules["PredicateObjectList"] = PredicateObjectList
+	p.Rules["QuotedTriple"] = QuotedTriple
+	p.Rules["TripleTerm"] = TripleTerm
+	return p, nil
+}
@@ -20,8 +20,3 @@ func (ctx *Context) bn() nt.BlankNode {
 	ctx.BnIndex++
 	return nt.BlankNode(fmt.Sprintf("b%d", ctx.BnIndex))
 }
-
-func (ctx *Context) el() nt.BlankNode {
-	ctx.ElIndex++
-	return nt.BlankNode(fmt.Sprintf("el%d", ctx.ElIndex))
-}
@@ -3,6 +3,7 @@ package trig
 import (
 	"fmt"
 	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
 	"github.com/0x51-dev/rdf/trig/grammar"
 	ttl "github.com/0x51-dev/rdf/turtle"
 	"github.com/0x51-dev/upeg/parser"
@@ -10,8 +11,16 @@ import (
 	"strings"
 )
 
-func EvaluateDocument(doc Document) (nq.Document, error) {
-	return NewContext().evaluateDocument(doc)
+// EvaluateDocument returns the quads of the document, relative IRIs are resolved against the base IRI cwd.
+func EvaluateDocument(doc Document, cwd string) (nq.Document, error) {
+	return NewContext().evaluateDocument(doc, cwd)
+}
+
+// EvaluateDocumentMode evaluates the document within the given version of RDF, see EvaluateDocument.
+func EvaluateDocumentMode(doc Document, cwd string, m nt.Mode) (nq.Document, error) {
+	ctx := NewContext()
+	ctx.Mode = m
+	return ctx.evaluateDocument(doc, cwd)
 }
 
 func ValidateDocument(doc Document) bool {
@@ -37,6 +37,27 @@ func Example_example1() {
 	// :G1 { :Monica a ex:Person ; ex:email <mailto:monica@monicamurphy.org> ; ex:hasSkill ex:Management, ex:Programming ; ex:homepage <http://www.monicamurphy.org> ; ex:name "Monica Murphy" . }
 }
 
+func TestEvaluateDocument_base(t *testing.T) {
+	doc, err := trig.ParseDocument(`@base <http://a/b/c/d> .
+@base <../x/> .
+@prefix p: <y/> .
+<g> { <#s> p:p <?q> . }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quads, err := trig.EvaluateDocument(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := nq.ParseDocument(`<http://a/b/x/#s> <http://a/b/x/y/p> <http://a/b/x/?q> <http://a/b/x/g> .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !quads.Equal(expected) {
+		t.Error(quads)
+	}
+}
+
 func TestExamples(t *testing.T) {
 	// Amount of triples in each example (manually counted).
 	triples := []int{
@@ -103,7 +124,7 @@ func TestSuite(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				if ntr, err := trig.EvaluateDocument(doc); err != nil {
+				if ntr, err := trig.EvaluateDocument(doc, ""); err != nil {
 					report.AddTest(e.Name, testsuite.Failed)
 					t.Fatal(err)
 				} else {
@@ -161,7 +182,7 @@ func TestSuite(t *testing.T) {
 				if _ = ntr; err != nil {
 					t.Fatal(err)
 				}
-				ntr2, err := trig.EvaluateDocument(doc)
+				ntr2, err := trig.EvaluateDocument(doc, "")
 				if err != nil {
 					report.AddTest(e.Name, testsuite.Failed)
 					t.Fatal(err)
@@ -195,7 +216,7 @@ func TestSuite(t *testing.T) {
 					t.Fatal(len(doc), len(doc2))
 				}
 
-				if _, err := trig.EvaluateDocument(doc); err == nil {
+				if _, err := trig.EvaluateDocument(doc, ""); err == nil {
 					report.AddTest(e.Name, testsuite.Failed)
 					t.Fatal(doc)
 				}
@@ -0,0 +1,97 @@
+package trig
+
+import (
+	"fmt"
+	nq "github.com/0x51-dev/rdf/nquads"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"sort"
+	"strings"
+)
+
+// Encode serializes the quads of the document as (pretty) TriG. The triples of the default graph are written at the top
+// level, the triples of named graphs are wrapped in a block with their graph label. Within each graph, triples are
+// grouped and abbreviated the same way as ttl.Encode does.
+func Encode(doc nq.Document, prefixes map[string]string) (string, error) {
+	e, err := ttl.NewEncoder(prefixes)
+	if err != nil {
+		return "", err
+	}
+
+	var labels []string
+	graphs := make(map[string]nt.Document)
+	graphLabels := make(map[string]nt.Subject)
+	// Blank nodes that are used in more than one graph, or as graph label, can not be nested.
+	blankNodes := make(map[string]string)
+	shared := make(map[nt.BlankNode]bool)
+	use := func(bn nt.BlankNode, graph string) {
+		if g, ok := blankNodes[string(bn)]; ok && g != graph {
+			shared[bn] = true
+		}
+		blankNodes[string(bn)] = graph
+	}
+	for _, q := range doc {
+		var label string
+		if q.GraphLabel != nil {
+			label = q.GraphLabel.String()
+			switch g := q.GraphLabel.(type) {
+			case nt.BlankNode:
+				shared[g] = true
+			case *nt.BlankNode:
+				shared[*g] = true
+			}
+		}
+		if _, ok := graphs[label]; !ok {
+			labels = append(labels, label)
+			graphLabels[label] = q.GraphLabel
+		}
+		graphs[label] = append(graphs[label], q.Triple)
+		for _, v := range []any{q.Subject, q.Object} {
+			switch bn := v.(type) {
+			case nt.BlankNode:
+				use(bn, label)
+			case *nt.BlankNode:
+				use(*bn, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+
+	isShared := func(bn nt.BlankNode) bool {
+		return shared[bn]
+	}
+	var blocks []string
+	for _, label := range labels {
+		if label == "" {
+			s, err := e.EncodeTriples(graphs[label], "", isShared)
+			if err != nil {
+				return "", err
+			}
+			blocks = append(blocks, s)
+			continue
+		}
+		s, err := e.EncodeTriples(graphs[label], "    ", isShared)
+		if err != nil {
+			return "", err
+		}
+		switch g := graphLabels[label].(type) {
+		case nt.IRIReference:
+			label = e.IRI(g)
+		case *nt.IRIReference:
+			label = e.IRI(*g)
+		case nt.BlankNode, *nt.BlankNode:
+		default:
+			return "", fmt.Errorf("encode: unknown graph label type %T", g)
+		}
+		blocks = append(blocks, fmt.Sprintf("%s {\n%s}\n", label, s))
+	}
+
+	body := strings.Join(blocks, "\n")
+	if directives := e.Directives(); directives != "" {
+		if body == "" {
+			return directives, nil
+		}
+		return directives + "\n" + body, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,93 @@
+package trig_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nq "github.com/0x51-dev/rdf/nquads"
+	"github.com/0x51-dev/rdf/trig"
+	"testing"
+)
+
+func ExampleEncode() {
+	doc, _ := nq.ParseDocument(`<http://example.org/bob> <http://purl.org/dc/terms/publisher> "Bob" .
+<http://example.org/alice> <http://purl.org/dc/terms/publisher> "Alice" .
+_:a <http://xmlns.com/foaf/0.1/name> "Bob" <http://example.org/bob> .
+_:a <http://xmlns.com/foaf/0.1/mbox> <mailto:bob@oldcorp.example.org> <http://example.org/bob> .
+_:a <http://xmlns.com/foaf/0.1/knows> _:b <http://example.org/bob> .
+_:b <http://xmlns.com/foaf/0.1/name> "Alice" <http://example.org/alice> .
+_:b <http://xmlns.com/foaf/0.1/mbox> <mailto:alice@work.example.org> <http://example.org/alice> .
+`)
+	s, _ := trig.Encode(doc, map[string]string{
+		"ex":   "http://example.org/",
+		"dc":   "http://purl.org/dc/terms/",
+		"foaf": "http://xmlns.com/foaf/0.1/",
+	})
+	fmt.Print(s)
+	// Output:
+	// @prefix dc: <http://purl.org/dc/terms/> .
+	// @prefix ex: <http://example.org/> .
+	// @prefix foaf: <http://xmlns.com/foaf/0.1/> .
+	//
+	// ex:alice dc:publisher "Alice" .
+	//
+	// ex:bob dc:publisher "Bob" .
+	//
+	// ex:alice {
+	//     _:b foaf:mbox <mailto:alice@work.example.org> ;
+	//         foaf:name "Alice" .
+	// }
+	//
+	// ex:bob {
+	//     [] foaf:knows _:b ;
+	//         foaf:mbox <mailto:bob@oldcorp.example.org> ;
+	//         foaf:name "Bob" .
+	// }
+}
+
+func TestEncode(t *testing.T) {
+	manifest, err := testsuite.LoadManifest(rawManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixes := map[string]string{
+		"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+		"ex":  "http://example.org/ns#",
+	}
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		if e.Type != "rdft:TestTrigEval" {
+			continue
+		}
+		t.Run(e.Name, func(t *testing.T) {
+			raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s", e.Action))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := trig.ParseDocument(string(raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nqr, err := trig.EvaluateDocument(doc, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := trig.Encode(nqr, prefixes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc2, err := trig.ParseDocument(s)
+			if err != nil {
+				t.Fatal(s, err)
+			}
+			nqr2, err := trig.EvaluateDocument(doc2, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !nqr.Equal(nqr2) {
+				t.Fatal(nqr, "\n", s, "\n", nqr2)
+			}
+		})
+	}
+}
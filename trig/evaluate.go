@@ -2,19 +2,21 @@ package trig
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/iri"
 	nq "github.com/0x51-dev/rdf/nquads"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	ttl "github.com/0x51-dev/rdf/turtle"
 )
 
-func (ctx *Context) evaluateDocument(d Document) (nq.Document, error) {
+func (ctx *Context) evaluateDocument(d Document, cwd string) (nq.Document, error) {
+	ctx.Context.Base = cwd
 	var triples []nq.Quad
 	for _, t := range d {
 		switch t := t.(type) {
 		case *Base:
-			ctx.Context.Base = string(*t)
+			ctx.Context.Base = iri.Resolve(ctx.Context.Base, string(*t))
 		case *Prefix:
-			ctx.Context.Prefixes[t.Name] = t.IRI
+			ctx.Context.Prefixes[t.Name] = iri.Resolve(ctx.Context.Base, t.IRI)
 		case *TriplesOrGraph:
 			switch los := t.LabelOrSubject.(type) {
 			case *IRI:
@@ -174,55 +176,14 @@ func (ctx *Context) evaluateDocument(d Document) (nq.Document, error) {
 					}
 				}
 			} else {
-				var subject nt.Subject
-				var objects []nt.Object
-				for _, o := range t.Collection {
-					os, ts, err := ctx.EvaluateObject(o)
-					if err != nil {
-						return nil, err
-					}
-					objects = append(objects, os...)
-					for _, t := range ts {
-						triples = append(triples, nq.NewQuadFromTriple(t, nil))
-					}
-				}
-				if len(objects) == 0 {
-					return triples, nil
+				o, ts, err := ctx.EvaluateCollection(t.Collection)
+				if err != nil {
+					return nil, err
 				}
-
-				var el nt.BlankNode
-				for i, o := range objects {
-					e := ctx.el()
-					if i == 0 {
-						subject = e
-					}
-					triples = append(triples, nq.NewQuadFromTriple(
-						nt.Triple{
-							Subject:   &e,
-							Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
-							Object:    o,
-						}, nil,
-					))
-					if i+1 != len(objects) {
-						el = ctx.el()
-						triples = append(triples, nq.NewQuadFromTriple(
-							nt.Triple{
-								Subject:   &e,
-								Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-								Object:    &el,
-							}, nil,
-						))
-					} else {
-						el = e
-					}
+				for _, t := range ts {
+					triples = append(triples, nq.NewQuadFromTriple(t, nil))
 				}
-				triples = append(triples, nq.NewQuadFromTriple(
-					nt.Triple{
-						Subject:   &el,
-						Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-						Object:    nt.IRIReference("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"),
-					}, nil,
-				))
+				subject := o.(nt.Subject)
 
 				for _, po := range t.PredicateObjectList {
 					p, os, ts, err := ctx.EvaluatePredicateObject(po)
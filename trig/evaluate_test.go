@@ -0,0 +1,37 @@
+package trig_test
+
+import (
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/trig"
+	"sort"
+	"testing"
+)
+
+func TestEvaluateDocument_collection(t *testing.T) {
+	doc, err := trig.ParseDocument(`( 1 2 3 ) <http://example.org/p> <http://example.org/o> .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quads, err := trig.EvaluateDocument(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := nt.ParseDocument(`
+_:el1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:el2 .
+_:el2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:el3 .
+_:el3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "3"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:el1 <http://example.org/p> <http://example.org/o> .
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every element links to the next one, all triples belong to the default graph.
+	triples := quads.Graphs()[""]
+	sort.Sort(triples)
+	if !triples.Equal(expected) {
+		t.Error(triples)
+	}
+}
@@ -0,0 +1,4 @@
+package trig
+
+// MediaType is the media type of TriG documents.
+const MediaType = "application/trig"
@@ -8,6 +8,8 @@ import (
 type Context struct {
 	Base     string
 	Prefixes map[string]string
+	// Mode is the version of RDF that is used to evaluate documents, RDF-star by default.
+	Mode nt.Mode
 
 	BnIndex, ElIndex int
 }
@@ -15,6 +17,7 @@ type Context struct {
 func NewContext() *Context {
 	return &Context{
 		Prefixes: make(map[string]string),
+		Mode:     nt.RDFStar,
 	}
 }
 
@@ -14,6 +14,13 @@ func EvaluateDocument(doc Document, cwd string) (nt.Document, error) {
 	return NewContext().evaluateDocument(doc, cwd)
 }
 
+// EvaluateDocumentMode evaluates the document within the given version of RDF, see EvaluateDocument.
+func EvaluateDocumentMode(doc Document, cwd string, m nt.Mode) (nt.Document, error) {
+	ctx := NewContext()
+	ctx.Mode = m
+	return ctx.evaluateDocument(doc, cwd)
+}
+
 func ValidateDocument(doc Document) bool {
 	return NewContext().validateDocument(doc)
 }
@@ -618,10 +625,12 @@ func ParseRDFLiteral(n *parser.Node) (Literal, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(n.Children()) > 1 {
-		switch n := n.Children()[1]; n.Name {
+	for _, n := range n.Children()[1:] {
+		switch n.Name {
 		case "LanguageTag":
 			v.LanguageTag = n.Value()
+		case "Direction":
+			v.Direction = n.Value()
 		case "IRI":
 			iri, err := ParseIRI(n)
 			if err != nil {
@@ -949,6 +958,8 @@ type StringLiteral struct {
 	Multiline   bool
 	SingleQuote bool
 	LanguageTag string
+	// Direction is the base direction of the language-tagged string (RDF 1.2).
+	Direction   string
 	DatatypeIRI *IRI
 }
 
@@ -1007,6 +1018,9 @@ func (sl StringLiteral) String() string {
 	if sl.LanguageTag != "" {
 		s += fmt.Sprintf("@%s", sl.LanguageTag)
 	}
+	if sl.Direction != "" {
+		s += fmt.Sprintf("--%s", sl.Direction)
+	}
 	if sl.DatatypeIRI != nil {
 		s += fmt.Sprintf("^^%s", sl.DatatypeIRI)
 	}
@@ -1018,7 +1032,7 @@ func (sl StringLiteral) equal(other StringLiteral) bool {
 		return false
 	}
 	return sl.Value == other.Value && sl.Multiline == other.Multiline &&
-		sl.SingleQuote == other.SingleQuote && sl.LanguageTag == other.LanguageTag
+		sl.SingleQuote == other.SingleQuote && sl.LanguageTag == other.LanguageTag && sl.Direction == other.Direction
 }
 
 func (sl StringLiteral) literal() {}
@@ -41,6 +41,28 @@ func Example_example1() {
 	// <#spiderman> a foaf:Person ; foaf:name "Spiderman", "Человек-паук"@ru ; rel:enemyOf <#green-goblin> .
 }
 
+func TestEvaluateDocument_base(t *testing.T) {
+	doc, err := ttl.ParseDocument(`@base <../x/> .
+@prefix p: <y/> .
+<#s> p:p <?q>, <//h/z>, <./../a:b> .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triples, err := ttl.EvaluateDocument(doc, "http://a/b/c/d;p?q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := nt.ParseDocument(`<http://a/b/x/#s> <http://a/b/x/y/p> <http://a/b/x/?q> .
+<http://a/b/x/#s> <http://a/b/x/y/p> <http://h/z> .
+<http://a/b/x/#s> <http://a/b/x/y/p> <http://a/b/a:b> .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !triples.Equal(expected) {
+		t.Error(triples)
+	}
+}
+
 func TestDocument_sort(t *testing.T) {
 	base := ttl.Base("base")
 	prefix := ttl.Prefix{
@@ -0,0 +1,495 @@
+package turtle
+
+import (
+	"fmt"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	"github.com/0x51-dev/rdf/turtle/grammar"
+	"github.com/0x51-dev/upeg/parser"
+	"github.com/0x51-dev/upeg/parser/op"
+	"sort"
+	"strings"
+)
+
+const (
+	rdfFirst = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfNil   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	rdfRest  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfType  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+	xsdBoolean = "http://www.w3.org/2001/XMLSchema#boolean"
+	xsdDecimal = "http://www.w3.org/2001/XMLSchema#decimal"
+	xsdDouble  = "http://www.w3.org/2001/XMLSchema#double"
+	xsdInteger = "http://www.w3.org/2001/XMLSchema#integer"
+
+	// indentation of one nesting level.
+	indentation = "    "
+)
+
+// Encode serializes the triples of the document as (pretty) Turtle. Triples are grouped by subject and predicate, IRIs
+// are abbreviated using the given prefixes (e.g. "foaf" -> "http://xmlns.com/foaf/0.1/"), blank nodes that are only
+// referenced once are nested and well-formed lists are written as collections.
+func Encode(doc nt.Document, prefixes map[string]string) (string, error) {
+	e, err := NewEncoder(prefixes)
+	if err != nil {
+		return "", err
+	}
+	body, err := e.EncodeTriples(doc, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if directives := e.Directives(); directives != "" {
+		if body == "" {
+			return directives, nil
+		}
+		return directives + "\n" + body, nil
+	}
+	return body, nil
+}
+
+func isBlankNode(v any) bool {
+	switch v.(type) {
+	case nt.BlankNode, *nt.BlankNode:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNil(v any) bool {
+	switch v := v.(type) {
+	case nt.IRIReference:
+		return v == rdfNil
+	case *nt.IRIReference:
+		return v != nil && *v == rdfNil
+	default:
+		return false
+	}
+}
+
+// matches returns true if the given operator matches the complete value.
+func matches(v string, o any) bool {
+	if len(v) == 0 {
+		return false
+	}
+	p, err := parser.New([]rune(v))
+	if err != nil {
+		return false
+	}
+	_, err = p.Match(op.And{o, op.EOF{}})
+	return err == nil
+}
+
+// Encoder serializes triples as Turtle statements. It keeps track of the prefixes that are used, so only those need to
+// be declared.
+type Encoder struct {
+	names    []string
+	prefixes map[string]string
+	used     map[string]bool
+}
+
+// NewEncoder returns a new encoder that abbreviates IRIs with the given prefixes. The prefix names can be given with or
+// without the trailing colon.
+func NewEncoder(prefixes map[string]string) (*Encoder, error) {
+	e := &Encoder{
+		prefixes: make(map[string]string),
+		used:     make(map[string]bool),
+	}
+	for name, iri := range prefixes {
+		name = strings.TrimSuffix(name, ":") + ":"
+		if !matches(name, grammar.PNAME_NS) {
+			return nil, fmt.Errorf("encode: invalid prefix name %q", name)
+		}
+		e.names = append(e.names, name)
+		e.prefixes[name] = iri
+	}
+	sort.Strings(e.names)
+	return e, nil
+}
+
+// Directives returns the prefix directives of all the prefixes that were used so far.
+func (e *Encoder) Directives() string {
+	var b strings.Builder
+	for _, name := range e.names {
+		if e.used[name] {
+			b.WriteString(Prefix{Name: name, IRI: e.prefixes[name]}.String())
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// EncodeTriples serializes the given triples as Turtle statements, each line is prefixed with the given indentation.
+// Blank nodes for which shared returns true are never nested, e.g. because they are also referenced outside the given
+// triples. The shared function can be nil.
+func (e *Encoder) EncodeTriples(d nt.Document, indent string, shared func(nt.BlankNode) bool) (string, error) {
+	w := &writer{
+		Encoder:  e,
+		indent:   indent,
+		subjects: make(map[string][]nt.Triple),
+		refs:     make(map[string]int),
+		via:      make(map[string]string),
+		shared:   make(map[string]bool),
+		nested:   make(map[string]bool),
+		lists:    make(map[string]*list),
+		members:  make(map[string]string),
+	}
+	if err := w.index(d, shared); err != nil {
+		return "", err
+	}
+	w.findLists()
+
+	// Nested blank nodes that are not reachable from any root are part of a cycle, these need a label.
+	for {
+		reached := make(map[string]bool)
+		for _, k := range w.keys {
+			if !w.nested[k] {
+				w.reach(k, reached)
+			}
+		}
+		var cycle string
+		for _, k := range w.keys {
+			if w.nested[k] && !reached[k] {
+				cycle = k
+				break
+			}
+		}
+		if cycle == "" {
+			break
+		}
+		w.unnest(cycle)
+	}
+
+	var roots []string
+	for _, k := range w.keys {
+		if !w.nested[k] && len(w.subjects[k]) != 0 {
+			roots = append(roots, k)
+		}
+	}
+	sort.Strings(roots)
+
+	var b strings.Builder
+	for i, k := range roots {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		s, err := w.statement(k)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+// IRI returns the prefixed name of the IRI if possible, otherwise the full IRI.
+func (e *Encoder) IRI(r nt.IRIReference) string {
+	var name, local string
+	for _, n := range e.names {
+		ns := e.prefixes[n]
+		if !strings.HasPrefix(string(r), ns) || (name != "" && len(ns) <= len(e.prefixes[name])) {
+			continue
+		}
+		l := strings.TrimPrefix(string(r), ns)
+		if l == "" || matches(l, grammar.PN_LOCAL) {
+			name, local = n, l
+		}
+	}
+	if name == "" {
+		return r.String()
+	}
+	e.used[name] = true
+	return name + local
+}
+
+// literal returns the shortest representation of the literal that evaluates to the same literal.
+func (e *Encoder) literal(l nt.Literal) string {
+	if l.Reference != nil {
+		switch string(*l.Reference) {
+		case xsdInteger:
+			if matches(l.Value, grammar.Integer) {
+				return l.Value
+			}
+		case xsdDecimal:
+			if matches(l.Value, grammar.Decimal) {
+				return l.Value
+			}
+		case xsdDouble:
+			if matches(l.Value, grammar.Double) {
+				return l.Value
+			}
+		case xsdBoolean:
+			if l.Value == "true" || l.Value == "false" {
+				return l.Value
+			}
+		}
+	}
+	s := fmt.Sprintf(`"%s"`, l.Value)
+	if l.Reference != nil {
+		return fmt.Sprintf("%s^^%s", s, e.IRI(*l.Reference))
+	}
+	if len(l.Direction) > 0 {
+		return fmt.Sprintf("%s@%s--%s", s, l.Language, l.Direction)
+	}
+	if len(l.Language) > 0 {
+		return fmt.Sprintf("%s@%s", s, l.Language)
+	}
+	return s
+}
+
+// list is a well-formed rdf:first/rdf:rest list.
+type list struct {
+	items []nt.Object
+	// nodes of the list, including the head.
+	nodes []string
+}
+
+// writer contains the state of a single EncodeTriples call.
+type writer struct {
+	*Encoder
+	indent string
+
+	// keys of all the subjects and blank nodes, in order of the document.
+	keys     []string
+	subjects map[string][]nt.Triple
+	// refs is the amount of times a blank node is referenced as object.
+	refs map[string]int
+	// via is the predicate through which a blank node is referenced.
+	via map[string]string
+	// shared blank nodes are referenced outside the triples.
+	shared map[string]bool
+	// nested blank nodes are written in place of their (single) reference.
+	nested map[string]bool
+	// lists are nested blank nodes that are written as collections, indexed by their head.
+	lists map[string]*list
+	// members maps the nodes of a list to the head of the list.
+	members map[string]string
+}
+
+// collection returns the list starting at the given head, if it is well-formed.
+func (w *writer) collection(head string) (*list, bool) {
+	var l list
+	visited := make(map[string]bool)
+	for k := head; ; {
+		if visited[k] || !w.nested[k] {
+			return nil, false
+		}
+		visited[k] = true
+		ts := w.subjects[k]
+		if len(ts) != 2 {
+			return nil, false
+		}
+		var first, rest nt.Object
+		for _, t := range ts {
+			switch t.Predicate {
+			case rdfFirst:
+				first = t.Object
+			case rdfRest:
+				rest = t.Object
+			}
+		}
+		if first == nil || rest == nil {
+			return nil, false
+		}
+		l.items = append(l.items, first)
+		l.nodes = append(l.nodes, k)
+		if isNil(rest) {
+			return &l, true
+		}
+		if !isBlankNode(rest) {
+			return nil, false
+		}
+		k = rest.String()
+	}
+}
+
+// findLists finds all nested blank nodes that are the head of a well-formed list.
+func (w *writer) findLists() {
+	for _, k := range w.keys {
+		if _, ok := w.members[k]; ok || !w.nested[k] || w.via[k] == rdfRest {
+			continue
+		}
+		if l, ok := w.collection(k); ok {
+			w.lists[k] = l
+			for _, n := range l.nodes {
+				w.members[n] = k
+			}
+		}
+	}
+}
+
+// index groups the triples by subject and counts the blank node references.
+func (w *writer) index(d nt.Document, shared func(nt.BlankNode) bool) error {
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if _, ok := w.subjects[k]; !ok {
+			w.keys = append(w.keys, k)
+			w.subjects[k] = nil
+		}
+	}
+	for _, t := range d {
+		if t.Subject == nil || t.Object == nil {
+			return fmt.Errorf("encode: incomplete triple: %s", t)
+		}
+		if s := t.String(); seen[s] {
+			continue
+		} else {
+			seen[s] = true
+		}
+		k := t.Subject.String()
+		add(k)
+		w.subjects[k] = append(w.subjects[k], t)
+		if isBlankNode(t.Object) {
+			k := t.Object.String()
+			add(k)
+			w.refs[k]++
+			w.via[k] = string(t.Predicate)
+		}
+	}
+	for _, k := range w.keys {
+		if !strings.HasPrefix(k, "_:") {
+			continue
+		}
+		if shared != nil && shared(nt.BlankNode(strings.TrimPrefix(k, "_:"))) {
+			w.shared[k] = true
+			continue
+		}
+		w.nested[k] = w.refs[k] == 1
+	}
+	return nil
+}
+
+func (w *writer) line(level int) string {
+	return w.indent + strings.Repeat(indentation, level)
+}
+
+func (w *writer) object(o nt.Object, level int) (string, error) {
+	switch o := o.(type) {
+	case nt.IRIReference:
+		if o == rdfNil {
+			return "()", nil
+		}
+		return w.IRI(o), nil
+	case *nt.IRIReference:
+		return w.object(*o, level)
+	case nt.BlankNode, *nt.BlankNode:
+		k := o.String()
+		if !w.nested[k] {
+			return k, nil
+		}
+		if l, ok := w.lists[k]; ok {
+			var items []string
+			for _, i := range l.items {
+				v, err := w.object(i, level)
+				if err != nil {
+					return "", err
+				}
+				items = append(items, v)
+			}
+			return fmt.Sprintf("( %s )", strings.Join(items, " ")), nil
+		}
+		if len(w.subjects[k]) == 0 {
+			return "[]", nil
+		}
+		pol, err := w.predicateObjectList(w.subjects[k], level+1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[\n%s%s\n%s]", w.line(level+1), pol, w.line(level)), nil
+	case nt.Literal:
+		return w.literal(o), nil
+	case *nt.Literal:
+		return w.literal(*o), nil
+	default:
+		return "", fmt.Errorf("encode: unknown object type %T", o)
+	}
+}
+
+// predicateObjectList returns the predicates and objects, grouped by predicate. The first predicate is not indented.
+func (w *writer) predicateObjectList(ts []nt.Triple, level int) (string, error) {
+	var predicates []nt.IRIReference
+	objects := make(map[nt.IRIReference][]nt.Object)
+	for _, t := range ts {
+		if _, ok := objects[t.Predicate]; !ok {
+			predicates = append(predicates, t.Predicate)
+		}
+		objects[t.Predicate] = append(objects[t.Predicate], t.Object)
+	}
+	sort.Slice(predicates, func(i, j int) bool {
+		if predicates[i] == rdfType || predicates[j] == rdfType {
+			return predicates[i] == rdfType
+		}
+		return predicates[i] < predicates[j]
+	})
+
+	var pol []string
+	for _, p := range predicates {
+		verb := "a"
+		if p != rdfType {
+			verb = w.IRI(p)
+		}
+		os := objects[p]
+		sort.Slice(os, func(i, j int) bool {
+			return os[i].String() < os[j].String()
+		})
+		var ol []string
+		for _, o := range os {
+			s, err := w.object(o, level)
+			if err != nil {
+				return "", err
+			}
+			ol = append(ol, s)
+		}
+		pol = append(pol, fmt.Sprintf("%s %s", verb, strings.Join(ol, ", ")))
+	}
+	return strings.Join(pol, fmt.Sprintf(" ;\n%s", w.line(level))), nil
+}
+
+// reach marks the subject and all the blank nodes that are nested within it.
+func (w *writer) reach(k string, reached map[string]bool) {
+	if reached[k] {
+		return
+	}
+	reached[k] = true
+	for _, t := range w.subjects[k] {
+		if isBlankNode(t.Object) {
+			if o := t.Object.String(); w.nested[o] {
+				w.reach(o, reached)
+			}
+		}
+	}
+}
+
+// statement returns the statement of the given (root) subject.
+func (w *writer) statement(k string) (string, error) {
+	ts := w.subjects[k]
+	var subject string
+	switch s := ts[0].Subject.(type) {
+	case nt.IRIReference:
+		subject = w.IRI(s)
+	case *nt.IRIReference:
+		subject = w.IRI(*s)
+	case nt.BlankNode, *nt.BlankNode:
+		subject = k
+		if w.refs[k] == 0 && !w.shared[k] {
+			subject = "[]"
+		}
+	default:
+		return "", fmt.Errorf("encode: unknown subject type %T", s)
+	}
+	pol, err := w.predicateObjectList(ts, 1)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s %s .\n", w.indent, subject, pol), nil
+}
+
+// unnest makes sure the blank node is written with its label, the list it is part of is no longer a collection.
+func (w *writer) unnest(k string) {
+	w.nested[k] = false
+	if head, ok := w.members[k]; ok {
+		for _, n := range w.lists[head].nodes {
+			delete(w.members, n)
+		}
+		delete(w.lists, head)
+	}
+}
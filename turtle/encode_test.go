@@ -0,0 +1,127 @@
+package turtle_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/rdf/internal/testsuite"
+	nt "github.com/0x51-dev/rdf/ntriples"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"testing"
+)
+
+func ExampleEncode() {
+	doc, _ := nt.ParseDocument(`<http://example.org/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://xmlns.com/foaf/0.1/Person> .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/age> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/knows> _:bob .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/knows> <http://example.org/carol> .
+_:bob <http://xmlns.com/foaf/0.1/name> "Bob"@en .
+_:bob <http://xmlns.com/foaf/0.1/nick> _:l1 .
+_:l1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "bobby" .
+_:l1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:l2 .
+_:l2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "bob" .
+_:l2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+`)
+	s, _ := ttl.Encode(doc, map[string]string{
+		"ex":   "http://example.org/",
+		"foaf": "http://xmlns.com/foaf/0.1/",
+		"xsd":  "http://www.w3.org/2001/XMLSchema#",
+	})
+	fmt.Print(s)
+	// Output:
+	// @prefix ex: <http://example.org/> .
+	// @prefix foaf: <http://xmlns.com/foaf/0.1/> .
+	//
+	// ex:alice a foaf:Person ;
+	//     foaf:age 42 ;
+	//     foaf:knows ex:carol, [
+	//         foaf:name "Bob"@en ;
+	//         foaf:nick ( "bobby" "bob" )
+	//     ] ;
+	//     foaf:name "Alice" .
+}
+
+func TestEncode(t *testing.T) {
+	manifest, err := testsuite.LoadManifest(rawManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixes := map[string]string{
+		"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+		"ex":  "http://example.org/ns#",
+	}
+	for _, k := range manifest.Keys {
+		e := manifest.Entries[k]
+		if e.Type != "rdft:TestTurtleEval" {
+			continue
+		}
+		t.Run(e.Name, func(t *testing.T) {
+			raw, err := suite.ReadFile(fmt.Sprintf("testdata/suite/%s", e.Action))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := ttl.ParseDocument(string(raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			cwd := fmt.Sprintf("http://www.w3.org/2013/TurtleTests/%s.ttl", e.Name)
+			ntr, err := ttl.EvaluateDocument(doc, cwd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := ttl.Encode(ntr, prefixes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc2, err := ttl.ParseDocument(s)
+			if err != nil {
+				t.Fatal(s, err)
+			}
+			ntr2, err := ttl.EvaluateDocument(doc2, cwd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ntr.Equal(ntr2) {
+				t.Fatal(ntr, "\n", s, "\n", ntr2)
+			}
+		})
+	}
+}
+
+func TestEncode_quotes(t *testing.T) {
+	doc, err := ttl.ParseDocument(`<http://example.org/s> <http://example.org/p> "a \"b\" 'c'", 'd "e" \'f\'', """g "h" 'i'""", "\\" .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triples, err := ttl.EvaluateDocument(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := nt.ParseDocument(`<http://example.org/s> <http://example.org/p> "a \"b\" 'c'" .
+<http://example.org/s> <http://example.org/p> "d \"e\" \'f\'" .
+<http://example.org/s> <http://example.org/p> "g \"h\" 'i'" .
+<http://example.org/s> <http://example.org/p> "\\" .`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !triples.Equal(expected) {
+		t.Fatal(triples)
+	}
+
+	s, err := ttl.Encode(triples, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc2, err := ttl.ParseDocument(s)
+	if err != nil {
+		t.Fatal(s, err)
+	}
+	triples2, err := ttl.EvaluateDocument(doc2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !triples.Equal(triples2) {
+		t.Fatal(s, "\n", triples2)
+	}
+}
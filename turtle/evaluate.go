@@ -2,6 +2,7 @@ package turtle
 
 import (
 	"fmt"
+	"github.com/0x51-dev/rdf/iri"
 	nt "github.com/0x51-dev/rdf/ntriples"
 	"github.com/0x51-dev/rdf/ntriples/grammar"
 	"github.com/0x51-dev/upeg/parser"
@@ -13,10 +14,9 @@ import (
 )
 
 func (ctx *Context) EvaluateBlankNodePropertyList(pl BlankNodePropertyList) ([]nt.Object, []nt.Triple, error) {
-	var objects []nt.Object
 	var triples []nt.Triple
+	bn := ctx.bn()
 	for _, n := range pl {
-		bn := ctx.bn()
 		p, os, ts, err := ctx.EvaluatePredicateObject(n)
 		if err != nil {
 			return nil, nil, err
@@ -29,9 +29,8 @@ func (ctx *Context) EvaluateBlankNodePropertyList(pl BlankNodePropertyList) ([]n
 				Object:    o,
 			})
 		}
-		objects = append(objects, &bn)
 	}
-	return objects, triples, nil
+	return []nt.Object{&bn}, triples, nil
 }
 
 func (ctx *Context) EvaluateBooleanLiteral(o *BooleanLiteral) (*nt.Literal, error) {
@@ -57,39 +56,32 @@ func (ctx *Context) EvaluateCollection(c Collection) (nt.Object, []nt.Triple, er
 		o := nt.IRIReference("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil")
 		return &o, triples, nil
 	}
-	var first, el nt.BlankNode
+	elements := make([]nt.BlankNode, len(objects))
+	for i := range elements {
+		elements[i] = ctx.el()
+	}
 	for i, o := range objects {
-		e := ctx.el()
-		if i == 0 {
-			first = e
-		}
 		triples = append(triples, nt.Triple{
-			Subject:   &e,
+			Subject:   &elements[i],
 			Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
 			Object:    o,
 		})
+		var rest nt.Object = nt.IRIReference("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil")
 		if i+1 != len(objects) {
-			el = ctx.el()
-			triples = append(triples, nt.Triple{
-				Subject:   &e,
-				Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-				Object:    &el,
-			})
-		} else {
-			el = e
+			rest = &elements[i+1]
 		}
+		triples = append(triples, nt.Triple{
+			Subject:   &elements[i],
+			Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
+			Object:    rest,
+		})
 	}
-	triples = append(triples, nt.Triple{
-		Subject:   &el,
-		Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-		Object:    nt.IRIReference("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"),
-	})
-	return &first, triples, nil
+	return &elements[0], triples, nil
 }
 
-func (ctx *Context) EvaluateIRI(iri *IRI) (*nt.IRIReference, error) {
-	if !iri.Prefixed {
-		v := iri.Value
+func (ctx *Context) EvaluateIRI(i *IRI) (*nt.IRIReference, error) {
+	if !i.Prefixed {
+		v := i.Value
 
 		// Validate IRI.
 		if strings.Contains(v, "\\u") || strings.Contains(v, "\\U") {
@@ -108,22 +100,17 @@ func (ctx *Context) EvaluateIRI(iri *IRI) (*nt.IRIReference, error) {
 		}
 
 		r := strings.ReplaceAll(v, "\\", "")
-		if !strings.Contains(r, ":") {
-			base := ctx.Base
-			if !strings.HasSuffix(base, "/") && !strings.HasSuffix(base, "#") {
-				i := strings.LastIndex(base, "/")
-				base = base[:i+1]
-			}
-			ref := nt.IRIReference(fmt.Sprintf("%s%s", base, r))
+		if !iri.IsAbsolute(r) {
+			ref := nt.IRIReference(iri.Resolve(ctx.Base, r))
 			return &ref, nil
 		}
 		ref := nt.IRIReference(r)
 		return &ref, nil
 	}
 
-	p := strings.SplitAfterN(iri.Value, ":", 2)
+	p := strings.SplitAfterN(i.Value, ":", 2)
 	if len(p) != 2 {
-		return nil, fmt.Errorf("invalid prefixed IRI %q", iri.Value)
+		return nil, fmt.Errorf("invalid prefixed IRI %q", i.Value)
 	}
 	prefix, ok := ctx.Prefixes[p[0]]
 	if !ok {
@@ -258,12 +245,8 @@ func (ctx *Context) EvaluateStringLiteral(o *StringLiteral) (*nt.Literal, error)
 	if o.Multiline {
 		v = strings.ReplaceAll(v, "\n", "\\n")
 		v = strings.ReplaceAll(v, "\r", "\\r")
-		v = strings.ReplaceAll(v, "\\\"", "\"")
 	}
-	v = strings.ReplaceAll(v, "\"", "\\\"")
 	v = strings.ReplaceAll(v, "\t", "\\t")
-	v = strings.ReplaceAll(v, "\\b", "\\u0008")
-	v = strings.ReplaceAll(v, "\\f", "\\u000C")
 
 	var esc string
 	var unicodeCount int
@@ -280,9 +263,13 @@ func (ctx *Context) EvaluateStringLiteral(o *StringLiteral) (*nt.Literal, error)
 				unicodeCount = 4
 			case 'U':
 				unicodeCount = 8
+			case 'b':
+				esc += "\\u0008"
+			case 'f':
+				esc += "\\u000C"
 			default:
+				// Other escape sequences, including \", are valid in N-Triples and are kept as is.
 				esc += "\\" + string(c)
-
 			}
 			escaped = false
 			continue
@@ -305,7 +292,10 @@ func (ctx *Context) EvaluateStringLiteral(o *StringLiteral) (*nt.Literal, error)
 			continue
 		}
 
-		if c <= 0x1F {
+		if c == '"' {
+			// Unescaped quotes only occur in single quoted and long strings.
+			esc += "\\\""
+		} else if c <= 0x1F {
 			esc += fmt.Sprintf("\\u%04X", c)
 		} else if 0x7F <= c && c <= 0xFFFF {
 			esc += fmt.Sprintf("\\u%04X", c)
@@ -319,9 +309,15 @@ func (ctx *Context) EvaluateStringLiteral(o *StringLiteral) (*nt.Literal, error)
 	v = esc
 
 	if o.LanguageTag != "" {
+		if o.Direction != "" {
+			if err := ctx.Mode.ValidateDirection(o.Direction); err != nil {
+				return nil, err
+			}
+		}
 		return &nt.Literal{
-			Value:    v,
-			Language: o.LanguageTag,
+			Value:     v,
+			Language:  o.LanguageTag,
+			Direction: o.Direction,
 		}, nil
 	}
 	if o.DatatypeIRI != nil {
@@ -359,46 +355,12 @@ func (ctx *Context) EvaluateTriple(t *Triple) ([]nt.Triple, error) {
 				subject = &bn
 			}
 		case Collection:
-			var objects []nt.Object
-			for _, o := range t {
-				os, ts, err := ctx.EvaluateObject(o)
-				if err != nil {
-					return nil, err
-				}
-				objects = append(objects, os...)
-				triples = append(triples, ts...)
-			}
-			if len(objects) == 0 {
-				return triples, nil
-			}
-
-			var el nt.BlankNode
-			for i, o := range objects {
-				e := ctx.el()
-				if i == 0 {
-					subject = e
-				}
-				triples = append(triples, nt.Triple{
-					Subject:   &e,
-					Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#first",
-					Object:    o,
-				})
-				if i+1 != len(objects) {
-					el = ctx.el()
-					triples = append(triples, nt.Triple{
-						Subject:   &e,
-						Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-						Object:    &el,
-					})
-				} else {
-					el = e
-				}
+			o, ts, err := ctx.EvaluateCollection(t)
+			if err != nil {
+				return nil, err
 			}
-			triples = append(triples, nt.Triple{
-				Subject:   &el,
-				Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest",
-				Object:    nt.IRIReference("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"),
-			})
+			triples = append(triples, ts...)
+			subject = o.(nt.Subject)
 		default:
 			panic(fmt.Errorf("unknown subject type %T", t))
 		}
@@ -457,16 +419,9 @@ func (ctx *Context) evaluateDocument(d Document, cwd string) (nt.Document, error
 	for _, t := range d {
 		switch t := t.(type) {
 		case *Base:
-			if s := string(*t); !strings.Contains(s, ":") {
-				ctx.Base = fmt.Sprintf("%s%s", ctx.Base, s)
-			} else {
-				ctx.Base = s
-			}
+			ctx.Base = iri.Resolve(ctx.Base, string(*t))
 		case *Prefix:
-			if !strings.Contains(t.IRI, ":") {
-				t.IRI = fmt.Sprintf("%s%s", ctx.Base, t.IRI)
-			}
-			ctx.Prefixes[t.Name] = t.IRI
+			ctx.Prefixes[t.Name] = iri.Resolve(ctx.Base, t.IRI)
 		case *Triple:
 			ts, err := ctx.EvaluateTriple(t)
 			if err != nil {
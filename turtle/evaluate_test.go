@@ -0,0 +1,50 @@
+package turtle_test
+
+import (
+	nt "github.com/0x51-dev/rdf/ntriples"
+	ttl "github.com/0x51-dev/rdf/turtle"
+	"sort"
+	"testing"
+)
+
+func TestEvaluateDocument_blankNodePropertyList(t *testing.T) {
+	// All predicate-object pairs of the property list share the same blank node.
+	evaluate(t, `<http://example.org/s> <http://example.org/p> [ <http://example.org/a> 1 ; <http://example.org/b> 2 ] .`, `
+_:b1 <http://example.org/a> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:b1 <http://example.org/b> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/s> <http://example.org/p> _:b1 .
+`)
+}
+
+func TestEvaluateDocument_collection(t *testing.T) {
+	// Every element links to the next one, the last one to rdf:nil.
+	evaluate(t, `( 1 2 3 ) <http://example.org/p> () .`, `
+_:el1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:el2 .
+_:el2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "2"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:el3 .
+_:el3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "3"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:el3 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:el1 <http://example.org/p> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+`)
+}
+
+func evaluate(t *testing.T, raw, expected string) {
+	t.Helper()
+	doc, err := ttl.ParseDocument(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triples, err := ttl.EvaluateDocument(doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := nt.ParseDocument(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Sort(triples)
+	if !triples.Equal(e) {
+		t.Error(triples)
+	}
+}
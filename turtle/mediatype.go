@@ -0,0 +1,4 @@
+package turtle
+
+// MediaType is the media type of Turtle documents.
+const MediaType = "text/turtle"